@@ -15,17 +15,48 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/subscriptions": {
+        "/analytics/spend": {
             "get": {
-                "description": "Retrieve subscriptions with optional filtering",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aggregate the charges of the subscriptions within a date range into one amount per month, optionally broken down by service, user or catalog category. Every month of the range has at least one point; months without charges have a zero amount. Without target_currency a month has one point per currency charged.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "analytics"
                 ],
-                "summary": "List subscriptions",
+                "summary": "Get monthly spend series",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date in MM-YYYY format",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in MM-YYYY format",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "month",
+                            "service",
+                            "user",
+                            "category"
+                        ],
+                        "type": "string",
+                        "description": "Breakdown of every month (default: month)",
+                        "name": "group_by",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Filter by user ID (UUID)",
@@ -33,92 +64,99 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Filter by service name",
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names",
                         "name": "service_name",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Number of results to return (default: 50)",
-                        "name": "limit",
+                        "description": "Minimum price",
+                        "name": "price_min",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Number of results to skip (default: 0)",
-                        "name": "offset",
+                        "description": "Maximum price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions active in this month (MM-YYYY)",
+                        "name": "active_on",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended",
+                            "upcoming"
+                        ],
+                        "type": "string",
+                        "description": "Status relative to the current month",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting after this month (MM-YYYY)",
+                        "name": "started_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting before this month (MM-YYYY)",
+                        "name": "started_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)",
+                        "name": "trial_ends_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO-4217 currency to convert every amount into",
+                        "name": "target_currency",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Subscriptions retrieved successfully",
+                        "description": "Spend series calculated successfully",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Subscription"
+                                "$ref": "#/definitions/models.SpendPoint"
                             }
                         }
                     },
                     "400": {
-                        "description": "Bad Request - Invalid query parameters",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error - Failed to retrieve subscriptions",
+                        "description": "Bad Request - Invalid date format, group_by or missing required parameters",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            },
-            "post": {
-                "description": "Create a new subscription for a user",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "subscriptions"
-                ],
-                "summary": "Create a new subscription",
-                "parameters": [
-                    {
-                        "description": "Subscription data",
-                        "name": "subscription",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateSubscriptionRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Subscription created successfully",
-                        "schema": {
-                            "$ref": "#/definitions/models.Subscription"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request - Invalid input data or validation errors",
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict - Duplicate subscription or constraint violation",
+                    "403": {
+                        "description": "Forbidden - Access to another user's subscriptions",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error - Database or server errors",
+                        "description": "Internal Server Error - Database query failed or server errors",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -126,59 +164,108 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions/calculate-cost": {
+        "/audit": {
             "get": {
-                "description": "Calculate the total cost of subscriptions within a date range",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the audit events matching the filters, newest first. Regular users only see events of their own subscriptions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "audit"
                 ],
-                "summary": "Calculate total cost of subscriptions",
+                "summary": "List audit events",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by subscription ID",
+                        "name": "subscription_id",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Start date in MM-YYYY format",
-                        "name": "start_date",
-                        "in": "query",
-                        "required": true
+                        "description": "Filter by the subscriptions' owner (UUID format)",
+                        "name": "user_id",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "End date in MM-YYYY format",
-                        "name": "end_date",
-                        "in": "query",
-                        "required": true
+                        "description": "Filter by actor, e.g. a user ID or an X-Actor value",
+                        "name": "actor",
+                        "in": "query"
                     },
                     {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete",
+                            "restore",
+                            "purge"
+                        ],
                         "type": "string",
-                        "description": "Filter by user ID (UUID)",
-                        "name": "user_id",
+                        "description": "Filter by action",
+                        "name": "action",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by service name",
-                        "name": "service_name",
+                        "description": "Only events at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to return (default: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip (default: 0)",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Cost calculation completed successfully",
+                        "description": "Audit events retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.CostCalculationResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEvent"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad Request - Invalid date format or missing required parameters",
+                        "description": "Bad Request - Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Cannot access another user's events",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error - Database query failed or server errors",
+                        "description": "Internal Server Error - Database query failed",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -186,48 +273,72 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions/{id}": {
+        "/budgets": {
             "get": {
-                "description": "Retrieve a single subscription by its ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the budgets of a user ordered by ID; regular users only see their own",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Get subscription by ID",
+                "summary": "List budgets",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subscription ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Filter by user ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Subscription retrieved successfully",
+                        "description": "Budgets retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.Subscription"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Budget"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad Request - Invalid subscription ID format",
+                        "description": "Bad Request - Invalid user_id",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found - Subscription not found",
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Access to another user's budgets",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database query failed",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Update subscription details by ID",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set a monthly spending limit over every subscription of a user, those of a catalog category or those of one service. The user defaults to the caller. Creating a subscription or changing its price so that the current or one of the next two months goes over the limit publishes a budget.exceeded webhook event.",
                 "consumes": [
                     "application/json"
                 ],
@@ -235,49 +346,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Update an existing subscription",
+                "summary": "Create budget",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Subscription ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Fields to update",
-                        "name": "updates",
+                        "description": "Budget",
+                        "name": "budget",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.CreateBudgetRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Subscription updated successfully",
+                    "201": {
+                        "description": "Budget created successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.Subscription"
+                            "$ref": "#/definitions/models.Budget"
                         }
                     },
                     "400": {
-                        "description": "Bad Request - Invalid input data or validation errors",
+                        "description": "Bad Request - Invalid scope, category, service, limit or currency",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found - Subscription does not exist",
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Budget for another user",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "Conflict - Constraint violation during update",
+                        "description": "Conflict - The user already has a budget with this scope",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -289,76 +398,3682 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a subscription by its ID",
+            }
+        },
+        "/budgets/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Report the spend of every budget of a user in the current month and the months after it, costed like a cost calculation of each month and converted into the budget's currency",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Delete subscription by ID",
+                "summary": "Get budget status",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by user ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Subscription ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Number of months, the current one included (default: 3, max: 12)",
+                        "name": "months",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Subscription deleted successfully"
+                    "200": {
+                        "description": "Budget status calculated successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BudgetStatus"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Bad Request - Invalid subscription ID format",
+                        "description": "Bad Request - Invalid query parameters or missing exchange rate",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found - Subscription not found",
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Access to another user's budgets",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error - Database or server errors",
+                        "description": "Internal Server Error - Database query failed",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "/budgets/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a budget of the caller",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Get budget by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Budget retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Budget"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid budget ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Budget not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the monthly limit or currency of a budget. The scope cannot be changed; delete the budget and create another one instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Update budget",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateBudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Budget updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Budget"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input data or validation errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Budget not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a budget of the caller",
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Delete budget",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Budget deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid budget ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Budget not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exchange-rates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the exchange rates used to convert cost reports (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange-rates"
+                ],
+                "summary": "List exchange rates",
+                "responses": {
+                    "200": {
+                        "description": "Exchange rates retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExchangeRate"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database query failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exchange-rates/{from}/{to}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create or replace the rate converting one currency into another (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange-rates"
+                ],
+                "summary": "Set exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Source ISO-4217 currency code",
+                        "name": "from",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target ISO-4217 currency code",
+                        "name": "to",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Exchange rate",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetExchangeRateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exchange rate stored successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExchangeRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid currency or rate",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the rate converting one currency into another (admin only)",
+                "tags": [
+                    "exchange-rates"
+                ],
+                "summary": "Delete exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Source ISO-4217 currency code",
+                        "name": "from",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target ISO-4217 currency code",
+                        "name": "to",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Exchange rate deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid currency",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Exchange rate does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/services": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the catalogued services ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "List catalog services",
+                "parameters": [
+                    {
+                        "enum": [
+                            "streaming",
+                            "music",
+                            "video",
+                            "cloud",
+                            "software",
+                            "gaming",
+                            "news",
+                            "education",
+                            "fitness",
+                            "other"
+                        ],
+                        "type": "string",
+                        "description": "Filter by category",
+                        "name": "category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Services retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Service"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid category",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database query failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a service to the catalog (admin only). Subscriptions created under its name or one of its aliases, in any case and spacing, are linked to it, take its canonical name and, when created without a price, its default price in its currency.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Create catalog service",
+                "parameters": [
+                    {
+                        "description": "Catalog service",
+                        "name": "service",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateServiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Service created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid name, alias, category, price or currency",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Name or alias already belongs to another service",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a catalogued service with its aliases",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Get catalog service by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Service retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid service ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Service not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the name, aliases, category, default price or currency of a catalogued service (admin only). A new aliases list replaces the old one; default_price may be null to clear it. Subscriptions keep the name they were created with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Update catalog service",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateServiceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Service updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input data or validation errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Service not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Name or alias already belongs to another service",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a service and its aliases from the catalog (admin only). Subscriptions linked to it keep their name and lose the link.",
+                "tags": [
+                    "services"
+                ],
+                "summary": "Delete catalog service",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Service deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid service ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Service not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a page of subscriptions ordered by creation time, with optional filtering. service_name matches whole names ignoring case.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by user ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum price",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions active in this month (MM-YYYY)",
+                        "name": "active_on",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended",
+                            "upcoming"
+                        ],
+                        "type": "string",
+                        "description": "Status relative to the current month",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting after this month (MM-YYYY)",
+                        "name": "started_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting before this month (MM-YYYY)",
+                        "name": "started_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)",
+                        "name": "trial_ends_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields (price, start_date, end_date, service_name, created_at), prefix with - for descending, e.g. price,-start_date",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to return (default: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from next_cursor or prev_cursor of a previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip when no cursor is given (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the total number of matching subscriptions",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscriptions retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SubscriptionPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Access to another user's subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Failed to retrieve subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new subscription for a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create a new subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key of this create; retries with the same key and body replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Subscription data",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Subscription created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the response was replayed for a retried Idempotency-Key"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input data or validation errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Access to another user's subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Duplicate subscription, constraint violation or Idempotency-Key still in use",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity - Idempotency-Key reused with a different body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/calculate-cost": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Calculate the total cost of subscriptions within a date range",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Calculate total cost of subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date in MM-YYYY format",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date in MM-YYYY format",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by user ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum price",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions active in this month (MM-YYYY)",
+                        "name": "active_on",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended",
+                            "upcoming"
+                        ],
+                        "type": "string",
+                        "description": "Status relative to the current month",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting after this month (MM-YYYY)",
+                        "name": "started_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting before this month (MM-YYYY)",
+                        "name": "started_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)",
+                        "name": "trial_ends_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order of the listed subscriptions, e.g. price,-start_date",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO-4217 currency to convert the total into",
+                        "name": "target_currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Response format; csv returns one row per subscription with its charges and subtotal (default: json)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cost calculation completed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.CostCalculationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid date format or missing required parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Access to another user's subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database query failed or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download every subscription matching the filters of GET /subscriptions, without paging. csv has a header line and one row per subscription, jsonl one JSON subscription per line. ics is an iCalendar feed with a recurring all-day event per subscription that starts on its next charge and repeats every billing cycle until its end date; subscriptions without charges left are left out.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "text/calendar"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Export subscriptions",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl",
+                            "ics"
+                        ],
+                        "type": "string",
+                        "description": "File format (default: csv)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by user ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum price",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions active in this month (MM-YYYY)",
+                        "name": "active_on",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended",
+                            "upcoming"
+                        ],
+                        "type": "string",
+                        "description": "Status relative to the current month",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting after this month (MM-YYYY)",
+                        "name": "started_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting before this month (MM-YYYY)",
+                        "name": "started_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)",
+                        "name": "trial_ends_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields (price, start_date, end_date, service_name, created_at), prefix with - for descending, e.g. price,-start_date",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported subscriptions",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid format or query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Access to another user's subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Failed to retrieve subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a CSV file with a header line naming the columns service_name, price, currency, billing_period, billing_interval_count, user_id, start_date, end_date, trial_end and trial_price (only service_name, price and start_date are required). The other columns of a CSV export are skipped, so an export can be imported again. Every row is validated like POST /subscriptions and imported on its own; rows with errors are listed by line number and not imported. With dry_run=true the rows are only validated and nothing is written. The file is sent either as a text/csv body or as the \"file\" field of a multipart form.",
+                "consumes": [
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Import subscriptions from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file (multipart uploads)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the rows (default: false)",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rows accepted and rejected",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Missing, malformed or too large CSV file",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type - Body is neither text/csv nor multipart/form-data",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a page of subscriptions in the trash, most recently deleted first, with the time each one will be purged permanently. Takes the filters of GET /subscriptions; pages are addressed with offset.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List deleted subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by user ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum price",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum price",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions active in this month (MM-YYYY)",
+                        "name": "active_on",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended",
+                            "upcoming"
+                        ],
+                        "type": "string",
+                        "description": "Status relative to the current month",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting after this month (MM-YYYY)",
+                        "name": "started_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only subscriptions starting before this month (MM-YYYY)",
+                        "name": "started_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)",
+                        "name": "trial_ends_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields (price, start_date, end_date, service_name, created_at), prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to return (default: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the total number of matching subscriptions",
+                        "name": "include_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted subscriptions retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrashPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Access to another user's subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Failed to retrieve subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/upcoming": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the next charge date and amount of every active subscription within the look-ahead window, sorted by date",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List upcoming charges",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by user ID (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Look-ahead window in days (default: 30, max: 366)",
+                        "name": "within_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of charges to return, earliest first (default: 100, max: 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upcoming charges retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UpcomingCharge"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Access to another user's subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Failed to retrieve subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a single subscription by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the expected version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid subscription ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed - If-Match does not match the current ETag",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace every field of a subscription. Optional fields that are omitted are reset to their defaults and an omitted end_date makes the subscription open-ended. user_id may be omitted but cannot be changed. Catalog defaults do not apply: an omitted price is rejected and an omitted currency is reset to RUB.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Replace a subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being replaced",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Subscription data",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription replaced successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input data, unknown or ill-typed fields",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Subscription does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Duplicate subscription or concurrent update",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed - If-Match does not match the current ETag",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type - Body is not json",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a subscription to the trash, from where it can be restored until the retention period ends. Admins can delete a subscription permanently, including one already in the trash, with hard=true.",
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Delete subscription by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Delete permanently instead of moving to the trash (admins only)",
+                        "name": "hard",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being deleted",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Subscription deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid subscription ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - hard=true requested by a non-admin",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Concurrent update",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed - If-Match does not match the current ETag",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply a JSON Merge Patch (RFC 7396) to a subscription. Omitted fields are left unchanged, end_date: null makes the subscription open-ended and trial_end: null ends the trial, resetting trial_price. Unknown or ill-typed fields are rejected.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Partially update a subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version the patch is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input data, unknown or ill-typed fields",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Subscription does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Duplicate subscription or concurrent update",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed - If-Match does not match the current ETag",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type - Body is not merge-patch+json or json",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the audit events of a subscription, newest first. Each event names the actor, the action and the value of every changed field before and after the change. Deleted subscriptions keep their history until they are purged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Get subscription history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to return (default: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "History retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid subscription ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database query failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/prices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the prices of a subscription, oldest first. Each price applies from its effective_from month until the next one, and cost calculations charge every month at the price in effect then.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Get subscription price history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price history retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SubscriptionPrice"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid subscription ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Undo the deletion of a subscription that is still in the trash. Fails with 409 when a subscription for the same user, service and start month was created in the meantime.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Restore a deleted subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the deleted version",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription restored successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the restored subscription"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid subscription ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - No such subscription in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Duplicate subscription or concurrent update",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed - If-Match does not match the current ETag",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions:batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply a list of operations. In atomic mode (default) all of them run in one transaction and the first failure rolls back the batch and is returned with the failed operation's index. In best_effort mode every operation runs on its own and the response reports a status per operation. Create data is validated like POST /subscriptions, update data is a merge patch validated like PATCH /subscriptions/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Create, update and delete subscriptions in bulk",
+                "parameters": [
+                    {
+                        "description": "Operations to apply",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of every operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Malformed operations, too many operations or, in atomic mode, a failed validation",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Access to another user's subscriptions (atomic mode)",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Subscription does not exist (atomic mode)",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Duplicate subscription or concurrent update (atomic mode)",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed - Version mismatch (atomic mode)",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type - Body is not json",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the registered webhook endpoints (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "Webhooks retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookEndpoint"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database query failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register an endpoint that receives signed subscription lifecycle events (admin only). The signing secret is only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook endpoint",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Webhook created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookEndpointWithSecret"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid URL, event type or secret",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a webhook endpoint by its ID (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookEndpoint"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid webhook ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the URL, event types or active flag of a webhook endpoint (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "updates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookEndpoint"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input data or validation errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a webhook endpoint; its pending deliveries are abandoned (admin only)",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Webhook deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid webhook ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database or server errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the delivery attempts of a webhook endpoint, newest first (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by delivery status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to return (default: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deliveries retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid webhook ID or status",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing or invalid bearer token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Database query failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "errs.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "invalid_date_format"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "start_date"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "start_date must be in MM-YYYY format"
+                }
+            }
+        },
+        "models.AuditAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "restore",
+                "purge"
+            ],
+            "x-enum-comments": {
+                "AuditActionDelete": "Moved to the trash",
+                "AuditActionPurge": "Deleted permanently",
+                "AuditActionRestore": "Taken out of the trash"
+            },
+            "x-enum-descriptions": [
+                "Moved to the trash",
+                "Taken out of the trash",
+                "Deleted permanently"
+            ],
+            "x-enum-varnames": [
+                "AuditActionCreate",
+                "AuditActionUpdate",
+                "AuditActionDelete",
+                "AuditActionRestore",
+                "AuditActionPurge"
+            ]
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.AuditAction"
+                        }
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "support@example.com"
+                },
+                "actor_user_id": {
+                    "description": "Authenticated caller, absent for background jobs",
+                    "type": "string"
+                },
+                "changes": {
+                    "description": "Field name to FieldChange",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "user_id": {
+                    "description": "Owner of the subscription",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "models.BatchMode": {
+            "type": "string",
+            "enum": [
+                "atomic",
+                "best_effort"
+            ],
+            "x-enum-comments": {
+                "BatchModeAtomic": "All operations run in one transaction; the first failure rolls everything back",
+                "BatchModeBestEffort": "Every operation runs on its own and reports its own result"
+            },
+            "x-enum-descriptions": [
+                "All operations run in one transaction; the first failure rolls everything back",
+                "Every operation runs on its own and reports its own result"
+            ],
+            "x-enum-varnames": [
+                "BatchModeAtomic",
+                "BatchModeBestEffort"
+            ]
+        },
+        "models.BatchOp": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "BatchOpCreate",
+                "BatchOpUpdate",
+                "BatchOpDelete"
+            ]
+        },
+        "models.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "description": "Subscription to update or delete",
+                    "type": "integer",
+                    "example": 1
+                },
+                "op": {
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BatchOp"
+                        }
+                    ],
+                    "example": "create"
+                },
+                "version": {
+                    "description": "Optional expected version, like If-Match",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.BatchRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "description": "Optional, defaults to atomic",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BatchMode"
+                        }
+                    ],
+                    "example": "atomic"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchOperation"
+                    }
+                }
+            }
+        },
+        "models.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "mode": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BatchMode"
+                        }
+                    ],
+                    "example": "best_effort"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.BatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/models.ErrorResponse"
+                },
+                "index": {
+                    "description": "Position of the operation in the request",
+                    "type": "integer",
+                    "example": 0
+                },
+                "op": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BatchOp"
+                        }
+                    ],
+                    "example": "create"
+                },
+                "status": {
+                    "description": "HTTP status the operation would have had as a single request",
+                    "type": "integer",
+                    "example": 201
+                },
+                "subscription": {
+                    "$ref": "#/definitions/models.Subscription"
+                }
+            }
+        },
+        "models.BillingPeriod": {
+            "type": "string",
+            "enum": [
+                "week",
+                "month",
+                "quarter",
+                "year"
+            ],
+            "x-enum-varnames": [
+                "BillingPeriodWeek",
+                "BillingPeriodMonth",
+                "BillingPeriodQuarter",
+                "BillingPeriodYear"
+            ]
+        },
+        "models.Budget": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Set for category budgets; other also covers services outside the catalog",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ServiceCategory"
+                        }
+                    ],
+                    "example": "streaming"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "monthly_limit": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "scope": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BudgetScope"
+                        }
+                    ],
+                    "example": "category"
+                },
+                "service_name": {
+                    "description": "Set for service budgets, matched ignoring case",
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BudgetMonth": {
+            "type": "object",
+            "properties": {
+                "over_budget": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "period": {
+                    "type": "string",
+                    "example": "03-2025"
+                },
+                "remaining": {
+                    "description": "Negative when over budget",
+                    "type": "integer",
+                    "example": -297
+                },
+                "spent": {
+                    "type": "integer",
+                    "example": 2297
+                }
+            }
+        },
+        "models.BudgetScope": {
+            "type": "string",
+            "enum": [
+                "overall",
+                "category",
+                "service"
+            ],
+            "x-enum-comments": {
+                "BudgetScopeCategory": "Subscriptions to services of a catalog category",
+                "BudgetScopeOverall": "Every subscription of the user",
+                "BudgetScopeService": "Subscriptions to one service"
+            },
+            "x-enum-descriptions": [
+                "Every subscription of the user",
+                "Subscriptions to services of a catalog category",
+                "Subscriptions to one service"
+            ],
+            "x-enum-varnames": [
+                "BudgetScopeOverall",
+                "BudgetScopeCategory",
+                "BudgetScopeService"
+            ]
+        },
+        "models.BudgetStatus": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Set for category budgets; other also covers services outside the catalog",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ServiceCategory"
+                        }
+                    ],
+                    "example": "streaming"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "monthly_limit": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BudgetMonth"
+                    }
+                },
+                "scope": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BudgetScope"
+                        }
+                    ],
+                    "example": "category"
+                },
+                "service_name": {
+                    "description": "Set for service budgets, matched ignoring case",
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
         "models.CostCalculationResponse": {
             "type": "object",
             "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "service_name": {
+                    "type": "string"
+                },
+                "service_names": {
+                    "description": "Set instead of ServiceName when several names were requested",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionCost"
+                    }
+                },
+                "total_cost": {
+                    "description": "TotalCost is expressed in Currency. It is omitted when subscriptions use several\ncurrencies and no target_currency was requested; see Totals instead.",
+                    "type": "integer",
+                    "example": 2997
+                },
+                "totals": {
+                    "description": "Totals grouped by subscription currency",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CurrencyAmount"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateBudgetRequest": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Required for category budgets",
+                    "type": "string",
+                    "enum": [
+                        "streaming",
+                        "music",
+                        "video",
+                        "cloud",
+                        "software",
+                        "gaming",
+                        "news",
+                        "education",
+                        "fitness",
+                        "other"
+                    ],
+                    "example": "streaming"
+                },
+                "currency": {
+                    "description": "Optional ISO-4217 code, defaults to RUB",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "monthly_limit": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "scope": {
+                    "description": "Optional, defaults to overall",
+                    "type": "string",
+                    "enum": [
+                        "overall",
+                        "category",
+                        "service"
+                    ],
+                    "example": "category"
+                },
+                "service_name": {
+                    "description": "Required for service budgets",
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "user_id": {
+                    "description": "Optional, defaults to the caller",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateServiceRequest": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "netflix hd"
+                    ]
+                },
+                "category": {
+                    "description": "Optional, defaults to other",
+                    "type": "string",
+                    "enum": [
+                        "streaming",
+                        "music",
+                        "video",
+                        "cloud",
+                        "software",
+                        "gaming",
+                        "news",
+                        "education",
+                        "fitness",
+                        "other"
+                    ],
+                    "example": "streaming"
+                },
+                "currency": {
+                    "description": "Optional ISO-4217 code, defaults to RUB",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "default_price": {
+                    "type": "integer",
+                    "example": 999
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Netflix"
+                }
+            }
+        },
+        "models.CreateSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "price",
+                "service_name",
+                "start_date",
+                "user_id"
+            ],
+            "properties": {
+                "billing_interval_count": {
+                    "description": "Optional, defaults to 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "billing_period": {
+                    "description": "Optional, defaults to month",
+                    "type": "string",
+                    "enum": [
+                        "week",
+                        "month",
+                        "quarter",
+                        "year"
+                    ],
+                    "example": "month"
+                },
+                "currency": {
+                    "description": "Optional ISO-4217 code, defaults to RUB",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "description": "Optional, Format: MM-YYYY",
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "service_name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Format: MM-YYYY",
+                    "type": "string"
+                },
+                "trial_end": {
+                    "description": "Optional last month of the trial, Format: MM-YYYY",
+                    "type": "string"
+                },
+                "trial_price": {
+                    "description": "Optional price charged during the trial, defaults to 0 (free)",
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "event_types": {
+                    "description": "Optional, defaults to every event type",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscription.created"
+                    ]
+                },
+                "secret": {
+                    "description": "Optional, generated when empty",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://billing.example.com/hooks/subscriptions"
+                }
+            }
+        },
+        "models.CurrencyAmount": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 2997
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "invalid_input"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/errs.FieldError"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Invalid input data"
+                }
+            }
+        },
+        "models.ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "from_currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 92.5
+                },
+                "to_currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportRejection": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/models.ErrorResponse"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "description": "Rows imported, or that would be imported in a dry run",
+                    "type": "integer",
+                    "example": 9
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "rejected": {
+                    "description": "Rows with errors, listed in Rejections",
+                    "type": "integer",
+                    "example": 1
+                },
+                "rejections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportRejection"
+                    }
+                },
+                "total": {
+                    "description": "Number of data rows in the file",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "models.Service": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Normalized alternative names",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "netflix hd"
+                    ]
+                },
+                "category": {
+                    "description": "One of ServiceCategories",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ServiceCategory"
+                        }
+                    ],
+                    "example": "streaming"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO-4217 code of DefaultPrice",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "default_price": {
+                    "description": "Price of new subscriptions that omit one",
+                    "type": "integer",
+                    "example": 999
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Canonical name",
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ServiceCategory": {
+            "type": "string",
+            "enum": [
+                "streaming",
+                "music",
+                "video",
+                "cloud",
+                "software",
+                "gaming",
+                "news",
+                "education",
+                "fitness",
+                "other"
+            ],
+            "x-enum-comments": {
+                "ServiceCategoryOther": "Also reported for subscriptions to services missing from the catalog"
+            },
+            "x-enum-descriptions": [
+                "Also reported for subscriptions to services missing from the catalog"
+            ],
+            "x-enum-varnames": [
+                "ServiceCategoryStreaming",
+                "ServiceCategoryMusic",
+                "ServiceCategoryVideo",
+                "ServiceCategoryCloud",
+                "ServiceCategorySoftware",
+                "ServiceCategoryGaming",
+                "ServiceCategoryNews",
+                "ServiceCategoryEducation",
+                "ServiceCategoryFitness",
+                "ServiceCategoryOther"
+            ]
+        },
+        "models.SetExchangeRateRequest": {
+            "type": "object",
+            "required": [
+                "rate"
+            ],
+            "properties": {
+                "rate": {
+                    "type": "number",
+                    "example": 92.5
+                }
+            }
+        },
+        "models.SpendPoint": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 999
+                },
+                "category": {
+                    "description": "Set when grouped by category",
+                    "type": "string",
+                    "example": "streaming"
+                },
+                "currency": {
+                    "description": "Omitted for months without charges unless target_currency is set",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "period": {
+                    "type": "string",
+                    "example": "03-2025"
+                },
+                "service_name": {
+                    "description": "Set when grouped by service",
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "user_id": {
+                    "description": "Set when grouped by user",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Subscription": {
+            "type": "object",
+            "required": [
+                "price",
+                "service_name",
+                "start_date",
+                "user_id"
+            ],
+            "properties": {
+                "billing_interval_count": {
+                    "description": "Charged on StartDate and then every N billing periods",
+                    "type": "integer",
+                    "example": 1
+                },
+                "billing_period": {
+                    "enum": [
+                        "week",
+                        "month",
+                        "quarter",
+                        "year"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BillingPeriod"
+                        }
+                    ],
+                    "example": "month"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO-4217 code",
+                    "type": "string",
+                    "example": "RUB"
+                },
                 "end_date": {
+                    "description": "Optional, Format: MM-YYYY",
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "service_id": {
+                    "description": "Catalogued service the name resolved to, if any",
+                    "type": "integer",
+                    "example": 1
+                },
+                "service_name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Format: MM-YYYY",
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "trial_end": {
+                    "description": "Last month billed at TrialPrice, Format: MM-YYYY",
+                    "type": "string",
+                    "example": "02-2025"
+                },
+                "trial_price": {
+                    "description": "Charged until TrialEnd instead of Price",
+                    "type": "integer",
+                    "example": 0
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incremented on every update, exposed as the ETag",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.SubscriptionCost": {
+            "type": "object",
+            "required": [
+                "price",
+                "service_name",
+                "start_date",
+                "user_id"
+            ],
+            "properties": {
+                "billed_months": {
+                    "description": "Months of the subscription that overlap the requested range",
+                    "type": "integer",
+                    "example": 3
+                },
+                "billing_interval_count": {
+                    "description": "Charged on StartDate and then every N billing periods",
+                    "type": "integer",
+                    "example": 1
+                },
+                "billing_period": {
+                    "enum": [
+                        "week",
+                        "month",
+                        "quarter",
+                        "year"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BillingPeriod"
+                        }
+                    ],
+                    "example": "month"
+                },
+                "charges": {
+                    "description": "Charge events of the subscription within the requested range",
+                    "type": "integer",
+                    "example": 3
+                },
+                "created_at": {
                     "type": "string"
                 },
+                "currency": {
+                    "description": "ISO-4217 code",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "description": "Optional, Format: MM-YYYY",
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "service_id": {
+                    "description": "Catalogued service the name resolved to, if any",
+                    "type": "integer",
+                    "example": 1
+                },
                 "service_name": {
                     "type": "string"
                 },
                 "start_date": {
+                    "description": "Format: MM-YYYY",
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "subtotal": {
+                    "description": "Sum of the charges, each at the price in effect in its month",
+                    "type": "integer",
+                    "example": 2997
+                },
+                "trial_end": {
+                    "description": "Last month billed at TrialPrice, Format: MM-YYYY",
+                    "type": "string",
+                    "example": "02-2025"
+                },
+                "trial_price": {
+                    "description": "Charged until TrialEnd instead of Price",
+                    "type": "integer",
+                    "example": 0
+                },
+                "updated_at": {
                     "type": "string"
                 },
-                "subscriptions": {
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incremented on every update, exposed as the ETag",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.SubscriptionPage": {
+            "type": "object",
+            "properties": {
+                "items": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/models.Subscription"
                     }
                 },
-                "total_cost": {
-                    "type": "integer"
+                "next_cursor": {
+                    "description": "Null on the last page",
+                    "type": "string"
                 },
-                "user_id": {
+                "prev_cursor": {
+                    "description": "Null on the first page",
                     "type": "string"
+                },
+                "total": {
+                    "description": "Only present when include_total=true",
+                    "type": "integer",
+                    "example": 124
                 }
             }
         },
-        "models.CreateSubscriptionRequest": {
+        "models.SubscriptionPrice": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "description": "Format: MM-YYYY",
+                    "type": "string",
+                    "example": "03-2025"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 1199
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.TrashPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrashedSubscription"
+                    }
+                },
+                "total": {
+                    "description": "Only present when include_total=true",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.TrashedSubscription": {
             "type": "object",
             "required": [
                 "price",
@@ -367,73 +4082,434 @@ const docTemplate = `{
                 "user_id"
             ],
             "properties": {
+                "billing_interval_count": {
+                    "description": "Charged on StartDate and then every N billing periods",
+                    "type": "integer",
+                    "example": 1
+                },
+                "billing_period": {
+                    "enum": [
+                        "week",
+                        "month",
+                        "quarter",
+                        "year"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BillingPeriod"
+                        }
+                    ],
+                    "example": "month"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO-4217 code",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
                 "end_date": {
                     "description": "Optional, Format: MM-YYYY",
-                    "type": "string"
+                    "type": "string",
+                    "example": "12-2025"
+                },
+                "id": {
+                    "type": "integer"
                 },
                 "price": {
                     "type": "integer",
                     "minimum": 1
                 },
+                "purge_after": {
+                    "description": "When the retention job deletes it permanently",
+                    "type": "string"
+                },
+                "service_id": {
+                    "description": "Catalogued service the name resolved to, if any",
+                    "type": "integer",
+                    "example": 1
+                },
                 "service_name": {
                     "type": "string"
                 },
                 "start_date": {
                     "description": "Format: MM-YYYY",
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "trial_end": {
+                    "description": "Last month billed at TrialPrice, Format: MM-YYYY",
+                    "type": "string",
+                    "example": "02-2025"
+                },
+                "trial_price": {
+                    "description": "Charged until TrialEnd instead of Price",
+                    "type": "integer",
+                    "example": 0
+                },
+                "updated_at": {
                     "type": "string"
                 },
                 "user_id": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "Incremented on every update, exposed as the ETag",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.UpcomingCharge": {
             "type": "object",
             "properties": {
-                "error": {
+                "amount": {
+                    "type": "integer",
+                    "example": 990
+                },
+                "billing_interval_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "billing_period": {
+                    "enum": [
+                        "week",
+                        "month",
+                        "quarter",
+                        "year"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BillingPeriod"
+                        }
+                    ],
+                    "example": "month"
+                },
+                "currency": {
                     "type": "string",
-                    "example": "Invalid input data"
+                    "example": "RUB"
+                },
+                "days_until": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "next_charge_date": {
+                    "description": "Format: YYYY-MM-DD",
+                    "type": "string",
+                    "example": "2025-08-01"
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "models.Subscription": {
+        "models.UpdateBudgetRequest": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "monthly_limit": {
+                    "type": "integer",
+                    "example": 2500
+                }
+            }
+        },
+        "models.UpdateServiceRequest": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "netflix hd"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "streaming",
+                        "music",
+                        "video",
+                        "cloud",
+                        "software",
+                        "gaming",
+                        "news",
+                        "education",
+                        "fitness",
+                        "other"
+                    ],
+                    "example": "streaming"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "default_price": {
+                    "type": "integer",
+                    "x-nullable": true,
+                    "example": 999
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Netflix"
+                }
+            }
+        },
+        "models.UpdateSubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "billing_interval_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "billing_period": {
+                    "type": "string",
+                    "enum": [
+                        "week",
+                        "month",
+                        "quarter",
+                        "year"
+                    ],
+                    "example": "month"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "description": "Format: MM-YYYY, null clears it",
+                    "type": "string",
+                    "x-nullable": true,
+                    "example": "12-2025"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 999
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "start_date": {
+                    "description": "Format: MM-YYYY",
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "trial_end": {
+                    "description": "Format: MM-YYYY, null ends the trial",
+                    "type": "string",
+                    "x-nullable": true,
+                    "example": "02-2025"
+                },
+                "trial_price": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "models.UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
             "type": "object",
-            "required": [
-                "price",
-                "service_name",
-                "start_date",
-                "user_id"
-            ],
             "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "end_date": {
-                    "description": "Optional, Format: MM-YYYY",
+                "delivered_at": {
                     "type": "string"
                 },
+                "endpoint_id": {
+                    "type": "integer"
+                },
+                "event": {
+                    "$ref": "#/definitions/models.WebhookEvent"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "price": {
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
                     "type": "integer",
-                    "minimum": 1
+                    "example": 502
                 },
-                "service_name": {
+                "next_attempt_at": {
                     "type": "string"
                 },
-                "start_date": {
-                    "description": "Format: MM-YYYY",
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.WebhookDeliveryStatus"
+                        }
+                    ],
+                    "example": "pending"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "WebhookDeliveryFailed": "Every attempt failed",
+                "WebhookDeliveryPending": "Waiting for its first attempt or a retry"
+            },
+            "x-enum-descriptions": [
+                "Waiting for its first attempt or a retry",
+                "Every attempt failed"
+            ],
+            "x-enum-varnames": [
+                "WebhookDeliveryPending",
+                "WebhookDeliverySucceeded",
+                "WebhookDeliveryFailed"
+            ]
+        },
+        "models.WebhookEndpoint": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
                     "type": "string"
                 },
+                "event_types": {
+                    "description": "Empty means every event type",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscription.created"
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
                 },
-                "user_id": {
+                "url": {
+                    "type": "string",
+                    "example": "https://billing.example.com/hooks/subscriptions"
+                }
+            }
+        },
+        "models.WebhookEndpointWithSecret": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "description": "Empty means every event type",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscription.created"
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "3f5c0e..."
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://billing.example.com/hooks/subscriptions"
+                }
+            }
+        },
+        "models.WebhookEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
+                },
+                "dispatched_at": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "subscription.created"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "subscription_id": {
+                    "description": "The subscription that changed",
+                    "type": "integer"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "JWT bearer token, e.g. \"Bearer eyJhbGciOi...\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -3,12 +3,17 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"subscription_tracker_api/internal/auth"
 	"subscription_tracker_api/internal/config"
 	"subscription_tracker_api/internal/handlers"
 	"subscription_tracker_api/internal/infra/database"
+	"subscription_tracker_api/internal/jobs"
+	"subscription_tracker_api/internal/middleware"
+	"subscription_tracker_api/internal/notify"
 	"subscription_tracker_api/internal/repository"
 	"subscription_tracker_api/internal/service"
 	"syscall"
@@ -25,6 +30,10 @@ import (
 // @description REST API for managing user subscriptions
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT bearer token, e.g. "Bearer eyJhbGciOi..."
 func main() {
 	// Load configuration
 	log.Println("Loading application configuration...")
@@ -64,16 +73,72 @@ func main() {
 	// Initialize repository
 	logger.Info("Initializing repository layer...")
 	subscriptionRepo := repository.NewSubscriptionRepository(db.DB, logger)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db.DB, logger)
+	reminderRepo := repository.NewReminderRepository(db.DB, logger)
+	webhookRepo := repository.NewWebhookRepository(db.DB, logger)
+	idempotencyRepo := repository.NewIdempotencyRepository(db.DB, logger)
+	auditRepo := repository.NewAuditRepository(db.DB, logger)
+	priceRepo := repository.NewPriceRepository(db.DB, logger)
+	serviceRepo := repository.NewServiceRepository(db.DB, logger)
+	budgetRepo := repository.NewBudgetRepository(db.DB, logger)
 	logger.Info("Repository layer initialized successfully")
 
 	// Initialize service with transaction manager
 	logger.Info("Initializing service layer...")
-	subscriptionService := service.NewSubscriptionService(subscriptionRepo, txMgr, logger)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepo, logger)
+	budgetService := service.NewBudgetService(budgetRepo, subscriptionRepo, serviceRepo, exchangeRateService, webhookRepo, txMgr, logger)
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo, exchangeRateService, webhookRepo, auditRepo, priceRepo, serviceRepo, budgetService, idempotencyRepo, cfg.Idempotency, cfg.Batch, cfg.Trash, txMgr, logger)
+	webhookService := service.NewWebhookService(webhookRepo, cfg.Webhooks, logger)
+	auditService := service.NewAuditService(auditRepo, subscriptionRepo, logger)
+	catalogService := service.NewCatalogService(serviceRepo, txMgr, logger)
 	logger.Info("Service layer initialized successfully")
 
+	// Initialize background jobs
+	logger.Info("Initializing background jobs...")
+	scheduler := jobs.NewScheduler(logger)
+	if cfg.Reminders.Enabled {
+		notifier, err := notify.New(cfg.Reminders, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize reminder notifier")
+		}
+		reminderService := service.NewReminderService(subscriptionRepo, reminderRepo, notifier, cfg.Reminders.LeadTime, cfg.Reminders.TrialLeadTime, cfg.Reminders.MaxAttempts, cfg.Reminders.BatchSize, logger)
+		scheduler.Add("renewal_reminders", cfg.Reminders.Interval, reminderService.SendDueReminders)
+		logger.WithFields(logrus.Fields{
+			"notifier":        notifier.Name(),
+			"interval":        cfg.Reminders.Interval.String(),
+			"lead_time":       cfg.Reminders.LeadTime.String(),
+			"trial_lead_time": cfg.Reminders.TrialLeadTime.String(),
+		}).Info("Renewal reminders configured successfully")
+	}
+
+	scheduler.Add("webhook_delivery", cfg.Webhooks.PollInterval, webhookService.DeliverPending)
+	logger.WithFields(logrus.Fields{
+		"poll_interval": cfg.Webhooks.PollInterval.String(),
+		"max_attempts":  cfg.Webhooks.MaxAttempts,
+	}).Info("Webhook delivery configured successfully")
+
+	scheduler.Add("idempotency_purge", cfg.Idempotency.PurgeInterval, subscriptionService.PurgeExpiredIdempotencyKeys)
+	logger.WithField("ttl", cfg.Idempotency.TTL.String()).Info("Idempotency key purge configured successfully")
+
+	scheduler.Add("trash_purge", cfg.Trash.PurgeInterval, subscriptionService.PurgeExpiredTrash)
+	logger.WithField("retention", cfg.Trash.Retention.String()).Info("Trash purge configured successfully")
+
+	// Initialize authentication
+	logger.Info("Initializing JWT authentication...")
+	authenticator, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize authentication")
+	}
+	logger.WithField("algorithm", cfg.Auth.Algorithm).Info("JWT authentication initialized successfully")
+
 	// Initialize handlers
 	logger.Info("Initializing HTTP handlers...")
 	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService, logger)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService, logger)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, logger)
+	auditHandler := handlers.NewAuditHandler(auditService, logger)
+	catalogHandler := handlers.NewCatalogHandler(catalogService, logger)
+	budgetHandler := handlers.NewBudgetHandler(budgetService, logger)
 	logger.Info("HTTP handlers initialized successfully")
 
 	// Setup Gin router
@@ -96,8 +161,9 @@ func main() {
 	// Add CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, Idempotency-Key, X-Actor")
+		c.Header("Access-Control-Expose-Headers", "ETag, Idempotent-Replayed, Content-Disposition")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -108,21 +174,77 @@ func main() {
 	})
 	logger.Info("CORS middleware configured successfully")
 
+	// Bound database work per request; streaming exports get a longer deadline of their own
+	router.Use(middleware.QueryTimeout(cfg.Database.QueryTimeout, map[string]time.Duration{
+		"/api/v1/subscriptions/export": cfg.Database.ExportTimeout,
+	}))
+	logger.WithFields(logrus.Fields{
+		"query_timeout":  cfg.Database.QueryTimeout.String(),
+		"export_timeout": cfg.Database.ExportTimeout.String(),
+	}).Info("Query timeout middleware configured successfully")
+
 	// API routes
 	logger.Info("Configuring API routes...")
 	v1 := router.Group("/api/v1")
+	v1.Use(middleware.Authenticate(authenticator), middleware.Actor())
 	{
 		// CRUDL operations for subscriptions
 		v1.POST("/subscriptions", subscriptionHandler.CreateSubscription)
 		v1.GET("/subscriptions/:id", subscriptionHandler.GetSubscription)
-		v1.PUT("/subscriptions/:id", subscriptionHandler.UpdateSubscription)
+		v1.PUT("/subscriptions/:id", subscriptionHandler.ReplaceSubscription)
+		v1.PATCH("/subscriptions/:id", subscriptionHandler.UpdateSubscription)
 		v1.DELETE("/subscriptions/:id", subscriptionHandler.DeleteSubscription)
 		v1.GET("/subscriptions", subscriptionHandler.ListSubscriptions)
+		v1.POST("/:resource", handlers.CustomMethods(map[string]gin.HandlerFunc{
+			"subscriptions:batch": subscriptionHandler.BatchSubscriptions,
+		}))
 
 		// Cost calculation endpoint
 		v1.GET("/subscriptions/calculate-cost", subscriptionHandler.CalculateTotalCost)
+		v1.GET("/analytics/spend", subscriptionHandler.CalculateSpendSeries)
+		v1.GET("/subscriptions/upcoming", subscriptionHandler.ListUpcomingCharges)
+		v1.POST("/subscriptions/import", subscriptionHandler.ImportSubscriptions)
+		v1.GET("/subscriptions/export", subscriptionHandler.ExportSubscriptions)
+		v1.GET("/subscriptions/trash", subscriptionHandler.ListTrash)
+		v1.POST("/subscriptions/:id/restore", subscriptionHandler.RestoreSubscription)
+		v1.GET("/subscriptions/:id/prices", subscriptionHandler.ListPriceHistory)
+
+		// Audit log of subscription changes
+		v1.GET("/subscriptions/:id/history", auditHandler.GetSubscriptionHistory)
+		v1.GET("/audit", auditHandler.ListAuditEvents)
+
+		// Service catalog used to resolve subscription names (changes are admin only)
+		v1.GET("/services", catalogHandler.ListServices)
+		v1.GET("/services/:id", catalogHandler.GetService)
+		catalog := v1.Group("/services", middleware.RequireRole(auth.RoleAdmin))
+		catalog.POST("", catalogHandler.CreateService)
+		catalog.PUT("/:id", catalogHandler.UpdateService)
+		catalog.DELETE("/:id", catalogHandler.DeleteService)
+
+		// Monthly spending budgets of the caller
+		v1.POST("/budgets", budgetHandler.CreateBudget)
+		v1.GET("/budgets", budgetHandler.ListBudgets)
+		v1.GET("/budgets/status", budgetHandler.GetBudgetStatus)
+		v1.GET("/budgets/:id", budgetHandler.GetBudget)
+		v1.PUT("/budgets/:id", budgetHandler.UpdateBudget)
+		v1.DELETE("/budgets/:id", budgetHandler.DeleteBudget)
+
+		// Exchange rates used for cost conversion (admin only)
+		rates := v1.Group("/exchange-rates", middleware.RequireRole(auth.RoleAdmin))
+		rates.GET("", exchangeRateHandler.ListExchangeRates)
+		rates.PUT("/:from/:to", exchangeRateHandler.SetExchangeRate)
+		rates.DELETE("/:from/:to", exchangeRateHandler.DeleteExchangeRate)
+
+		// Outbound webhooks for subscription lifecycle events (admin only)
+		webhooks := v1.Group("/webhooks", middleware.RequireRole(auth.RoleAdmin))
+		webhooks.POST("", webhookHandler.CreateWebhook)
+		webhooks.GET("", webhookHandler.ListWebhooks)
+		webhooks.GET("/:id", webhookHandler.GetWebhook)
+		webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+		webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", webhookHandler.ListWebhookDeliveries)
 	}
-	logger.WithField("routes_count", 6).Info("API routes configured successfully")
+	logger.WithField("routes_count", len(router.Routes())).Info("API routes configured successfully")
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -141,11 +263,18 @@ func main() {
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
 	logger.Info("Swagger documentation configured at /swagger/index.html")
 
-	// Create HTTP server
+	// Create HTTP server. Request contexts derive from requestsCtx so that
+	// in-flight queries can be cancelled once the shutdown grace period is over.
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	serverAddr := cfg.Server.Host + ":" + cfg.Server.Port
 	srv := &http.Server{
 		Addr:    serverAddr,
 		Handler: router,
+		BaseContext: func(net.Listener) context.Context {
+			return requestsCtx
+		},
 	}
 
 	// Channel to listen for interrupt signals
@@ -164,6 +293,9 @@ func main() {
 		}
 	}()
 
+	// Start background jobs
+	scheduler.Start(context.Background())
+
 	logger.Info("Server started successfully. Press Ctrl+C to gracefully shutdown...")
 
 	// Wait for interrupt signal
@@ -182,6 +314,15 @@ func main() {
 		logger.Info("HTTP server shutdown gracefully")
 	}
 
+	// Abort database work of requests that outlived the grace period
+	cancelRequests()
+
+	// Stop background jobs before the database goes away
+	logger.Info("Stopping background jobs...")
+	if err := scheduler.Stop(ctx); err != nil {
+		logger.WithError(err).Error("Background jobs did not stop in time")
+	}
+
 	// Close database connection
 	logger.Info("Closing database connection...")
 	db.Close()
@@ -0,0 +1,16 @@
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS chk_end_date_first_of_month;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS chk_start_date_first_of_month;
+
+ALTER TABLE subscriptions
+    ALTER COLUMN start_date TYPE VARCHAR(7) USING to_char(start_date, 'MM-YYYY');
+
+ALTER TABLE subscriptions
+    ALTER COLUMN end_date TYPE VARCHAR(7) USING to_char(end_date, 'MM-YYYY');
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_start_date_format
+        CHECK (start_date ~ '^(0[1-9]|1[0-2])-[0-9]{4}$');
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_end_date_format
+        CHECK (end_date IS NULL OR end_date ~ '^(0[1-9]|1[0-2])-[0-9]{4}$');
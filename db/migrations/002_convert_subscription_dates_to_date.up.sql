@@ -0,0 +1,18 @@
+-- Convert MM-YYYY strings into proper DATE columns (first day of the month)
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS chk_start_date_format;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS chk_end_date_format;
+
+ALTER TABLE subscriptions
+    ALTER COLUMN start_date TYPE DATE USING to_date(start_date, 'MM-YYYY');
+
+ALTER TABLE subscriptions
+    ALTER COLUMN end_date TYPE DATE USING to_date(end_date, 'MM-YYYY');
+
+-- Only the first day of a month is a valid period boundary
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_start_date_first_of_month
+        CHECK (EXTRACT(DAY FROM start_date) = 1);
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_end_date_first_of_month
+        CHECK (end_date IS NULL OR EXTRACT(DAY FROM end_date) = 1);
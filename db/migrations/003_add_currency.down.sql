@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+DROP INDEX IF EXISTS idx_subscriptions_currency;
+
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS chk_currency_format;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,20 @@
+-- Attach an ISO-4217 currency to every subscription. Existing rows were entered in roubles.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_currency_format
+        CHECK (currency ~ '^[A-Z]{3}$');
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_currency ON subscriptions(currency);
+
+-- Exchange rates used to convert cost reports offline: 1 unit of from_currency = rate units of to_currency
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (from_currency, to_currency),
+    CONSTRAINT chk_exchange_rate_currencies CHECK (from_currency <> to_currency)
+);
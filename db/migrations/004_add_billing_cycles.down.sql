@@ -0,0 +1,7 @@
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS chk_billing_interval_count;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS billing_interval_count,
+    DROP COLUMN IF EXISTS billing_period;
+
+DROP TYPE IF EXISTS billing_period;
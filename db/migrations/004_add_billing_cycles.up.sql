@@ -0,0 +1,11 @@
+-- Billing cycle of a subscription: it is charged on start_date and then every
+-- billing_interval_count billing periods. Existing rows were billed monthly.
+CREATE TYPE billing_period AS ENUM ('week', 'month', 'quarter', 'year');
+
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS billing_period billing_period NOT NULL DEFAULT 'month',
+    ADD COLUMN IF NOT EXISTS billing_interval_count INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_billing_interval_count
+        CHECK (billing_interval_count > 0);
@@ -0,0 +1,19 @@
+-- One row per reminder about a subscription event. The unique key is claimed before a
+-- notification is sent, so a restarted scheduler never sends the same reminder twice.
+CREATE TABLE IF NOT EXISTS reminder_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    due_date DATE NOT NULL,
+    notifier VARCHAR(32) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'sending',
+    attempts INTEGER NOT NULL DEFAULT 1,
+    last_error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_reminder_deliveries UNIQUE (subscription_id, kind, due_date),
+    CONSTRAINT chk_reminder_delivery_status CHECK (status IN ('sending', 'sent', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminder_deliveries_status ON reminder_deliveries(status);
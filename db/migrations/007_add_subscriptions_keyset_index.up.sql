@@ -0,0 +1,3 @@
+-- Keyset pagination walks subscriptions in (created_at, id) order, usually per user
+CREATE INDEX IF NOT EXISTS idx_subscriptions_created_at_id ON subscriptions(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_created_at_id ON subscriptions(user_id, created_at, id);
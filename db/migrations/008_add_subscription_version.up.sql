@@ -0,0 +1,4 @@
+-- Optimistic concurrency: every update bumps version and only applies if the row is
+-- still at the version the writer read. It is exposed to clients as the ETag.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
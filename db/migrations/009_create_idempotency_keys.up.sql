@@ -0,0 +1,13 @@
+-- Responses of create requests sent with an Idempotency-Key, written in the same transaction
+-- as the subscription and replayed when the client retries with the same key and body
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope VARCHAR(64) NOT NULL, -- Caller the key belongs to
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    response_body JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,16 @@
+-- Who changed which subscription and how, written in the same transaction as the change
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL, -- Kept after the subscription is purged
+    user_id UUID NOT NULL, -- Owner of the subscription
+    actor VARCHAR(255) NOT NULL, -- X-Actor header, else the caller's user ID, else "system"
+    actor_user_id UUID, -- Authenticated caller, if any
+    action VARCHAR(16) NOT NULL,
+    changes JSONB NOT NULL, -- {"field": {"before": ..., "after": ...}}
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_audit_events_action CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_subscription ON audit_events(subscription_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_user ON audit_events(user_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
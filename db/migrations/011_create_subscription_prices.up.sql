@@ -0,0 +1,15 @@
+-- Price history: a subscription costs the price of its latest row whose effective_from is not
+-- after the charged month, so a price change no longer rewrites the cost of past months
+CREATE TABLE IF NOT EXISTS subscription_prices (
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    effective_from DATE NOT NULL, -- First day of the month the price applies from
+    price INTEGER NOT NULL CHECK (price > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (subscription_id, effective_from),
+    CONSTRAINT chk_effective_from_first_of_month CHECK (EXTRACT(DAY FROM effective_from) = 1)
+);
+
+-- Existing subscriptions have always cost their current price
+INSERT INTO subscription_prices (subscription_id, effective_from, price)
+SELECT id, start_date, price FROM subscriptions
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,26 @@
+-- Catalog of known services. Subscriptions created under a catalogued name or alias are linked
+-- to the service and take its canonical name.
+CREATE TABLE IF NOT EXISTS services (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL, -- Canonical name
+    category VARCHAR(32) NOT NULL DEFAULT 'other',
+    default_price INTEGER CHECK (default_price > 0), -- Price of new subscriptions that omit one
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_services_category CHECK (category IN ('streaming', 'music', 'video', 'cloud', 'software', 'gaming', 'news', 'education', 'fitness', 'other'))
+);
+
+-- Every normalized name a service is known by, its canonical name included, so that a name
+-- resolves to at most one service
+CREATE TABLE IF NOT EXISTS service_aliases (
+    alias VARCHAR(255) PRIMARY KEY, -- Lower case with single spaces
+    service_id INTEGER NOT NULL REFERENCES services(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_aliases_service ON service_aliases(service_id);
+
+-- Subscriptions keep their name when their service is removed from the catalog
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS service_id INTEGER REFERENCES services(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_service_id ON subscriptions(service_id);
@@ -0,0 +1,21 @@
+-- Monthly spending limits of a user, over all of their subscriptions, those of a catalog
+-- category or those of one service
+CREATE TABLE IF NOT EXISTS budgets (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    scope VARCHAR(16) NOT NULL,
+    category VARCHAR(32), -- Set for category budgets
+    service_name VARCHAR(255), -- Set for service budgets, matched ignoring case
+    monthly_limit INTEGER NOT NULL CHECK (monthly_limit > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_budgets_scope CHECK (
+        (scope = 'overall' AND category IS NULL AND service_name IS NULL) OR
+        (scope = 'category' AND category IS NOT NULL AND service_name IS NULL) OR
+        (scope = 'service' AND category IS NULL AND service_name IS NOT NULL)
+    )
+);
+
+-- A user has at most one budget per scope, category and service
+CREATE UNIQUE INDEX IF NOT EXISTS uq_budgets_target ON budgets(user_id, scope, COALESCE(category, ''), LOWER(COALESCE(service_name, '')));
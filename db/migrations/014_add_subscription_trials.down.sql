@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_subscriptions_trial_end;
+
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS chk_trial_price;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS trial_price,
+    DROP COLUMN IF EXISTS trial_end;
@@ -0,0 +1,13 @@
+-- Free or discounted trial: every charge up to and including the trial_end month is billed
+-- trial_price instead of the subscription price.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS trial_end DATE,
+    ADD COLUMN IF NOT EXISTS trial_price INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT chk_trial_price
+        CHECK (trial_price >= 0 AND (trial_end IS NOT NULL OR trial_price = 0));
+
+-- Backs the "trials ending soon" filter
+CREATE INDEX IF NOT EXISTS idx_subscriptions_trial_end ON subscriptions (trial_end)
+    WHERE trial_end IS NOT NULL;
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"subscription_tracker_api/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, expired or wrongly signed
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the JWT claims understood by the API. The subject holds the user UUID.
+type Claims struct {
+	Role string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Authenticator verifies bearer tokens and turns them into principals
+type Authenticator struct {
+	key    interface{}
+	parser *jwt.Parser
+}
+
+// NewAuthenticator creates an authenticator for the configured algorithm and key
+func NewAuthenticator(cfg config.AuthConfig) (*Authenticator, error) {
+	var key interface{}
+
+	switch strings.ToUpper(cfg.Algorithm) {
+	case jwt.SigningMethodHS256.Alg():
+		if cfg.Secret == "" {
+			return nil, errors.New("auth secret is required for HS256")
+		}
+		key = []byte(cfg.Secret)
+	case jwt.SigningMethodRS256.Alg():
+		if cfg.PublicKey == "" {
+			return nil, errors.New("auth public key is required for RS256")
+		}
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse auth public key: %w", err)
+		}
+		key = publicKey
+	default:
+		return nil, fmt.Errorf("unsupported auth algorithm %q", cfg.Algorithm)
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{strings.ToUpper(cfg.Algorithm)}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &Authenticator{
+		key:    key,
+		parser: jwt.NewParser(options...),
+	}, nil
+}
+
+// Authenticate validates a raw token and returns the principal it identifies
+func (a *Authenticator) Authenticate(tokenString string) (Principal, error) {
+	claims := &Claims{}
+	_, err := a.parser.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return a.key, nil
+	})
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: subject must be a user UUID", ErrInvalidToken)
+	}
+
+	return Principal{UserID: userID, Role: claims.Role}, nil
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "test-secret"
+
+func signHS256(t *testing.T, claims Claims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func validClaims(userID uuid.UUID, role string) Claims {
+	return Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func TestAuthenticate_HS256(t *testing.T) {
+	authenticator, err := NewAuthenticator(config.AuthConfig{Algorithm: "HS256", Secret: testSecret})
+	assert.NoError(t, err)
+
+	userID := uuid.New()
+	principal, err := authenticator.Authenticate(signHS256(t, validClaims(userID, RoleAdmin)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, principal.UserID)
+	assert.True(t, principal.IsAdmin())
+}
+
+func TestAuthenticate_RejectsInvalidTokens(t *testing.T) {
+	authenticator, err := NewAuthenticator(config.AuthConfig{Algorithm: "HS256", Secret: testSecret, Issuer: "billing"})
+	assert.NoError(t, err)
+
+	userID := uuid.New()
+
+	expired := validClaims(userID, "")
+	expired.Issuer = "billing"
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+	noExpiry := validClaims(userID, "")
+	noExpiry.Issuer = "billing"
+	noExpiry.ExpiresAt = nil
+
+	wrongIssuer := validClaims(userID, "")
+	wrongIssuer.Issuer = "someone-else"
+
+	badSubject := validClaims(userID, "")
+	badSubject.Issuer = "billing"
+	badSubject.Subject = "not-a-uuid"
+
+	goodClaims := validClaims(userID, "")
+	goodClaims.Issuer = "billing"
+	wrongKey, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, goodClaims).SignedString([]byte("other-secret"))
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, goodClaims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	tokens := map[string]string{
+		"expired":      signHS256(t, expired),
+		"no expiry":    signHS256(t, noExpiry),
+		"wrong issuer": signHS256(t, wrongIssuer),
+		"bad subject":  signHS256(t, badSubject),
+		"wrong key":    wrongKey,
+		"alg none":     unsigned,
+		"garbage":      "not.a.token",
+	}
+
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestAuthenticate_RS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	assert.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	authenticator, err := NewAuthenticator(config.AuthConfig{Algorithm: "RS256", PublicKey: string(publicPEM)})
+	assert.NoError(t, err)
+
+	userID := uuid.New()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims(userID, "")).SignedString(privateKey)
+	assert.NoError(t, err)
+
+	principal, err := authenticator.Authenticate(token)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, principal.UserID)
+	assert.False(t, principal.IsAdmin())
+
+	// An HS256 token signed with the public key must not be accepted (algorithm confusion)
+	confused, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims(userID, RoleAdmin)).SignedString(publicPEM)
+	_, err = authenticator.Authenticate(confused)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNewAuthenticator_ConfigErrors(t *testing.T) {
+	_, err := NewAuthenticator(config.AuthConfig{Algorithm: "HS256"})
+	assert.Error(t, err)
+
+	_, err = NewAuthenticator(config.AuthConfig{Algorithm: "RS256", PublicKey: "not pem"})
+	assert.Error(t, err)
+
+	_, err = NewAuthenticator(config.AuthConfig{Algorithm: "ES256", Secret: testSecret})
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RoleAdmin grants access to every user's subscriptions
+const RoleAdmin = "admin"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID uuid.UUID
+	Role   string
+}
+
+// IsAdmin reports whether the caller may act on behalf of any user
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// CanAccess reports whether the caller may see data owned by userID
+func (p Principal) CanAccess(userID uuid.UUID) bool {
+	return p.IsAdmin() || p.UserID == userID
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated caller
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated caller stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx naming the person a request is made on behalf of
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx, if any
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok && actor != ""
+}
@@ -1,17 +1,115 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	Logging  LoggingConfig  `yaml:"logging"`
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Reminders RemindersConfig `yaml:"reminders"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks"`
+	// Idempotency configures stored Idempotency-Key responses
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	// Batch configures POST /subscriptions:batch and POST /subscriptions/import
+	Batch BatchConfig `yaml:"batch"`
+	// Trash configures how long soft-deleted subscriptions can be restored
+	Trash TrashConfig `yaml:"trash"`
+}
+
+type TrashConfig struct {
+	// Retention is how long a soft-deleted subscription is kept before it is purged
+	Retention time.Duration `yaml:"retention"`
+	// PurgeInterval is how often expired subscriptions are purged
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+type BatchConfig struct {
+	// MaxSize is the largest number of operations accepted in one batch
+	MaxSize int `yaml:"max_size"`
+	// ImportMaxRows is the largest number of rows accepted in one CSV import
+	ImportMaxRows int `yaml:"import_max_rows"`
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a stored response is replayed for a retried request
+	TTL time.Duration `yaml:"ttl"`
+	// PurgeInterval is how often expired keys are deleted
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+type WebhooksConfig struct {
+	// PollInterval is how often the delivery worker looks for events and due retries
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// MaxAttempts bounds how often a delivery is attempted before it is marked failed
+	MaxAttempts int `yaml:"max_attempts"`
+	// InitialBackoff is the delay before the first retry; it doubles on every further retry up to MaxBackoff
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	// Timeout bounds a single delivery request
+	Timeout time.Duration `yaml:"timeout"`
+	// BatchSize is the number of events and deliveries handled per poll
+	BatchSize int `yaml:"batch_size"`
+}
+
+type RemindersConfig struct {
+	// Enabled starts the background reminder scheduler
+	Enabled bool `yaml:"enabled"`
+	// Interval is how often due reminders are looked up
+	Interval time.Duration `yaml:"interval"`
+	// LeadTime is how far ahead of a renewal or expiry the reminder is sent
+	LeadTime time.Duration `yaml:"lead_time"`
+	// TrialLeadTime is how far ahead of the first full-price charge after a trial the reminder
+	// is sent, leaving time to cancel
+	TrialLeadTime time.Duration `yaml:"trial_lead_time"`
+	// MaxAttempts bounds how often a failed reminder is retried
+	MaxAttempts int `yaml:"max_attempts"`
+	// BatchSize is the number of subscriptions read from the database at a time
+	BatchSize int `yaml:"batch_size"`
+	// Notifier selects the delivery channel: log, smtp or webhook
+	Notifier string                `yaml:"notifier"`
+	SMTP     SMTPConfig            `yaml:"smtp"`
+	Webhook  ReminderWebhookConfig `yaml:"webhook"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+	// To is the recipient address; {user_id} is replaced with the subscription owner's ID
+	To string `yaml:"to"`
+}
+
+type ReminderWebhookConfig struct {
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type AuthConfig struct {
+	// Algorithm is the JWT signing algorithm accepted by the API: HS256 or RS256
+	Algorithm string `yaml:"algorithm"`
+	// Secret is the shared HMAC key used with HS256
+	Secret string `yaml:"secret"`
+	// PublicKey is the PEM encoded RSA public key used with RS256
+	PublicKey string `yaml:"public_key"`
+	// PublicKeyFile is read into PublicKey when PublicKey is empty
+	PublicKeyFile string `yaml:"public_key_file"`
+	// Issuer and Audience are checked against the token claims when set
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
 }
 
 type LoggingConfig struct {
@@ -31,6 +129,10 @@ type DatabaseConfig struct {
 	Password string `yaml:"password"`
 	DBName   string `yaml:"dbname"`
 	SSLMode  string `yaml:"sslmode"`
+	// QueryTimeout bounds how long a single API request may spend on database work
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+	// ExportTimeout replaces QueryTimeout for streaming exports, which read every matching row
+	ExportTimeout time.Duration `yaml:"export_timeout"`
 }
 
 func Load() (*Config, error) {
@@ -73,6 +175,20 @@ func Load() (*Config, error) {
 	if sslMode := os.Getenv("DB_SSLMODE"); sslMode != "" {
 		config.Database.SSLMode = sslMode
 	}
+	if queryTimeout := os.Getenv("DB_QUERY_TIMEOUT"); queryTimeout != "" {
+		timeout, err := time.ParseDuration(queryTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DB_QUERY_TIMEOUT: %w", err)
+		}
+		config.Database.QueryTimeout = timeout
+	}
+	if exportTimeout := os.Getenv("DB_EXPORT_TIMEOUT"); exportTimeout != "" {
+		timeout, err := time.ParseDuration(exportTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DB_EXPORT_TIMEOUT: %w", err)
+		}
+		config.Database.ExportTimeout = timeout
+	}
 
 	// Set defaults if not provided
 	if config.Server.Port == "" {
@@ -84,6 +200,53 @@ func Load() (*Config, error) {
 	if config.Database.SSLMode == "" {
 		config.Database.SSLMode = "disable"
 	}
+	if config.Database.QueryTimeout <= 0 {
+		config.Database.QueryTimeout = 10 * time.Second
+	}
+	if config.Database.ExportTimeout <= 0 {
+		config.Database.ExportTimeout = 10 * time.Minute
+	}
+
+	if err := loadAuth(&config.Auth); err != nil {
+		return nil, err
+	}
+
+	if err := loadReminders(&config.Reminders); err != nil {
+		return nil, err
+	}
+
+	if err := loadWebhooks(&config.Webhooks); err != nil {
+		return nil, err
+	}
+
+	if err := loadIdempotency(&config.Idempotency); err != nil {
+		return nil, err
+	}
+
+	if err := loadTrash(&config.Trash); err != nil {
+		return nil, err
+	}
+
+	if maxSize := os.Getenv("BATCH_MAX_SIZE"); maxSize != "" {
+		size, err := strconv.Atoi(maxSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BATCH_MAX_SIZE: %w", err)
+		}
+		config.Batch.MaxSize = size
+	}
+	if config.Batch.MaxSize <= 0 {
+		config.Batch.MaxSize = 100
+	}
+	if maxRows := os.Getenv("IMPORT_MAX_ROWS"); maxRows != "" {
+		rows, err := strconv.Atoi(maxRows)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IMPORT_MAX_ROWS: %w", err)
+		}
+		config.Batch.ImportMaxRows = rows
+	}
+	if config.Batch.ImportMaxRows <= 0 {
+		config.Batch.ImportMaxRows = 10000
+	}
 
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		config.Logging.Level = logLevel
@@ -103,6 +266,218 @@ func Load() (*Config, error) {
 	return config, nil
 }
 
+// minAuthSecretLength is the minimum size of an HS256 secret in bytes, the output size of SHA-256
+const minAuthSecretLength = 32
+
+// placeholderAuthSecrets are example secrets from configuration files and docs, which must
+// never sign real tokens whatever their length
+var placeholderAuthSecrets = []string{"change-me-in-production", "changeme", "change-me", "secret", "your-secret-key"}
+
+// loadAuth applies environment overrides and defaults to the JWT settings and refuses to use
+// HS256 without a real secret. AUTH_SECRET takes precedence over the older JWT_SECRET.
+func loadAuth(auth *AuthConfig) error {
+	if algorithm := os.Getenv("JWT_ALGORITHM"); algorithm != "" {
+		auth.Algorithm = algorithm
+	}
+	for _, env := range []string{"JWT_SECRET", "AUTH_SECRET"} {
+		if secret := os.Getenv(env); secret != "" {
+			auth.Secret = secret
+		}
+	}
+	if publicKeyFile := os.Getenv("JWT_PUBLIC_KEY_FILE"); publicKeyFile != "" {
+		auth.PublicKeyFile = publicKeyFile
+	}
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		auth.Issuer = issuer
+	}
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		auth.Audience = audience
+	}
+
+	// Set auth defaults
+	if auth.Algorithm == "" {
+		auth.Algorithm = "HS256"
+	}
+	if auth.PublicKey == "" && auth.PublicKeyFile != "" {
+		publicKey, err := os.ReadFile(auth.PublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("error reading JWT public key file: %w", err)
+		}
+		auth.PublicKey = string(publicKey)
+	}
+
+	if strings.EqualFold(auth.Algorithm, "HS256") {
+		switch {
+		case auth.Secret == "":
+			return errors.New("AUTH_SECRET is required for HS256")
+		case slices.Contains(placeholderAuthSecrets, strings.ToLower(strings.TrimSpace(auth.Secret))):
+			return errors.New("AUTH_SECRET is a placeholder, set it to a random secret")
+		case len(auth.Secret) < minAuthSecretLength:
+			return fmt.Errorf("AUTH_SECRET must be at least %d bytes long", minAuthSecretLength)
+		}
+	}
+	return nil
+}
+
+// loadReminders applies environment overrides and defaults to the reminder settings
+func loadReminders(reminders *RemindersConfig) error {
+	if enabled := os.Getenv("REMINDERS_ENABLED"); enabled != "" {
+		reminders.Enabled = enabled == "true" || enabled == "1"
+	}
+	if notifier := os.Getenv("REMINDERS_NOTIFIER"); notifier != "" {
+		reminders.Notifier = notifier
+	}
+	for env, target := range map[string]*time.Duration{
+		"REMINDERS_INTERVAL":        &reminders.Interval,
+		"REMINDERS_LEAD_TIME":       &reminders.LeadTime,
+		"REMINDERS_TRIAL_LEAD_TIME": &reminders.TrialLeadTime,
+		"REMINDERS_WEBHOOK_TIMEOUT": &reminders.Webhook.Timeout,
+	} {
+		if value := os.Getenv(env); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", env, err)
+			}
+			*target = duration
+		}
+	}
+	for env, target := range map[string]*string{
+		"SMTP_HOST":             &reminders.SMTP.Host,
+		"SMTP_PORT":             &reminders.SMTP.Port,
+		"SMTP_USERNAME":         &reminders.SMTP.Username,
+		"SMTP_PASSWORD":         &reminders.SMTP.Password,
+		"SMTP_FROM":             &reminders.SMTP.From,
+		"SMTP_TO":               &reminders.SMTP.To,
+		"REMINDERS_WEBHOOK_URL": &reminders.Webhook.URL,
+	} {
+		if value := os.Getenv(env); value != "" {
+			*target = value
+		}
+	}
+
+	// Set reminder defaults
+	if reminders.Interval <= 0 {
+		reminders.Interval = time.Hour
+	}
+	if reminders.LeadTime <= 0 {
+		reminders.LeadTime = 72 * time.Hour
+	}
+	if reminders.TrialLeadTime <= 0 {
+		reminders.TrialLeadTime = 7 * 24 * time.Hour
+	}
+	if reminders.MaxAttempts <= 0 {
+		reminders.MaxAttempts = 3
+	}
+	if reminders.BatchSize <= 0 {
+		reminders.BatchSize = 100
+	}
+	if reminders.Notifier == "" {
+		reminders.Notifier = "log"
+	}
+	if reminders.SMTP.Port == "" {
+		reminders.SMTP.Port = "25"
+	}
+	if reminders.Webhook.Timeout <= 0 {
+		reminders.Webhook.Timeout = 10 * time.Second
+	}
+	return nil
+}
+
+// loadWebhooks applies environment overrides and defaults to the webhook delivery settings
+func loadWebhooks(webhooks *WebhooksConfig) error {
+	for env, target := range map[string]*time.Duration{
+		"WEBHOOKS_POLL_INTERVAL":   &webhooks.PollInterval,
+		"WEBHOOKS_INITIAL_BACKOFF": &webhooks.InitialBackoff,
+		"WEBHOOKS_MAX_BACKOFF":     &webhooks.MaxBackoff,
+		"WEBHOOKS_TIMEOUT":         &webhooks.Timeout,
+	} {
+		if value := os.Getenv(env); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", env, err)
+			}
+			*target = duration
+		}
+	}
+	if maxAttempts := os.Getenv("WEBHOOKS_MAX_ATTEMPTS"); maxAttempts != "" {
+		attempts, err := strconv.Atoi(maxAttempts)
+		if err != nil {
+			return fmt.Errorf("invalid WEBHOOKS_MAX_ATTEMPTS: %w", err)
+		}
+		webhooks.MaxAttempts = attempts
+	}
+
+	// Set webhook defaults
+	if webhooks.PollInterval <= 0 {
+		webhooks.PollInterval = 5 * time.Second
+	}
+	if webhooks.MaxAttempts <= 0 {
+		webhooks.MaxAttempts = 8
+	}
+	if webhooks.InitialBackoff <= 0 {
+		webhooks.InitialBackoff = 30 * time.Second
+	}
+	if webhooks.MaxBackoff <= 0 {
+		webhooks.MaxBackoff = 6 * time.Hour
+	}
+	if webhooks.Timeout <= 0 {
+		webhooks.Timeout = 10 * time.Second
+	}
+	if webhooks.BatchSize <= 0 {
+		webhooks.BatchSize = 100
+	}
+	return nil
+}
+
+// loadIdempotency applies environment overrides and defaults to the idempotency key settings
+func loadIdempotency(idempotency *IdempotencyConfig) error {
+	for env, target := range map[string]*time.Duration{
+		"IDEMPOTENCY_TTL":            &idempotency.TTL,
+		"IDEMPOTENCY_PURGE_INTERVAL": &idempotency.PurgeInterval,
+	} {
+		if value := os.Getenv(env); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", env, err)
+			}
+			*target = duration
+		}
+	}
+
+	// Set idempotency defaults
+	if idempotency.TTL <= 0 {
+		idempotency.TTL = 24 * time.Hour
+	}
+	if idempotency.PurgeInterval <= 0 {
+		idempotency.PurgeInterval = time.Hour
+	}
+	return nil
+}
+
+func loadTrash(trash *TrashConfig) error {
+	for env, target := range map[string]*time.Duration{
+		"TRASH_RETENTION":      &trash.Retention,
+		"TRASH_PURGE_INTERVAL": &trash.PurgeInterval,
+	} {
+		if value := os.Getenv(env); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", env, err)
+			}
+			*target = duration
+		}
+	}
+
+	// Set trash defaults
+	if trash.Retention <= 0 {
+		trash.Retention = 30 * 24 * time.Hour
+	}
+	if trash.PurgeInterval <= 0 {
+		trash.PurgeInterval = time.Hour
+	}
+	return nil
+}
+
 func (c *Config) GetDatabaseDSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host,
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAuth_RequiresRealSecretForHS256(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		err    string
+	}{
+		{"unset", "", "AUTH_SECRET is required for HS256"},
+		{"shipped placeholder", "change-me-in-production", "AUTH_SECRET is a placeholder, set it to a random secret"},
+		{"placeholder in another case", "  CHANGEME ", "AUTH_SECRET is a placeholder, set it to a random secret"},
+		{"too short", strings.Repeat("k", 31), "AUTH_SECRET must be at least 32 bytes long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_ALGORITHM", "")
+			t.Setenv("AUTH_SECRET", "")
+			t.Setenv("JWT_SECRET", "")
+
+			err := loadAuth(&AuthConfig{Secret: tt.secret})
+
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestLoadAuth_SecretFromEnvironment(t *testing.T) {
+	secret := strings.Repeat("k", 32)
+	t.Setenv("JWT_ALGORITHM", "")
+
+	t.Run("AUTH_SECRET replaces the configured secret", func(t *testing.T) {
+		t.Setenv("AUTH_SECRET", secret)
+		t.Setenv("JWT_SECRET", "")
+
+		auth := &AuthConfig{Secret: "change-me-in-production"}
+		assert.NoError(t, loadAuth(auth))
+		assert.Equal(t, "HS256", auth.Algorithm)
+		assert.Equal(t, secret, auth.Secret)
+	})
+
+	t.Run("JWT_SECRET is still read", func(t *testing.T) {
+		t.Setenv("AUTH_SECRET", "")
+		t.Setenv("JWT_SECRET", secret)
+
+		auth := &AuthConfig{}
+		assert.NoError(t, loadAuth(auth))
+		assert.Equal(t, secret, auth.Secret)
+	})
+
+	t.Run("AUTH_SECRET takes precedence over JWT_SECRET", func(t *testing.T) {
+		t.Setenv("AUTH_SECRET", secret)
+		t.Setenv("JWT_SECRET", "change-me-in-production")
+
+		auth := &AuthConfig{}
+		assert.NoError(t, loadAuth(auth))
+		assert.Equal(t, secret, auth.Secret)
+	})
+}
+
+func TestLoadAuth_RS256NeedsNoSecret(t *testing.T) {
+	t.Setenv("AUTH_SECRET", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_ALGORITHM", "RS256")
+
+	auth := &AuthConfig{}
+	assert.NoError(t, loadAuth(auth))
+	assert.Equal(t, "RS256", auth.Algorithm)
+}
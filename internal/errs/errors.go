@@ -0,0 +1,147 @@
+package errs
+
+import (
+	"errors"
+)
+
+// Sentinel errors identifying the category of a domain error. Match them with errors.Is.
+var (
+	ErrValidation    = errors.New("validation failed")
+	ErrNotFound      = errors.New("not found")
+	ErrConflict      = errors.New("conflict")
+	ErrMediaType     = errors.New("unsupported media type")
+	ErrPrecondition  = errors.New("precondition failed")
+	ErrUnprocessable = errors.New("unprocessable request")
+	ErrForbidden     = errors.New("forbidden")
+	ErrTimeout       = errors.New("timeout")
+	ErrInternal      = errors.New("internal error")
+)
+
+// Machine-readable error codes returned to API clients
+const (
+	CodeInvalidInput         = "invalid_input"
+	CodeRequired             = "required"
+	CodeInvalidDateFormat    = "invalid_date_format"
+	CodeInvalidDateRange     = "invalid_date_range"
+	CodeInvalidPrice         = "invalid_price"
+	CodeInvalidCurrency      = "invalid_currency"
+	CodeInvalidRate          = "invalid_rate"
+	CodeInvalidBillingPeriod = "invalid_billing_period"
+	CodeInvalidIntervalCount = "invalid_billing_interval_count"
+	CodeInvalidID            = "invalid_id"
+	CodeInvalidCursor        = "invalid_cursor"
+	CodeInvalidSort          = "invalid_sort"
+	CodeInvalidJSON          = "invalid_json"
+	CodeBatchTooLarge        = "batch_too_large"
+	CodeInvalidCSV           = "invalid_csv"
+	CodeImportTooLarge       = "import_too_large"
+	CodeUnknownField         = "unknown_field"
+	CodeInvalidType          = "invalid_type"
+	CodeSubscriptionNotFound = "subscription_not_found"
+	CodeRouteNotFound        = "route_not_found"
+	CodeSubscriptionExists   = "subscription_exists"
+	CodeVersionConflict      = "version_conflict"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeIdempotencyKeyInUse  = "idempotency_key_in_use"
+	CodeExchangeRateNotFound = "exchange_rate_not_found"
+	CodeInvalidURL           = "invalid_url"
+	CodeInvalidEventType     = "invalid_event_type"
+	CodeWebhookNotFound      = "webhook_not_found"
+	CodeServiceNotFound      = "service_not_found"
+	CodeServiceExists        = "service_exists"
+	CodeBudgetNotFound       = "budget_not_found"
+	CodeBudgetExists         = "budget_exists"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodePreconditionFailed   = "precondition_failed"
+	CodeTimeout              = "timeout"
+	CodeInternal             = "internal_error"
+)
+
+// FieldError describes a problem with a single input field
+type FieldError struct {
+	Field   string `json:"field" example:"start_date"`
+	Code    string `json:"code" example:"invalid_date_format"`
+	Message string `json:"message" example:"start_date must be in MM-YYYY format"`
+}
+
+// Error is a domain error carrying its category, a machine code and the offending field(s)
+type Error struct {
+	Kind    error
+	Code    string
+	Field   string
+	Message string
+	Details []FieldError
+}
+
+// Error returns the human-readable message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap exposes the sentinel category so errors.Is works
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// FieldErrors returns the field-level details, deriving one from Field when no explicit details exist
+func (e *Error) FieldErrors() []FieldError {
+	if len(e.Details) > 0 {
+		return e.Details
+	}
+	if e.Field != "" {
+		return []FieldError{{Field: e.Field, Code: e.Code, Message: e.Message}}
+	}
+	return nil
+}
+
+// Validation creates a validation error for a single field
+func Validation(field, code, message string) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Field: field, Message: message}
+}
+
+// ValidationFields creates a validation error that covers several fields
+func ValidationFields(message string, details []FieldError) *Error {
+	return &Error{Kind: ErrValidation, Code: CodeInvalidInput, Message: message, Details: details}
+}
+
+// NotFound creates a not-found error
+func NotFound(code, message string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+// Conflict creates a conflict error for the given field
+func Conflict(field, code, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Field: field, Message: message}
+}
+
+// UnsupportedMediaType creates an error for request bodies in a format the endpoint does not accept
+func UnsupportedMediaType(message string) *Error {
+	return &Error{Kind: ErrMediaType, Code: CodeUnsupportedMediaType, Message: message}
+}
+
+// PreconditionFailed creates an error for writes whose If-Match does not match the current version
+func PreconditionFailed(message string) *Error {
+	return &Error{Kind: ErrPrecondition, Code: CodePreconditionFailed, Message: message}
+}
+
+// Unprocessable creates an error for well-formed requests that cannot be applied as sent
+func Unprocessable(field, code, message string) *Error {
+	return &Error{Kind: ErrUnprocessable, Code: code, Field: field, Message: message}
+}
+
+// Forbidden creates an error for callers acting on data they do not own
+func Forbidden(field, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: CodeForbidden, Field: field, Message: message}
+}
+
+// Timeout creates an error for requests that ran past their deadline
+func Timeout(message string) *Error {
+	return &Error{Kind: ErrTimeout, Code: CodeTimeout, Message: message}
+}
+
+// Internal creates an error for failures the client cannot fix
+func Internal(message string) *Error {
+	return &Error{Kind: ErrInternal, Code: CodeInternal, Message: message}
+}
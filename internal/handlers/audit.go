@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type AuditHandler struct {
+	service service.AuditServiceInterface
+	logger  *logrus.Logger
+}
+
+func NewAuditHandler(service service.AuditServiceInterface, logger *logrus.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetSubscriptionHistory lists the changes made to a subscription
+// @Summary Get subscription history
+// @Description Retrieve the audit events of a subscription, newest first. Each event names the actor, the action and the value of every changed field before and after the change. Deleted subscriptions keep their history until they are purged.
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param limit query int false "Number of results to return (default: 50)"
+// @Param offset query int false "Number of results to skip (default: 0)"
+// @Success 200 {array} models.AuditEvent "History retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid subscription ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Subscription not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed"
+// @Router /subscriptions/{id}/history [get]
+func (h *AuditHandler) GetSubscriptionHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		h.logger.WithError(err).WithField("subscription_id", idStr).Error("Invalid subscription ID format")
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid subscription ID"))
+		return
+	}
+
+	limit, offset := parseLimitOffset(c)
+	events, err := h.service.ListHistory(c.Request.Context(), uint(id), limit, offset)
+	if err != nil {
+		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to list subscription history")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"subscription_id": id,
+		"count":           len(events),
+	}).Info("Subscription history retrieved successfully")
+	c.JSON(http.StatusOK, events)
+}
+
+// ListAuditEvents lists the audit log of subscription changes
+// @Summary List audit events
+// @Description Retrieve the audit events matching the filters, newest first. Regular users only see events of their own subscriptions.
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Param subscription_id query int false "Filter by subscription ID"
+// @Param user_id query string false "Filter by the subscriptions' owner (UUID format)"
+// @Param actor query string false "Filter by actor, e.g. a user ID or an X-Actor value"
+// @Param action query string false "Filter by action" Enums(create, update, delete, restore, purge)
+// @Param from query string false "Only events at or after this time (RFC 3339)"
+// @Param to query string false "Only events before this time (RFC 3339)"
+// @Param limit query int false "Number of results to return (default: 50)"
+// @Param offset query int false "Number of results to skip (default: 0)"
+// @Success 200 {array} models.AuditEvent "Audit events retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid filter"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Cannot access another user's events"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed"
+// @Router /audit [get]
+func (h *AuditHandler) ListAuditEvents(c *gin.Context) {
+	filter := &models.AuditFilterRequest{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+	}
+
+	if subscriptionIDStr := c.Query("subscription_id"); subscriptionIDStr != "" {
+		parsed, err := strconv.ParseUint(subscriptionIDStr, 10, 32)
+		if err != nil {
+			respondWithError(c, errs.Validation("subscription_id", errs.CodeInvalidID, "Invalid subscription_id"))
+			return
+		}
+		subscriptionID := uint(parsed)
+		filter.SubscriptionID = &subscriptionID
+	}
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		parsedUUID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			respondWithError(c, errs.Validation("user_id", errs.CodeInvalidInput, "Invalid user_id format"))
+			return
+		}
+		filter.UserID = &parsedUUID
+	}
+
+	limit, offset := parseLimitOffset(c)
+	events, err := h.service.ListEvents(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list audit events")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithField("count", len(events)).Info("Audit events retrieved successfully")
+	c.JSON(http.StatusOK, events)
+}
+
+// parseLimitOffset reads the limit and offset query parameters, ignoring malformed values
+func parseLimitOffset(c *gin.Context) (int, int) {
+	limit := 50 // default
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+			limit = parsedLimit
+		}
+	}
+
+	offset := 0 // default
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil {
+			offset = parsedOffset
+		}
+	}
+	return limit, offset
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditService is a mock implementation of AuditServiceInterface
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) ListHistory(ctx context.Context, subscriptionID uint, limit, offset int) ([]models.AuditEvent, error) {
+	args := m.Called(ctx, subscriptionID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+func (m *MockAuditService) ListEvents(ctx context.Context, filter *models.AuditFilterRequest, limit, offset int) ([]models.AuditEvent, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+func setupAuditRouter() (*gin.Engine, *MockAuditService) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	mockService := &MockAuditService{}
+	handler := NewAuditHandler(mockService, logger)
+
+	router := gin.New()
+	router.GET("/subscriptions/:id/history", handler.GetSubscriptionHistory)
+	router.GET("/audit", handler.ListAuditEvents)
+	return router, mockService
+}
+
+func TestGetSubscriptionHistory(t *testing.T) {
+	router, mockService := setupAuditRouter()
+	mockService.On("ListHistory", mock.Anything, uint(1), 10, 0).Return([]models.AuditEvent{{
+		ID:             7,
+		SubscriptionID: 1,
+		Actor:          "support@example.com",
+		Action:         models.AuditActionUpdate,
+		Changes:        models.RawJSON(`{"price":{"before":999,"after":1199}}`),
+	}}, nil).Once()
+	mockService.On("ListHistory", mock.Anything, uint(2), 50, 0).Return(nil, errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions/1/history?limit=10", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"action":"update"`)
+	assert.Contains(t, w.Body.String(), `"changes":{"price":{"before":999,"after":1199}}`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions/2/history", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions/abc/history", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListAuditEvents(t *testing.T) {
+	router, mockService := setupAuditRouter()
+
+	userID := uuid.New()
+	subscriptionID := uint(3)
+	mockService.On("ListEvents", mock.Anything, &models.AuditFilterRequest{
+		SubscriptionID: &subscriptionID,
+		UserID:         &userID,
+		Actor:          "support@example.com",
+		Action:         "delete",
+		From:           "2025-01-01T00:00:00Z",
+	}, 50, 5).Return([]models.AuditEvent{}, nil).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/audit?subscription_id=3&user_id="+userID.String()+"&actor=support@example.com&action=delete&from=2025-01-01T00:00:00Z&offset=5", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+
+	for _, query := range []string{"subscription_id=x", "user_id=nope"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/audit?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type BudgetHandler struct {
+	service service.BudgetServiceInterface
+	logger  *logrus.Logger
+}
+
+func NewBudgetHandler(service service.BudgetServiceInterface, logger *logrus.Logger) *BudgetHandler {
+	return &BudgetHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateBudget sets a monthly spending budget
+// @Summary Create budget
+// @Description Set a monthly spending limit over every subscription of a user, those of a catalog category or those of one service. The user defaults to the caller. Creating a subscription or changing its price so that the current or one of the next two months goes over the limit publishes a budget.exceeded webhook event.
+// @Tags budgets
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param budget body models.CreateBudgetRequest true "Budget"
+// @Success 201 {object} models.Budget "Budget created successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid scope, category, service, limit or currency"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Budget for another user"
+// @Failure 409 {object} models.ErrorResponse "Conflict - The user already has a budget with this scope"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /budgets [post]
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	h.logger.Info("Received request to create budget")
+
+	var req models.CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to bind JSON")
+		respondWithError(c, errs.Validation("", errs.CodeInvalidJSON, "Invalid JSON format"))
+		return
+	}
+
+	created, err := h.service.CreateBudget(c.Request.Context(), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create budget")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListBudgets lists spending budgets
+// @Summary List budgets
+// @Description List the budgets of a user ordered by ID; regular users only see their own
+// @Tags budgets
+// @Security BearerAuth
+// @Produce json
+// @Param user_id query string false "Filter by user ID (UUID)"
+// @Success 200 {array} models.Budget "Budgets retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid user_id"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's budgets"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed"
+// @Router /budgets [get]
+func (h *BudgetHandler) ListBudgets(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	budgets, err := h.service.ListBudgets(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list budgets")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithField("count", len(budgets)).Info("Budgets retrieved successfully")
+	c.JSON(http.StatusOK, budgets)
+}
+
+// GetBudgetStatus reports the spend of every budget against its limit
+// @Summary Get budget status
+// @Description Report the spend of every budget of a user in the current month and the months after it, costed like a cost calculation of each month and converted into the budget's currency
+// @Tags budgets
+// @Security BearerAuth
+// @Produce json
+// @Param user_id query string false "Filter by user ID (UUID)"
+// @Param months query int false "Number of months, the current one included (default: 3, max: 12)"
+// @Success 200 {array} models.BudgetStatus "Budget status calculated successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid query parameters or missing exchange rate"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's budgets"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed"
+// @Router /budgets/status [get]
+func (h *BudgetHandler) GetBudgetStatus(c *gin.Context) {
+	userID, ok := h.parseUserID(c)
+	if !ok {
+		return
+	}
+
+	months := service.DefaultBudgetMonths
+	if monthsStr := c.Query("months"); monthsStr != "" {
+		parsed, err := strconv.Atoi(monthsStr)
+		if err != nil {
+			h.logger.WithError(err).WithField("months", monthsStr).Error("Invalid months format")
+			respondWithError(c, errs.Validation("months", errs.CodeInvalidInput, "months must be an integer"))
+			return
+		}
+		months = parsed
+	}
+
+	statuses, err := h.service.GetBudgetStatus(c.Request.Context(), userID, months)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get budget status")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
+
+// GetBudget retrieves a budget
+// @Summary Get budget by ID
+// @Description Retrieve a budget of the caller
+// @Tags budgets
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Budget ID"
+// @Success 200 {object} models.Budget "Budget retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid budget ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Budget not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /budgets/{id} [get]
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	budget, err := h.service.GetBudget(c.Request.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).WithField("budget_id", id).Error("Failed to get budget")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// UpdateBudget changes the limit of a budget
+// @Summary Update budget
+// @Description Change the monthly limit or currency of a budget. The scope cannot be changed; delete the budget and create another one instead.
+// @Tags budgets
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Budget ID"
+// @Param updates body models.UpdateBudgetRequest true "Fields to update"
+// @Success 200 {object} models.Budget "Budget updated successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid input data or validation errors"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Budget not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /budgets/{id} [put]
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).WithField("budget_id", id).Error("Failed to bind JSON for update")
+		respondWithError(c, errs.Validation("", errs.CodeInvalidJSON, "Invalid JSON format"))
+		return
+	}
+
+	updated, err := h.service.UpdateBudget(c.Request.Context(), id, &req)
+	if err != nil {
+		h.logger.WithError(err).WithField("budget_id", id).Error("Failed to update budget")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteBudget removes a budget
+// @Summary Delete budget
+// @Description Remove a budget of the caller
+// @Tags budgets
+// @Security BearerAuth
+// @Param id path int true "Budget ID"
+// @Success 204 "Budget deleted successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid budget ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Budget not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /budgets/{id} [delete]
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBudget(c.Request.Context(), id); err != nil {
+		h.logger.WithError(err).WithField("budget_id", id).Error("Failed to delete budget")
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseID reads the budget ID path parameter, responding with 400 when it is malformed
+func (h *BudgetHandler) parseID(c *gin.Context) (uint, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		h.logger.WithError(err).WithField("budget_id", idStr).Error("Invalid budget ID format")
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid budget ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseUserID reads the optional user_id query parameter, responding with 400 when it is malformed
+func (h *BudgetHandler) parseUserID(c *gin.Context) (*uuid.UUID, bool) {
+	userIDStr := c.Query("user_id")
+	if userIDStr == "" {
+		return nil, true
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.logger.WithError(err).WithField("user_id", userIDStr).Error("Invalid user_id format")
+		respondWithError(c, errs.Validation("user_id", errs.CodeInvalidInput, "Invalid user_id format"))
+		return nil, false
+	}
+	return &userID, true
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBudgetService is a mock implementation of BudgetServiceInterface
+type MockBudgetService struct {
+	mock.Mock
+}
+
+func (m *MockBudgetService) CreateBudget(ctx context.Context, req *models.CreateBudgetRequest) (*models.Budget, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetService) GetBudget(ctx context.Context, id uint) (*models.Budget, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetService) ListBudgets(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetService) UpdateBudget(ctx context.Context, id uint, req *models.UpdateBudgetRequest) (*models.Budget, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetService) DeleteBudget(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockBudgetService) GetBudgetStatus(ctx context.Context, userID *uuid.UUID, months int) ([]models.BudgetStatus, error) {
+	args := m.Called(ctx, userID, months)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BudgetStatus), args.Error(1)
+}
+
+func setupBudgetRouter() (*gin.Engine, *MockBudgetService) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	mockService := &MockBudgetService{}
+	handler := NewBudgetHandler(mockService, logger)
+
+	router := gin.New()
+	router.POST("/budgets", handler.CreateBudget)
+	router.GET("/budgets", handler.ListBudgets)
+	router.GET("/budgets/status", handler.GetBudgetStatus)
+	router.GET("/budgets/:id", handler.GetBudget)
+	router.PUT("/budgets/:id", handler.UpdateBudget)
+	router.DELETE("/budgets/:id", handler.DeleteBudget)
+	return router, mockService
+}
+
+func TestCreateBudget(t *testing.T) {
+	router, mockService := setupBudgetRouter()
+	streaming := models.ServiceCategoryStreaming
+	mockService.On("CreateBudget", mock.Anything, &models.CreateBudgetRequest{Scope: "category", Category: "streaming", MonthlyLimit: 2000}).Return(&models.Budget{
+		ID:           1,
+		UserID:       uuid.New(),
+		Scope:        models.BudgetScopeCategory,
+		Category:     &streaming,
+		MonthlyLimit: 2000,
+		Currency:     "RUB",
+	}, nil).Once()
+	mockService.On("CreateBudget", mock.Anything, &models.CreateBudgetRequest{MonthlyLimit: 1000}).Return(nil, errs.Conflict("scope", errs.CodeBudgetExists, "the user already has an overall budget")).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/budgets", bytes.NewBufferString(`{"scope":"category","category":"streaming","monthly_limit":2000}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"category":"streaming"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/budgets", bytes.NewBufferString(`{"monthly_limit":1000}`)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/budgets", bytes.NewBufferString(`{"monthly_limit":`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetBudgetStatus(t *testing.T) {
+	router, mockService := setupBudgetRouter()
+	userID := uuid.New()
+	mockService.On("GetBudgetStatus", mock.Anything, (*uuid.UUID)(nil), service.DefaultBudgetMonths).Return([]models.BudgetStatus{}, nil).Once()
+	mockService.On("GetBudgetStatus", mock.Anything, &userID, 6).Return([]models.BudgetStatus{{
+		Budget: models.Budget{ID: 1, UserID: userID, Scope: models.BudgetScopeOverall, MonthlyLimit: 2000, Currency: "RUB"},
+		Months: []models.BudgetMonth{{Period: models.YearMonth{Year: 2025, Month: 3}, Spent: 2297, Remaining: -297, OverBudget: true}},
+	}}, nil).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/budgets/status", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/budgets/status?months=6&user_id="+userID.String(), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"over_budget":true`)
+
+	for _, query := range []string{"?months=three", "?user_id=nope"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/budgets/status"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateAndDeleteBudget(t *testing.T) {
+	router, mockService := setupBudgetRouter()
+	limit := 2500
+	mockService.On("UpdateBudget", mock.Anything, uint(1), &models.UpdateBudgetRequest{MonthlyLimit: &limit}).Return(&models.Budget{ID: 1, MonthlyLimit: 2500, Currency: "RUB"}, nil).Once()
+	mockService.On("DeleteBudget", mock.Anything, uint(1)).Return(nil).Once()
+	mockService.On("DeleteBudget", mock.Anything, uint(2)).Return(errs.NotFound(errs.CodeBudgetNotFound, "budget not found")).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/budgets/1", bytes.NewBufferString(`{"monthly_limit":2500}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"monthly_limit":2500`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/budgets/1", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/budgets/2", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/budgets/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
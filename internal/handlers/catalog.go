@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type CatalogHandler struct {
+	service service.CatalogServiceInterface
+	logger  *logrus.Logger
+}
+
+func NewCatalogHandler(service service.CatalogServiceInterface, logger *logrus.Logger) *CatalogHandler {
+	return &CatalogHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateService adds a service to the catalog
+// @Summary Create catalog service
+// @Description Add a service to the catalog (admin only). Subscriptions created under its name or one of its aliases, in any case and spacing, are linked to it, take its canonical name and, when created without a price, its default price in its currency.
+// @Tags services
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param service body models.CreateServiceRequest true "Catalog service"
+// @Success 201 {object} models.Service "Service created successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid name, alias, category, price or currency"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 409 {object} models.ErrorResponse "Conflict - Name or alias already belongs to another service"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /services [post]
+func (h *CatalogHandler) CreateService(c *gin.Context) {
+	h.logger.Info("Received request to create catalog service")
+
+	var req models.CreateServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to bind JSON")
+		respondWithError(c, errs.Validation("", errs.CodeInvalidJSON, "Invalid JSON format"))
+		return
+	}
+
+	created, err := h.service.CreateService(c.Request.Context(), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create catalog service")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListServices lists the service catalog
+// @Summary List catalog services
+// @Description List the catalogued services ordered by name
+// @Tags services
+// @Security BearerAuth
+// @Produce json
+// @Param category query string false "Filter by category" Enums(streaming, music, video, cloud, software, gaming, news, education, fitness, other)
+// @Success 200 {array} models.Service "Services retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid category"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed"
+// @Router /services [get]
+func (h *CatalogHandler) ListServices(c *gin.Context) {
+	services, err := h.service.ListServices(c.Request.Context(), c.Query("category"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list catalog services")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithField("count", len(services)).Info("Catalog services retrieved successfully")
+	c.JSON(http.StatusOK, services)
+}
+
+// GetService retrieves a catalogued service
+// @Summary Get catalog service by ID
+// @Description Retrieve a catalogued service with its aliases
+// @Tags services
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} models.Service "Service retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid service ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Service not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /services/{id} [get]
+func (h *CatalogHandler) GetService(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	found, err := h.service.GetService(c.Request.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).WithField("service_id", id).Error("Failed to get catalog service")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, found)
+}
+
+// UpdateService changes a catalogued service
+// @Summary Update catalog service
+// @Description Change the name, aliases, category, default price or currency of a catalogued service (admin only). A new aliases list replaces the old one; default_price may be null to clear it. Subscriptions keep the name they were created with.
+// @Tags services
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param updates body models.UpdateServiceRequest true "Fields to update"
+// @Success 200 {object} models.Service "Service updated successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid input data or validation errors"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Service not found"
+// @Failure 409 {object} models.ErrorResponse "Conflict - Name or alias already belongs to another service"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /services/{id} [put]
+func (h *CatalogHandler) UpdateService(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).WithField("service_id", id).Error("Failed to bind JSON for update")
+		respondWithError(c, errs.Validation("", errs.CodeInvalidJSON, "Invalid JSON format"))
+		return
+	}
+
+	updated, err := h.service.UpdateService(c.Request.Context(), id, &req)
+	if err != nil {
+		h.logger.WithError(err).WithField("service_id", id).Error("Failed to update catalog service")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteService removes a service from the catalog
+// @Summary Delete catalog service
+// @Description Remove a service and its aliases from the catalog (admin only). Subscriptions linked to it keep their name and lose the link.
+// @Tags services
+// @Security BearerAuth
+// @Param id path int true "Service ID"
+// @Success 204 "Service deleted successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid service ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Service not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /services/{id} [delete]
+func (h *CatalogHandler) DeleteService(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteService(c.Request.Context(), id); err != nil {
+		h.logger.WithError(err).WithField("service_id", id).Error("Failed to delete catalog service")
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseID reads the service ID path parameter, responding with 400 when it is malformed
+func (h *CatalogHandler) parseID(c *gin.Context) (uint, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		h.logger.WithError(err).WithField("service_id", idStr).Error("Invalid service ID format")
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid service ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCatalogService is a mock implementation of CatalogServiceInterface
+type MockCatalogService struct {
+	mock.Mock
+}
+
+func (m *MockCatalogService) CreateService(ctx context.Context, req *models.CreateServiceRequest) (*models.Service, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Service), args.Error(1)
+}
+
+func (m *MockCatalogService) GetService(ctx context.Context, id uint) (*models.Service, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Service), args.Error(1)
+}
+
+func (m *MockCatalogService) ListServices(ctx context.Context, category string) ([]models.Service, error) {
+	args := m.Called(ctx, category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Service), args.Error(1)
+}
+
+func (m *MockCatalogService) UpdateService(ctx context.Context, id uint, req *models.UpdateServiceRequest) (*models.Service, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Service), args.Error(1)
+}
+
+func (m *MockCatalogService) DeleteService(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func setupCatalogRouter() (*gin.Engine, *MockCatalogService) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	mockService := &MockCatalogService{}
+	handler := NewCatalogHandler(mockService, logger)
+
+	router := gin.New()
+	router.POST("/services", handler.CreateService)
+	router.GET("/services", handler.ListServices)
+	router.GET("/services/:id", handler.GetService)
+	router.PUT("/services/:id", handler.UpdateService)
+	router.DELETE("/services/:id", handler.DeleteService)
+	return router, mockService
+}
+
+func TestCreateService(t *testing.T) {
+	router, mockService := setupCatalogRouter()
+	mockService.On("CreateService", mock.Anything, &models.CreateServiceRequest{Name: "Netflix", Aliases: []string{"Netflix HD"}, Category: "streaming"}).Return(&models.Service{
+		ID:       1,
+		Name:     "Netflix",
+		Aliases:  []string{"netflix hd"},
+		Category: models.ServiceCategoryStreaming,
+		Currency: "RUB",
+	}, nil).Once()
+	mockService.On("CreateService", mock.Anything, &models.CreateServiceRequest{Name: "netflix"}).Return(nil, errs.Conflict("name", errs.CodeServiceExists, `"netflix" already belongs to another service`)).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/services", bytes.NewBufferString(`{"name":"Netflix","aliases":["Netflix HD"],"category":"streaming"}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"aliases":["netflix hd"]`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/services", bytes.NewBufferString(`{"name":"netflix"}`)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/services", bytes.NewBufferString(`{"name":`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListAndGetServices(t *testing.T) {
+	router, mockService := setupCatalogRouter()
+	mockService.On("ListServices", mock.Anything, "music").Return([]models.Service{{ID: 2, Name: "Spotify", Aliases: []string{}, Category: models.ServiceCategoryMusic}}, nil).Once()
+	mockService.On("GetService", mock.Anything, uint(3)).Return(nil, errs.NotFound(errs.CodeServiceNotFound, "service not found")).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/services?category=music", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Spotify"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/services/3", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/services/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateAndDeleteService(t *testing.T) {
+	router, mockService := setupCatalogRouter()
+	mockService.On("UpdateService", mock.Anything, uint(1), mock.MatchedBy(func(req *models.UpdateServiceRequest) bool {
+		return req.DefaultPrice.Set && req.DefaultPrice.Value == nil && req.Name == nil
+	})).Return(&models.Service{ID: 1, Name: "Netflix"}, nil).Once()
+	mockService.On("DeleteService", mock.Anything, uint(1)).Return(nil).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/services/1", bytes.NewBufferString(`{"default_price":null}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/services/1", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
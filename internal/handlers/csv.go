@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+)
+
+// Media types accepted for CSV uploads
+const (
+	mediaTypeCSV       = "text/csv"
+	mediaTypeMultipart = "multipart/form-data"
+)
+
+// maxImportBytes limits the size of an uploaded CSV file
+const maxImportBytes = 10 << 20
+
+// requiredImportColumns must be present in the header of an import
+var requiredImportColumns = []string{"service_name", "price", "start_date"}
+
+// utf8BOM is written at the start of CSV files by some spreadsheet applications
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// readImportCSV reads the rows of an import file. The first line is a header naming the columns,
+// in any order; only service_name, price and start_date are required, and the columns of a CSV
+// export that the import has no use for are skipped. Rows are numbered by the line they start on.
+func readImportCSV(r io.Reader) ([]models.ImportRow, error) {
+	buffered := bufio.NewReader(r)
+	if prefix, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		_, _ = buffered.Discard(len(utf8BOM))
+	}
+
+	// Rows with the wrong number of fields are rejected on their own instead of failing the file
+	reader := csv.NewReader(buffered)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errs.Validation("file", errs.CodeRequired, "the CSV file is empty")
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if slices.Contains(models.IgnoredImportColumns, name) {
+			continue
+		}
+		if !slices.Contains(models.ImportColumns, name) {
+			return nil, errs.Validation("file", errs.CodeInvalidCSV, fmt.Sprintf("unknown column %q, expected %s", name, strings.Join(models.ImportColumns, ",")))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, errs.Validation("file", errs.CodeInvalidCSV, fmt.Sprintf("column %q appears more than once", name))
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, errs.Validation("file", errs.CodeInvalidCSV, fmt.Sprintf("missing column %q", name))
+		}
+	}
+
+	value := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+
+	var rows []models.ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			rows = append(rows, models.ImportRow{
+				Line: line,
+				Err:  errs.Validation("file", errs.CodeInvalidCSV, fmt.Sprintf("expected %d fields, found %d", len(header), len(record))),
+			})
+			continue
+		}
+		rows = append(rows, models.ImportRow{
+			Line:                 line,
+			ServiceName:          csvUnescape(value(record, "service_name")),
+			Price:                value(record, "price"),
+			Currency:             value(record, "currency"),
+			BillingPeriod:        value(record, "billing_period"),
+			BillingIntervalCount: value(record, "billing_interval_count"),
+			UserID:               value(record, "user_id"),
+			StartDate:            value(record, "start_date"),
+			EndDate:              value(record, "end_date"),
+			TrialEnd:             value(record, "trial_end"),
+			TrialPrice:           value(record, "trial_price"),
+		})
+	}
+	return rows, nil
+}
+
+// csvUnescape reverses csvSafe, so that exported names keep their leading character on import
+func csvUnescape(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// csvError describes a file that could not be read as CSV
+func csvError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errs.Validation("file", errs.CodeImportTooLarge, fmt.Sprintf("the CSV file must not exceed %d bytes", maxBytesErr.Limit))
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return errs.Validation("file", errs.CodeInvalidCSV, fmt.Sprintf("line %d: %s", parseErr.Line, parseErr.Err))
+	}
+	return errs.Validation("file", errs.CodeInvalidCSV, "failed to read the CSV file")
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"slices"
+	"strings"
+	"subscription_tracker_api/internal/errs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Media types accepted for request bodies
+const (
+	mediaTypeJSON       = "application/json"
+	mediaTypeMergePatch = "application/merge-patch+json"
+)
+
+// nullable is implemented by fields that accept null in a merge patch, i.e. models.Nullable
+type nullable interface {
+	IsNull() bool
+}
+
+// requireContentType rejects request bodies whose Content-Type is not one of the accepted media types
+func requireContentType(c *gin.Context, accepted ...string) error {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || !slices.Contains(accepted, mediaType) {
+		return errs.UnsupportedMediaType("Content-Type must be " + strings.Join(accepted, " or "))
+	}
+	return nil
+}
+
+// decodeBody checks the Content-Type and strictly decodes the JSON object body into target
+func decodeBody(c *gin.Context, target interface{}, mergePatch bool, accepted ...string) error {
+	if err := requireContentType(c, accepted...); err != nil {
+		return err
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		return errs.Validation("", errs.CodeInvalidJSON, "Failed to read request body")
+	}
+	return decodeJSONObject(body, target, mergePatch)
+}
+
+// decodeJSONObject decodes a JSON object into the struct pointed to by target. Unlike
+// ShouldBindJSON it reports every unknown or ill-typed field as a field-level error. With
+// mergePatch set, null is only accepted for nullable fields, since it would remove the field.
+func decodeJSONObject(body []byte, target interface{}, mergePatch bool) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+		return errs.Validation("", errs.CodeInvalidJSON, "Request body must be a JSON object")
+	}
+
+	value := reflect.ValueOf(target).Elem()
+	fields := make(map[string]reflect.Value, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = value.Field(i)
+		}
+	}
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var fieldErrs []errs.FieldError
+	for _, key := range keys {
+		field, ok := fields[key]
+		if !ok {
+			fieldErrs = append(fieldErrs, errs.FieldError{Field: key, Code: errs.CodeUnknownField, Message: "unknown field " + key})
+			continue
+		}
+
+		if mergePatch && bytes.Equal(bytes.TrimSpace(raw[key]), []byte("null")) {
+			if _, ok := field.Addr().Interface().(nullable); !ok {
+				fieldErrs = append(fieldErrs, errs.FieldError{Field: key, Code: errs.CodeInvalidType, Message: key + " cannot be null"})
+				continue
+			}
+		}
+
+		if err := json.Unmarshal(raw[key], field.Addr().Interface()); err != nil {
+			fieldErrs = append(fieldErrs, errs.FieldError{Field: key, Code: errs.CodeInvalidType, Message: typeErrorMessage(key, err)})
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return errs.ValidationFields("invalid request body", fieldErrs)
+	}
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// typeErrorMessage describes why a field value could not be decoded
+func typeErrorMessage(field string, err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return field + " has an invalid value"
+	}
+
+	kind := typeErr.Type.Kind()
+	switch {
+	case reflect.PointerTo(typeErr.Type).Implements(textUnmarshalerType):
+		// Types such as uuid.UUID are encoded as strings
+		return field + " must be a string"
+	case kind >= reflect.Int && kind <= reflect.Uint64:
+		return field + " must be an integer"
+	case kind == reflect.String:
+		return field + " must be a string"
+	case kind == reflect.Bool:
+		return field + " must be a boolean"
+	case kind == reflect.Slice || kind == reflect.Array:
+		return field + " must be an array"
+	default:
+		return fmt.Sprintf("%s cannot be a %s", field, typeErr.Value)
+	}
+}
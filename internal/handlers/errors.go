@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getStatusCodeForError determines the HTTP status code matching a domain error
+func getStatusCodeForError(err error) int {
+	switch {
+	case errors.Is(err, errs.ErrValidation):
+		return http.StatusBadRequest // 400
+	case errors.Is(err, errs.ErrForbidden):
+		return http.StatusForbidden // 403
+	case errors.Is(err, errs.ErrNotFound):
+		return http.StatusNotFound // 404
+	case errors.Is(err, errs.ErrConflict):
+		return http.StatusConflict // 409
+	case errors.Is(err, errs.ErrPrecondition):
+		return http.StatusPreconditionFailed // 412
+	case errors.Is(err, errs.ErrMediaType):
+		return http.StatusUnsupportedMediaType // 415
+	case errors.Is(err, errs.ErrUnprocessable):
+		return http.StatusUnprocessableEntity // 422
+	case errors.Is(err, errs.ErrTimeout):
+		return http.StatusGatewayTimeout // 504
+	default:
+		return http.StatusInternalServerError // 500
+	}
+}
+
+// respondWithError writes err as an ErrorResponse with the matching HTTP status code.
+// Internal failures caused by the request running past its deadline are reported as timeouts.
+func respondWithError(c *gin.Context, err error) {
+	err = requestError(c, err)
+	c.JSON(getStatusCodeForError(err), newErrorResponse(err))
+}
+
+// requestError reports internal failures caused by the request running past its deadline as
+// timeouts and leaves other errors unchanged
+func requestError(c *gin.Context, err error) error {
+	if errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) && getStatusCodeForError(err) == http.StatusInternalServerError {
+		return errs.Timeout("request timed out")
+	}
+	return err
+}
+
+// newErrorResponse converts an error into the API error payload. Errors that are not
+// domain errors are reported generically so internal details do not leak to clients.
+func newErrorResponse(err error) models.ErrorResponse {
+	var domainErr *errs.Error
+	if errors.As(err, &domainErr) {
+		return models.ErrorResponse{
+			Error:   domainErr.Message,
+			Code:    domainErr.Code,
+			Details: domainErr.FieldErrors(),
+		}
+	}
+	return models.ErrorResponse{
+		Error: "internal server error",
+		Code:  errs.CodeInternal,
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ExchangeRateHandler struct {
+	service service.ExchangeRateServiceInterface
+	logger  *logrus.Logger
+}
+
+func NewExchangeRateHandler(service service.ExchangeRateServiceInterface, logger *logrus.Logger) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListExchangeRates lists every stored exchange rate
+// @Summary List exchange rates
+// @Description List the exchange rates used to convert cost reports (admin only)
+// @Tags exchange-rates
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.ExchangeRate "Exchange rates retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed"
+// @Router /exchange-rates [get]
+func (h *ExchangeRateHandler) ListExchangeRates(c *gin.Context) {
+	h.logger.Info("Received request to list exchange rates")
+
+	rates, err := h.service.ListRates(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list exchange rates")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithField("count", len(rates)).Info("Exchange rates retrieved successfully")
+	c.JSON(http.StatusOK, rates)
+}
+
+// SetExchangeRate creates or replaces an exchange rate
+// @Summary Set exchange rate
+// @Description Create or replace the rate converting one currency into another (admin only)
+// @Tags exchange-rates
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param from path string true "Source ISO-4217 currency code"
+// @Param to path string true "Target ISO-4217 currency code"
+// @Param rate body models.SetExchangeRateRequest true "Exchange rate"
+// @Success 200 {object} models.ExchangeRate "Exchange rate stored successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid currency or rate"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /exchange-rates/{from}/{to} [put]
+func (h *ExchangeRateHandler) SetExchangeRate(c *gin.Context) {
+	from, to := c.Param("from"), c.Param("to")
+	h.logger.WithFields(logrus.Fields{
+		"from_currency": from,
+		"to_currency":   to,
+	}).Info("Received request to set exchange rate")
+
+	var req models.SetExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to bind JSON")
+		respondWithError(c, errs.Validation("", errs.CodeInvalidJSON, "Invalid JSON format"))
+		return
+	}
+
+	rate, err := h.service.SetRate(c.Request.Context(), from, to, req.Rate)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to set exchange rate")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// DeleteExchangeRate removes an exchange rate
+// @Summary Delete exchange rate
+// @Description Delete the rate converting one currency into another (admin only)
+// @Tags exchange-rates
+// @Security BearerAuth
+// @Param from path string true "Source ISO-4217 currency code"
+// @Param to path string true "Target ISO-4217 currency code"
+// @Success 204 "Exchange rate deleted successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid currency"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Exchange rate does not exist"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /exchange-rates/{from}/{to} [delete]
+func (h *ExchangeRateHandler) DeleteExchangeRate(c *gin.Context) {
+	from, to := c.Param("from"), c.Param("to")
+	h.logger.WithFields(logrus.Fields{
+		"from_currency": from,
+		"to_currency":   to,
+	}).Info("Received request to delete exchange rate")
+
+	if err := h.service.DeleteRate(c.Request.Context(), from, to); err != nil {
+		h.logger.WithError(err).Error("Failed to delete exchange rate")
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
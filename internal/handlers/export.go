@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"subscription_tracker_api/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Media types of exported files
+const (
+	mediaTypeNDJSON   = "application/x-ndjson"
+	mediaTypeCalendar = "text/calendar"
+)
+
+// download streams a file to the client. The status line and headers are only sent with the
+// first buffered chunk, so an error raised before any data is flushed can still be answered
+// with an ErrorResponse.
+type download struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+	w           *bufio.Writer
+}
+
+func newDownload(c *gin.Context, contentType, filename string) *download {
+	return &download{c: c, contentType: contentType, filename: filename}
+}
+
+// Write buffers p, starting the response when the buffer is first flushed
+func (d *download) Write(p []byte) (int, error) {
+	if d.w == nil {
+		d.w = bufio.NewWriterSize(writerFunc(d.send), 32<<10)
+	}
+	return d.w.Write(p)
+}
+
+// Close flushes the remaining data, starting the response if nothing was sent yet
+func (d *download) Close() error {
+	if d.w == nil {
+		d.start()
+		return nil
+	}
+	return d.w.Flush()
+}
+
+// Started reports whether the status line has been sent
+func (d *download) Started() bool {
+	return d.c.Writer.Written()
+}
+
+// Abort ends a download that failed after its status line was sent. The marker is appended to
+// the data, then the connection is closed without finishing the response, so that clients see a
+// truncated transfer instead of a complete file.
+func (d *download) Abort(marker []byte) {
+	if len(marker) > 0 {
+		_, _ = d.Write(marker)
+	}
+	if d.w != nil {
+		_ = d.w.Flush()
+	}
+	d.c.Writer.Flush()
+	if conn, _, err := d.c.Writer.Hijack(); err == nil {
+		_ = conn.Close()
+	}
+	d.c.Abort()
+}
+
+func (d *download) start() {
+	if d.Started() {
+		return
+	}
+	d.c.Header("Content-Type", d.contentType+"; charset=utf-8")
+	d.c.Header("Content-Disposition", `attachment; filename="`+d.filename+`"`)
+	d.c.Writer.WriteHeaderNow()
+}
+
+func (d *download) send(p []byte) (int, error) {
+	d.start()
+	return d.c.Writer.Write(p)
+}
+
+// writerFunc adapts a function to io.Writer
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// subscriptionCSVHeader names the columns of a subscriptions CSV export
+var subscriptionCSVHeader = []string{
+	"id", "service_name", "service_id", "price", "currency", "billing_period", "billing_interval_count",
+	"user_id", "start_date", "end_date", "trial_end", "trial_price", "version", "created_at", "updated_at",
+}
+
+// subscriptionCSVRecord formats a subscription as a row of subscriptionCSVHeader
+func subscriptionCSVRecord(subscription *models.Subscription) []string {
+	return []string{
+		strconv.FormatUint(uint64(subscription.ID), 10),
+		csvSafe(subscription.ServiceName),
+		optionalID(subscription.ServiceID),
+		strconv.Itoa(subscription.Price),
+		subscription.Currency,
+		string(subscription.BillingPeriod),
+		strconv.Itoa(subscription.BillingIntervalCount),
+		subscription.UserID.String(),
+		subscription.StartDate.String(),
+		optionalMonth(subscription.EndDate),
+		optionalMonth(subscription.TrialEnd),
+		strconv.Itoa(subscription.TrialPrice),
+		strconv.Itoa(subscription.Version),
+		subscription.CreatedAt.UTC().Format(time.RFC3339),
+		subscription.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// costCSVHeader names the columns of a cost calculation CSV export
+var costCSVHeader = []string{
+	"id", "service_name", "user_id", "price", "currency", "billing_period", "billing_interval_count",
+	"start_date", "end_date", "charges", "subtotal",
+}
+
+// costCSVRecord formats a subscription's share of a cost calculation as a row of costCSVHeader
+func costCSVRecord(cost *models.SubscriptionCost) []string {
+	return []string{
+		strconv.FormatUint(uint64(cost.ID), 10),
+		csvSafe(cost.ServiceName),
+		cost.UserID.String(),
+		strconv.Itoa(cost.Price),
+		cost.Currency,
+		string(cost.BillingPeriod),
+		strconv.Itoa(cost.BillingIntervalCount),
+		cost.StartDate.String(),
+		optionalMonth(cost.EndDate),
+		strconv.Itoa(cost.Charges),
+		strconv.Itoa(cost.Subtotal),
+	}
+}
+
+// exportErrorMarker is the last line of an export that failed after data was sent: a "#error"
+// row for CSV and an ErrorResponse line for JSONL. Calendars are left without END:VCALENDAR.
+func exportErrorMarker(format models.ExportFormat, response models.ErrorResponse) []byte {
+	var marker bytes.Buffer
+	switch format {
+	case models.ExportFormatCSV:
+		writer := csv.NewWriter(&marker)
+		_ = writer.Write([]string{"#error", response.Code, response.Error})
+		writer.Flush()
+	case models.ExportFormatJSONL:
+		_ = json.NewEncoder(&marker).Encode(response)
+	}
+	return marker.Bytes()
+}
+
+// optionalMonth formats an optional month, leaving the cell empty when it is not set
+func optionalMonth(month *models.YearMonth) string {
+	if month == nil {
+		return ""
+	}
+	return month.String()
+}
+
+// optionalID formats an optional ID, leaving it empty when unset
+func optionalID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
+
+// csvSafe keeps spreadsheet applications from evaluating user-provided text as a formula
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
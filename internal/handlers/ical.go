@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"subscription_tracker_api/internal/models"
+	"unicode/utf8"
+)
+
+// icalDate is the iCalendar DATE format
+const icalDate = "20060102"
+
+// icalLineLength is the maximum length of a content line in octets, excluding the line break
+const icalLineLength = 75
+
+// calendarWriter writes an iCalendar (RFC 5545) feed with a recurring all-day event for the
+// remaining charges of each subscription. Write errors are kept and reported by End.
+type calendarWriter struct {
+	w   io.Writer
+	err error
+}
+
+func newCalendarWriter(w io.Writer) *calendarWriter {
+	return &calendarWriter{w: w}
+}
+
+// Begin writes the calendar header
+func (cw *calendarWriter) Begin() {
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:-//Subscription Tracker//Billing Calendar//EN")
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	cw.line("X-WR-CALNAME:Subscription charges")
+}
+
+// Event writes the event of one subscription, starting on its next charge and repeating every
+// billing cycle until its end date. The amount is left out: the price of the charges in a
+// series changes with the price history and at the end of a trial.
+func (cw *calendarWriter) Event(schedule *models.ChargeSchedule) {
+	subscription := schedule.Subscription
+	cw.line("BEGIN:VEVENT")
+	cw.line(fmt.Sprintf("UID:subscription-%d@subscription-tracker", subscription.ID))
+	cw.line("DTSTAMP:" + subscription.UpdatedAt.UTC().Format("20060102T150405Z"))
+	cw.line("DTSTART;VALUE=DATE:" + schedule.NextCharge.Format(icalDate))
+	cw.line("DTEND;VALUE=DATE:" + schedule.NextCharge.AddDate(0, 0, 1).Format(icalDate))
+	cw.line("RRULE:" + recurrenceRule(subscription))
+	cw.line("SUMMARY:" + icalText(subscription.ServiceName))
+	cw.line("TRANSP:TRANSPARENT")
+	cw.line("END:VEVENT")
+}
+
+// End writes the calendar footer and returns the first write error
+func (cw *calendarWriter) End() error {
+	cw.line("END:VCALENDAR")
+	return cw.err
+}
+
+// line writes a content line, folding it into continuation lines of at most icalLineLength
+// octets without splitting a UTF-8 sequence
+func (cw *calendarWriter) line(content string) {
+	if cw.err != nil {
+		return
+	}
+	var b strings.Builder
+	limit := icalLineLength
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// Continuation lines start with a space, which counts towards their length
+		limit = icalLineLength - 1
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+	_, cw.err = io.WriteString(cw.w, b.String())
+}
+
+// recurrenceRule describes the billing cycle of a subscription as an RRULE value. The rule
+// ends on the last day of the end month, which is still billed.
+func recurrenceRule(subscription *models.Subscription) string {
+	intervalCount := subscription.BillingIntervalCount
+	if intervalCount < 1 {
+		intervalCount = 1
+	}
+
+	frequency := "MONTHLY"
+	switch subscription.BillingPeriod {
+	case models.BillingPeriodWeek:
+		frequency = "WEEKLY"
+	case models.BillingPeriodQuarter:
+		intervalCount *= 3
+	case models.BillingPeriodYear:
+		frequency = "YEARLY"
+	}
+
+	rule := "FREQ=" + frequency
+	if intervalCount > 1 {
+		rule += fmt.Sprintf(";INTERVAL=%d", intervalCount)
+	}
+	if subscription.EndDate != nil {
+		rule += ";UNTIL=" + subscription.EndDate.AddMonths(1).Time().AddDate(0, 0, -1).Format(icalDate)
+	}
+	return rule
+}
+
+// icalText escapes a TEXT property value
+var icalText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace
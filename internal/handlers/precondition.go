@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag exposes the subscription version as a strong entity tag
+func setETag(c *gin.Context, subscription *models.Subscription) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(subscription.Version)))
+}
+
+// parseIfMatch turns the If-Match header into a precondition. Without the header, or with "*",
+// the request is unconditional. If-Match uses strong comparison, so weak and malformed entity
+// tags are dropped and can never match.
+func parseIfMatch(c *gin.Context) *models.Precondition {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	precondition := &models.Precondition{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			precondition.Versions = append(precondition.Versions, version)
+		}
+	}
+	return precondition
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"subscription_tracker_api/internal/errs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CustomMethods dispatches custom methods such as POST /subscriptions:batch. gin cannot register
+// a literal colon inside a path segment, so the methods share one route with a :resource
+// parameter and are looked up by its value, e.g. "subscriptions:batch".
+func CustomMethods(methods map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler, ok := methods[c.Param("resource")]
+		if !ok {
+			respondWithError(c, errs.NotFound(errs.CodeRouteNotFound, "route not found"))
+			return
+		}
+		handler(c)
+	}
+}
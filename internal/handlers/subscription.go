@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"subscription_tracker_api/internal/errs"
 	"subscription_tracker_api/internal/models"
 	"subscription_tracker_api/internal/service"
 
@@ -28,12 +34,18 @@ func NewSubscriptionHandler(service service.SubscriptionServiceInterface, logger
 // @Summary Create a new subscription
 // @Description Create a new subscription for a user
 // @Tags subscriptions
+// @Security BearerAuth
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Unique key of this create; retries with the same key and body replay the first response"
 // @Param subscription body models.CreateSubscriptionRequest true "Subscription data"
 // @Success 201 {object} models.Subscription "Subscription created successfully"
+// @Header 201 {string} Idempotent-Replayed "true when the response was replayed for a retried Idempotency-Key"
 // @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid input data or validation errors"
-// @Failure 409 {object} models.ErrorResponse "Conflict - Duplicate subscription or constraint violation"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's subscriptions"
+// @Failure 409 {object} models.ErrorResponse "Conflict - Duplicate subscription, constraint violation or Idempotency-Key still in use"
+// @Failure 422 {object} models.ErrorResponse "Unprocessable Entity - Idempotency-Key reused with a different body"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
 // @Router /subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
@@ -43,7 +55,7 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to bind JSON")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format"})
+		respondWithError(c, errs.Validation("", errs.CodeInvalidJSON, "Invalid JSON format"))
 		return
 	}
 
@@ -54,16 +66,22 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 		"start_date":   req.StartDate,
 	}).Info("Creating subscription with validated input")
 
-	subscription, err := h.service.CreateSubscription(&req)
+	var subscription *models.Subscription
+	var replayed bool
+	var err error
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		subscription, replayed, err = h.service.CreateSubscriptionIdempotent(c.Request.Context(), &req, key)
+	} else {
+		subscription, err = h.service.CreateSubscription(c.Request.Context(), &req)
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create subscription")
-
-		// Determine appropriate status code based on error type
-		statusCode := h.getStatusCodeForError(err)
-
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		respondWithError(c, err)
 		return
 	}
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
 
 	h.logger.WithFields(logrus.Fields{
 		"subscription_id": subscription.ID,
@@ -77,11 +95,17 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 // @Summary Get subscription by ID
 // @Description Retrieve a single subscription by its ID
 // @Tags subscriptions
+// @Security BearerAuth
 // @Produce json
 // @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the expected version"
 // @Success 200 {object} models.Subscription "Subscription retrieved successfully"
+// @Header 200 {string} ETag "Current version of the subscription"
 // @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid subscription ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
 // @Failure 404 {object} models.ErrorResponse "Not Found - Subscription not found"
+// @Failure 412 {object} models.ErrorResponse "Precondition Failed - If-Match does not match the current ETag"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
 // @Router /subscriptions/{id} [get]
 func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 	idStr := c.Param("id")
@@ -91,14 +115,18 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
 		h.logger.WithError(err).WithField("subscription_id", idStr).Error("Invalid subscription ID format")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid subscription ID"))
 		return
 	}
 
-	subscription, err := h.service.GetSubscriptionByID(uint(id))
+	subscription, err := h.service.GetSubscriptionByID(c.Request.Context(), uint(id))
 	if err != nil {
 		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to get subscription")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+		respondWithError(c, err)
+		return
+	}
+	if !parseIfMatch(c).Matches(subscription.Version) {
+		respondWithError(c, errs.PreconditionFailed("subscription has been modified"))
 		return
 	}
 
@@ -108,23 +136,30 @@ func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
 		"user_id":         subscription.UserID,
 	}).Info("Subscription retrieved successfully")
 
+	setETag(c, subscription)
 	c.JSON(http.StatusOK, subscription)
 }
 
-// UpdateSubscription updates an existing subscription
-// @Summary Update an existing subscription
-// @Description Update subscription details by ID
+// UpdateSubscription partially updates an existing subscription
+// @Summary Partially update a subscription
+// @Description Apply a JSON Merge Patch (RFC 7396) to a subscription. Omitted fields are left unchanged, end_date: null makes the subscription open-ended and trial_end: null ends the trial, resetting trial_price. Unknown or ill-typed fields are rejected.
 // @Tags subscriptions
-// @Accept json
+// @Security BearerAuth
+// @Accept json,application/merge-patch+json
 // @Produce json
 // @Param id path int true "Subscription ID"
-// @Param updates body map[string]interface{} true "Fields to update"
+// @Param If-Match header string false "ETag of the version the patch is based on"
+// @Param updates body models.UpdateSubscriptionRequest true "Fields to update"
 // @Success 200 {object} models.Subscription "Subscription updated successfully"
-// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid input data or validation errors"
+// @Header 200 {string} ETag "New version of the subscription"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid input data, unknown or ill-typed fields"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
 // @Failure 404 {object} models.ErrorResponse "Not Found - Subscription does not exist"
-// @Failure 409 {object} models.ErrorResponse "Conflict - Constraint violation during update"
+// @Failure 409 {object} models.ErrorResponse "Conflict - Duplicate subscription or concurrent update"
+// @Failure 412 {object} models.ErrorResponse "Precondition Failed - If-Match does not match the current ETag"
+// @Failure 415 {object} models.ErrorResponse "Unsupported Media Type - Body is not merge-patch+json or json"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
-// @Router /subscriptions/{id} [put]
+// @Router /subscriptions/{id} [patch]
 func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	idStr := c.Param("id")
 
@@ -133,49 +168,105 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
 		h.logger.WithError(err).WithField("subscription_id", idStr).Error("Invalid subscription ID format")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid subscription ID"))
 		return
 	}
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to bind JSON for update")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format"})
+	var req models.UpdateSubscriptionRequest
+	if err := decodeBody(c, &req, true, mediaTypeMergePatch, mediaTypeJSON); err != nil {
+		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to decode merge patch")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithField("subscription_id", id).Info("Processing subscription update with validated input")
+
+	subscription, err := h.service.UpdateSubscription(c.Request.Context(), uint(id), &req, parseIfMatch(c))
+	if err != nil {
+		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to update subscription")
+		respondWithError(c, err)
 		return
 	}
 
 	h.logger.WithFields(logrus.Fields{
 		"subscription_id": id,
-		"updates":         updates,
-	}).Info("Processing subscription update with validated input")
+		"service_name":    subscription.ServiceName,
+	}).Info("Subscription update request completed successfully")
+
+	setETag(c, subscription)
+	c.JSON(http.StatusOK, subscription)
+}
 
-	subscription, err := h.service.UpdateSubscription(uint(id), updates)
+// ReplaceSubscription replaces an existing subscription
+// @Summary Replace a subscription
+// @Description Replace every field of a subscription. Optional fields that are omitted are reset to their defaults and an omitted end_date makes the subscription open-ended. user_id may be omitted but cannot be changed. Catalog defaults do not apply: an omitted price is rejected and an omitted currency is reset to RUB.
+// @Tags subscriptions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the version being replaced"
+// @Param subscription body models.CreateSubscriptionRequest true "Subscription data"
+// @Success 200 {object} models.Subscription "Subscription replaced successfully"
+// @Header 200 {string} ETag "New version of the subscription"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid input data, unknown or ill-typed fields"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Subscription does not exist"
+// @Failure 409 {object} models.ErrorResponse "Conflict - Duplicate subscription or concurrent update"
+// @Failure 412 {object} models.ErrorResponse "Precondition Failed - If-Match does not match the current ETag"
+// @Failure 415 {object} models.ErrorResponse "Unsupported Media Type - Body is not json"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /subscriptions/{id} [put]
+func (h *SubscriptionHandler) ReplaceSubscription(c *gin.Context) {
+	idStr := c.Param("id")
+
+	h.logger.WithField("subscription_id", idStr).Info("Received request to replace subscription")
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to update subscription")
+		h.logger.WithError(err).WithField("subscription_id", idStr).Error("Invalid subscription ID format")
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid subscription ID"))
+		return
+	}
 
-		// Determine appropriate status code based on error type
-		statusCode := h.getStatusCodeForError(err)
+	var req models.CreateSubscriptionRequest
+	if err := decodeBody(c, &req, false, mediaTypeJSON); err != nil {
+		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to decode replacement")
+		respondWithError(c, err)
+		return
+	}
 
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+	subscription, err := h.service.ReplaceSubscription(c.Request.Context(), uint(id), &req, parseIfMatch(c))
+	if err != nil {
+		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to replace subscription")
+		respondWithError(c, err)
 		return
 	}
 
 	h.logger.WithFields(logrus.Fields{
 		"subscription_id": id,
 		"service_name":    subscription.ServiceName,
-	}).Info("Subscription update request completed successfully")
+	}).Info("Subscription replace request completed successfully")
 
+	setETag(c, subscription)
 	c.JSON(http.StatusOK, subscription)
 }
 
 // DeleteSubscription deletes a subscription
 // @Summary Delete subscription by ID
-// @Description Delete a subscription by its ID
+// @Description Move a subscription to the trash, from where it can be restored until the retention period ends. Admins can delete a subscription permanently, including one already in the trash, with hard=true.
 // @Tags subscriptions
+// @Security BearerAuth
 // @Param id path int true "Subscription ID"
+// @Param hard query bool false "Delete permanently instead of moving to the trash (admins only)"
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 204 "Subscription deleted successfully"
 // @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid subscription ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - hard=true requested by a non-admin"
 // @Failure 404 {object} models.ErrorResponse "Not Found - Subscription not found"
+// @Failure 409 {object} models.ErrorResponse "Conflict - Concurrent update"
+// @Failure 412 {object} models.ErrorResponse "Precondition Failed - If-Match does not match the current ETag"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
 // @Router /subscriptions/{id} [delete]
 func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
@@ -186,103 +277,645 @@ func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
 		h.logger.WithError(err).WithField("subscription_id", idStr).Error("Invalid subscription ID format")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid subscription ID"))
 		return
 	}
 
-	err = h.service.DeleteSubscription(uint(id))
+	hard := false
+	if hardStr := c.Query("hard"); hardStr != "" {
+		hard, err = strconv.ParseBool(hardStr)
+		if err != nil {
+			respondWithError(c, errs.Validation("hard", errs.CodeInvalidInput, "hard must be a boolean"))
+			return
+		}
+	}
+
+	if hard {
+		err = h.service.PurgeSubscription(c.Request.Context(), uint(id), parseIfMatch(c))
+	} else {
+		err = h.service.DeleteSubscription(c.Request.Context(), uint(id), parseIfMatch(c))
+	}
 	if err != nil {
 		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to delete subscription")
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondWithError(c, err)
 		return
 	}
 
-	h.logger.WithField("subscription_id", id).Info("Subscription deletion request completed successfully")
+	h.logger.WithFields(logrus.Fields{
+		"subscription_id": id,
+		"hard":            hard,
+	}).Info("Subscription deletion request completed successfully")
 
 	c.Status(http.StatusNoContent)
 }
 
+// ListPriceHistory lists the prices a subscription has had
+// @Summary Get subscription price history
+// @Description List the prices of a subscription, oldest first. Each price applies from its effective_from month until the next one, and cost calculations charge every month at the price in effect then.
+// @Tags subscriptions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {array} models.SubscriptionPrice "Price history retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid subscription ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Subscription not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /subscriptions/{id}/prices [get]
+func (h *SubscriptionHandler) ListPriceHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		h.logger.WithError(err).WithField("subscription_id", idStr).Error("Invalid subscription ID format")
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid subscription ID"))
+		return
+	}
+
+	prices, err := h.service.ListPriceHistory(c.Request.Context(), uint(id))
+	if err != nil {
+		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to list subscription prices")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"subscription_id": id,
+		"price_count":     len(prices),
+	}).Info("Subscription price history retrieved successfully")
+	c.JSON(http.StatusOK, prices)
+}
+
+// RestoreSubscription takes a subscription out of the trash
+// @Summary Restore a deleted subscription
+// @Description Undo the deletion of a subscription that is still in the trash. Fails with 409 when a subscription for the same user, service and start month was created in the meantime.
+// @Tags subscriptions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param If-Match header string false "ETag of the deleted version"
+// @Success 200 {object} models.Subscription "Subscription restored successfully"
+// @Header 200 {string} ETag "Version of the restored subscription"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid subscription ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 404 {object} models.ErrorResponse "Not Found - No such subscription in the trash"
+// @Failure 409 {object} models.ErrorResponse "Conflict - Duplicate subscription or concurrent update"
+// @Failure 412 {object} models.ErrorResponse "Precondition Failed - If-Match does not match the current ETag"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /subscriptions/{id}/restore [post]
+func (h *SubscriptionHandler) RestoreSubscription(c *gin.Context) {
+	idStr := c.Param("id")
+
+	h.logger.WithField("subscription_id", idStr).Info("Received request to restore subscription")
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		h.logger.WithError(err).WithField("subscription_id", idStr).Error("Invalid subscription ID format")
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid subscription ID"))
+		return
+	}
+
+	subscription, err := h.service.RestoreSubscription(c.Request.Context(), uint(id), parseIfMatch(c))
+	if err != nil {
+		h.logger.WithError(err).WithField("subscription_id", id).Error("Failed to restore subscription")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithField("subscription_id", id).Info("Subscription restore request completed successfully")
+
+	setETag(c, subscription)
+	c.JSON(http.StatusOK, subscription)
+}
+
+// ListTrash lists soft-deleted subscriptions
+// @Summary List deleted subscriptions
+// @Description Retrieve a page of subscriptions in the trash, most recently deleted first, with the time each one will be purged permanently. Takes the filters of GET /subscriptions; pages are addressed with offset.
+// @Tags subscriptions
+// @Security BearerAuth
+// @Produce json
+// @Param user_id query string false "Filter by user ID (UUID)"
+// @Param service_name query []string false "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names" collectionFormat(multi)
+// @Param price_min query int false "Minimum price"
+// @Param price_max query int false "Maximum price"
+// @Param active_on query string false "Only subscriptions active in this month (MM-YYYY)"
+// @Param status query string false "Status relative to the current month" Enums(active, ended, upcoming)
+// @Param started_after query string false "Only subscriptions starting after this month (MM-YYYY)"
+// @Param started_before query string false "Only subscriptions starting before this month (MM-YYYY)"
+// @Param trial_ends_within query int false "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)"
+// @Param sort query string false "Comma separated sort fields (price, start_date, end_date, service_name, created_at), prefix with - for descending"
+// @Param limit query int false "Number of results to return (default: 50)"
+// @Param offset query int false "Number of results to skip (default: 0)"
+// @Param include_total query bool false "Include the total number of matching subscriptions"
+// @Success 200 {object} models.TrashPage "Deleted subscriptions retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's subscriptions"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Failed to retrieve subscriptions"
+// @Router /subscriptions/trash [get]
+func (h *SubscriptionHandler) ListTrash(c *gin.Context) {
+	h.logger.Info("Received request to list deleted subscriptions")
+
+	filter, err := h.parseFilter(c)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+	page, err := h.parsePage(c)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	result, err := h.service.ListTrash(c.Request.Context(), filter, page)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list deleted subscriptions")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithField("subscription_count", len(result.Items)).Info("Successfully retrieved deleted subscriptions")
+
+	c.JSON(http.StatusOK, result)
+}
+
+// BatchSubscriptions applies several create, update and delete operations in one request
+// @Summary Create, update and delete subscriptions in bulk
+// @Description Apply a list of operations. In atomic mode (default) all of them run in one transaction and the first failure rolls back the batch and is returned with the failed operation's index. In best_effort mode every operation runs on its own and the response reports a status per operation. Create data is validated like POST /subscriptions, update data is a merge patch validated like PATCH /subscriptions/{id}.
+// @Tags subscriptions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param batch body models.BatchRequest true "Operations to apply"
+// @Success 200 {object} models.BatchResponse "Result of every operation"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Malformed operations, too many operations or, in atomic mode, a failed validation"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's subscriptions (atomic mode)"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Subscription does not exist (atomic mode)"
+// @Failure 409 {object} models.ErrorResponse "Conflict - Duplicate subscription or concurrent update (atomic mode)"
+// @Failure 412 {object} models.ErrorResponse "Precondition Failed - Version mismatch (atomic mode)"
+// @Failure 415 {object} models.ErrorResponse "Unsupported Media Type - Body is not json"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /subscriptions:batch [post]
+func (h *SubscriptionHandler) BatchSubscriptions(c *gin.Context) {
+	h.logger.Info("Received request to apply subscription batch")
+
+	if err := requireContentType(c, mediaTypeJSON); err != nil {
+		respondWithError(c, err)
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		respondWithError(c, errs.Validation("", errs.CodeInvalidJSON, "Failed to read request body"))
+		return
+	}
+	req, err := decodeBatchRequest(body)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to decode subscription batch")
+		respondWithError(c, err)
+		return
+	}
+
+	response, err := h.service.ExecuteBatch(c.Request.Context(), req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to apply subscription batch")
+		respondWithError(c, err)
+		return
+	}
+
+	for i := range response.Results {
+		result := &response.Results[i]
+		switch {
+		case result.Err != nil:
+			errResponse := newErrorResponse(result.Err)
+			result.Status = getStatusCodeForError(result.Err)
+			result.Error = &errResponse
+		case result.Op == models.BatchOpCreate:
+			result.Status = http.StatusCreated
+		case result.Op == models.BatchOpDelete:
+			result.Status = http.StatusNoContent
+		default:
+			result.Status = http.StatusOK
+		}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"mode":      response.Mode,
+		"succeeded": response.Succeeded,
+		"failed":    response.Failed,
+	}).Info("Subscription batch request completed successfully")
+
+	c.JSON(http.StatusOK, response)
+}
+
+// batchEnvelope is the outer shape of a batch request, with operations left undecoded so that
+// each one can be decoded strictly
+type batchEnvelope struct {
+	Mode       models.BatchMode  `json:"mode"`
+	Operations []json.RawMessage `json:"operations"`
+}
+
+// decodeBatchRequest strictly decodes a batch and the data of each operation, reporting every
+// malformed field prefixed with the operation's position, e.g. operations[2].data.price
+func decodeBatchRequest(body []byte) (*models.BatchRequest, error) {
+	var envelope batchEnvelope
+	if err := decodeJSONObject(body, &envelope, false); err != nil {
+		return nil, err
+	}
+
+	req := &models.BatchRequest{Mode: envelope.Mode, Operations: make([]models.BatchOperation, len(envelope.Operations))}
+	var fieldErrs []errs.FieldError
+	collect := func(prefix string, err error) {
+		var domainErr *errs.Error
+		if !errors.As(err, &domainErr) {
+			return
+		}
+		for _, fieldErr := range domainErr.FieldErrors() {
+			fieldErr.Field = strings.TrimSuffix(prefix+"."+fieldErr.Field, ".")
+			fieldErrs = append(fieldErrs, fieldErr)
+		}
+		if len(domainErr.FieldErrors()) == 0 {
+			fieldErrs = append(fieldErrs, errs.FieldError{Field: prefix, Code: domainErr.Code, Message: domainErr.Message})
+		}
+	}
+
+	for i, raw := range envelope.Operations {
+		prefix := fmt.Sprintf("operations[%d]", i)
+		op := &req.Operations[i]
+		if err := decodeJSONObject(raw, op, false); err != nil {
+			collect(prefix, err)
+			continue
+		}
+
+		switch op.Op {
+		case models.BatchOpCreate:
+			if len(op.Data) > 0 {
+				op.Create = &models.CreateSubscriptionRequest{}
+				if err := decodeJSONObject(op.Data, op.Create, false); err != nil {
+					collect(prefix+".data", err)
+				}
+			}
+		case models.BatchOpUpdate:
+			if len(op.Data) > 0 {
+				op.Update = &models.UpdateSubscriptionRequest{}
+				if err := decodeJSONObject(op.Data, op.Update, true); err != nil {
+					collect(prefix+".data", err)
+				}
+			}
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return nil, errs.ValidationFields("invalid batch operations", fieldErrs)
+	}
+	return req, nil
+}
+
+// ImportSubscriptions creates subscriptions from an uploaded CSV file
+// @Summary Import subscriptions from CSV
+// @Description Upload a CSV file with a header line naming the columns service_name, price, currency, billing_period, billing_interval_count, user_id, start_date, end_date, trial_end and trial_price (only service_name, price and start_date are required). The other columns of a CSV export are skipped, so an export can be imported again. Every row is validated like POST /subscriptions and imported on its own; rows with errors are listed by line number and not imported. With dry_run=true the rows are only validated and nothing is written. The file is sent either as a text/csv body or as the "file" field of a multipart form.
+// @Tags subscriptions
+// @Security BearerAuth
+// @Accept text/csv,mpfd
+// @Produce json
+// @Param file formData file false "CSV file (multipart uploads)"
+// @Param dry_run query bool false "Only validate the rows (default: false)"
+// @Success 200 {object} models.ImportResponse "Rows accepted and rejected"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Missing, malformed or too large CSV file"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 415 {object} models.ErrorResponse "Unsupported Media Type - Body is neither text/csv nor multipart/form-data"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /subscriptions/import [post]
+func (h *SubscriptionHandler) ImportSubscriptions(c *gin.Context) {
+	h.logger.Info("Received request to import subscriptions")
+
+	dryRun := false
+	if dryRunStr := c.Query("dry_run"); dryRunStr != "" {
+		parsed, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			h.logger.WithError(err).WithField("dry_run", dryRunStr).Error("Invalid dry_run format")
+			respondWithError(c, errs.Validation("dry_run", errs.CodeInvalidInput, "dry_run must be a boolean"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	if err := requireContentType(c, mediaTypeCSV, mediaTypeMultipart); err != nil {
+		respondWithError(c, err)
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	var file io.Reader = c.Request.Body
+	if c.ContentType() == mediaTypeMultipart {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respondWithError(c, csvError(err))
+				return
+			}
+			respondWithError(c, errs.Validation("file", errs.CodeRequired, "file is required"))
+			return
+		}
+		upload, err := fileHeader.Open()
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to open uploaded file")
+			respondWithError(c, errs.Internal("failed to read uploaded file"))
+			return
+		}
+		defer upload.Close()
+		file = upload
+	}
+
+	rows, err := readImportCSV(file)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read import file")
+		respondWithError(c, err)
+		return
+	}
+
+	response, err := h.service.ImportSubscriptions(c.Request.Context(), rows, dryRun)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to import subscriptions")
+		respondWithError(c, err)
+		return
+	}
+
+	for i := range response.Rejections {
+		errResponse := newErrorResponse(response.Rejections[i].Err)
+		response.Rejections[i].Error = &errResponse
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"dry_run":  response.DryRun,
+		"accepted": response.Accepted,
+		"rejected": response.Rejected,
+	}).Info("Subscription import request completed successfully")
+
+	c.JSON(http.StatusOK, response)
+}
+
 // ListSubscriptions retrieves all subscriptions with optional filtering
 // @Summary List subscriptions
-// @Description Retrieve subscriptions with optional filtering
+// @Description Retrieve a page of subscriptions ordered by creation time, with optional filtering. service_name matches whole names ignoring case.
 // @Tags subscriptions
+// @Security BearerAuth
 // @Produce json
 // @Param user_id query string false "Filter by user ID (UUID)"
-// @Param service_name query string false "Filter by service name"
+// @Param service_name query []string false "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names" collectionFormat(multi)
+// @Param price_min query int false "Minimum price"
+// @Param price_max query int false "Maximum price"
+// @Param active_on query string false "Only subscriptions active in this month (MM-YYYY)"
+// @Param status query string false "Status relative to the current month" Enums(active, ended, upcoming)
+// @Param started_after query string false "Only subscriptions starting after this month (MM-YYYY)"
+// @Param started_before query string false "Only subscriptions starting before this month (MM-YYYY)"
+// @Param trial_ends_within query int false "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)"
+// @Param sort query string false "Comma separated sort fields (price, start_date, end_date, service_name, created_at), prefix with - for descending, e.g. price,-start_date"
 // @Param limit query int false "Number of results to return (default: 50)"
-// @Param offset query int false "Number of results to skip (default: 0)"
-// @Success 200 {array} models.Subscription "Subscriptions retrieved successfully"
+// @Param cursor query string false "Opaque cursor from next_cursor or prev_cursor of a previous page"
+// @Param offset query int false "Number of results to skip when no cursor is given (default: 0)"
+// @Param include_total query bool false "Include the total number of matching subscriptions"
+// @Success 200 {object} models.SubscriptionPage "Subscriptions retrieved successfully"
 // @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's subscriptions"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error - Failed to retrieve subscriptions"
 // @Router /subscriptions [get]
 func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
 	h.logger.Info("Received request to list subscriptions")
 
 	// Parse query parameters
+	filter, err := h.parseFilter(c)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	page, err := h.parsePage(c)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_id":       filter.UserID,
+		"service_names": filter.ServiceNames,
+		"sort":          filter.Sort,
+		"limit":         page.Limit,
+		"offset":        page.Offset,
+		"cursor":        page.Cursor != "",
+	}).Info("Processing list subscriptions request with filters")
+
+	result, err := h.service.ListSubscriptions(c.Request.Context(), filter, page)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list subscriptions")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"subscription_count": len(result.Items),
+		"user_id":            filter.UserID,
+		"service_names":      filter.ServiceNames,
+	}).Info("Successfully retrieved subscriptions list")
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListUpcomingCharges lists the next charge of every active subscription
+// @Summary List upcoming charges
+// @Description List the next charge date and amount of every active subscription within the look-ahead window, sorted by date
+// @Tags subscriptions
+// @Security BearerAuth
+// @Produce json
+// @Param user_id query string false "Filter by user ID (UUID)"
+// @Param within_days query int false "Look-ahead window in days (default: 30, max: 366)"
+// @Param limit query int false "Maximum number of charges to return, earliest first (default: 100, max: 1000)"
+// @Success 200 {array} models.UpcomingCharge "Upcoming charges retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's subscriptions"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Failed to retrieve subscriptions"
+// @Router /subscriptions/upcoming [get]
+func (h *SubscriptionHandler) ListUpcomingCharges(c *gin.Context) {
+	h.logger.Info("Received request to list upcoming charges")
+
 	var userID *uuid.UUID
 	if userIDStr := c.Query("user_id"); userIDStr != "" {
 		parsedUUID, err := uuid.Parse(userIDStr)
 		if err != nil {
 			h.logger.WithError(err).WithField("user_id", userIDStr).Error("Invalid user_id format")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id format"})
+			respondWithError(c, errs.Validation("user_id", errs.CodeInvalidInput, "Invalid user_id format"))
 			return
 		}
 		userID = &parsedUUID
 	}
 
-	var serviceName *string
-	if serviceNameStr := c.Query("service_name"); serviceNameStr != "" {
-		serviceName = &serviceNameStr
+	withinDays := service.DefaultUpcomingWithinDays
+	if withinDaysStr := c.Query("within_days"); withinDaysStr != "" {
+		parsedDays, err := strconv.Atoi(withinDaysStr)
+		if err != nil {
+			h.logger.WithError(err).WithField("within_days", withinDaysStr).Error("Invalid within_days format")
+			respondWithError(c, errs.Validation("within_days", errs.CodeInvalidInput, "within_days must be an integer"))
+			return
+		}
+		withinDays = parsedDays
 	}
 
-	limit := 50 // default
+	limit := service.DefaultUpcomingLimit
 	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
-			limit = parsedLimit
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			h.logger.WithError(err).WithField("limit", limitStr).Error("Invalid limit format")
+			respondWithError(c, errs.Validation("limit", errs.CodeInvalidInput, "limit must be an integer"))
+			return
 		}
+		limit = parsedLimit
 	}
 
-	offset := 0 // default
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil {
-			offset = parsedOffset
-		}
+	charges, err := h.service.ListUpcomingCharges(c.Request.Context(), userID, withinDays, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list upcoming charges")
+		respondWithError(c, err)
+		return
 	}
 
 	h.logger.WithFields(logrus.Fields{
+		"charge_count": len(charges),
 		"user_id":      userID,
-		"service_name": serviceName,
+		"within_days":  withinDays,
 		"limit":        limit,
-		"offset":       offset,
-	}).Info("Processing list subscriptions request with filters")
+	}).Info("Successfully retrieved upcoming charges")
+
+	c.JSON(http.StatusOK, charges)
+}
+
+// ExportSubscriptions streams every subscription matching the filters as a file
+// @Summary Export subscriptions
+// @Description Download every subscription matching the filters of GET /subscriptions, without paging. csv has a header line and one row per subscription, jsonl one JSON subscription per line. ics is an iCalendar feed with a recurring all-day event per subscription that starts on its next charge and repeats every billing cycle until its end date; subscriptions without charges left are left out.
+// @Tags subscriptions
+// @Security BearerAuth
+// @Produce text/csv,application/x-ndjson,text/calendar
+// @Param format query string false "File format (default: csv)" Enums(csv, jsonl, ics)
+// @Param user_id query string false "Filter by user ID (UUID)"
+// @Param service_name query []string false "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names" collectionFormat(multi)
+// @Param price_min query int false "Minimum price"
+// @Param price_max query int false "Maximum price"
+// @Param active_on query string false "Only subscriptions active in this month (MM-YYYY)"
+// @Param status query string false "Status relative to the current month" Enums(active, ended, upcoming)
+// @Param started_after query string false "Only subscriptions starting after this month (MM-YYYY)"
+// @Param started_before query string false "Only subscriptions starting before this month (MM-YYYY)"
+// @Param trial_ends_within query int false "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)"
+// @Param sort query string false "Comma separated sort fields (price, start_date, end_date, service_name, created_at), prefix with - for descending, e.g. price,-start_date"
+// @Success 200 {file} file "Exported subscriptions"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid format or query parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's subscriptions"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Failed to retrieve subscriptions"
+// @Router /subscriptions/export [get]
+func (h *SubscriptionHandler) ExportSubscriptions(c *gin.Context) {
+	h.logger.Info("Received request to export subscriptions")
+
+	format := models.ExportFormat(c.DefaultQuery("format", string(models.ExportFormatCSV)))
+	var contentType string
+	switch format {
+	case models.ExportFormatCSV:
+		contentType = mediaTypeCSV
+	case models.ExportFormatJSONL:
+		contentType = mediaTypeNDJSON
+	case models.ExportFormatICS:
+		contentType = mediaTypeCalendar
+	default:
+		respondWithError(c, errs.Validation("format", errs.CodeInvalidInput, "format must be one of csv, jsonl, ics"))
+		return
+	}
 
-	subscriptions, err := h.service.ListSubscriptions(userID, serviceName, limit, offset)
+	filter, err := h.parseFilter(c)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to list subscriptions")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve subscriptions"})
+		respondWithError(c, err)
 		return
 	}
 
-	h.logger.WithFields(logrus.Fields{
-		"subscription_count": len(subscriptions),
-		"user_id":            userID,
-		"service_name":       serviceName,
-	}).Info("Successfully retrieved subscriptions list")
+	ctx := c.Request.Context()
+	file := newDownload(c, contentType, "subscriptions."+string(format))
+	rows := 0
+	switch format {
+	case models.ExportFormatCSV:
+		writer := csv.NewWriter(file)
+		_ = writer.Write(subscriptionCSVHeader)
+		err = h.service.ExportSubscriptions(ctx, filter, func(subscription *models.Subscription) error {
+			rows++
+			return writer.Write(subscriptionCSVRecord(subscription))
+		})
+		// Flush complete rows even on failure, so that an error marker follows the last one
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+	case models.ExportFormatJSONL:
+		encoder := json.NewEncoder(file)
+		err = h.service.ExportSubscriptions(ctx, filter, func(subscription *models.Subscription) error {
+			rows++
+			return encoder.Encode(subscription)
+		})
+	case models.ExportFormatICS:
+		calendar := newCalendarWriter(file)
+		calendar.Begin()
+		err = h.service.ExportChargeSchedules(ctx, filter, func(schedule *models.ChargeSchedule) error {
+			rows++
+			calendar.Event(schedule)
+			return nil
+		})
+		if err == nil {
+			err = calendar.End()
+		}
+	}
+	if err == nil {
+		err = file.Close()
+	}
 
-	c.JSON(http.StatusOK, subscriptions)
+	if err != nil {
+		h.logger.WithError(err).WithField("rows", rows).Error("Failed to export subscriptions")
+		if !file.Started() {
+			respondWithError(c, err)
+			return
+		}
+		// The 200 has already been sent, so mark the file as failed and cut the connection to
+		// keep the partial file from passing as complete
+		file.Abort(exportErrorMarker(format, newErrorResponse(requestError(c, err))))
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"format": format,
+		"rows":   rows,
+	}).Info("Subscriptions exported successfully")
 }
 
 // CalculateTotalCost calculates total cost of subscriptions for a period
 // @Summary Calculate total cost of subscriptions
 // @Description Calculate the total cost of subscriptions within a date range
 // @Tags subscriptions
-// @Produce json
+// @Security BearerAuth
+// @Produce json,text/csv
 // @Param start_date query string true "Start date in MM-YYYY format"
 // @Param end_date query string true "End date in MM-YYYY format"
 // @Param user_id query string false "Filter by user ID (UUID)"
-// @Param service_name query string false "Filter by service name"
+// @Param service_name query []string false "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names" collectionFormat(multi)
+// @Param price_min query int false "Minimum price"
+// @Param price_max query int false "Maximum price"
+// @Param active_on query string false "Only subscriptions active in this month (MM-YYYY)"
+// @Param status query string false "Status relative to the current month" Enums(active, ended, upcoming)
+// @Param started_after query string false "Only subscriptions starting after this month (MM-YYYY)"
+// @Param started_before query string false "Only subscriptions starting before this month (MM-YYYY)"
+// @Param trial_ends_within query int false "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)"
+// @Param sort query string false "Order of the listed subscriptions, e.g. price,-start_date"
+// @Param target_currency query string false "ISO-4217 currency to convert the total into"
+// @Param format query string false "Response format; csv returns one row per subscription with its charges and subtotal (default: json)" Enums(json, csv)
 // @Success 200 {object} models.CostCalculationResponse "Cost calculation completed successfully"
 // @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid date format or missing required parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's subscriptions"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed or server errors"
 // @Router /subscriptions/calculate-cost [get]
 func (h *SubscriptionHandler) CalculateTotalCost(c *gin.Context) {
@@ -294,107 +927,208 @@ func (h *SubscriptionHandler) CalculateTotalCost(c *gin.Context) {
 		EndDate:   c.Query("end_date"),
 	}
 
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		respondWithError(c, errs.Validation("format", errs.CodeInvalidInput, "format must be one of json, csv"))
+		return
+	}
+
 	// Validate required parameters
 	if req.StartDate == "" || req.EndDate == "" {
 		h.logger.Error("Missing required parameters for cost calculation")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date are required"})
+		respondWithError(c, errs.Validation("", errs.CodeRequired, "start_date and end_date are required"))
 		return
 	}
 
-	// Parse optional user_id
-	if userIDStr := c.Query("user_id"); userIDStr != "" {
-		parsedUUID, err := uuid.Parse(userIDStr)
-		if err != nil {
-			h.logger.WithError(err).WithField("user_id", userIDStr).Error("Invalid user_id format")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id format"})
-			return
-		}
-		req.UserID = &parsedUUID
+	// Parse optional filters
+	filter, err := h.parseFilter(c)
+	if err != nil {
+		respondWithError(c, err)
+		return
 	}
+	req.SubscriptionFilterRequest = *filter
 
-	// Parse optional service_name
-	if serviceNameStr := c.Query("service_name"); serviceNameStr != "" {
-		req.ServiceName = &serviceNameStr
+	// Parse optional target_currency
+	if targetCurrencyStr := c.Query("target_currency"); targetCurrencyStr != "" {
+		req.TargetCurrency = &targetCurrencyStr
 	}
 
 	h.logger.WithFields(logrus.Fields{
-		"user_id":      req.UserID,
-		"service_name": req.ServiceName,
-		"start_date":   req.StartDate,
-		"end_date":     req.EndDate,
+		"user_id":         req.UserID,
+		"service_names":   req.ServiceNames,
+		"start_date":      req.StartDate,
+		"end_date":        req.EndDate,
+		"target_currency": req.TargetCurrency,
 	}).Info("Processing cost calculation request with validated parameters")
 
-	response, err := h.service.CalculateTotalCost(req)
+	response, err := h.service.CalculateTotalCost(c.Request.Context(), req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to calculate total cost")
-
-		// Determine appropriate status code based on error type
-		statusCode := h.getStatusCodeForError(err)
-
-		c.JSON(statusCode, gin.H{"error": err.Error()})
+		respondWithError(c, err)
 		return
 	}
 
 	h.logger.WithFields(logrus.Fields{
 		"total_cost":         response.TotalCost,
+		"currency":           response.Currency,
 		"subscription_count": len(response.Subscriptions),
 		"date_range":         req.StartDate + " to " + req.EndDate,
 	}).Info("Cost calculation completed successfully")
 
+	if format == "csv" {
+		file := newDownload(c, mediaTypeCSV, "cost_"+response.StartDate.String()+"_"+response.EndDate.String()+".csv")
+		writer := csv.NewWriter(file)
+		_ = writer.Write(costCSVHeader)
+		for i := range response.Subscriptions {
+			_ = writer.Write(costCSVRecord(&response.Subscriptions[i]))
+		}
+		writer.Flush()
+		if err := errors.Join(writer.Error(), file.Close()); err != nil {
+			h.logger.WithError(err).Error("Failed to write cost calculation CSV")
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
-// Helper method to determine appropriate HTTP status code based on error type
-func (h *SubscriptionHandler) getStatusCodeForError(err error) int {
-	errorMsg := err.Error()
-
-	// Validation errors - 400 Bad Request
-	validationErrors := []string{
-		"invalid input data",
-		"must be in MM-YYYY format",
-		"must be after start_date",
-		"must be before end_date",
-		"are required",
-		"must be greater than 0",
-		"price must be greater than 0",
-		"service_name, price, and user_id are required",
-	}
-
-	for _, validationErr := range validationErrors {
-		if strings.Contains(errorMsg, validationErr) {
-			return http.StatusBadRequest // 400
+// CalculateSpendSeries calculates the monthly spend on subscriptions for a period
+// @Summary Get monthly spend series
+// @Description Aggregate the charges of the subscriptions within a date range into one amount per month, optionally broken down by service, user or catalog category. Every month of the range has at least one point; months without charges have a zero amount. Without target_currency a month has one point per currency charged.
+// @Tags analytics
+// @Security BearerAuth
+// @Produce json
+// @Param start_date query string true "Start date in MM-YYYY format"
+// @Param end_date query string true "End date in MM-YYYY format"
+// @Param group_by query string false "Breakdown of every month (default: month)" Enums(month, service, user, category)
+// @Param user_id query string false "Filter by user ID (UUID)"
+// @Param service_name query []string false "Filter by exact service name, compared case-insensitively (not a substring); repeat for several names" collectionFormat(multi)
+// @Param price_min query int false "Minimum price"
+// @Param price_max query int false "Maximum price"
+// @Param active_on query string false "Only subscriptions active in this month (MM-YYYY)"
+// @Param status query string false "Status relative to the current month" Enums(active, ended, upcoming)
+// @Param started_after query string false "Only subscriptions starting after this month (MM-YYYY)"
+// @Param started_before query string false "Only subscriptions starting before this month (MM-YYYY)"
+// @Param trial_ends_within query int false "Only trials whose first charge at the full price falls between today and this many days ahead (max: 366)"
+// @Param target_currency query string false "ISO-4217 currency to convert every amount into"
+// @Success 200 {array} models.SpendPoint "Spend series calculated successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid date format, group_by or missing required parameters"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Access to another user's subscriptions"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed or server errors"
+// @Router /analytics/spend [get]
+func (h *SubscriptionHandler) CalculateSpendSeries(c *gin.Context) {
+	req := &models.SpendRequest{
+		CostCalculationRequest: models.CostCalculationRequest{
+			StartDate: c.Query("start_date"),
+			EndDate:   c.Query("end_date"),
+		},
+		GroupBy: c.Query("group_by"),
+	}
+
+	if req.StartDate == "" || req.EndDate == "" {
+		h.logger.Error("Missing required parameters for spend series")
+		respondWithError(c, errs.Validation("", errs.CodeRequired, "start_date and end_date are required"))
+		return
+	}
+
+	filter, err := h.parseFilter(c)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+	req.SubscriptionFilterRequest = *filter
+
+	if targetCurrencyStr := c.Query("target_currency"); targetCurrencyStr != "" {
+		req.TargetCurrency = &targetCurrencyStr
+	}
+
+	points, err := h.service.CalculateSpendSeries(c.Request.Context(), req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to calculate spend series")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"group_by":    req.GroupBy,
+		"date_range":  req.StartDate + " to " + req.EndDate,
+		"point_count": len(points),
+	}).Info("Spend series calculated successfully")
+	c.JSON(http.StatusOK, points)
+}
+
+// parsePage reads the pagination query parameters shared by the list endpoints
+func (h *SubscriptionHandler) parsePage(c *gin.Context) (models.PageRequest, error) {
+	page := models.PageRequest{
+		Limit:  50, // default
+		Cursor: c.Query("cursor"),
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+			page.Limit = parsedLimit
+		}
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil {
+			page.Offset = parsedOffset
+		}
+	}
+
+	if includeTotalStr := c.Query("include_total"); includeTotalStr != "" {
+		parsed, err := strconv.ParseBool(includeTotalStr)
+		if err != nil {
+			h.logger.WithError(err).WithField("include_total", includeTotalStr).Error("Invalid include_total format")
+			return page, errs.Validation("include_total", errs.CodeInvalidInput, "include_total must be a boolean")
 		}
+		page.IncludeTotal = parsed
 	}
 
-	// Not found errors - 404 Not Found
-	notFoundErrors := []string{
-		"not found",
-		"does not exist",
-		"record not found",
+	return page, nil
+}
+
+// parseFilter reads the filter and sort query parameters shared by the list and cost endpoints.
+// Dates, status and sort are validated by the service.
+func (h *SubscriptionHandler) parseFilter(c *gin.Context) (*models.SubscriptionFilterRequest, error) {
+	filter := &models.SubscriptionFilterRequest{
+		ServiceNames:  c.QueryArray("service_name"),
+		ActiveOn:      c.Query("active_on"),
+		Status:        c.Query("status"),
+		StartedAfter:  c.Query("started_after"),
+		StartedBefore: c.Query("started_before"),
+		Sort:          c.Query("sort"),
 	}
 
-	for _, notFoundErr := range notFoundErrors {
-		if strings.Contains(errorMsg, notFoundErr) {
-			return http.StatusNotFound // 404
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		parsedUUID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			h.logger.WithError(err).WithField("user_id", userIDStr).Error("Invalid user_id format")
+			return nil, errs.Validation("user_id", errs.CodeInvalidInput, "Invalid user_id format")
 		}
+		filter.UserID = &parsedUUID
 	}
 
-	// Conflict errors - 409 Conflict (e.g., duplicate subscription)
-	conflictErrors := []string{
-		"already exists",
-		"duplicate",
-		"conflict",
-		"constraint violation",
-		"unique constraint",
-		"UNIQUE constraint failed",
+	if withinStr := c.Query("trial_ends_within"); withinStr != "" {
+		within, err := strconv.Atoi(withinStr)
+		if err != nil {
+			h.logger.WithError(err).WithField("trial_ends_within", withinStr).Error("Invalid trial_ends_within format")
+			return nil, errs.Validation("trial_ends_within", errs.CodeInvalidInput, "trial_ends_within must be an integer")
+		}
+		filter.TrialEndsWithin = &within
 	}
 
-	for _, conflictErr := range conflictErrors {
-		if strings.Contains(errorMsg, conflictErr) {
-			return http.StatusConflict // 409
+	for field, target := range map[string]**int{"price_min": &filter.PriceMin, "price_max": &filter.PriceMax} {
+		if value := c.Query(field); value != "" {
+			price, err := strconv.Atoi(value)
+			if err != nil {
+				h.logger.WithError(err).WithField(field, value).Error("Invalid price filter format")
+				return nil, errs.Validation(field, errs.CodeInvalidPrice, field+" must be an integer")
+			}
+			*target = &price
 		}
 	}
 
-	// Database/internal errors - 500 Internal Server Error
-	return http.StatusInternalServerError // 500
+	return filter, nil
 }
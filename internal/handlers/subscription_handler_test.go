@@ -2,12 +2,22 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/middleware"
 	"subscription_tracker_api/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -22,36 +32,138 @@ type MockSubscriptionService struct {
 	mock.Mock
 }
 
-func (m *MockSubscriptionService) CreateSubscription(req *models.CreateSubscriptionRequest) (*models.Subscription, error) {
-	args := m.Called(req)
+func (m *MockSubscriptionService) CreateSubscription(ctx context.Context, req *models.CreateSubscriptionRequest) (*models.Subscription, error) {
+	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Subscription), args.Error(1)
 }
 
-func (m *MockSubscriptionService) GetSubscriptionByID(id uint) (*models.Subscription, error) {
-	args := m.Called(id)
+func (m *MockSubscriptionService) CreateSubscriptionIdempotent(ctx context.Context, req *models.CreateSubscriptionRequest, idempotencyKey string) (*models.Subscription, bool, error) {
+	args := m.Called(ctx, req, idempotencyKey)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.Subscription), args.Bool(1), args.Error(2)
+}
+
+func (m *MockSubscriptionService) GetSubscriptionByID(ctx context.Context, id uint) (*models.Subscription, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Subscription), args.Error(1)
 }
 
-func (m *MockSubscriptionService) DeleteSubscription(id uint) error {
-	args := m.Called(id)
+func (m *MockSubscriptionService) DeleteSubscription(ctx context.Context, id uint, precondition *models.Precondition) error {
+	args := m.Called(ctx, id, precondition)
 	return args.Error(0)
 }
 
+func (m *MockSubscriptionService) UpdateSubscription(ctx context.Context, id uint, req *models.UpdateSubscriptionRequest, precondition *models.Precondition) (*models.Subscription, error) {
+	args := m.Called(ctx, id, req, precondition)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) ReplaceSubscription(ctx context.Context, id uint, req *models.CreateSubscriptionRequest, precondition *models.Precondition) (*models.Subscription, error) {
+	args := m.Called(ctx, id, req, precondition)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) ExecuteBatch(ctx context.Context, req *models.BatchRequest) (*models.BatchResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchResponse), args.Error(1)
+}
+
+func (m *MockSubscriptionService) ImportSubscriptions(ctx context.Context, rows []models.ImportRow, dryRun bool) (*models.ImportResponse, error) {
+	args := m.Called(ctx, rows, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportResponse), args.Error(1)
+}
+
 // Add other interface methods as needed (can be empty for now)
-func (m *MockSubscriptionService) UpdateSubscription(id uint, updates map[string]interface{}) (*models.Subscription, error) {
-	return nil, nil
+func (m *MockSubscriptionService) ListSubscriptions(ctx context.Context, filter *models.SubscriptionFilterRequest, page models.PageRequest) (*models.SubscriptionPage, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SubscriptionPage), args.Error(1)
+}
+func (m *MockSubscriptionService) PurgeSubscription(ctx context.Context, id uint, precondition *models.Precondition) error {
+	args := m.Called(ctx, id, precondition)
+	return args.Error(0)
+}
+func (m *MockSubscriptionService) ListPriceHistory(ctx context.Context, id uint) ([]models.SubscriptionPrice, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SubscriptionPrice), args.Error(1)
+}
+func (m *MockSubscriptionService) RestoreSubscription(ctx context.Context, id uint, precondition *models.Precondition) (*models.Subscription, error) {
+	args := m.Called(ctx, id, precondition)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+func (m *MockSubscriptionService) ListTrash(ctx context.Context, filter *models.SubscriptionFilterRequest, page models.PageRequest) (*models.TrashPage, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TrashPage), args.Error(1)
+}
+func (m *MockSubscriptionService) ExportSubscriptions(ctx context.Context, filter *models.SubscriptionFilterRequest, fn func(subscription *models.Subscription) error) error {
+	args := m.Called(ctx, filter)
+	for _, subscription := range args.Get(0).([]models.Subscription) {
+		if err := fn(&subscription); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+func (m *MockSubscriptionService) ExportChargeSchedules(ctx context.Context, filter *models.SubscriptionFilterRequest, fn func(schedule *models.ChargeSchedule) error) error {
+	args := m.Called(ctx, filter)
+	for _, schedule := range args.Get(0).([]models.ChargeSchedule) {
+		if err := fn(&schedule); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+func (m *MockSubscriptionService) CalculateTotalCost(ctx context.Context, req *models.CostCalculationRequest) (*models.CostCalculationResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CostCalculationResponse), args.Error(1)
 }
-func (m *MockSubscriptionService) ListSubscriptions(userID *uuid.UUID, serviceName *string, limit, offset int) ([]models.Subscription, error) {
-	return nil, nil
+func (m *MockSubscriptionService) CalculateSpendSeries(ctx context.Context, req *models.SpendRequest) ([]models.SpendPoint, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SpendPoint), args.Error(1)
 }
-func (m *MockSubscriptionService) CalculateTotalCost(req *models.CostCalculationRequest) (*models.CostCalculationResponse, error) {
-	return nil, nil
+func (m *MockSubscriptionService) ListUpcomingCharges(ctx context.Context, userID *uuid.UUID, withinDays, limit int) ([]models.UpcomingCharge, error) {
+	args := m.Called(ctx, userID, withinDays, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UpcomingCharge), args.Error(1)
 }
 
 func setupTestHandler() (*SubscriptionHandler, *MockSubscriptionService) {
@@ -81,11 +193,11 @@ func TestCreateSubscription_Success(t *testing.T) {
 		ServiceName: "Netflix",
 		Price:       999,
 		UserID:      userID,
-		StartDate:   "01-2024",
+		StartDate:   models.NewYearMonth(2024, time.January),
 	}
 
 	// Fix: Change from CreateSubscriptionWithTransaction to CreateSubscription
-	mockService.On("CreateSubscription", mock.AnythingOfType("*models.CreateSubscriptionRequest")).Return(expectedSubscription, nil)
+	mockService.On("CreateSubscription", mock.Anything, mock.AnythingOfType("*models.CreateSubscriptionRequest")).Return(expectedSubscription, nil)
 
 	// Create request
 	jsonBody, _ := json.Marshal(requestBody)
@@ -108,6 +220,8 @@ func TestCreateSubscription_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedSubscription.ServiceName, response.ServiceName)
 	assert.Equal(t, expectedSubscription.Price, response.Price)
+	assert.Equal(t, expectedSubscription.StartDate, response.StartDate)
+	assert.Contains(t, w.Body.String(), `"start_date":"01-2024"`)
 
 	mockService.AssertExpectations(t)
 }
@@ -142,11 +256,11 @@ func TestGetSubscription_Success(t *testing.T) {
 		ServiceName: "Netflix",
 		Price:       999,
 		UserID:      uuid.New(),
-		StartDate:   "01-2024",
+		StartDate:   models.NewYearMonth(2024, time.January),
 	}
 
 	// Setup mock
-	mockService.On("GetSubscriptionByID", uint(1)).Return(expectedSubscription, nil)
+	mockService.On("GetSubscriptionByID", mock.Anything, uint(1)).Return(expectedSubscription, nil)
 
 	// Create request
 	req := httptest.NewRequest("GET", "/subscriptions/1", nil)
@@ -194,7 +308,7 @@ func TestGetSubscription_NotFound(t *testing.T) {
 	handler, mockService := setupTestHandler()
 
 	// Setup mock to return error
-	mockService.On("GetSubscriptionByID", uint(999)).Return(nil, errors.New("subscription not found"))
+	mockService.On("GetSubscriptionByID", mock.Anything, uint(999)).Return(nil, errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found"))
 
 	// Create request
 	req := httptest.NewRequest("GET", "/subscriptions/999", nil)
@@ -209,12 +323,16 @@ func TestGetSubscription_NotFound(t *testing.T) {
 	// Assertions
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
+	var response models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, errs.CodeSubscriptionNotFound, response.Code)
+
 	mockService.AssertExpectations(t)
 }
 
 func TestDeleteSubscription_Success(t *testing.T) {
 	handler, mockService := setupTestHandler()
-	mockService.On("DeleteSubscription", uint(1)).Return(nil)
+	mockService.On("DeleteSubscription", mock.Anything, uint(1), (*models.Precondition)(nil)).Return(nil)
 
 	// Use a full Gin router instead of just context
 	gin.SetMode(gin.TestMode)
@@ -230,9 +348,99 @@ func TestDeleteSubscription_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
-func TestGetStatusCodeForError(t *testing.T) {
-	handler := &SubscriptionHandler{logger: logrus.New()}
+func TestDeleteSubscription_Hard(t *testing.T) {
+	handler, mockService := setupTestHandler()
+	mockService.On("PurgeSubscription", mock.Anything, uint(1), &models.Precondition{Versions: []int{2}}).Return(nil).Once()
+
+	router := gin.New()
+	router.DELETE("/subscriptions/:id", handler.DeleteSubscription)
+
+	req := httptest.NewRequest("DELETE", "/subscriptions/1?hard=true", nil)
+	req.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/subscriptions/1?hard=maybe", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"hard"`)
+	mockService.AssertNotCalled(t, "DeleteSubscription", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertExpectations(t)
+}
 
+func TestRestoreSubscription(t *testing.T) {
+	handler, mockService := setupTestHandler()
+	mockService.On("RestoreSubscription", mock.Anything, uint(1), (*models.Precondition)(nil)).Return(&models.Subscription{ID: 1, ServiceName: "Netflix", Version: 3}, nil).Once()
+	mockService.On("RestoreSubscription", mock.Anything, uint(2), (*models.Precondition)(nil)).Return(nil, errs.NotFound(errs.CodeSubscriptionNotFound, "deleted subscription not found")).Once()
+
+	router := gin.New()
+	router.POST("/subscriptions/:id/restore", handler.RestoreSubscription)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/subscriptions/1/restore", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"id":1`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/subscriptions/2/restore", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListTrash(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	deletedAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	mockService.On("ListTrash", mock.Anything, mock.Anything, mock.MatchedBy(func(page models.PageRequest) bool {
+		return page.Limit == 5 && page.Offset == 10
+	})).Return(&models.TrashPage{Items: []models.TrashedSubscription{{
+		Subscription: models.Subscription{ID: 1, ServiceName: "Netflix"},
+		DeletedAt:    deletedAt,
+		PurgeAfter:   deletedAt.Add(30 * 24 * time.Hour),
+	}}}, nil).Once()
+
+	router := gin.New()
+	router.GET("/subscriptions/trash", handler.ListTrash)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions/trash?limit=5&offset=10", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deleted_at":"2025-03-01T12:00:00Z"`)
+	assert.Contains(t, w.Body.String(), `"purge_after":"2025-03-31T12:00:00Z"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestListPriceHistory(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	mockService.On("ListPriceHistory", mock.Anything, uint(1)).Return([]models.SubscriptionPrice{
+		{SubscriptionID: 1, EffectiveFrom: models.NewYearMonth(2024, time.January), Price: 999},
+		{SubscriptionID: 1, EffectiveFrom: models.NewYearMonth(2025, time.March), Price: 1199},
+	}, nil).Once()
+
+	router := gin.New()
+	router.GET("/subscriptions/:id/prices", handler.ListPriceHistory)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions/1/prices", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `{"subscription_id":1,"effective_from":"03-2025","price":1199`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions/x/prices", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetStatusCodeForError(t *testing.T) {
 	tests := []struct {
 		name           string
 		error          error
@@ -240,19 +448,44 @@ func TestGetStatusCodeForError(t *testing.T) {
 	}{
 		{
 			name:           "validation error",
-			error:          errors.New("price must be greater than 0"),
+			error:          errs.Validation("price", errs.CodeInvalidPrice, "price must be greater than 0"),
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name:           "not found error",
-			error:          errors.New("subscription not found"),
+			error:          errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found"),
 			expectedStatus: http.StatusNotFound,
 		},
 		{
 			name:           "conflict error",
-			error:          errors.New("subscription already exists"),
+			error:          errs.Conflict("start_date", errs.CodeSubscriptionExists, "subscription already exists"),
 			expectedStatus: http.StatusConflict,
 		},
+		{
+			name:           "wrapped conflict error",
+			error:          fmt.Errorf("create: %w", errs.Conflict("start_date", errs.CodeSubscriptionExists, "subscription already exists")),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "precondition failed error",
+			error:          errs.PreconditionFailed("subscription has been modified"),
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "unprocessable error",
+			error:          errs.Unprocessable("Idempotency-Key", errs.CodeIdempotencyKeyReused, "Idempotency-Key was already used with a different request"),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "unsupported media type error",
+			error:          errs.UnsupportedMediaType("Content-Type must be application/json"),
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "untyped error mentioning not found",
+			error:          errors.New("record not found"),
+			expectedStatus: http.StatusInternalServerError,
+		},
 		{
 			name:           "unknown error",
 			error:          errors.New("database connection failed"),
@@ -262,8 +495,721 @@ func TestGetStatusCodeForError(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			statusCode := handler.getStatusCodeForError(tt.error)
+			statusCode := getStatusCodeForError(tt.error)
 			assert.Equal(t, tt.expectedStatus, statusCode)
 		})
 	}
 }
+
+func TestCreateSubscription_ValidationErrorDetails(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	validationErr := errs.ValidationFields("invalid input data: service_name, price, and user_id are required", []errs.FieldError{
+		{Field: "service_name", Code: errs.CodeRequired, Message: "service_name is required"},
+		{Field: "price", Code: errs.CodeInvalidPrice, Message: "price must be greater than 0"},
+	})
+	mockService.On("CreateSubscription", mock.Anything, mock.AnythingOfType("*models.CreateSubscriptionRequest")).Return(nil, validationErr)
+
+	req := httptest.NewRequest("POST", "/subscriptions", bytes.NewBufferString(`{"user_id":"`+uuid.New().String()+`","start_date":"01-2024"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.CreateSubscription(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response models.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, errs.CodeInvalidInput, response.Code)
+	assert.Len(t, response.Details, 2)
+	assert.Equal(t, "service_name", response.Details[0].Field)
+	assert.Equal(t, errs.CodeInvalidPrice, response.Details[1].Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetSubscription_InternalErrorIsNotLeaked(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	mockService.On("GetSubscriptionByID", mock.Anything, uint(1)).Return(nil, errors.New("pq: connection refused"))
+
+	req := httptest.NewRequest("GET", "/subscriptions/1", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = []gin.Param{{Key: "id", Value: "1"}}
+
+	handler.GetSubscription(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "connection refused")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetSubscription_QueryTimeoutCancelsServiceCall(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	// The service blocks until the request context is cancelled, like a slow query would
+	mockService.On("GetSubscriptionByID", mock.Anything, uint(1)).
+		Return(nil, errs.Internal("failed to retrieve subscription")).
+		Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			<-ctx.Done()
+		})
+
+	router := gin.New()
+	router.Use(middleware.QueryTimeout(20*time.Millisecond, nil))
+	router.GET("/subscriptions/:id", handler.GetSubscription)
+
+	req := httptest.NewRequest("GET", "/subscriptions/1", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+	var response models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, errs.CodeTimeout, response.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestListUpcomingCharges(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	charges := []models.UpcomingCharge{{SubscriptionID: 1, ServiceName: "Netflix", Amount: 990, Currency: "RUB", NextChargeDate: "2025-08-01"}}
+	mockService.On("ListUpcomingCharges", mock.Anything, (*uuid.UUID)(nil), 7, 100).Return(charges, nil)
+
+	router := gin.New()
+	router.GET("/subscriptions/upcoming", handler.ListUpcomingCharges)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions/upcoming?within_days=7", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"next_charge_date":"2025-08-01"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions/upcoming?within_days=week", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListSubscriptions_ReturnsPageEnvelope(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	next := "eyJ0IjoiMjAyNS0wMS0wMVQwMDowMDowMFoiLCJpZCI6MX0"
+	total := int64(3)
+	priceMin := 100
+	mockService.On("ListSubscriptions", mock.Anything, &models.SubscriptionFilterRequest{
+		ServiceNames: []string{"Netflix", "Spotify"},
+		PriceMin:     &priceMin,
+		Status:       "active",
+		Sort:         "-price",
+	}, models.PageRequest{
+		Limit:        1,
+		Offset:       0,
+		Cursor:       "abc",
+		IncludeTotal: true,
+	}).Return(&models.SubscriptionPage{
+		Items:      []models.Subscription{{ID: 1, ServiceName: "Netflix"}},
+		NextCursor: &next,
+		Total:      &total,
+	}, nil)
+
+	router := gin.New()
+	router.GET("/subscriptions", handler.ListSubscriptions)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions?limit=1&cursor=abc&include_total=true&service_name=Netflix&service_name=Spotify&price_min=100&status=active&sort=-price", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"next_cursor":"`+next+`"`)
+	assert.Contains(t, w.Body.String(), `"prev_cursor":null`)
+	assert.Contains(t, w.Body.String(), `"total":3`)
+	assert.Contains(t, w.Body.String(), `"items":[{"id":1`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions?include_total=maybe", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions?price_max=cheap", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"price_max"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions?trial_ends_within=soon", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"trial_ends_within"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateSubscription_MergePatch(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	price := 1199
+	mockService.On("UpdateSubscription", mock.Anything, uint(1), &models.UpdateSubscriptionRequest{
+		Price:   &price,
+		EndDate: models.Nullable[string]{Set: true},
+	}, (*models.Precondition)(nil)).Return(&models.Subscription{ID: 1, ServiceName: "Netflix", Price: 1199, Version: 2}, nil).Once()
+
+	router := gin.New()
+	router.PATCH("/subscriptions/:id", handler.UpdateSubscription)
+
+	patch := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/subscriptions/1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch("application/merge-patch+json", `{"price":1199,"end_date":null}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"price":1199`)
+
+	// Fractional prices are rejected instead of being truncated
+	w = patch("application/merge-patch+json", `{"price":9.99}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response models.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []errs.FieldError{{Field: "price", Code: errs.CodeInvalidType, Message: "price must be an integer"}}, response.Details)
+
+	w = patch("application/json", `{"service_name":null,"colour":"red","start_date":5}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []errs.FieldError{
+		{Field: "colour", Code: errs.CodeUnknownField, Message: "unknown field colour"},
+		{Field: "service_name", Code: errs.CodeInvalidType, Message: "service_name cannot be null"},
+		{Field: "start_date", Code: errs.CodeInvalidType, Message: "start_date must be a string"},
+	}, response.Details)
+
+	w = patch("application/merge-patch+json", `[]`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), errs.CodeInvalidJSON)
+
+	w = patch("text/plain", `{"price":1199}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Contains(t, w.Body.String(), errs.CodeUnsupportedMediaType)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestReplaceSubscription(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	mockService.On("ReplaceSubscription", mock.Anything, uint(1), &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       1299,
+		StartDate:   "01-2024",
+	}, &models.Precondition{Versions: []int{2}}).Return(&models.Subscription{ID: 1, ServiceName: "Netflix", Price: 1299, Version: 3}, nil).Once()
+
+	router := gin.New()
+	router.PUT("/subscriptions/:id", handler.ReplaceSubscription)
+
+	put := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/subscriptions/1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		// Weak entity tags never match with If-Match
+		req.Header.Set("If-Match", `W/"3", "2"`)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := put("application/json; charset=utf-8", `{"service_name":"Netflix","price":1299,"start_date":"01-2024"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	w = put("application/json", `{"service_name":"Netflix","price":"1299","user_id":42,"start_date":"01-2024"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response models.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []errs.FieldError{
+		{Field: "price", Code: errs.CodeInvalidType, Message: "price must be an integer"},
+		{Field: "user_id", Code: errs.CodeInvalidType, Message: "user_id must be a string"},
+	}, response.Details)
+
+	// Merge patches only make sense for PATCH
+	w = put("application/merge-patch+json", `{"price":1299}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetSubscription_ETag(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	mockService.On("GetSubscriptionByID", mock.Anything, uint(1)).Return(&models.Subscription{ID: 1, ServiceName: "Netflix", Version: 4}, nil)
+
+	router := gin.New()
+	router.GET("/subscriptions/:id", handler.GetSubscription)
+
+	get := func(ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/subscriptions/1", nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+
+	assert.Equal(t, http.StatusOK, get(`"3", "4"`).Code)
+	assert.Equal(t, http.StatusOK, get("*").Code)
+
+	w = get(`"3"`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Contains(t, w.Body.String(), errs.CodePreconditionFailed)
+	assert.Equal(t, http.StatusPreconditionFailed, get("4").Code)
+}
+
+func TestCreateSubscription_IdempotencyKey(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	created := &models.Subscription{ID: 1, ServiceName: "Netflix", Price: 999}
+	mockService.On("CreateSubscriptionIdempotent", mock.Anything, mock.AnythingOfType("*models.CreateSubscriptionRequest"), "key-1").Return(created, false, nil).Once()
+	mockService.On("CreateSubscriptionIdempotent", mock.Anything, mock.AnythingOfType("*models.CreateSubscriptionRequest"), "key-1").Return(created, true, nil).Once()
+	mockService.On("CreateSubscriptionIdempotent", mock.Anything, mock.AnythingOfType("*models.CreateSubscriptionRequest"), "key-1").
+		Return(nil, false, errs.Unprocessable("Idempotency-Key", errs.CodeIdempotencyKeyReused, "Idempotency-Key was already used with a different request")).Once()
+
+	router := gin.New()
+	router.POST("/subscriptions", handler.CreateSubscription)
+
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/subscriptions", bytes.NewBufferString(`{"service_name":"Netflix","price":999,"start_date":"01-2025"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "key-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post()
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+
+	w = post()
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	assert.Contains(t, w.Body.String(), `"id":1`)
+
+	w = post()
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), errs.CodeIdempotencyKeyReused)
+
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "CreateSubscription", mock.Anything, mock.Anything)
+}
+
+func TestBatchSubscriptions(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	price := 1199
+	mockService.On("ExecuteBatch", mock.Anything, mock.MatchedBy(func(req *models.BatchRequest) bool {
+		return req.Mode == models.BatchModeBestEffort && len(req.Operations) == 3 &&
+			req.Operations[0].Create.ServiceName == "Netflix" &&
+			*req.Operations[1].Update.Price == price && *req.Operations[1].Version == 2 &&
+			req.Operations[2].Op == models.BatchOpDelete
+	})).Return(&models.BatchResponse{
+		Mode:      models.BatchModeBestEffort,
+		Succeeded: 2,
+		Failed:    1,
+		Results: []models.BatchResult{
+			{Index: 0, Op: models.BatchOpCreate, Subscription: &models.Subscription{ID: 5, ServiceName: "Netflix"}},
+			{Index: 1, Op: models.BatchOpUpdate, Err: errs.PreconditionFailed("subscription has been modified")},
+			{Index: 2, Op: models.BatchOpDelete},
+		},
+	}, nil).Once()
+
+	router := gin.New()
+	router.POST("/:resource", CustomMethods(map[string]gin.HandlerFunc{"subscriptions:batch": handler.BatchSubscriptions}))
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/subscriptions:batch", `{"mode":"best_effort","operations":[
+		{"op":"create","data":{"service_name":"Netflix","price":999,"start_date":"01-2025"}},
+		{"op":"update","id":1,"version":2,"data":{"price":1199}},
+		{"op":"delete","id":2}
+	]}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.BatchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []int{http.StatusCreated, http.StatusPreconditionFailed, http.StatusNoContent},
+		[]int{response.Results[0].Status, response.Results[1].Status, response.Results[2].Status})
+	assert.Equal(t, errs.CodePreconditionFailed, response.Results[1].Error.Code)
+	assert.Nil(t, response.Results[0].Error)
+
+	// Malformed operations are reported by position before anything is applied
+	w = post("/subscriptions:batch", `{"operations":[{"op":"create","data":{"price":9.99}},{"op":"delete","idd":2}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var errResponse models.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResponse))
+	assert.Equal(t, []errs.FieldError{
+		{Field: "operations[0].data.price", Code: errs.CodeInvalidType, Message: "price must be an integer"},
+		{Field: "operations[1].idd", Code: errs.CodeUnknownField, Message: "unknown field idd"},
+	}, errResponse.Details)
+
+	assert.Equal(t, http.StatusNotFound, post("/subscriptions:merge", `{}`).Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestImportSubscriptions(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	router := gin.New()
+	router.POST("/subscriptions/import", handler.ImportSubscriptions)
+
+	userID := uuid.New()
+	csvBody := "\xef\xbb\xbfService_Name,price,start_date,user_id\n" +
+		"Netflix,999,01-2025," + userID.String() + "\n" +
+		"\"Yandex, Plus\",9.99,02-2025,\n"
+	expectedRows := []models.ImportRow{
+		{Line: 2, ServiceName: "Netflix", Price: "999", UserID: userID.String(), StartDate: "01-2025"},
+		{Line: 3, ServiceName: "Yandex, Plus", Price: "9.99", StartDate: "02-2025"},
+	}
+
+	mockService.On("ImportSubscriptions", mock.Anything, expectedRows, true).Return(&models.ImportResponse{
+		DryRun:     true,
+		Total:      2,
+		Accepted:   1,
+		Rejected:   1,
+		Rejections: []models.ImportRejection{{Line: 3, Err: errs.Validation("price", errs.CodeInvalidType, "price must be an integer")}},
+	}, nil).Once()
+
+	req := httptest.NewRequest("POST", "/subscriptions/import?dry_run=true", bytes.NewBufferString(csvBody))
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.ImportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Rejections[0].Line)
+	assert.Equal(t, errs.CodeInvalidType, response.Rejections[0].Error.Code)
+	assert.Equal(t, "price", response.Rejections[0].Error.Details[0].Field)
+
+	// The same file as a multipart upload
+	mockService.On("ImportSubscriptions", mock.Anything, expectedRows, false).Return(&models.ImportResponse{Total: 2, Accepted: 2, Rejections: []models.ImportRejection{}}, nil).Once()
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("file", "subscriptions.csv")
+	assert.NoError(t, err)
+	_, _ = part.Write([]byte(csvBody))
+	assert.NoError(t, writer.Close())
+
+	req = httptest.NewRequest("POST", "/subscriptions/import", &form)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestImportSubscriptions_RaggedRows(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	router := gin.New()
+	router.POST("/subscriptions/import", handler.ImportSubscriptions)
+
+	// Rows with too few or too many fields are rejected on their own, the others still go through
+	csvBody := "service_name,price,start_date\n" +
+		"Netflix,999,01-2025\n" +
+		"Spotify,299\n" +
+		"Okko,399,03-2025,extra\n" +
+		"Kinopoisk,499,04-2025\n"
+	mockService.On("ImportSubscriptions", mock.Anything, mock.Anything, false).Return(&models.ImportResponse{}, nil).Once()
+
+	req := httptest.NewRequest("POST", "/subscriptions/import", bytes.NewBufferString(csvBody))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	rows := mockService.Calls[0].Arguments.Get(1).([]models.ImportRow)
+	if assert.Len(t, rows, 4) {
+		assert.Equal(t, models.ImportRow{Line: 2, ServiceName: "Netflix", Price: "999", StartDate: "01-2025"}, rows[0])
+		assert.Equal(t, 3, rows[1].Line)
+		assert.EqualError(t, rows[1].Err, "expected 3 fields, found 2")
+		assert.Equal(t, 4, rows[2].Line)
+		assert.EqualError(t, rows[2].Err, "expected 3 fields, found 4")
+		assert.Equal(t, models.ImportRow{Line: 5, ServiceName: "Kinopoisk", Price: "499", StartDate: "04-2025"}, rows[3])
+	}
+}
+
+func TestImportSubscriptions_InvalidFiles(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	router := gin.New()
+	router.POST("/subscriptions/import", handler.ImportSubscriptions)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		code        string
+	}{
+		{"wrong media type", "application/json", `{}`, http.StatusUnsupportedMediaType, errs.CodeUnsupportedMediaType},
+		{"empty file", "text/csv", "", http.StatusBadRequest, errs.CodeRequired},
+		{"unknown column", "text/csv", "service_name,price,start_date,colour\n", http.StatusBadRequest, errs.CodeInvalidCSV},
+		{"missing column", "text/csv", "service_name,start_date\n", http.StatusBadRequest, errs.CodeInvalidCSV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/subscriptions/import", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			var response models.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+
+	mockService.AssertNotCalled(t, "ImportSubscriptions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExportSubscriptions(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	router := gin.New()
+	router.GET("/subscriptions/export", handler.ExportSubscriptions)
+
+	userID := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	endDate := models.YearMonth{Year: 2026, Month: time.June}
+	updatedAt := time.Date(2025, time.March, 4, 10, 30, 0, 0, time.UTC)
+	serviceID := uint(4)
+	trialEnd := models.YearMonth{Year: 2025, Month: time.February}
+	subscriptions := []models.Subscription{
+		{ID: 1, ServiceName: "Netflix", ServiceID: &serviceID, Price: 999, Currency: "RUB", BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, UserID: userID, StartDate: models.YearMonth{Year: 2025, Month: time.January}, TrialEnd: &trialEnd, TrialPrice: 99, Version: 1, CreatedAt: updatedAt, UpdatedAt: updatedAt},
+		{ID: 2, ServiceName: "=HYPERLINK(\"x\")", Price: 2990, Currency: "USD", BillingPeriod: models.BillingPeriodQuarter, BillingIntervalCount: 2, UserID: userID, StartDate: models.YearMonth{Year: 2025, Month: time.February}, EndDate: &endDate, Version: 3, CreatedAt: updatedAt, UpdatedAt: updatedAt},
+	}
+	filter := mock.MatchedBy(func(filter *models.SubscriptionFilterRequest) bool {
+		return filter.Status == "active" && filter.Sort == "price"
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		mockService.On("ExportSubscriptions", mock.Anything, filter).Return(subscriptions, nil).Once()
+
+		w := get("/subscriptions/export?status=active&sort=price")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="subscriptions.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,service_name,service_id,price,currency,billing_period,billing_interval_count,user_id,start_date,end_date,trial_end,trial_price,version,created_at,updated_at\n"+
+			"1,Netflix,4,999,RUB,month,1,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2025,,02-2025,99,1,2025-03-04T10:30:00Z,2025-03-04T10:30:00Z\n"+
+			"2,\"'=HYPERLINK(\"\"x\"\")\",,2990,USD,quarter,2,60601fee-2bf1-4721-ae6f-7636e79a0cba,02-2025,06-2026,,0,3,2025-03-04T10:30:00Z,2025-03-04T10:30:00Z\n", w.Body.String())
+
+		// The export can be imported again as it is
+		rows, err := readImportCSV(strings.NewReader(w.Body.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ImportRow{
+			{Line: 2, ServiceName: "Netflix", Price: "999", Currency: "RUB", BillingPeriod: "month", BillingIntervalCount: "1", UserID: userID.String(), StartDate: "01-2025", TrialEnd: "02-2025", TrialPrice: "99"},
+			{Line: 3, ServiceName: "=HYPERLINK(\"x\")", Price: "2990", Currency: "USD", BillingPeriod: "quarter", BillingIntervalCount: "2", UserID: userID.String(), StartDate: "02-2025", EndDate: "06-2026", TrialPrice: "0"},
+		}, rows)
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		mockService.On("ExportSubscriptions", mock.Anything, filter).Return(subscriptions, nil).Once()
+
+		w := get("/subscriptions/export?format=jsonl&status=active&sort=price")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson; charset=utf-8", w.Header().Get("Content-Type"))
+		lines := bytes.Split(bytes.TrimSpace(w.Body.Bytes()), []byte("\n"))
+		assert.Len(t, lines, 2)
+		var second models.Subscription
+		assert.NoError(t, json.Unmarshal(lines[1], &second))
+		assert.Equal(t, uint(2), second.ID)
+	})
+
+	t.Run("ics", func(t *testing.T) {
+		mockService.On("ExportChargeSchedules", mock.Anything, filter).Return([]models.ChargeSchedule{
+			{Subscription: &subscriptions[0], NextCharge: time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)},
+			{Subscription: &subscriptions[1], NextCharge: time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		}, nil).Once()
+
+		w := get("/subscriptions/export?format=ics&status=active&sort=price")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+		assert.True(t, strings.HasSuffix(body, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+		assert.Contains(t, body, "UID:subscription-1@subscription-tracker\r\nDTSTAMP:20250304T103000Z\r\nDTSTART;VALUE=DATE:20250401\r\nDTEND;VALUE=DATE:20250402\r\nRRULE:FREQ=MONTHLY\r\nSUMMARY:Netflix\r\n")
+		assert.Contains(t, body, "DTSTART;VALUE=DATE:20250801\r\nDTEND;VALUE=DATE:20250802\r\nRRULE:FREQ=MONTHLY;INTERVAL=6;UNTIL=20260630\r\n")
+		assert.Equal(t, 2, strings.Count(body, "BEGIN:VEVENT"))
+	})
+
+	t.Run("errors before the first row are reported as JSON", func(t *testing.T) {
+		mockService.On("ExportSubscriptions", mock.Anything, mock.Anything).Return([]models.Subscription{}, errs.Internal("failed to export subscriptions")).Once()
+
+		w := get("/subscriptions/export")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		w := get("/subscriptions/export?format=xlsx")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestExportSubscriptions_PastDeadlineIsNotCleanResponse(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	router := gin.New()
+	router.Use(middleware.QueryTimeout(20*time.Millisecond, nil))
+	router.GET("/subscriptions/export", handler.ExportSubscriptions)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Enough rows to start the response, streamed once the deadline has passed and followed by
+	// the error the rows iteration reports
+	subscriptions := make([]models.Subscription, 2000)
+	for i := range subscriptions {
+		subscriptions[i] = models.Subscription{ID: uint(i + 1), ServiceName: "Netflix", Price: 999, Currency: "RUB", BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: models.YearMonth{Year: 2025, Month: time.January}}
+	}
+	for _, format := range []string{"csv", "jsonl"} {
+		mockService.On("ExportSubscriptions", mock.Anything, mock.Anything).
+			Return(subscriptions, context.DeadlineExceeded).
+			Run(func(args mock.Arguments) {
+				<-args.Get(0).(context.Context).Done()
+			}).Once()
+
+		resp, err := http.Get(server.URL + "/subscriptions/export?format=" + format)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, format)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "the %s transfer must not end cleanly", format)
+		lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+		switch format {
+		case "csv":
+			assert.Equal(t, "#error,timeout,request timed out", lines[len(lines)-1])
+		case "jsonl":
+			assert.JSONEq(t, `{"error":"request timed out","code":"timeout"}`, lines[len(lines)-1])
+		}
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestCalculateTotalCost_CSV(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	router := gin.New()
+	router.GET("/subscriptions/calculate-cost", handler.CalculateTotalCost)
+
+	userID := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	mockService.On("CalculateTotalCost", mock.Anything, mock.MatchedBy(func(req *models.CostCalculationRequest) bool {
+		return req.StartDate == "01-2025" && req.EndDate == "03-2025"
+	})).Return(&models.CostCalculationResponse{
+		StartDate: models.YearMonth{Year: 2025, Month: time.January},
+		EndDate:   models.YearMonth{Year: 2025, Month: time.March},
+		Subscriptions: []models.SubscriptionCost{{
+			Subscription: models.Subscription{ID: 1, ServiceName: "Netflix", Price: 999, Currency: "RUB", BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, UserID: userID, StartDate: models.YearMonth{Year: 2024, Month: time.June}},
+			Charges:      3,
+			Subtotal:     2997,
+		}},
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/subscriptions/calculate-cost?start_date=01-2025&end_date=03-2025&format=csv", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="cost_01-2025_03-2025.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,service_name,user_id,price,currency,billing_period,billing_interval_count,start_date,end_date,charges,subtotal\n"+
+		"1,Netflix,60601fee-2bf1-4721-ae6f-7636e79a0cba,999,RUB,month,1,06-2024,,3,2997\n", w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestCalculateSpendSeries(t *testing.T) {
+	handler, mockService := setupTestHandler()
+
+	router := gin.New()
+	router.GET("/analytics/spend", handler.CalculateSpendSeries)
+
+	mockService.On("CalculateSpendSeries", mock.Anything, mock.MatchedBy(func(req *models.SpendRequest) bool {
+		return req.StartDate == "01-2025" && req.EndDate == "02-2025" && req.GroupBy == "service" &&
+			slices.Equal(req.ServiceNames, []string{"Netflix"}) && req.TargetCurrency != nil && *req.TargetCurrency == "USD"
+	})).Return([]models.SpendPoint{
+		{Period: models.YearMonth{Year: 2025, Month: time.January}, ServiceName: "Netflix", Currency: "USD", Amount: 10},
+		{Period: models.YearMonth{Year: 2025, Month: time.February}, ServiceName: "Netflix", Currency: "USD", Amount: 0},
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/analytics/spend?start_date=01-2025&end_date=02-2025&group_by=service&service_name=Netflix&target_currency=USD", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[
+		{"period":"01-2025","service_name":"Netflix","currency":"USD","amount":10},
+		{"period":"02-2025","service_name":"Netflix","currency":"USD","amount":0}
+	]`, w.Body.String())
+	mockService.AssertExpectations(t)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/analytics/spend?start_date=01-2025", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCalendarWriter_FoldsLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	calendar := newCalendarWriter(&buf)
+	calendar.line("SUMMARY:" + strings.Repeat("Подписка ", 20))
+	assert.NoError(t, calendar.End())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	assert.Greater(t, len(lines), 2)
+	unfolded := ""
+	for i, line := range lines[:len(lines)-1] {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line))
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "))
+			line = line[1:]
+		}
+		unfolded += line
+	}
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("Подписка ", 20), unfolded)
+}
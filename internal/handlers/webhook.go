@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookHandler struct {
+	service service.WebhookServiceInterface
+	logger  *logrus.Logger
+}
+
+func NewWebhookHandler(service service.WebhookServiceInterface, logger *logrus.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateWebhook registers a webhook endpoint
+// @Summary Create webhook
+// @Description Register an endpoint that receives signed subscription lifecycle events (admin only). The signing secret is only returned in this response.
+// @Tags webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param webhook body models.CreateWebhookRequest true "Webhook endpoint"
+// @Success 201 {object} models.WebhookEndpointWithSecret "Webhook created successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid URL, event type or secret"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	h.logger.Info("Received request to create webhook")
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to bind JSON")
+		respondWithError(c, errs.Validation("", errs.CodeInvalidJSON, "Invalid JSON format"))
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create webhook")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// ListWebhooks lists every webhook endpoint
+// @Summary List webhooks
+// @Description List the registered webhook endpoints (admin only)
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.WebhookEndpoint "Webhooks retrieved successfully"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed"
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	h.logger.Info("Received request to list webhooks")
+
+	webhooks, err := h.service.ListWebhooks(c.Request.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list webhooks")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithField("count", len(webhooks)).Info("Webhooks retrieved successfully")
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// GetWebhook retrieves a webhook endpoint
+// @Summary Get webhook by ID
+// @Description Retrieve a webhook endpoint by its ID (admin only)
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.WebhookEndpoint "Webhook retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid webhook ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Webhook not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).WithField("webhook_id", id).Error("Failed to get webhook")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook changes a webhook endpoint
+// @Summary Update webhook
+// @Description Change the URL, event types or active flag of a webhook endpoint (admin only)
+// @Tags webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param updates body models.UpdateWebhookRequest true "Fields to update"
+// @Success 200 {object} models.WebhookEndpoint "Webhook updated successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid input data or validation errors"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Webhook not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).WithField("webhook_id", id).Error("Failed to bind JSON for update")
+		respondWithError(c, errs.Validation("", errs.CodeInvalidJSON, "Invalid JSON format"))
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(c.Request.Context(), id, &req)
+	if err != nil {
+		h.logger.WithError(err).WithField("webhook_id", id).Error("Failed to update webhook")
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook removes a webhook endpoint
+// @Summary Delete webhook
+// @Description Delete a webhook endpoint; its pending deliveries are abandoned (admin only)
+// @Tags webhooks
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 204 "Webhook deleted successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid webhook ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Webhook not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database or server errors"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhook(c.Request.Context(), id); err != nil {
+		h.logger.WithError(err).WithField("webhook_id", id).Error("Failed to delete webhook")
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries lists the delivery log of a webhook endpoint
+// @Summary List webhook deliveries
+// @Description Retrieve the delivery attempts of a webhook endpoint, newest first (admin only)
+// @Tags webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "Filter by delivery status" Enums(pending, succeeded, failed)
+// @Param limit query int false "Number of results to return (default: 50)"
+// @Param offset query int false "Number of results to skip (default: 0)"
+// @Success 200 {array} models.WebhookDelivery "Deliveries retrieved successfully"
+// @Failure 400 {object} models.ErrorResponse "Bad Request - Invalid webhook ID or status"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized - Missing or invalid bearer token"
+// @Failure 403 {object} models.ErrorResponse "Forbidden - Admin role required"
+// @Failure 404 {object} models.ErrorResponse "Not Found - Webhook not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error - Database query failed"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var status *string
+	if statusStr := c.Query("status"); statusStr != "" {
+		status = &statusStr
+	}
+
+	limit := 50 // default
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+			limit = parsedLimit
+		}
+	}
+
+	offset := 0 // default
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil {
+			offset = parsedOffset
+		}
+	}
+
+	deliveries, err := h.service.ListDeliveries(c.Request.Context(), id, status, limit, offset)
+	if err != nil {
+		h.logger.WithError(err).WithField("webhook_id", id).Error("Failed to list webhook deliveries")
+		respondWithError(c, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"webhook_id": id,
+		"count":      len(deliveries),
+	}).Info("Webhook deliveries retrieved successfully")
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// parseID reads the webhook ID path parameter, responding with 400 when it is malformed
+func (h *WebhookHandler) parseID(c *gin.Context) (uint, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		h.logger.WithError(err).WithField("webhook_id", idStr).Error("Invalid webhook ID format")
+		respondWithError(c, errs.Validation("id", errs.CodeInvalidID, "Invalid webhook ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
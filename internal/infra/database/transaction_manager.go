@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"gorm.io/gorm"
 )
@@ -10,11 +12,11 @@ type Transaction interface{}
 
 // TransactionManager defines the contract for managing database transactions
 type TransactionManager interface {
-	Begin() (Transaction, error)
-	Commit(tx Transaction) error
-	Rollback(tx Transaction) error
-	Execute(fn func(tx Transaction) error) error
-	ExecuteWithResult(fn func(tx Transaction) (interface{}, error)) (interface{}, error)
+	Begin(ctx context.Context) (Transaction, error)
+	Commit(ctx context.Context, tx Transaction) error
+	Rollback(ctx context.Context, tx Transaction) error
+	Execute(ctx context.Context, fn func(tx Transaction) error) error
+	ExecuteWithResult(ctx context.Context, fn func(tx Transaction) (interface{}, error)) (interface{}, error)
 }
 
 // GormTransactionManager implements TransactionManager for GORM
@@ -29,37 +31,45 @@ func NewGormTransactionManager(db *gorm.DB) TransactionManager {
 	}
 }
 
-// Begin starts a new transaction
-func (m *GormTransactionManager) Begin() (Transaction, error) {
-	tx := m.db.Begin()
+// Begin starts a new transaction bound to ctx; every statement run on it is cancelled with ctx
+func (m *GormTransactionManager) Begin(ctx context.Context) (Transaction, error) {
+	tx := m.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
 	return tx, nil
 }
 
-// Commit commits the transaction
-func (m *GormTransactionManager) Commit(tx Transaction) error {
+// Commit commits the transaction unless ctx has already been cancelled
+func (m *GormTransactionManager) Commit(ctx context.Context, tx Transaction) error {
 	gormTx, ok := tx.(*gorm.DB)
 	if !ok {
 		return errors.New("invalid transaction type")
 	}
+	if err := ctx.Err(); err != nil {
+		m.Rollback(ctx, tx)
+		return err
+	}
 	return gormTx.Commit().Error
 }
 
-// Rollback rolls back the transaction
-func (m *GormTransactionManager) Rollback(tx Transaction) error {
+// Rollback rolls back the transaction. It runs even if ctx has been cancelled; a transaction
+// that database/sql already rolled back because of the cancellation is not reported as an error.
+func (m *GormTransactionManager) Rollback(ctx context.Context, tx Transaction) error {
 	gormTx, ok := tx.(*gorm.DB)
 	if !ok {
 		return errors.New("invalid transaction type")
 	}
-	return gormTx.Rollback().Error
+	if err := gormTx.Rollback().Error; err != nil && !errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	return nil
 }
 
 // Execute executes a function within a database transaction
 // This is a utility method that handles the transaction lifecycle automatically
-func (m *GormTransactionManager) Execute(fn func(tx Transaction) error) error {
-	tx, err := m.Begin()
+func (m *GormTransactionManager) Execute(ctx context.Context, fn func(tx Transaction) error) error {
+	tx, err := m.Begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -67,26 +77,26 @@ func (m *GormTransactionManager) Execute(fn func(tx Transaction) error) error {
 	// Ensure rollback on panic or error
 	defer func() {
 		if r := recover(); r != nil {
-			m.Rollback(tx)
+			m.Rollback(ctx, tx)
 			panic(r) // re-panic after rollback
 		}
 	}()
 
 	err = fn(tx)
 	if err != nil {
-		if rollbackErr := m.Rollback(tx); rollbackErr != nil {
+		if rollbackErr := m.Rollback(ctx, tx); rollbackErr != nil {
 			// Log rollback error but return the original error
 			return errors.New("transaction failed and rollback failed: " + err.Error() + "; rollback error: " + rollbackErr.Error())
 		}
 		return err
 	}
 
-	return m.Commit(tx)
+	return m.Commit(ctx, tx)
 }
 
 // ExecuteWithResult executes a function within a transaction and returns a result
-func (m *GormTransactionManager) ExecuteWithResult(fn func(tx Transaction) (interface{}, error)) (interface{}, error) {
-	tx, err := m.Begin()
+func (m *GormTransactionManager) ExecuteWithResult(ctx context.Context, fn func(tx Transaction) (interface{}, error)) (interface{}, error) {
+	tx, err := m.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -94,20 +104,20 @@ func (m *GormTransactionManager) ExecuteWithResult(fn func(tx Transaction) (inte
 	// Ensure rollback on panic or error
 	defer func() {
 		if r := recover(); r != nil {
-			m.Rollback(tx)
+			m.Rollback(ctx, tx)
 			panic(r) // re-panic after rollback
 		}
 	}()
 
 	result, err := fn(tx)
 	if err != nil {
-		if rollbackErr := m.Rollback(tx); rollbackErr != nil {
+		if rollbackErr := m.Rollback(ctx, tx); rollbackErr != nil {
 			return nil, errors.New("transaction failed and rollback failed: " + err.Error() + "; rollback error: " + rollbackErr.Error())
 		}
 		return nil, err
 	}
 
-	if commitErr := m.Commit(tx); commitErr != nil {
+	if commitErr := m.Commit(ctx, tx); commitErr != nil {
 		return nil, commitErr
 	}
 
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type record struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+func setupTestTransactionManager(t *testing.T) (TransactionManager, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // keep the in-memory database on a single connection
+
+	if err := db.AutoMigrate(&record{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	return NewGormTransactionManager(db), db
+}
+
+func TestExecute_CommitsOnSuccess(t *testing.T) {
+	txMgr, db := setupTestTransactionManager(t)
+
+	err := txMgr.Execute(context.Background(), func(tx Transaction) error {
+		return GetDB(tx).Create(&record{Name: "netflix"}).Error
+	})
+
+	assert.NoError(t, err)
+
+	var count int64
+	db.Model(&record{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestExecute_CancelledContextAbortsBeforeQuery(t *testing.T) {
+	txMgr, db := setupTestTransactionManager(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	err := txMgr.Execute(ctx, func(tx Transaction) error {
+		called = true
+		return GetDB(tx).Create(&record{Name: "netflix"}).Error
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, called)
+
+	var count int64
+	db.Model(&record{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestExecute_DeadlineAbortsRunningQuery(t *testing.T) {
+	txMgr, _ := setupTestTransactionManager(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	err := txMgr.Execute(ctx, func(tx Transaction) error {
+		// A query that would run for a very long time if it were not interrupted
+		var n int64
+		return GetDB(tx).Raw(`WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT COUNT(*) FROM c`).Scan(&n).Error
+	})
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
+
+func TestExecuteWithResult_CancelledBeforeCommitRollsBack(t *testing.T) {
+	txMgr, db := setupTestTransactionManager(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	result, err := txMgr.ExecuteWithResult(ctx, func(tx Transaction) (interface{}, error) {
+		rec := &record{Name: "netflix"}
+		if err := GetDB(tx).Create(rec).Error; err != nil {
+			return nil, err
+		}
+		cancel() // client goes away after the write but before commit
+		return rec, nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, result)
+
+	var count int64
+	db.Model(&record{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
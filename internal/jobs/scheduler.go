@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job is a unit of background work run periodically by the Scheduler
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs background jobs on fixed intervals until it is stopped
+type Scheduler struct {
+	jobs   []Job
+	logger *logrus.Logger
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates an empty scheduler
+func NewScheduler(logger *logrus.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Add registers a job. Jobs must be added before Start.
+func (s *Scheduler) Add(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start runs every job once immediately and then on its interval. The context passed to
+// jobs is cancelled when ctx is done or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+
+	s.logger.WithField("job_count", len(s.jobs)).Info("Background job scheduler started successfully")
+}
+
+// Stop cancels running jobs and waits for them to return, or until ctx is done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Info("Background job scheduler stopped successfully")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		if ctx.Err() == nil {
+			s.logger.WithError(err).WithField("job", job.Name).Error("Background job failed")
+		}
+		return
+	}
+	s.logger.WithFields(logrus.Fields{
+		"job":      job.Name,
+		"duration": time.Since(start),
+	}).Debug("Background job completed successfully")
+}
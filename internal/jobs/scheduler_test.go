@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestScheduler() *Scheduler {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewScheduler(logger)
+}
+
+func TestScheduler_RunsJobsUntilStopped(t *testing.T) {
+	scheduler := newTestScheduler()
+
+	var runs atomic.Int32
+	scheduler.Add("counter", 5*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	scheduler.Start(context.Background())
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+
+	assert.NoError(t, scheduler.Stop(context.Background()))
+	stopped := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+}
+
+func TestScheduler_StopCancelsRunningJob(t *testing.T) {
+	scheduler := newTestScheduler()
+
+	started := make(chan struct{})
+	scheduler.Add("blocking", time.Hour, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	scheduler.Start(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, scheduler.Stop(ctx))
+}
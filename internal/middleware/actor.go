@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActorHeader names the person behind a request, e.g. a support agent acting through a shared token
+const ActorHeader = "X-Actor"
+
+// maxActorLength matches the actor column of the audit log
+const maxActorLength = 255
+
+// Actor stores the X-Actor header in the request context so changes can be attributed to it
+// in the audit log
+func Actor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := strings.TrimSpace(c.GetHeader(ActorHeader))
+		if actor == "" {
+			c.Next()
+			return
+		}
+
+		if len(actor) > maxActorLength || !utf8.ValidString(actor) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+				Error: "X-Actor must be valid UTF-8 of at most 255 bytes",
+				Code:  errs.CodeInvalidInput,
+			})
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"subscription_tracker_api/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Actor())
+	router.GET("/whoami", func(c *gin.Context) {
+		actor, _ := auth.ActorFromContext(c.Request.Context())
+		c.String(http.StatusOK, actor)
+	})
+
+	get := func(actor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/whoami", nil)
+		if actor != "" {
+			req.Header.Set(ActorHeader, actor)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(" support@example.com ")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "support@example.com", w.Body.String())
+
+	w = get("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, get(strings.Repeat("a", 256)).Code)
+}
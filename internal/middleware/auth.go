@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticate requires a valid bearer token and stores the caller's principal in the request context
+func Authenticate(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			abortUnauthorized(c, "missing bearer token")
+			return
+		}
+
+		principal, err := authenticator.Authenticate(strings.TrimSpace(token))
+		if err != nil {
+			abortUnauthorized(c, "invalid or expired token")
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+func abortUnauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+		Error: message,
+		Code:  errs.CodeUnauthorized,
+	})
+}
+
+// RequireRole only lets through callers whose principal carries the given role
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.PrincipalFromContext(c.Request.Context())
+		if !ok || principal.Role != role {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error: "insufficient permissions",
+				Code:  errs.CodeForbidden,
+			})
+			return
+		}
+		c.Next()
+	}
+}
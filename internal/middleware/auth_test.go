@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAuthRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	authenticator, err := auth.NewAuthenticator(config.AuthConfig{Algorithm: "HS256", Secret: "test-secret"})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(Authenticate(authenticator))
+	router.GET("/whoami", func(c *gin.Context) {
+		principal, _ := auth.PrincipalFromContext(c.Request.Context())
+		c.String(http.StatusOK, principal.UserID.String())
+	})
+	return router
+}
+
+func TestAuthenticate_ValidToken(t *testing.T) {
+	router := setupAuthRouter(t)
+
+	userID := uuid.New()
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte("test-secret"))
+
+	req := httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID.String(), w.Body.String())
+}
+
+func TestAuthenticate_RejectsMissingOrInvalidToken(t *testing.T) {
+	router := setupAuthRouter(t)
+
+	for name, header := range map[string]string{
+		"missing header": "",
+		"wrong scheme":   "Basic dXNlcjpwYXNz",
+		"invalid token":  "Bearer not.a.token",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/whoami", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), `"code":"unauthorized"`)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for name, tc := range map[string]struct {
+		principal      *auth.Principal
+		expectedStatus int
+	}{
+		"admin":        {&auth.Principal{UserID: uuid.New(), Role: auth.RoleAdmin}, http.StatusOK},
+		"regular user": {&auth.Principal{UserID: uuid.New()}, http.StatusForbidden},
+		"anonymous":    {nil, http.StatusForbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tc.principal != nil {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), *tc.principal))
+				}
+			})
+			router.GET("/admin", RequireRole(auth.RoleAdmin), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/admin", nil))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
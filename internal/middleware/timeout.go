@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryTimeout bounds the request context with the configured timeout so that database
+// work started by the handler is cancelled once the deadline passes or the client disconnects.
+// Routes listed in routeTimeouts by their full path, such as streaming downloads, are bounded by
+// their own timeout instead.
+func QueryTimeout(timeout time.Duration, routeTimeouts map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := timeout
+		if routeTimeout, ok := routeTimeouts[c.FullPath()]; ok {
+			limit = routeTimeout
+		}
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), limit)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryTimeout_RouteTimeouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(QueryTimeout(time.Second, map[string]time.Duration{"/export/:kind": time.Hour}))
+	deadline := func(c *gin.Context) {
+		deadline, _ := c.Request.Context().Deadline()
+		c.String(http.StatusOK, time.Until(deadline).Round(time.Minute).String())
+	}
+	router.GET("/list", deadline)
+	router.GET("/export/:kind", deadline)
+
+	get := func(path string) string {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "0s", get("/list"))
+	assert.Equal(t, "1h0m0s", get("/export/csv"))
+}
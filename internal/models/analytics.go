@@ -0,0 +1,40 @@
+package models
+
+import "github.com/google/uuid"
+
+// SpendGroupBy is the breakdown of a spend series
+type SpendGroupBy string
+
+const (
+	SpendGroupByMonth    SpendGroupBy = "month"    // One point per month
+	SpendGroupByService  SpendGroupBy = "service"  // One point per month and service name
+	SpendGroupByUser     SpendGroupBy = "user"     // One point per month and user
+	SpendGroupByCategory SpendGroupBy = "category" // One point per month and catalog category
+)
+
+// SpendGroupBys lists every supported breakdown of a spend series
+var SpendGroupBys = []SpendGroupBy{
+	SpendGroupByMonth,
+	SpendGroupByService,
+	SpendGroupByUser,
+	SpendGroupByCategory,
+}
+
+// SpendRequest represents the request for a monthly spend series. It takes the same range,
+// filters and target currency as a cost calculation.
+type SpendRequest struct {
+	CostCalculationRequest
+	GroupBy string `form:"group_by"` // One of SpendGroupBys, month by default
+}
+
+// SpendPoint is the amount charged in one month, for one service, user or category when the
+// series is broken down by them. Every month of the range has at least one point; months
+// without charges have a zero amount.
+type SpendPoint struct {
+	Period      YearMonth  `json:"period" swaggertype:"string" example:"03-2025"`
+	ServiceName string     `json:"service_name,omitempty" example:"Netflix"` // Set when grouped by service
+	UserID      *uuid.UUID `json:"user_id,omitempty"`                        // Set when grouped by user
+	Category    string     `json:"category,omitempty" example:"streaming"`   // Set when grouped by category
+	Currency    string     `json:"currency,omitempty" example:"RUB"`         // Omitted for months without charges unless target_currency is set
+	Amount      int        `json:"amount" example:"999"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is the kind of change an audit event records
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"  // Moved to the trash
+	AuditActionRestore AuditAction = "restore" // Taken out of the trash
+	AuditActionPurge   AuditAction = "purge"   // Deleted permanently
+)
+
+// AuditActions lists every action an audit event can record
+var AuditActions = []AuditAction{
+	AuditActionCreate,
+	AuditActionUpdate,
+	AuditActionDelete,
+	AuditActionRestore,
+	AuditActionPurge,
+}
+
+// AuditActorSystem is the actor of changes made by background jobs
+const AuditActorSystem = "system"
+
+// FieldChange is the value of a subscription field before and after a change. Before is null
+// for created fields and After is null for deleted ones.
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEvent records who changed a subscription, when and how. Events are written in the same
+// transaction as the change.
+type AuditEvent struct {
+	ID             uint        `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint        `json:"subscription_id" gorm:"not null" example:"1"`
+	UserID         uuid.UUID   `json:"user_id" gorm:"type:uuid;not null" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"` // Owner of the subscription
+	Actor          string      `json:"actor" gorm:"type:varchar(255);not null" example:"support@example.com"`
+	ActorUserID    *uuid.UUID  `json:"actor_user_id,omitempty" gorm:"type:uuid"` // Authenticated caller, absent for background jobs
+	Action         AuditAction `json:"action" gorm:"type:varchar(16);not null" example:"update"`
+	Changes        RawJSON     `json:"changes" gorm:"type:jsonb;not null" swaggertype:"object"` // Field name to FieldChange
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// AuditFilterRequest holds the raw filter parameters of the audit log
+type AuditFilterRequest struct {
+	SubscriptionID *uint
+	UserID         *uuid.UUID
+	Actor          string
+	Action         string
+	From           string // RFC 3339, inclusive
+	To             string // RFC 3339, exclusive
+}
+
+// AuditFilter is the validated form of AuditFilterRequest used by the repository. Nil and
+// empty fields do not filter.
+type AuditFilter struct {
+	SubscriptionID *uint
+	UserID         *uuid.UUID
+	Actor          string
+	Action         *AuditAction
+	From           *time.Time
+	To             *time.Time
+}
@@ -0,0 +1,65 @@
+package models
+
+import "encoding/json"
+
+// BatchMode controls what happens when an operation of a batch fails
+type BatchMode string
+
+const (
+	BatchModeAtomic     BatchMode = "atomic"      // All operations run in one transaction; the first failure rolls everything back
+	BatchModeBestEffort BatchMode = "best_effort" // Every operation runs on its own and reports its own result
+)
+
+// BatchOp is the kind of a batch operation
+type BatchOp string
+
+const (
+	BatchOpCreate BatchOp = "create"
+	BatchOpUpdate BatchOp = "update"
+	BatchOpDelete BatchOp = "delete"
+)
+
+// BatchRequest is a list of subscription operations applied in one request
+type BatchRequest struct {
+	Mode       BatchMode        `json:"mode,omitempty" enums:"atomic,best_effort" example:"atomic"` // Optional, defaults to atomic
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation creates, updates or deletes a single subscription. Data holds a
+// CreateSubscriptionRequest for create and a merge patch (UpdateSubscriptionRequest) for update.
+type BatchOperation struct {
+	Op      BatchOp         `json:"op" enums:"create,update,delete" example:"create"`
+	ID      uint            `json:"id,omitempty" example:"1"`      // Subscription to update or delete
+	Version *int            `json:"version,omitempty" example:"3"` // Optional expected version, like If-Match
+	Data    json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+
+	Create *CreateSubscriptionRequest `json:"-"` // Decoded Data of a create
+	Update *UpdateSubscriptionRequest `json:"-"` // Decoded Data of an update
+}
+
+// Precondition returns the version requirement of the operation, if any
+func (o *BatchOperation) Precondition() *Precondition {
+	if o.Version == nil {
+		return nil
+	}
+	return &Precondition{Versions: []int{*o.Version}}
+}
+
+// BatchResult is the outcome of one batch operation
+type BatchResult struct {
+	Index        int            `json:"index" example:"0"` // Position of the operation in the request
+	Op           BatchOp        `json:"op" example:"create"`
+	Status       int            `json:"status" example:"201"` // HTTP status the operation would have had as a single request
+	Subscription *Subscription  `json:"subscription,omitempty"`
+	Error        *ErrorResponse `json:"error,omitempty"`
+
+	Err error `json:"-"` // Failure reported by the service, turned into Status and Error by the handler
+}
+
+// BatchResponse reports the result of every operation of a batch
+type BatchResponse struct {
+	Mode      BatchMode     `json:"mode" example:"best_effort"`
+	Succeeded int           `json:"succeeded" example:"2"`
+	Failed    int           `json:"failed" example:"1"`
+	Results   []BatchResult `json:"results"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// BillingPeriod is the unit of a subscription's billing cycle
+type BillingPeriod string
+
+const (
+	BillingPeriodWeek    BillingPeriod = "week"
+	BillingPeriodMonth   BillingPeriod = "month"
+	BillingPeriodQuarter BillingPeriod = "quarter"
+	BillingPeriodYear    BillingPeriod = "year"
+)
+
+// ParseBillingPeriod validates a billing period name
+func ParseBillingPeriod(s string) (BillingPeriod, error) {
+	switch period := BillingPeriod(s); period {
+	case BillingPeriodWeek, BillingPeriodMonth, BillingPeriodQuarter, BillingPeriodYear:
+		return period, nil
+	default:
+		return "", fmt.Errorf("invalid billing period %q: expected week, month, quarter or year", s)
+	}
+}
+
+// Months returns the length of the period in months, or 0 for weekly billing
+func (p BillingPeriod) Months() int {
+	switch p {
+	case BillingPeriodMonth:
+		return 1
+	case BillingPeriodQuarter:
+		return 3
+	case BillingPeriodYear:
+		return 12
+	default:
+		return 0
+	}
+}
+
+// NextChargeOn returns the first charge of the subscription on or after day. A subscription is
+// charged on the first day of StartDate and then every BillingIntervalCount billing periods; the
+// EndDate month is included in full. The second result is false once the subscription has ended.
+func (s *Subscription) NextChargeOn(day time.Time) (time.Time, bool) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	first := s.StartDate.Time()
+
+	intervalCount := s.BillingIntervalCount
+	if intervalCount < 1 {
+		intervalCount = 1
+	}
+
+	next := first
+	if day.After(first) {
+		if s.BillingPeriod == BillingPeriodWeek {
+			step := 7 * intervalCount
+			days := int(day.Sub(first).Hours() / 24)
+			next = first.AddDate(0, 0, ceilDiv(days, step)*step)
+		} else {
+			months := s.BillingPeriod.Months()
+			if months == 0 {
+				months = 1
+			}
+			step := months * intervalCount
+			firstMonth := YearMonthOf(day)
+			if day.Day() > 1 {
+				firstMonth = firstMonth.AddMonths(1)
+			}
+			next = s.StartDate.AddMonths(ceilDiv(s.StartDate.MonthsUntil(firstMonth), step) * step).Time()
+		}
+	}
+
+	if s.EndDate != nil && !next.Before(s.EndDate.AddMonths(1).Time()) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// ceilDiv divides non-negative a by positive b, rounding up
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
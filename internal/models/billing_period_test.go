@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscription_NextChargeOn(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	endDate := func(s string) *YearMonth {
+		ym, _ := ParseYearMonth(s)
+		return &ym
+	}
+	start, _ := ParseYearMonth("01-2025")
+
+	testCases := []struct {
+		name          string
+		period        BillingPeriod
+		intervalCount int
+		endDate       *YearMonth
+		day           string
+		expected      string
+	}{
+		{name: "before start", period: BillingPeriodMonth, intervalCount: 1, day: "2024-11-15", expected: "2025-01-01"},
+		{name: "monthly on charge day", period: BillingPeriodMonth, intervalCount: 1, day: "2025-03-01", expected: "2025-03-01"},
+		{name: "monthly mid month", period: BillingPeriodMonth, intervalCount: 1, day: "2025-03-02", expected: "2025-04-01"},
+		{name: "quarterly", period: BillingPeriodQuarter, intervalCount: 1, day: "2025-02-10", expected: "2025-04-01"},
+		{name: "every two years", period: BillingPeriodYear, intervalCount: 2, day: "2025-06-01", expected: "2027-01-01"},
+		{name: "weekly", period: BillingPeriodWeek, intervalCount: 1, day: "2025-01-09", expected: "2025-01-15"},
+		{name: "biweekly across month", period: BillingPeriodWeek, intervalCount: 2, day: "2025-01-30", expected: "2025-02-12"},
+		{name: "charge in end month", period: BillingPeriodWeek, intervalCount: 1, endDate: endDate("02-2025"), day: "2025-02-20", expected: "2025-02-26"},
+		{name: "ended", period: BillingPeriodYear, intervalCount: 1, endDate: endDate("12-2025"), day: "2025-01-02", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sub := &Subscription{StartDate: start, EndDate: tc.endDate, BillingPeriod: tc.period, BillingIntervalCount: tc.intervalCount}
+
+			next, ok := sub.NextChargeOn(date(tc.day))
+
+			if tc.expected == "" {
+				assert.False(t, ok)
+				return
+			}
+			assert.True(t, ok)
+			assert.Equal(t, date(tc.expected), next)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BudgetScope selects the subscriptions a budget covers
+type BudgetScope string
+
+const (
+	BudgetScopeOverall  BudgetScope = "overall"  // Every subscription of the user
+	BudgetScopeCategory BudgetScope = "category" // Subscriptions to services of a catalog category
+	BudgetScopeService  BudgetScope = "service"  // Subscriptions to one service
+)
+
+// BudgetScopes lists every supported budget scope
+var BudgetScopes = []BudgetScope{
+	BudgetScopeOverall,
+	BudgetScopeCategory,
+	BudgetScopeService,
+}
+
+// Budget is a monthly spending limit of a user. Its spend in a month is the cost of the
+// subscriptions it covers in that month, converted into its currency.
+type Budget struct {
+	ID           uint             `json:"id" gorm:"primaryKey"`
+	UserID       uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;index"`
+	Scope        BudgetScope      `json:"scope" gorm:"type:varchar(16);not null" example:"category"`
+	Category     *ServiceCategory `json:"category,omitempty" gorm:"type:varchar(32)" example:"streaming"`    // Set for category budgets; other also covers services outside the catalog
+	ServiceName  *string          `json:"service_name,omitempty" gorm:"type:varchar(255)" example:"Netflix"` // Set for service budgets, matched ignoring case
+	MonthlyLimit int              `json:"monthly_limit" gorm:"not null" example:"2000"`
+	Currency     string           `json:"currency" gorm:"type:char(3);not null;default:RUB" example:"RUB"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// CreateBudgetRequest represents the request payload for setting a budget
+type CreateBudgetRequest struct {
+	UserID       uuid.UUID `json:"user_id,omitempty"`                                                                                                       // Optional, defaults to the caller
+	Scope        string    `json:"scope" enums:"overall,category,service" example:"category"`                                                               // Optional, defaults to overall
+	Category     string    `json:"category,omitempty" enums:"streaming,music,video,cloud,software,gaming,news,education,fitness,other" example:"streaming"` // Required for category budgets
+	ServiceName  string    `json:"service_name,omitempty" example:"Netflix"`                                                                                // Required for service budgets
+	MonthlyLimit int       `json:"monthly_limit" example:"2000"`
+	Currency     string    `json:"currency,omitempty" example:"RUB"` // Optional ISO-4217 code, defaults to RUB
+}
+
+// UpdateBudgetRequest changes the limit of a budget. Absent fields are left unchanged; the scope
+// of a budget cannot be changed.
+type UpdateBudgetRequest struct {
+	MonthlyLimit *int    `json:"monthly_limit,omitempty" example:"2500"`
+	Currency     *string `json:"currency,omitempty" example:"RUB"`
+}
+
+// BudgetMonth is the spend of a budget in one month
+type BudgetMonth struct {
+	Period     YearMonth `json:"period" swaggertype:"string" example:"03-2025"`
+	Spent      int       `json:"spent" example:"2297"`
+	Remaining  int       `json:"remaining" example:"-297"` // Negative when over budget
+	OverBudget bool      `json:"over_budget" example:"true"`
+}
+
+// BudgetStatus is a budget with its spend in the current and upcoming months
+type BudgetStatus struct {
+	Budget
+	Months []BudgetMonth `json:"months"`
+}
+
+// BudgetAlert is the payload of a budget.exceeded event
+type BudgetAlert struct {
+	Budget         Budget    `json:"budget"`
+	Period         YearMonth `json:"period" swaggertype:"string" example:"03-2025"`
+	Spent          int       `json:"spent" example:"2297"`
+	SubscriptionID uint      `json:"subscription_id" example:"42"` // The subscription whose creation or price change exceeded the budget
+}
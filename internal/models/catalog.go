@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ServiceCategory groups catalogued services in reports
+type ServiceCategory string
+
+const (
+	ServiceCategoryStreaming ServiceCategory = "streaming"
+	ServiceCategoryMusic     ServiceCategory = "music"
+	ServiceCategoryVideo     ServiceCategory = "video"
+	ServiceCategoryCloud     ServiceCategory = "cloud"
+	ServiceCategorySoftware  ServiceCategory = "software"
+	ServiceCategoryGaming    ServiceCategory = "gaming"
+	ServiceCategoryNews      ServiceCategory = "news"
+	ServiceCategoryEducation ServiceCategory = "education"
+	ServiceCategoryFitness   ServiceCategory = "fitness"
+	ServiceCategoryOther     ServiceCategory = "other" // Also reported for subscriptions to services missing from the catalog
+)
+
+// ServiceCategories lists every category a catalogued service can belong to
+var ServiceCategories = []ServiceCategory{
+	ServiceCategoryStreaming,
+	ServiceCategoryMusic,
+	ServiceCategoryVideo,
+	ServiceCategoryCloud,
+	ServiceCategorySoftware,
+	ServiceCategoryGaming,
+	ServiceCategoryNews,
+	ServiceCategoryEducation,
+	ServiceCategoryFitness,
+	ServiceCategoryOther,
+}
+
+// Service is an entry of the service catalog. Subscriptions created under its name or one of
+// its aliases are linked to it and take its canonical name.
+type Service struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	Name         string          `json:"name" gorm:"type:varchar(255);not null" example:"Netflix"`                    // Canonical name
+	Aliases      []string        `json:"aliases" gorm:"-" example:"netflix hd"`                                       // Normalized alternative names
+	Category     ServiceCategory `json:"category" gorm:"type:varchar(32);not null;default:other" example:"streaming"` // One of ServiceCategories
+	DefaultPrice *int            `json:"default_price,omitempty" example:"999"`                                       // Price of new subscriptions that omit one
+	Currency     string          `json:"currency" gorm:"type:char(3);not null;default:RUB" example:"RUB"`             // ISO-4217 code of DefaultPrice
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// ServiceAlias maps a normalized name to the catalogued service it resolves to. Every service
+// has one for its canonical name besides its aliases, so that a name resolves to at most one
+// service.
+type ServiceAlias struct {
+	Alias     string `gorm:"type:varchar(255);primaryKey"`
+	ServiceID uint   `gorm:"not null;index"`
+}
+
+// CreateServiceRequest represents the request payload for adding a service to the catalog
+type CreateServiceRequest struct {
+	Name         string   `json:"name" example:"Netflix"`
+	Aliases      []string `json:"aliases,omitempty" example:"netflix hd"`
+	Category     string   `json:"category,omitempty" enums:"streaming,music,video,cloud,software,gaming,news,education,fitness,other" example:"streaming"` // Optional, defaults to other
+	DefaultPrice *int     `json:"default_price,omitempty" example:"999"`
+	Currency     string   `json:"currency,omitempty" example:"RUB"` // Optional ISO-4217 code, defaults to RUB
+}
+
+// UpdateServiceRequest changes a catalogued service. Absent fields are left unchanged; aliases
+// replaces every alias and default_price may be null to clear it.
+type UpdateServiceRequest struct {
+	Name         *string       `json:"name,omitempty" example:"Netflix"`
+	Aliases      *[]string     `json:"aliases,omitempty" example:"netflix hd"`
+	Category     *string       `json:"category,omitempty" enums:"streaming,music,video,cloud,software,gaming,news,education,fitness,other" example:"streaming"`
+	DefaultPrice Nullable[int] `json:"default_price" swaggertype:"integer" example:"999" extensions:"x-nullable"`
+	Currency     *string       `json:"currency,omitempty" example:"RUB"`
+}
+
+// CleanServiceName trims a service name and collapses its inner whitespace
+func CleanServiceName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// NormalizeServiceName is the form in which names and aliases are matched: cleaned and in
+// lower case, so that "Netflix", "netflix " and "NETFLIX" are the same service
+func NormalizeServiceName(name string) string {
+	return strings.ToLower(CleanServiceName(name))
+}
@@ -1,6 +1,10 @@
 package models
 
+import "subscription_tracker_api/internal/errs"
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error string `json:"error" example:"Invalid input data"`
+	Error   string            `json:"error" example:"Invalid input data"`
+	Code    string            `json:"code,omitempty" example:"invalid_input"`
+	Details []errs.FieldError `json:"details,omitempty"`
 }
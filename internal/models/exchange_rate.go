@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ExchangeRate converts amounts from one currency to another: 1 FromCurrency = Rate ToCurrency
+type ExchangeRate struct {
+	FromCurrency string    `json:"from_currency" gorm:"primaryKey;type:char(3)" example:"USD"`
+	ToCurrency   string    `json:"to_currency" gorm:"primaryKey;type:char(3)" example:"RUB"`
+	Rate         float64   `json:"rate" gorm:"type:numeric(20,10);not null" example:"92.5"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SetExchangeRateRequest represents the request payload for creating or replacing an exchange rate
+type SetExchangeRateRequest struct {
+	Rate float64 `json:"rate" validate:"required,gt=0" example:"92.5"`
+}
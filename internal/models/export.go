@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ExportFormat is the file format of a subscription export
+type ExportFormat string
+
+const (
+	ExportFormatCSV   ExportFormat = "csv"   // One row per subscription with a header line
+	ExportFormatJSONL ExportFormat = "jsonl" // One JSON subscription per line
+	ExportFormatICS   ExportFormat = "ics"   // iCalendar feed with a recurring event per subscription
+)
+
+// ChargeSchedule is the remaining charges of a subscription: the first one on or after today,
+// then every billing cycle until the subscription ends
+type ChargeSchedule struct {
+	Subscription *Subscription
+	NextCharge   time.Time // Date of the next charge, at midnight UTC
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubscriptionStatus classifies a subscription relative to the current month
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusActive   SubscriptionStatus = "active"   // Started and not yet ended
+	SubscriptionStatusEnded    SubscriptionStatus = "ended"    // end_date is before the current month
+	SubscriptionStatusUpcoming SubscriptionStatus = "upcoming" // start_date is after the current month
+)
+
+// SortableFields lists the subscription fields accepted by the sort parameter
+var SortableFields = []string{"price", "start_date", "end_date", "service_name", "created_at"}
+
+// SubscriptionFilterRequest holds the raw filter and sort parameters shared by the list and
+// cost calculation endpoints
+type SubscriptionFilterRequest struct {
+	UserID          *uuid.UUID
+	ServiceNames    []string // Any of the names, case-insensitive
+	PriceMin        *int
+	PriceMax        *int
+	ActiveOn        string // Format: MM-YYYY
+	Status          string // active, ended or upcoming
+	StartedAfter    string // Format: MM-YYYY, exclusive
+	StartedBefore   string // Format: MM-YYYY, exclusive
+	TrialEndsWithin *int   // Days; only trials converting to the full price within them
+	Sort            string // Comma separated fields, "-" prefix for descending, e.g. "price,-start_date"
+}
+
+// SubscriptionFilter is the validated form of SubscriptionFilterRequest used by the repository.
+// Nil and empty fields do not filter.
+type SubscriptionFilter struct {
+	UserID            *uuid.UUID
+	ServiceNames      []string // Lower-cased
+	PriceMin          *int
+	PriceMax          *int
+	ActiveOn          *YearMonth
+	Status            *SubscriptionStatus
+	StatusMonth       YearMonth // The current month Status is evaluated against
+	StartedAfter      *YearMonth
+	StartedBefore     *YearMonth
+	TrialConvertsFrom *time.Time       // Trial converts to the full price on or after this day
+	TrialConvertsTo   *time.Time       // Trial converts to the full price on or before this day
+	Category          *ServiceCategory // Catalog category; other also matches services outside the catalog
+	Sort              []SortField      // Empty means the endpoint's default order
+}
+
+// SortField orders subscriptions by one of SortableFields
+type SortField struct {
+	Field string
+	Desc  bool
+}
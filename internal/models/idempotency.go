@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// IdempotencyKey stores the response of a create request sent with an Idempotency-Key header,
+// so that a retry of the same request is answered from it instead of creating a duplicate
+type IdempotencyKey struct {
+	Scope        string  `gorm:"primaryKey;type:varchar(64)"`  // Caller the key belongs to, keys of different callers never collide
+	Key          string  `gorm:"primaryKey;type:varchar(255)"` // Client supplied Idempotency-Key
+	RequestHash  string  `gorm:"type:char(64);not null"`       // SHA-256 of the request, a reused key must come with the same request
+	ResponseBody RawJSON `gorm:"type:jsonb;not null"`
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// ImportColumns are the CSV columns accepted by the subscription import, in their usual order
+var ImportColumns = []string{
+	"service_name", "price", "currency", "billing_period", "billing_interval_count",
+	"user_id", "start_date", "end_date", "trial_end", "trial_price",
+}
+
+// IgnoredImportColumns are the columns of a subscriptions CSV export that the import skips, so
+// that an export can be imported again. Imported subscriptions get new values for them.
+var IgnoredImportColumns = []string{"id", "service_id", "version", "created_at", "updated_at"}
+
+// ImportRow is one CSV row of a subscription import, with its values as written in the file
+type ImportRow struct {
+	Line                 int // Line of the row in the file, counting the header as line 1
+	ServiceName          string
+	Price                string
+	Currency             string
+	BillingPeriod        string
+	BillingIntervalCount string
+	UserID               string
+	StartDate            string
+	EndDate              string
+	TrialEnd             string
+	TrialPrice           string
+
+	Err error // Why the row could not be read, such as a wrong number of fields; it is rejected with it
+}
+
+// ImportRejection reports why a row of an import was not (or, in a dry run, would not be) imported
+type ImportRejection struct {
+	Line  int            `json:"line" example:"3"`
+	Error *ErrorResponse `json:"error"`
+
+	Err error `json:"-"` // Failure reported by the service, turned into Error by the handler
+}
+
+// ImportResponse summarizes a subscription import
+type ImportResponse struct {
+	DryRun     bool              `json:"dry_run" example:"false"`
+	Total      int               `json:"total" example:"10"`   // Number of data rows in the file
+	Accepted   int               `json:"accepted" example:"9"` // Rows imported, or that would be imported in a dry run
+	Rejected   int               `json:"rejected" example:"1"` // Rows with errors, listed in Rejections
+	Rejections []ImportRejection `json:"rejections"`
+}
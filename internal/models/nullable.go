@@ -0,0 +1,35 @@
+package models
+
+import "encoding/json"
+
+// Nullable is a JSON Merge Patch field that may be cleared. Set reports whether the field was
+// present in the patch; Value is nil when it was null.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// NullableOf returns a Nullable set to value
+func NullableOf[T any](value T) Nullable[T] {
+	return Nullable[T]{Set: true, Value: &value}
+}
+
+// IsNull reports whether the field was explicitly set to null
+func (n Nullable[T]) IsNull() bool {
+	return n.Set && n.Value == nil
+}
+
+// UnmarshalJSON marks the field as present and decodes its value, if any
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	n.Value = &value
+	return nil
+}
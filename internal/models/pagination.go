@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// PageRequest holds the pagination parameters of a list request
+type PageRequest struct {
+	Limit        int
+	Offset       int    // Ignored when Cursor is set; kept for backward compatibility
+	Cursor       string // Opaque cursor taken from next_cursor or prev_cursor of a previous page
+	IncludeTotal bool   // Count every matching row; costs an extra query
+}
+
+// Cursor is a position in a list ordered by (created_at, id). A forward cursor selects the rows
+// after the position, a backward cursor the rows before it.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// CursorAfter returns the forward cursor following the subscription
+func CursorAfter(s *Subscription) *Cursor {
+	return &Cursor{CreatedAt: s.CreatedAt, ID: s.ID}
+}
+
+// CursorBefore returns the backward cursor preceding the subscription
+func CursorBefore(s *Subscription) *Cursor {
+	return &Cursor{CreatedAt: s.CreatedAt, ID: s.ID, Backward: true}
+}
+
+// Encode serializes the cursor into an opaque URL-safe token
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by Encode
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.ID == 0 || cursor.CreatedAt.IsZero() {
+		return nil, errors.New("incomplete cursor")
+	}
+	return &cursor, nil
+}
+
+// SubscriptionPage is a page of subscriptions ordered by creation time
+type SubscriptionPage struct {
+	Items      []Subscription `json:"items"`
+	NextCursor *string        `json:"next_cursor"`                   // Null on the last page
+	PrevCursor *string        `json:"prev_cursor"`                   // Null on the first page
+	Total      *int64         `json:"total,omitempty" example:"124"` // Only present when include_total=true
+}
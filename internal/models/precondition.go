@@ -0,0 +1,20 @@
+package models
+
+// Precondition is the If-Match requirement of a request on a single subscription. A nil
+// precondition always holds; otherwise the subscription must currently be at one of Versions.
+type Precondition struct {
+	Versions []int
+}
+
+// Matches reports whether a subscription at the given version satisfies the precondition
+func (p *Precondition) Matches(version int) bool {
+	if p == nil {
+		return true
+	}
+	for _, v := range p.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ReminderKind identifies the subscription event a reminder announces
+type ReminderKind string
+
+const (
+	ReminderKindRenewal ReminderKind = "renewal" // The subscription is about to be charged again
+	ReminderKindExpiry  ReminderKind = "expiry"  // The subscription is about to end
+	ReminderKindTrial   ReminderKind = "trial"   // The trial is about to convert to the full price
+)
+
+// ReminderStatus is the delivery state of a reminder
+type ReminderStatus string
+
+const (
+	ReminderStatusSending ReminderStatus = "sending" // Claimed by a scheduler; never retried automatically
+	ReminderStatusSent    ReminderStatus = "sent"
+	ReminderStatusFailed  ReminderStatus = "failed" // The notifier reported an error; retried up to the attempt limit
+)
+
+// Reminder is a notification about an upcoming subscription event
+type Reminder struct {
+	Kind         ReminderKind `json:"kind" example:"renewal"`
+	DueDate      string       `json:"due_date" example:"2025-08-01"` // Format: YYYY-MM-DD
+	Subscription Subscription `json:"subscription"`
+}
+
+// ReminderDelivery records that a reminder was claimed and whether it was delivered
+type ReminderDelivery struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint           `json:"subscription_id" gorm:"not null;uniqueIndex:uq_reminder_deliveries"`
+	Kind           ReminderKind   `json:"kind" gorm:"type:varchar(32);not null;uniqueIndex:uq_reminder_deliveries"`
+	DueDate        time.Time      `json:"due_date" gorm:"type:date;not null;uniqueIndex:uq_reminder_deliveries"`
+	Notifier       string         `json:"notifier" gorm:"type:varchar(32);not null"`
+	Status         ReminderStatus `json:"status" gorm:"type:varchar(16);not null;default:sending"`
+	Attempts       int            `json:"attempts" gorm:"not null;default:1"`
+	LastError      *string        `json:"last_error,omitempty"`
+	SentAt         *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
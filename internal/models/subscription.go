@@ -9,40 +9,127 @@ import (
 
 // Subscription represents a user's subscription to a service
 type Subscription struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	ServiceName string         `json:"service_name" gorm:"not null" validate:"required"`
-	Price       int            `json:"price" gorm:"not null" validate:"required,min=1"`
-	UserID      uuid.UUID      `json:"user_id" gorm:"type:uuid;not null" validate:"required"`
-	StartDate   string         `json:"start_date" gorm:"not null" validate:"required"` // Format: MM-YYYY
-	EndDate     *string        `json:"end_date,omitempty"`                             // Optional, Format: MM-YYYY
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                   uint           `json:"id" gorm:"primaryKey"`
+	ServiceName          string         `json:"service_name" gorm:"not null" validate:"required"`
+	ServiceID            *uint          `json:"service_id,omitempty" gorm:"index" example:"1"` // Catalogued service the name resolved to, if any
+	Price                int            `json:"price" gorm:"not null" validate:"required,min=1"`
+	Currency             string         `json:"currency" gorm:"type:char(3);not null;default:RUB" example:"RUB"` // ISO-4217 code
+	BillingPeriod        BillingPeriod  `json:"billing_period" gorm:"type:billing_period;not null;default:month" enums:"week,month,quarter,year" example:"month"`
+	BillingIntervalCount int            `json:"billing_interval_count" gorm:"not null;default:1" example:"1"` // Charged on StartDate and then every N billing periods
+	UserID               uuid.UUID      `json:"user_id" gorm:"type:uuid;not null" validate:"required"`
+	StartDate            YearMonth      `json:"start_date" gorm:"type:date;not null" validate:"required" swaggertype:"string" example:"01-2025"` // Format: MM-YYYY
+	EndDate              *YearMonth     `json:"end_date,omitempty" gorm:"type:date" swaggertype:"string" example:"12-2025"`                      // Optional, Format: MM-YYYY
+	TrialEnd             *YearMonth     `json:"trial_end,omitempty" gorm:"type:date" swaggertype:"string" example:"02-2025"`                     // Last month billed at TrialPrice, Format: MM-YYYY
+	TrialPrice           int            `json:"trial_price,omitempty" gorm:"not null;default:0" example:"0"`                                     // Charged until TrialEnd instead of Price
+	Version              int            `json:"version" gorm:"not null;default:1" example:"1"`                                                   // Incremented on every update, exposed as the ETag
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// PriceOn is the price of a charge made on day: the trial price until the trial is over, the
+// subscription price afterwards
+func (s *Subscription) PriceOn(day time.Time) int {
+	if s.TrialEnd != nil && !YearMonthOf(day).After(*s.TrialEnd) {
+		return s.TrialPrice
+	}
+	return s.Price
+}
+
+// TrialConversion is the first charge at the full price after the trial. It reports false when
+// the subscription has no trial or ends before the trial is over.
+func (s *Subscription) TrialConversion() (time.Time, bool) {
+	if s.TrialEnd == nil {
+		return time.Time{}, false
+	}
+	return s.NextChargeOn(s.TrialEnd.AddMonths(1).Time())
+}
+
+// SubscriptionCost is a subscription together with its share of a cost calculation
+type SubscriptionCost struct {
+	Subscription
+	BilledMonths int `json:"billed_months" example:"3"` // Months of the subscription that overlap the requested range
+	Charges      int `json:"charges" example:"3"`       // Charge events of the subscription within the requested range
+	Subtotal     int `json:"subtotal" example:"2997"`   // Sum of the charges, each at the price in effect in its month
 }
 
 // CreateSubscriptionRequest represents the request payload for creating a subscription
 type CreateSubscriptionRequest struct {
-	ServiceName string    `json:"service_name" validate:"required"`
-	Price       int       `json:"price" validate:"required,min=1"`
-	UserID      uuid.UUID `json:"user_id" validate:"required"`
-	StartDate   string    `json:"start_date" validate:"required"` // Format: MM-YYYY
-	EndDate     *string   `json:"end_date,omitempty"`             // Optional, Format: MM-YYYY
+	ServiceName          string    `json:"service_name" validate:"required"`
+	Price                int       `json:"price" validate:"required,min=1"`
+	Currency             string    `json:"currency,omitempty" example:"RUB"`                                         // Optional ISO-4217 code, defaults to RUB
+	BillingPeriod        string    `json:"billing_period,omitempty" enums:"week,month,quarter,year" example:"month"` // Optional, defaults to month
+	BillingIntervalCount int       `json:"billing_interval_count,omitempty" example:"1"`                             // Optional, defaults to 1
+	UserID               uuid.UUID `json:"user_id" validate:"required"`
+	StartDate            string    `json:"start_date" validate:"required"` // Format: MM-YYYY
+	EndDate              *string   `json:"end_date,omitempty"`             // Optional, Format: MM-YYYY
+	TrialEnd             *string   `json:"trial_end,omitempty"`            // Optional last month of the trial, Format: MM-YYYY
+	TrialPrice           int       `json:"trial_price,omitempty"`          // Optional price charged during the trial, defaults to 0 (free)
+}
+
+// UpdateSubscriptionRequest is a JSON Merge Patch (RFC 7396) of a subscription. Absent fields are
+// left unchanged; end_date and trial_end may be null to clear them, the other fields cannot be
+// null. Clearing trial_end also resets trial_price.
+type UpdateSubscriptionRequest struct {
+	ServiceName          *string          `json:"service_name,omitempty" example:"Netflix"`
+	Price                *int             `json:"price,omitempty" example:"999"`
+	Currency             *string          `json:"currency,omitempty" example:"RUB"`
+	BillingPeriod        *string          `json:"billing_period,omitempty" enums:"week,month,quarter,year" example:"month"`
+	BillingIntervalCount *int             `json:"billing_interval_count,omitempty" example:"1"`
+	StartDate            *string          `json:"start_date,omitempty" example:"01-2025"`                                   // Format: MM-YYYY
+	EndDate              Nullable[string] `json:"end_date" swaggertype:"string" example:"12-2025" extensions:"x-nullable"`  // Format: MM-YYYY, null clears it
+	TrialEnd             Nullable[string] `json:"trial_end" swaggertype:"string" example:"02-2025" extensions:"x-nullable"` // Format: MM-YYYY, null ends the trial
+	TrialPrice           *int             `json:"trial_price,omitempty" example:"0"`
 }
 
 // CostCalculationRequest represents the request for calculating total cost
 type CostCalculationRequest struct {
-	UserID      *uuid.UUID `form:"user_id"`
-	ServiceName *string    `form:"service_name"`
-	StartDate   string     `form:"start_date" validate:"required"` // Format: MM-YYYY
-	EndDate     string     `form:"end_date" validate:"required"`   // Format: MM-YYYY
+	SubscriptionFilterRequest        // Restricts the subscriptions included in the report
+	StartDate                 string `form:"start_date" validate:"required"` // Format: MM-YYYY
+	EndDate                   string `form:"end_date" validate:"required"`   // Format: MM-YYYY
+	// TargetCurrency converts every total into this ISO-4217 currency using the stored exchange rates
+	TargetCurrency *string `form:"target_currency"`
+}
+
+// CurrencyAmount is an amount of money in a single currency
+type CurrencyAmount struct {
+	Currency string `json:"currency" example:"RUB"`
+	Amount   int    `json:"amount" example:"2997"`
 }
 
 // CostCalculationResponse represents the response for cost calculation
 type CostCalculationResponse struct {
-	TotalCost     int            `json:"total_cost"`
-	StartDate     string         `json:"start_date"`
-	EndDate       string         `json:"end_date"`
-	UserID        *uuid.UUID     `json:"user_id,omitempty"`
-	ServiceName   *string        `json:"service_name,omitempty"`
-	Subscriptions []Subscription `json:"subscriptions"`
+	// TotalCost is expressed in Currency. It is omitted when subscriptions use several
+	// currencies and no target_currency was requested; see Totals instead.
+	TotalCost     *int               `json:"total_cost,omitempty" example:"2997"`
+	Currency      string             `json:"currency,omitempty" example:"RUB"`
+	Totals        []CurrencyAmount   `json:"totals"` // Totals grouped by subscription currency
+	StartDate     YearMonth          `json:"start_date" swaggertype:"string" example:"01-2025"`
+	EndDate       YearMonth          `json:"end_date" swaggertype:"string" example:"12-2025"`
+	UserID        *uuid.UUID         `json:"user_id,omitempty"`
+	ServiceName   *string            `json:"service_name,omitempty"`
+	ServiceNames  []string           `json:"service_names,omitempty"` // Set instead of ServiceName when several names were requested
+	Subscriptions []SubscriptionCost `json:"subscriptions"`
+}
+
+// UpcomingCharge is the next charge of an active subscription
+type UpcomingCharge struct {
+	SubscriptionID       uint          `json:"subscription_id" example:"1"`
+	ServiceName          string        `json:"service_name" example:"Netflix"`
+	UserID               uuid.UUID     `json:"user_id"`
+	Amount               int           `json:"amount" example:"990"`
+	Currency             string        `json:"currency" example:"RUB"`
+	BillingPeriod        BillingPeriod `json:"billing_period" enums:"week,month,quarter,year" example:"month"`
+	BillingIntervalCount int           `json:"billing_interval_count" example:"1"`
+	NextChargeDate       string        `json:"next_charge_date" example:"2025-08-01"` // Format: YYYY-MM-DD
+	DaysUntil            int           `json:"days_until" example:"12"`
+}
+
+// SubscriptionPrice is the price of a subscription from a month on, until the month of the next
+// price. The first price also applies to any month before it.
+type SubscriptionPrice struct {
+	SubscriptionID uint      `json:"subscription_id" gorm:"primaryKey;autoIncrement:false" example:"1"`
+	EffectiveFrom  YearMonth `json:"effective_from" gorm:"primaryKey;type:date" swaggertype:"string" example:"03-2025"` // Format: MM-YYYY
+	Price          int       `json:"price" gorm:"not null" example:"1199"`
+	CreatedAt      time.Time `json:"created_at"`
 }
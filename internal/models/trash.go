@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// TrashedSubscription is a soft-deleted subscription that can still be restored
+type TrashedSubscription struct {
+	Subscription
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAfter time.Time `json:"purge_after"` // When the retention job deletes it permanently
+}
+
+// TrashPage is a page of soft-deleted subscriptions, most recently deleted first unless a sort is requested
+type TrashPage struct {
+	Items []TrashedSubscription `json:"items"`
+	Total *int64                `json:"total,omitempty" example:"3"` // Only present when include_total=true
+}
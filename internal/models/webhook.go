@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Subscription lifecycle events published to webhook endpoints
+const (
+	EventSubscriptionCreated  = "subscription.created"
+	EventSubscriptionUpdated  = "subscription.updated"
+	EventSubscriptionEnded    = "subscription.ended" // end_date was set
+	EventSubscriptionDeleted  = "subscription.deleted"
+	EventSubscriptionRestored = "subscription.restored" // Taken out of the trash
+)
+
+// EventBudgetExceeded is published when creating a subscription or changing its price pushes a
+// month over a budget of its user
+const EventBudgetExceeded = "budget.exceeded"
+
+// EventTypes lists every event a webhook endpoint can subscribe to
+var EventTypes = []string{
+	EventSubscriptionCreated,
+	EventSubscriptionUpdated,
+	EventSubscriptionEnded,
+	EventSubscriptionDeleted,
+	EventSubscriptionRestored,
+	EventBudgetExceeded,
+}
+
+// WebhookDeliveryStatus is the state of delivering one event to one endpoint
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending" // Waiting for its first attempt or a retry
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // Every attempt failed
+)
+
+// WebhookEndpoint is a receiver of subscription lifecycle events
+type WebhookEndpoint struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	URL        string         `json:"url" gorm:"not null" example:"https://billing.example.com/hooks/subscriptions"`
+	Secret     string         `json:"-" gorm:"not null"`                                                                                // HMAC-SHA256 signing key
+	EventTypes StringList     `json:"event_types" gorm:"type:jsonb;not null" swaggertype:"array,string" example:"subscription.created"` // Empty means every event type
+	Active     bool           `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Accepts reports whether the endpoint is subscribed to the event type
+func (e *WebhookEndpoint) Accepts(eventType string) bool {
+	if len(e.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range e.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEndpointWithSecret is returned once, when an endpoint is created, so the receiver
+// can verify signatures
+type WebhookEndpointWithSecret struct {
+	WebhookEndpoint
+	Secret string `json:"secret" example:"3f5c0e..."`
+}
+
+// WebhookEvent is an outbox entry written in the same transaction as the subscription change
+type WebhookEvent struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	EventType      string     `json:"event_type" gorm:"type:varchar(64);not null" example:"subscription.created"`
+	SubscriptionID uint       `json:"subscription_id" gorm:"not null"` // The subscription that changed
+	Payload        RawJSON    `json:"payload" gorm:"type:jsonb;not null" swaggertype:"object"`
+	DispatchedAt   *time.Time `json:"dispatched_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// WebhookDelivery tracks delivering one event to one endpoint
+type WebhookDelivery struct {
+	ID             uint                  `json:"id" gorm:"primaryKey"`
+	EventID        uint                  `json:"event_id" gorm:"not null;uniqueIndex:uq_webhook_deliveries"`
+	EndpointID     uint                  `json:"endpoint_id" gorm:"not null;uniqueIndex:uq_webhook_deliveries"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"type:varchar(16);not null;default:pending" example:"pending"`
+	Attempts       int                   `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" gorm:"not null"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty" example:"502"`
+	LastError      *string               `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	Event          *WebhookEvent         `json:"event,omitempty" gorm:"foreignKey:EventID"`
+	Endpoint       *WebhookEndpoint      `json:"-" gorm:"foreignKey:EndpointID"`
+}
+
+// WebhookPayload is the signed JSON body posted to endpoints
+type WebhookPayload struct {
+	ID        uint      `json:"id" example:"42"` // Event ID; stable across retries
+	Type      string    `json:"type" example:"subscription.created"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      RawJSON   `json:"data" swaggertype:"object"` // The subscription, or the BudgetAlert of budget.exceeded events
+}
+
+// CreateWebhookRequest represents the request payload for registering a webhook endpoint
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required" example:"https://billing.example.com/hooks/subscriptions"`
+	EventTypes []string `json:"event_types,omitempty" example:"subscription.created"` // Optional, defaults to every event type
+	Secret     string   `json:"secret,omitempty"`                                     // Optional, generated when empty
+}
+
+// UpdateWebhookRequest represents the request payload for changing a webhook endpoint
+type UpdateWebhookRequest struct {
+	URL        *string   `json:"url,omitempty"`
+	EventTypes *[]string `json:"event_types,omitempty"`
+	Active     *bool     `json:"active,omitempty"`
+}
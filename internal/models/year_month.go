@@ -0,0 +1,165 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// yearMonthPattern matches the MM-YYYY wire format
+var yearMonthPattern = regexp.MustCompile(`^(0[1-9]|1[0-2])-([0-9]{4})$`)
+
+// YearMonth represents a calendar month. It is stored in the database as a DATE
+// pointing to the first day of the month and is exchanged over the wire as MM-YYYY.
+type YearMonth struct {
+	Year  int
+	Month time.Month
+}
+
+// NewYearMonth creates a YearMonth, normalising out-of-range months
+func NewYearMonth(year int, month time.Month) YearMonth {
+	return YearMonthOf(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// YearMonthOf returns the month containing the given time
+func YearMonthOf(t time.Time) YearMonth {
+	return YearMonth{Year: t.Year(), Month: t.Month()}
+}
+
+// ParseYearMonth parses a date in MM-YYYY format
+func ParseYearMonth(s string) (YearMonth, error) {
+	parts := yearMonthPattern.FindStringSubmatch(s)
+	if parts == nil {
+		return YearMonth{}, fmt.Errorf("invalid month %q: expected MM-YYYY format", s)
+	}
+
+	month, _ := strconv.Atoi(parts[1])
+	year, _ := strconv.Atoi(parts[2])
+
+	return YearMonth{Year: year, Month: time.Month(month)}, nil
+}
+
+// String formats the month as MM-YYYY
+func (ym YearMonth) String() string {
+	return fmt.Sprintf("%02d-%04d", int(ym.Month), ym.Year)
+}
+
+// IsZero reports whether the month is unset
+func (ym YearMonth) IsZero() bool {
+	return ym.Year == 0 && ym.Month == 0
+}
+
+// Time returns midnight UTC on the first day of the month
+func (ym YearMonth) Time() time.Time {
+	return time.Date(ym.Year, ym.Month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// AddMonths returns the month n months after ym (n may be negative)
+func (ym YearMonth) AddMonths(n int) YearMonth {
+	return NewYearMonth(ym.Year, ym.Month+time.Month(n))
+}
+
+// Compare returns -1, 0 or +1 depending on whether ym is before, equal to or after other
+func (ym YearMonth) Compare(other YearMonth) int {
+	a, b := ym.index(), other.index()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Before reports whether ym is strictly before other
+func (ym YearMonth) Before(other YearMonth) bool {
+	return ym.Compare(other) < 0
+}
+
+// After reports whether ym is strictly after other
+func (ym YearMonth) After(other YearMonth) bool {
+	return ym.Compare(other) > 0
+}
+
+// MonthsUntil returns the number of months from ym to other (negative if other is earlier)
+func (ym YearMonth) MonthsUntil(other YearMonth) int {
+	return other.index() - ym.index()
+}
+
+func (ym YearMonth) index() int {
+	return ym.Year*12 + int(ym.Month) - 1
+}
+
+// MarshalJSON encodes the month as an MM-YYYY string
+func (ym YearMonth) MarshalJSON() ([]byte, error) {
+	if ym.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(ym.String())
+}
+
+// UnmarshalJSON decodes an MM-YYYY string
+func (ym *YearMonth) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*ym = YearMonth{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("month must be a string in MM-YYYY format: %w", err)
+	}
+
+	parsed, err := ParseYearMonth(s)
+	if err != nil {
+		return err
+	}
+	*ym = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, storing the first day of the month
+func (ym YearMonth) Value() (driver.Value, error) {
+	if ym.IsZero() {
+		return nil, nil
+	}
+	return ym.Time(), nil
+}
+
+// Scan implements sql.Scanner for DATE columns
+func (ym *YearMonth) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*ym = YearMonth{}
+		return nil
+	case time.Time:
+		*ym = YearMonthOf(v)
+		return nil
+	case []byte:
+		return ym.scanString(string(v))
+	case string:
+		return ym.scanString(v)
+	default:
+		return fmt.Errorf("cannot scan %T into YearMonth", src)
+	}
+}
+
+func (ym *YearMonth) scanString(s string) error {
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			*ym = YearMonthOf(t)
+			return nil
+		}
+	}
+
+	parsed, err := ParseYearMonth(s)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into YearMonth", s)
+	}
+	*ym = parsed
+	return nil
+}
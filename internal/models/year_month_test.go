@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYearMonth_CompareAcrossYearBoundary(t *testing.T) {
+	dec2025, _ := ParseYearMonth("12-2025")
+	jan2026, _ := ParseYearMonth("01-2026")
+
+	assert.True(t, dec2025.Before(jan2026))
+	assert.True(t, jan2026.After(dec2025))
+	assert.Equal(t, 1, dec2025.MonthsUntil(jan2026))
+	assert.Equal(t, jan2026, dec2025.AddMonths(1))
+}
+
+func TestParseYearMonth(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"valid date", "01-2024", true},
+		{"valid date dec", "12-2024", true},
+		{"invalid format year-month", "2024-01", false},
+		{"invalid month", "13-2024", false},
+		{"invalid month zero", "00-2024", false},
+		{"missing dash", "012024", false},
+		{"extra characters", "01-2024-01", false},
+		{"empty string", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseYearMonth(tc.value)
+			assert.Equal(t, tc.valid, err == nil)
+		})
+	}
+}
+
+func TestYearMonth_JSONRoundTrip(t *testing.T) {
+	var sub struct {
+		Start YearMonth  `json:"start"`
+		End   *YearMonth `json:"end,omitempty"`
+	}
+
+	err := json.Unmarshal([]byte(`{"start":"07-2025","end":"01-2026"}`), &sub)
+	assert.NoError(t, err)
+	assert.Equal(t, NewYearMonth(2025, time.July), sub.Start)
+	assert.Equal(t, NewYearMonth(2026, time.January), *sub.End)
+
+	data, err := json.Marshal(sub)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"start":"07-2025","end":"01-2026"}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"start":"2025-07"}`), &sub)
+	assert.Error(t, err)
+}
+
+func TestYearMonth_SQL(t *testing.T) {
+	ym := NewYearMonth(2025, time.March)
+
+	value, err := ym.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), value)
+
+	var scanned YearMonth
+	assert.NoError(t, scanned.Scan(time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, ym, scanned)
+
+	assert.NoError(t, scanned.Scan("2025-03-01"))
+	assert.Equal(t, ym, scanned)
+
+	assert.Error(t, scanned.Scan(42))
+}
@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"context"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogNotifier writes reminders to the application log. It is useful in development and
+// as a safe default when no delivery channel is configured.
+type LogNotifier struct {
+	logger *logrus.Logger
+}
+
+// NewLogNotifier creates a notifier that logs reminders
+func NewLogNotifier(logger *logrus.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Name() string {
+	return "log"
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	n.logger.WithFields(logrus.Fields{
+		"kind":            reminder.Kind,
+		"due_date":        reminder.DueDate,
+		"subscription_id": reminder.Subscription.ID,
+		"user_id":         reminder.Subscription.UserID,
+		"service_name":    reminder.Subscription.ServiceName,
+	}).Info(Subject(reminder))
+	return nil
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"subscription_tracker_api/internal/config"
+	"subscription_tracker_api/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Notifier delivers reminders about subscription events to their owners
+type Notifier interface {
+	// Name identifies the delivery channel in the reminder delivery log
+	Name() string
+	Notify(ctx context.Context, reminder models.Reminder) error
+}
+
+// New builds the notifier selected in the reminder configuration
+func New(cfg config.RemindersConfig, logger *logrus.Logger) (Notifier, error) {
+	switch cfg.Notifier {
+	case "log":
+		return NewLogNotifier(logger), nil
+	case "smtp":
+		return NewSMTPNotifier(cfg.SMTP)
+	case "webhook":
+		return NewWebhookNotifier(cfg.Webhook)
+	default:
+		return nil, fmt.Errorf("unknown notifier %q: expected log, smtp or webhook", cfg.Notifier)
+	}
+}
+
+// Subject returns a one-line summary of the reminder
+func Subject(reminder models.Reminder) string {
+	sub := reminder.Subscription
+	switch reminder.Kind {
+	case models.ReminderKindExpiry:
+		return fmt.Sprintf("%s subscription ends on %s", sub.ServiceName, reminder.DueDate)
+	case models.ReminderKindTrial:
+		return fmt.Sprintf("%s trial converts to a paid subscription on %s", sub.ServiceName, reminder.DueDate)
+	default:
+		return fmt.Sprintf("%s subscription renews on %s", sub.ServiceName, reminder.DueDate)
+	}
+}
+
+// Body returns the human-readable reminder text
+func Body(reminder models.Reminder) string {
+	sub := reminder.Subscription
+	switch reminder.Kind {
+	case models.ReminderKindExpiry:
+		return fmt.Sprintf("Your %s subscription ends on %s. You will not be charged after that date.\r\n",
+			sub.ServiceName, reminder.DueDate)
+	case models.ReminderKindTrial:
+		return fmt.Sprintf("Your %s trial ends soon: the subscription will be charged %d %s on %s. Cancel it before then to avoid the charge.\r\n",
+			sub.ServiceName, sub.Price, sub.Currency, reminder.DueDate)
+	default:
+		dueDate, _ := time.Parse(time.DateOnly, reminder.DueDate)
+		return fmt.Sprintf("Your %s subscription renews on %s and will be charged %d %s.\r\n",
+			sub.ServiceName, reminder.DueDate, sub.PriceOn(dueDate), sub.Currency)
+	}
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"subscription_tracker_api/internal/config"
+	"subscription_tracker_api/internal/models"
+	"time"
+)
+
+// SMTPNotifier emails reminders through an SMTP relay
+type SMTPNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   string
+}
+
+// NewSMTPNotifier creates a notifier sending mail through the configured relay
+func NewSMTPNotifier(cfg config.SMTPConfig) (*SMTPNotifier, error) {
+	if cfg.Host == "" || cfg.From == "" || cfg.To == "" {
+		return nil, errors.New("smtp notifier requires host, from and to")
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return &SMTPNotifier{
+		addr: net.JoinHostPort(cfg.Host, cfg.Port),
+		auth: auth,
+		from: cfg.From,
+		to:   cfg.To,
+	}, nil
+}
+
+func (n *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+// Notify sends the reminder to the subscription owner. smtp.SendMail does not take a
+// context, so cancellation is only checked before the message is handed to the relay.
+func (n *SMTPNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	to := strings.ReplaceAll(n.to, "{user_id}", reminder.Subscription.UserID.String())
+	if err := smtp.SendMail(n.addr, n.auth, n.from, []string{to}, n.message(to, reminder)); err != nil {
+		return fmt.Errorf("send reminder email: %w", err)
+	}
+	return nil
+}
+
+func (n *SMTPNotifier) message(to string, reminder models.Reminder) []byte {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", Subject(reminder))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(Body(reminder))
+	return []byte(msg.String())
+}
@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"subscription_tracker_api/internal/config"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTPServer accepts a single SMTP session and records the envelope and message
+type fakeSMTPServer struct {
+	listener net.Listener
+	rcpt     chan string
+	data     chan string
+}
+
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTPServer{listener: listener, rcpt: make(chan string, 1), data: make(chan string, 1)}
+	go server.serve()
+	return server
+}
+
+func (s *fakeSMTPServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP fake")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.rcpt <- strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>")
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.data <- data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPNotifier_SendsReminderToOwner(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	notifier, err := NewSMTPNotifier(config.SMTPConfig{
+		Host: host,
+		Port: port,
+		From: "reminders@example.com",
+		To:   "{user_id}@users.example.com",
+	})
+	assert.NoError(t, err)
+
+	userID := uuid.New()
+	err = notifier.Notify(context.Background(), models.Reminder{
+		Kind:    models.ReminderKindRenewal,
+		DueDate: "2025-08-01",
+		Subscription: models.Subscription{
+			ServiceName: "Netflix",
+			Price:       990,
+			Currency:    "RUB",
+			UserID:      userID,
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID.String()+"@users.example.com", <-server.rcpt)
+	data := <-server.data
+	assert.Contains(t, data, "Subject: Netflix subscription renews on 2025-08-01")
+	assert.Contains(t, data, "will be charged 990 RUB")
+}
+
+func TestNewSMTPNotifier_RequiresAddresses(t *testing.T) {
+	_, err := NewSMTPNotifier(config.SMTPConfig{Host: "localhost", Port: "25"})
+	assert.Error(t, err)
+}
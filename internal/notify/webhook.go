@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"subscription_tracker_api/internal/config"
+	"subscription_tracker_api/internal/models"
+)
+
+// WebhookNotifier posts reminders as JSON to a configured URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to the configured URL
+func NewWebhookNotifier(cfg config.ReminderWebhookConfig) (*WebhookNotifier, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("webhook notifier requires a url")
+	}
+	return &WebhookNotifier{
+		url:    cfg.URL,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify posts the reminder; any non-2xx response is treated as a failed delivery
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	payload, err := json.Marshal(reminder)
+	if err != nil {
+		return fmt.Errorf("encode reminder: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post reminder webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("reminder webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/config"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier_PostsReminder(t *testing.T) {
+	received := make(chan models.Reminder, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reminder models.Reminder
+		json.NewDecoder(r.Body).Decode(&reminder)
+		received <- reminder
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(config.ReminderWebhookConfig{URL: server.URL, Timeout: time.Second})
+	assert.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), models.Reminder{
+		Kind:         models.ReminderKindExpiry,
+		DueDate:      "2025-09-01",
+		Subscription: models.Subscription{ID: 7, ServiceName: "Spotify"},
+	})
+
+	assert.NoError(t, err)
+	reminder := <-received
+	assert.Equal(t, models.ReminderKindExpiry, reminder.Kind)
+	assert.Equal(t, uint(7), reminder.Subscription.ID)
+}
+
+func TestWebhookNotifier_FailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	notifier, _ := NewWebhookNotifier(config.ReminderWebhookConfig{URL: server.URL, Timeout: time.Second})
+
+	err := notifier.Notify(context.Background(), models.Reminder{Kind: models.ReminderKindRenewal})
+
+	assert.ErrorContains(t, err, "status 502")
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// AuditRepository handles database operations for the subscription audit log
+type AuditRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewAuditRepository creates a new audit log repository
+func NewAuditRepository(db *gorm.DB, logger *logrus.Logger) *AuditRepository {
+	return &AuditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Record writes an audit event. Pass the transaction that changes the subscription so the
+// event is only kept if the change commits.
+func (r *AuditRepository) Record(ctx context.Context, tx *gorm.DB, event *models.AuditEvent) error {
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	return db.WithContext(ctx).Create(event).Error
+}
+
+// List retrieves the audit events matching the filter, newest first
+func (r *AuditRepository) List(ctx context.Context, filter *models.AuditFilter, limit, offset int) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	query := r.applyFilter(r.db.WithContext(ctx), filter)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	err := query.Order("id DESC").Find(&events).Error
+	return events, err
+}
+
+// applyFilter restricts query to the audit events matching filter
+func (r *AuditRepository) applyFilter(query *gorm.DB, filter *models.AuditFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if filter.SubscriptionID != nil {
+		query = query.Where("subscription_id = ?", *filter.SubscriptionID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != nil {
+		query = query.Where("action = ?", *filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAuditRepository(t *testing.T) *AuditRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.AuditEvent{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewAuditRepository(db, logger)
+}
+
+func TestAuditRepository_ListFilters(t *testing.T) {
+	repo := setupAuditRepository(t)
+	ctx := context.Background()
+
+	alice, bob := uuid.New(), uuid.New()
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	events := []*models.AuditEvent{
+		{SubscriptionID: 1, UserID: alice, Actor: alice.String(), Action: models.AuditActionCreate, CreatedAt: start},
+		{SubscriptionID: 1, UserID: alice, Actor: "support@example.com", Action: models.AuditActionUpdate, CreatedAt: start.Add(time.Hour)},
+		{SubscriptionID: 2, UserID: bob, Actor: bob.String(), Action: models.AuditActionCreate, CreatedAt: start.Add(2 * time.Hour)},
+		{SubscriptionID: 1, UserID: alice, Actor: models.AuditActorSystem, Action: models.AuditActionPurge, CreatedAt: start.Add(3 * time.Hour)},
+	}
+	for _, event := range events {
+		event.Changes = models.RawJSON(`{}`)
+		assert.NoError(t, repo.Record(ctx, nil, event))
+	}
+
+	ids := func(filter *models.AuditFilter, limit, offset int) []uint {
+		found, err := repo.List(ctx, filter, limit, offset)
+		assert.NoError(t, err)
+		var result []uint
+		for _, event := range found {
+			result = append(result, event.ID)
+		}
+		return result
+	}
+
+	subscriptionID := uint(1)
+	create := models.AuditActionCreate
+	from, to := start.Add(time.Hour), start.Add(3*time.Hour)
+
+	assert.Equal(t, []uint{4, 3, 2, 1}, ids(nil, 0, 0))
+	assert.Equal(t, []uint{4, 2, 1}, ids(&models.AuditFilter{SubscriptionID: &subscriptionID}, 0, 0))
+	assert.Equal(t, []uint{2}, ids(&models.AuditFilter{SubscriptionID: &subscriptionID}, 1, 1))
+	assert.Equal(t, []uint{3}, ids(&models.AuditFilter{UserID: &bob}, 0, 0))
+	assert.Equal(t, []uint{2}, ids(&models.AuditFilter{Actor: "support@example.com"}, 0, 0))
+	assert.Equal(t, []uint{3, 1}, ids(&models.AuditFilter{Action: &create}, 0, 0))
+	assert.Equal(t, []uint{3, 2}, ids(&models.AuditFilter{From: &from, To: &to}, 0, 0))
+}
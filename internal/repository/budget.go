@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// BudgetRepository handles database operations for spending budgets
+type BudgetRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewBudgetRepository creates a new budget repository
+func NewBudgetRepository(db *gorm.DB, logger *logrus.Logger) *BudgetRepository {
+	return &BudgetRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new budget
+func (r *BudgetRepository) Create(ctx context.Context, tx *gorm.DB, budget *models.Budget) error {
+	return r.getDB(ctx, tx).Create(budget).Error
+}
+
+// GetByID retrieves a budget by ID
+func (r *BudgetRepository) GetByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Budget, error) {
+	var budget models.Budget
+	if err := r.getDB(ctx, tx).First(&budget, id).Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// List retrieves the budgets of a user, or of every user when userID is nil, ordered by ID
+func (r *BudgetRepository) List(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error) {
+	query := r.getDB(ctx, nil).Order("id")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var budgets []models.Budget
+	err := query.Find(&budgets).Error
+	return budgets, err
+}
+
+// ListForSubscription retrieves the budgets a subscription counts towards: the overall budget of
+// its user, the budget of its service and the budget of its service's catalog category
+func (r *BudgetRepository) ListForSubscription(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := r.getDB(ctx, tx).
+		Where("user_id = ?", subscription.UserID).
+		Where(
+			"scope = ? OR (scope = ? AND LOWER(service_name) = LOWER(?)) OR (scope = ? AND category = COALESCE((SELECT category FROM services WHERE id = ?), ?))",
+			models.BudgetScopeOverall,
+			models.BudgetScopeService, subscription.ServiceName,
+			models.BudgetScopeCategory, subscription.ServiceID, models.ServiceCategoryOther,
+		).
+		Order("id").
+		Find(&budgets).Error
+	return budgets, err
+}
+
+// Update saves the changes of a budget
+func (r *BudgetRepository) Update(ctx context.Context, tx *gorm.DB, budget *models.Budget) error {
+	return r.getDB(ctx, tx).Save(budget).Error
+}
+
+// Delete removes a budget and reports whether it existed
+func (r *BudgetRepository) Delete(ctx context.Context, tx *gorm.DB, id uint) (bool, error) {
+	result := r.getDB(ctx, tx).Delete(&models.Budget{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Exists reports whether the user of a budget already has one with the same scope, category and
+// service. Service names are compared ignoring case.
+func (r *BudgetRepository) Exists(ctx context.Context, tx *gorm.DB, budget *models.Budget) (bool, error) {
+	query := r.getDB(ctx, tx).Model(&models.Budget{}).
+		Where("user_id = ? AND scope = ?", budget.UserID, budget.Scope)
+	if budget.Category != nil {
+		query = query.Where("category = ?", *budget.Category)
+	}
+	if budget.ServiceName != nil {
+		query = query.Where("LOWER(service_name) = LOWER(?)", *budget.ServiceName)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// Helper to get the correct DB instance (transaction or regular) bound to ctx
+func (r *BudgetRepository) getDB(ctx context.Context, tx *gorm.DB) *gorm.DB {
+	if tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx)
+}
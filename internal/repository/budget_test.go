@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupBudgetRepository(t *testing.T) *BudgetRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Budget{}, &models.Service{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewBudgetRepository(db, logger)
+}
+
+func TestBudgetRepository_ListForSubscription(t *testing.T) {
+	repo := setupBudgetRepository(t)
+	ctx := context.Background()
+
+	netflix := &models.Service{Name: "Netflix", Category: models.ServiceCategoryStreaming, Currency: "RUB"}
+	assert.NoError(t, repo.db.Create(netflix).Error)
+
+	userID, otherID := uuid.New(), uuid.New()
+	streaming, other := models.ServiceCategoryStreaming, models.ServiceCategoryOther
+	netflixName, spotifyName := "Netflix", "Spotify"
+	budgets := []*models.Budget{
+		{UserID: userID, Scope: models.BudgetScopeOverall, MonthlyLimit: 5000, Currency: "RUB"},
+		{UserID: userID, Scope: models.BudgetScopeCategory, Category: &streaming, MonthlyLimit: 2000, Currency: "RUB"},
+		{UserID: userID, Scope: models.BudgetScopeCategory, Category: &other, MonthlyLimit: 1000, Currency: "RUB"},
+		{UserID: userID, Scope: models.BudgetScopeService, ServiceName: &netflixName, MonthlyLimit: 1000, Currency: "RUB"},
+		{UserID: userID, Scope: models.BudgetScopeService, ServiceName: &spotifyName, MonthlyLimit: 500, Currency: "RUB"},
+		{UserID: otherID, Scope: models.BudgetScopeOverall, MonthlyLimit: 5000, Currency: "RUB"},
+	}
+	for _, budget := range budgets {
+		assert.NoError(t, repo.Create(ctx, nil, budget))
+	}
+
+	ids := func(subscription *models.Subscription) []uint {
+		found, err := repo.ListForSubscription(ctx, nil, subscription)
+		assert.NoError(t, err)
+		result := make([]uint, 0, len(found))
+		for _, budget := range found {
+			result = append(result, budget.ID)
+		}
+		return result
+	}
+
+	// A catalogued service counts towards its category; other services towards other
+	assert.Equal(t, []uint{1, 2, 4}, ids(&models.Subscription{UserID: userID, ServiceName: "netflix", ServiceID: &netflix.ID}))
+	assert.Equal(t, []uint{1, 3, 5}, ids(&models.Subscription{UserID: userID, ServiceName: "SPOTIFY"}))
+
+	listed, err := repo.List(ctx, &otherID)
+	assert.NoError(t, err)
+	assert.Len(t, listed, 1)
+}
+
+func TestBudgetRepository_ExistsMatchesScopeAndTarget(t *testing.T) {
+	repo := setupBudgetRepository(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	netflix, spotify := "Netflix", "spotify"
+	assert.NoError(t, repo.Create(ctx, nil, &models.Budget{UserID: userID, Scope: models.BudgetScopeService, ServiceName: &netflix, MonthlyLimit: 1000, Currency: "RUB"}))
+
+	sameService := "NETFLIX"
+	exists, err := repo.Exists(ctx, nil, &models.Budget{UserID: userID, Scope: models.BudgetScopeService, ServiceName: &sameService})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.Exists(ctx, nil, &models.Budget{UserID: userID, Scope: models.BudgetScopeService, ServiceName: &spotify})
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = repo.Exists(ctx, nil, &models.Budget{UserID: userID, Scope: models.BudgetScopeOverall})
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ServiceRepository handles database operations for the service catalog and its aliases
+type ServiceRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewServiceRepository creates a new service catalog repository
+func NewServiceRepository(db *gorm.DB, logger *logrus.Logger) *ServiceRepository {
+	return &ServiceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create adds a service to the catalog together with the aliases of its canonical name and of
+// service.Aliases, which are expected to be normalized
+func (r *ServiceRepository) Create(ctx context.Context, tx *gorm.DB, service *models.Service) error {
+	db := r.getDB(ctx, tx)
+	if err := db.Create(service).Error; err != nil {
+		return err
+	}
+	return r.saveAliases(db, service)
+}
+
+// GetByID retrieves a catalogued service with its aliases
+func (r *ServiceRepository) GetByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Service, error) {
+	db := r.getDB(ctx, tx)
+	var service models.Service
+	if err := db.First(&service, id).Error; err != nil {
+		return nil, err
+	}
+	if err := r.loadAliases(db, []*models.Service{&service}); err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// List retrieves the catalogued services ordered by name, optionally only those of a category
+func (r *ServiceRepository) List(ctx context.Context, category *models.ServiceCategory) ([]models.Service, error) {
+	db := r.getDB(ctx, nil)
+	query := db.Order("LOWER(name), id")
+	if category != nil {
+		query = query.Where("category = ?", *category)
+	}
+
+	var services []models.Service
+	if err := query.Find(&services).Error; err != nil {
+		return nil, err
+	}
+	refs := make([]*models.Service, len(services))
+	for i := range services {
+		refs[i] = &services[i]
+	}
+	if err := r.loadAliases(db, refs); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// Update saves a catalogued service and replaces its aliases
+func (r *ServiceRepository) Update(ctx context.Context, tx *gorm.DB, service *models.Service) error {
+	db := r.getDB(ctx, tx)
+	if err := db.Save(service).Error; err != nil {
+		return err
+	}
+	if err := db.Where("service_id = ?", service.ID).Delete(&models.ServiceAlias{}).Error; err != nil {
+		return err
+	}
+	return r.saveAliases(db, service)
+}
+
+// Delete removes a service and its aliases from the catalog and reports whether it existed.
+// Subscriptions linked to it keep their name and lose the link.
+func (r *ServiceRepository) Delete(ctx context.Context, tx *gorm.DB, id uint) (bool, error) {
+	db := r.getDB(ctx, tx)
+	if err := db.Where("service_id = ?", id).Delete(&models.ServiceAlias{}).Error; err != nil {
+		return false, err
+	}
+	result := db.Delete(&models.Service{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// TakenAliases returns the normalized names among aliases that already resolve to a service
+// other than excludeID
+func (r *ServiceRepository) TakenAliases(ctx context.Context, tx *gorm.DB, aliases []string, excludeID uint) ([]string, error) {
+	var taken []string
+	err := r.getDB(ctx, tx).Model(&models.ServiceAlias{}).
+		Where("alias IN ? AND service_id <> ?", aliases, excludeID).
+		Order("alias").
+		Pluck("alias", &taken).Error
+	return taken, err
+}
+
+// ResolveName retrieves the service a name or alias resolves to once normalized. It returns
+// nil when the name is not catalogued.
+func (r *ServiceRepository) ResolveName(ctx context.Context, name string) (*models.Service, error) {
+	var alias models.ServiceAlias
+	err := r.getDB(ctx, nil).Where("alias = ?", models.NormalizeServiceName(name)).First(&alias).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.GetByID(ctx, nil, alias.ServiceID)
+}
+
+// saveAliases writes the aliases of a service, its canonical name first
+func (r *ServiceRepository) saveAliases(db *gorm.DB, service *models.Service) error {
+	aliases := []models.ServiceAlias{{Alias: models.NormalizeServiceName(service.Name), ServiceID: service.ID}}
+	for _, alias := range service.Aliases {
+		aliases = append(aliases, models.ServiceAlias{Alias: alias, ServiceID: service.ID})
+	}
+	return db.Create(&aliases).Error
+}
+
+// loadAliases fills in the aliases of services, leaving out the one of each canonical name
+func (r *ServiceRepository) loadAliases(db *gorm.DB, services []*models.Service) error {
+	if len(services) == 0 {
+		return nil
+	}
+	byID := make(map[uint]*models.Service, len(services))
+	for _, service := range services {
+		service.Aliases = []string{}
+		byID[service.ID] = service
+	}
+
+	var aliases []models.ServiceAlias
+	if err := db.Where("service_id IN ?", slices.Collect(maps.Keys(byID))).Order("alias").Find(&aliases).Error; err != nil {
+		return err
+	}
+	for _, alias := range aliases {
+		service := byID[alias.ServiceID]
+		if alias.Alias != models.NormalizeServiceName(service.Name) {
+			service.Aliases = append(service.Aliases, alias.Alias)
+		}
+	}
+	return nil
+}
+
+// Helper to get the correct DB instance (transaction or regular) bound to ctx
+func (r *ServiceRepository) getDB(ctx context.Context, tx *gorm.DB) *gorm.DB {
+	if tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx)
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupServiceRepository(t *testing.T) *ServiceRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Service{}, &models.ServiceAlias{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewServiceRepository(db, logger)
+}
+
+func TestServiceRepository_ResolvesNamesAndAliases(t *testing.T) {
+	repo := setupServiceRepository(t)
+	ctx := context.Background()
+
+	price := 999
+	netflix := &models.Service{Name: "Netflix", Aliases: []string{"netflix hd"}, Category: models.ServiceCategoryStreaming, DefaultPrice: &price, Currency: "RUB"}
+	spotify := &models.Service{Name: "Spotify", Category: models.ServiceCategoryMusic, Currency: "USD"}
+	assert.NoError(t, repo.Create(ctx, nil, netflix))
+	assert.NoError(t, repo.Create(ctx, nil, spotify))
+
+	for _, name := range []string{"Netflix", "  NETFLIX ", "Netflix   HD"} {
+		found, err := repo.ResolveName(ctx, name)
+		assert.NoError(t, err)
+		if assert.NotNil(t, found, name) {
+			assert.Equal(t, netflix.ID, found.ID)
+			assert.Equal(t, []string{"netflix hd"}, found.Aliases)
+		}
+	}
+
+	missing, err := repo.ResolveName(ctx, "Hulu")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	taken, err := repo.TakenAliases(ctx, nil, []string{"netflix hd", "spotify", "hulu"}, spotify.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"netflix hd"}, taken)
+
+	music := models.ServiceCategoryMusic
+	services, err := repo.List(ctx, &music)
+	assert.NoError(t, err)
+	if assert.Len(t, services, 1) {
+		assert.Equal(t, "Spotify", services[0].Name)
+		assert.Empty(t, services[0].Aliases)
+	}
+}
+
+func TestServiceRepository_UpdateReplacesAliasesAndDelete(t *testing.T) {
+	repo := setupServiceRepository(t)
+	ctx := context.Background()
+
+	service := &models.Service{Name: "YouTube Premium", Aliases: []string{"youtube"}, Category: models.ServiceCategoryVideo, Currency: "RUB"}
+	assert.NoError(t, repo.Create(ctx, nil, service))
+
+	service.Name = "YouTube"
+	service.Aliases = []string{"yt premium"}
+	assert.NoError(t, repo.Update(ctx, nil, service))
+
+	for name, resolves := range map[string]bool{"youtube": true, "yt premium": true, "youtube premium": false} {
+		found, err := repo.ResolveName(ctx, name)
+		assert.NoError(t, err)
+		assert.Equal(t, resolves, found != nil, name)
+	}
+
+	deleted, err := repo.Delete(ctx, nil, service.ID)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	found, err := repo.ResolveName(ctx, "youtube")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	deleted, err = repo.Delete(ctx, nil, service.ID)
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+}
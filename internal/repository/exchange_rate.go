@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRateRepository handles database operations for exchange rates
+type ExchangeRateRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewExchangeRateRepository creates a new exchange rate repository
+func NewExchangeRateRepository(db *gorm.DB, logger *logrus.Logger) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// List retrieves all exchange rates
+func (r *ExchangeRateRepository) List(ctx context.Context) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	err := r.db.WithContext(ctx).Order("from_currency, to_currency").Find(&rates).Error
+	return rates, err
+}
+
+// Get retrieves the rate for a currency pair, returning nil when none is stored
+func (r *ExchangeRateRepository) Get(ctx context.Context, fromCurrency, toCurrency string) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.WithContext(ctx).
+		Where("from_currency = ? AND to_currency = ?", fromCurrency, toCurrency).
+		First(&rate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// Upsert creates or replaces the rate for a currency pair
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, rate *models.ExchangeRate) error {
+	r.logger.WithFields(logrus.Fields{
+		"from_currency": rate.FromCurrency,
+		"to_currency":   rate.ToCurrency,
+		"rate":          rate.Rate,
+	}).Info("Storing exchange rate")
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "from_currency"}, {Name: "to_currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error
+}
+
+// Delete removes the rate for a currency pair and reports whether it existed
+func (r *ExchangeRateRepository) Delete(ctx context.Context, fromCurrency, toCurrency string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("from_currency = ? AND to_currency = ?", fromCurrency, toCurrency).
+		Delete(&models.ExchangeRate{})
+	return result.RowsAffected > 0, result.Error
+}
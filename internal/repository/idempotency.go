@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"subscription_tracker_api/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository handles database operations for stored idempotency keys
+type IdempotencyRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository
+func NewIdempotencyRepository(db *gorm.DB, logger *logrus.Logger) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Get retrieves the stored key of a caller. Keys that expired before now are reported as
+// missing with gorm.ErrRecordNotFound.
+func (r *IdempotencyRepository) Get(ctx context.Context, tx *gorm.DB, scope, key string, now time.Time) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	err := r.getDB(ctx, tx).
+		Where("scope = ? AND key = ? AND expires_at > ?", scope, key, now).
+		First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Save stores a key and reports whether it was stored. It reports false when the caller
+// already holds an unexpired record under the same key, e.g. written by a concurrent request.
+func (r *IdempotencyRepository) Save(ctx context.Context, tx *gorm.DB, record *models.IdempotencyKey) (bool, error) {
+	db := r.getDB(ctx, tx)
+
+	// An expired record that was not purged yet must not block the key
+	err := db.Where("scope = ? AND key = ? AND expires_at <= ?", record.Scope, record.Key, record.CreatedAt).
+		Delete(&models.IdempotencyKey{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpired removes the keys that expired before now and returns how many were removed
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		r.logger.WithField("deleted_count", result.RowsAffected).Info("Expired idempotency keys deleted successfully")
+	}
+	return result.RowsAffected, nil
+}
+
+// getDB returns the transaction if one is given, otherwise the repository's connection
+func (r *IdempotencyRepository) getDB(ctx context.Context, tx *gorm.DB) *gorm.DB {
+	if tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx)
+}
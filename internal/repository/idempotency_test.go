@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupIdempotencyRepository(t *testing.T) *IdempotencyRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.IdempotencyKey{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewIdempotencyRepository(db, logger)
+}
+
+func TestIdempotencyRepository_SaveAndExpire(t *testing.T) {
+	repo := setupIdempotencyRepository(t)
+	ctx := context.Background()
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	record := func(hash string, createdAt time.Time) *models.IdempotencyKey {
+		return &models.IdempotencyKey{
+			Scope:        "user-1",
+			Key:          "key-1",
+			RequestHash:  hash,
+			ResponseBody: models.RawJSON(`{"id":1}`),
+			CreatedAt:    createdAt,
+			ExpiresAt:    createdAt.Add(time.Hour),
+		}
+	}
+
+	saved, err := repo.Save(ctx, nil, record("first", now))
+	assert.NoError(t, err)
+	assert.True(t, saved)
+
+	// The key is taken while it has not expired
+	saved, err = repo.Save(ctx, nil, record("second", now.Add(30*time.Minute)))
+	assert.NoError(t, err)
+	assert.False(t, saved)
+
+	stored, err := repo.Get(ctx, nil, "user-1", "key-1", now.Add(30*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, "first", stored.RequestHash)
+	assert.JSONEq(t, `{"id":1}`, string(stored.ResponseBody))
+
+	// Keys are scoped to their caller
+	_, err = repo.Get(ctx, nil, "user-2", "key-1", now)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Once expired the key is gone and may be used again
+	_, err = repo.Get(ctx, nil, "user-1", "key-1", now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	saved, err = repo.Save(ctx, nil, record("third", now.Add(2*time.Hour)))
+	assert.NoError(t, err)
+	assert.True(t, saved)
+
+	deleted, err := repo.DeleteExpired(ctx, now.Add(4*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"subscription_tracker_api/internal/models"
@@ -8,13 +11,109 @@ import (
 
 // SubscriptionRepositoryInterface defines the contract for subscription data operations
 type SubscriptionRepositoryInterface interface {
-	Create(tx *gorm.DB, subscription *models.Subscription) error
-	ExistsByID(tx *gorm.DB, id uint) (bool, error)
-	GetByID(tx *gorm.DB, id uint) (*models.Subscription, error)
-	Update(tx *gorm.DB, subscription *models.Subscription) error
-	Delete(tx *gorm.DB, id uint) error
-	List(userID *uuid.UUID, serviceName *string, limit, offset int) ([]models.Subscription, error)
-	GetSubscriptionsInDateRange(userID *uuid.UUID, serviceName *string, startDate, endDate string) ([]models.Subscription, error)
-	CalculateTotalCostInDB(userID *uuid.UUID, serviceName *string, startDate, endDate string, totalMonths int) (int, error)
-	ExistsByUserServiceAndDate(tx *gorm.DB, userID uuid.UUID, serviceName, startDate string) (bool, error)
+	Create(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) error
+	ExistsByID(ctx context.Context, tx *gorm.DB, id uint) (bool, error)
+	GetByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Subscription, error)
+	Update(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) (bool, error)
+	Delete(ctx context.Context, tx *gorm.DB, id uint, version int) (bool, error)
+	List(ctx context.Context, filter *models.SubscriptionFilter, cursor *models.Cursor, limit, offset int) ([]models.Subscription, error)
+	Count(ctx context.Context, filter *models.SubscriptionFilter) (int64, error)
+	Stream(ctx context.Context, filter *models.SubscriptionFilter, fn func(subscription *models.Subscription) error) error
+	GetSubscriptionsInDateRange(ctx context.Context, filter *models.SubscriptionFilter, startDate, endDate models.YearMonth) ([]models.SubscriptionCost, error)
+	CalculateTotalCostInDB(ctx context.Context, tx *gorm.DB, filter *models.SubscriptionFilter, startDate, endDate models.YearMonth) ([]models.CurrencyAmount, error)
+	CalculateSpendSeriesInDB(ctx context.Context, filter *models.SubscriptionFilter, startDate, endDate models.YearMonth, groupBy models.SpendGroupBy) ([]models.SpendPoint, error)
+	ListChargedWithin(ctx context.Context, filter *models.SubscriptionFilter, today, horizon time.Time, limit int) ([]models.Subscription, error)
+	ListDueForReminders(ctx context.Context, today, horizon, trialHorizon time.Time, afterID uint, limit int) ([]models.Subscription, error)
+	ExistsByUserServiceAndDate(ctx context.Context, tx *gorm.DB, userID uuid.UUID, serviceName string, startDate models.YearMonth) (bool, error)
+	GetByIDUnscoped(ctx context.Context, tx *gorm.DB, id uint) (*models.Subscription, error)
+	ListDeleted(ctx context.Context, filter *models.SubscriptionFilter, limit, offset int) ([]models.Subscription, error)
+	CountDeleted(ctx context.Context, filter *models.SubscriptionFilter) (int64, error)
+	Restore(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) (bool, error)
+	Purge(ctx context.Context, tx *gorm.DB, id uint, version int) (bool, error)
+	ListDeletedBefore(ctx context.Context, tx *gorm.DB, before time.Time) ([]models.Subscription, error)
+}
+
+// IdempotencyRepositoryInterface defines the contract for stored idempotency keys
+type IdempotencyRepositoryInterface interface {
+	Get(ctx context.Context, tx *gorm.DB, scope, key string, now time.Time) (*models.IdempotencyKey, error)
+	Save(ctx context.Context, tx *gorm.DB, record *models.IdempotencyKey) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// AuditLogInterface records subscription audit events inside the caller's transaction
+type AuditLogInterface interface {
+	Record(ctx context.Context, tx *gorm.DB, event *models.AuditEvent) error
+}
+
+// AuditRepositoryInterface defines the contract for the subscription audit log
+type AuditRepositoryInterface interface {
+	AuditLogInterface
+	List(ctx context.Context, filter *models.AuditFilter, limit, offset int) ([]models.AuditEvent, error)
+}
+
+// PriceHistoryInterface defines the contract for the price history of subscriptions
+type PriceHistoryInterface interface {
+	SavePrice(ctx context.Context, tx *gorm.DB, price *models.SubscriptionPrice) error
+	ListPrices(ctx context.Context, subscriptionID uint) ([]models.SubscriptionPrice, error)
+}
+
+// ServiceCatalogInterface resolves subscription service names through the service catalog
+type ServiceCatalogInterface interface {
+	ResolveName(ctx context.Context, name string) (*models.Service, error)
+}
+
+// ServiceRepositoryInterface defines the contract for the service catalog
+type ServiceRepositoryInterface interface {
+	ServiceCatalogInterface
+	Create(ctx context.Context, tx *gorm.DB, service *models.Service) error
+	GetByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Service, error)
+	List(ctx context.Context, category *models.ServiceCategory) ([]models.Service, error)
+	Update(ctx context.Context, tx *gorm.DB, service *models.Service) error
+	Delete(ctx context.Context, tx *gorm.DB, id uint) (bool, error)
+	TakenAliases(ctx context.Context, tx *gorm.DB, aliases []string, excludeID uint) ([]string, error)
+}
+
+// BudgetRepositoryInterface defines the contract for spending budgets
+type BudgetRepositoryInterface interface {
+	Create(ctx context.Context, tx *gorm.DB, budget *models.Budget) error
+	GetByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Budget, error)
+	List(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error)
+	ListForSubscription(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) ([]models.Budget, error)
+	Update(ctx context.Context, tx *gorm.DB, budget *models.Budget) error
+	Delete(ctx context.Context, tx *gorm.DB, id uint) (bool, error)
+	Exists(ctx context.Context, tx *gorm.DB, budget *models.Budget) (bool, error)
+}
+
+// ExchangeRateRepositoryInterface defines the contract for exchange rate data operations
+type ExchangeRateRepositoryInterface interface {
+	List(ctx context.Context) ([]models.ExchangeRate, error)
+	Get(ctx context.Context, fromCurrency, toCurrency string) (*models.ExchangeRate, error)
+	Upsert(ctx context.Context, rate *models.ExchangeRate) error
+	Delete(ctx context.Context, fromCurrency, toCurrency string) (bool, error)
+}
+
+// ReminderRepositoryInterface defines the contract for reminder delivery state
+type ReminderRepositoryInterface interface {
+	Claim(ctx context.Context, delivery *models.ReminderDelivery, maxAttempts int) (bool, error)
+	MarkSent(ctx context.Context, id uint) error
+	MarkFailed(ctx context.Context, id uint, reason string) error
+}
+
+// WebhookRepositoryInterface defines the contract for webhook endpoints, the event outbox and deliveries
+type WebhookRepositoryInterface interface {
+	CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	GetEndpoint(ctx context.Context, id uint) (*models.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	DeleteEndpoint(ctx context.Context, id uint) (bool, error)
+	DispatchEvents(ctx context.Context, limit int) (int, error)
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error)
+	ClaimDelivery(ctx context.Context, delivery *models.WebhookDelivery, leaseUntil time.Time) (bool, error)
+	SaveDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, endpointID uint, status *models.WebhookDeliveryStatus, limit, offset int) ([]models.WebhookDelivery, error)
+}
+
+// EventOutboxInterface records subscription lifecycle events inside the caller's transaction
+type EventOutboxInterface interface {
+	RecordEvent(ctx context.Context, tx *gorm.DB, event *models.WebhookEvent) error
 }
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PriceRepository handles database operations for the price history of subscriptions
+type PriceRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewPriceRepository creates a new price history repository
+func NewPriceRepository(db *gorm.DB, logger *logrus.Logger) *PriceRepository {
+	return &PriceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SavePrice stores the price a subscription costs from a month on, replacing a price stored
+// earlier for the same month. Pass the transaction that changes the subscription.
+func (r *PriceRepository) SavePrice(ctx context.Context, tx *gorm.DB, price *models.SubscriptionPrice) error {
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	return db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "effective_from"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price"}),
+	}).Create(price).Error
+}
+
+// ListPrices retrieves the price history of a subscription, oldest first
+func (r *PriceRepository) ListPrices(ctx context.Context, subscriptionID uint) ([]models.SubscriptionPrice, error) {
+	var prices []models.SubscriptionPrice
+	err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("effective_from").
+		Find(&prices).Error
+	return prices, err
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPriceRepository(t *testing.T) *PriceRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.SubscriptionPrice{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewPriceRepository(db, logger)
+}
+
+func TestPriceRepository_SaveReplacesPriceOfSameMonth(t *testing.T) {
+	repo := setupPriceRepository(t)
+	ctx := context.Background()
+
+	january, april := models.NewYearMonth(2025, 1), models.NewYearMonth(2025, 4)
+	assert.NoError(t, repo.SavePrice(ctx, nil, &models.SubscriptionPrice{SubscriptionID: 1, EffectiveFrom: april, Price: 1199}))
+	assert.NoError(t, repo.SavePrice(ctx, nil, &models.SubscriptionPrice{SubscriptionID: 1, EffectiveFrom: january, Price: 999}))
+	assert.NoError(t, repo.SavePrice(ctx, nil, &models.SubscriptionPrice{SubscriptionID: 2, EffectiveFrom: january, Price: 500}))
+	assert.NoError(t, repo.SavePrice(ctx, nil, &models.SubscriptionPrice{SubscriptionID: 1, EffectiveFrom: april, Price: 1299}))
+
+	prices, err := repo.ListPrices(ctx, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, prices, 2) {
+		assert.Equal(t, january, prices[0].EffectiveFrom)
+		assert.Equal(t, 999, prices[0].Price)
+		assert.Equal(t, april, prices[1].EffectiveFrom)
+		assert.Equal(t, 1299, prices[1].Price)
+	}
+}
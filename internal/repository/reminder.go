@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"subscription_tracker_api/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReminderRepository handles database operations for reminder delivery state
+type ReminderRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewReminderRepository creates a new reminder repository
+func NewReminderRepository(db *gorm.DB, logger *logrus.Logger) *ReminderRepository {
+	return &ReminderRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Claim reserves a reminder for sending and reports whether the caller now owns it. A reminder
+// is claimed when it has never been attempted, or when earlier attempts failed and fewer than
+// maxAttempts were made. Reminders left in the sending state are never claimed again.
+func (r *ReminderRepository) Claim(ctx context.Context, delivery *models.ReminderDelivery, maxAttempts int) (bool, error) {
+	db := r.db.WithContext(ctx)
+
+	delivery.Status = models.ReminderStatusSending
+	delivery.Attempts = 1
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// Already recorded: take over a failed delivery that still has attempts left
+	key := db.Model(&models.ReminderDelivery{}).
+		Where("subscription_id = ? AND kind = ? AND due_date = ?", delivery.SubscriptionID, delivery.Kind, delivery.DueDate)
+	result = key.Session(&gorm.Session{}).
+		Where("status = ? AND attempts < ?", models.ReminderStatusFailed, maxAttempts).
+		Updates(map[string]interface{}{
+			"status":     models.ReminderStatusSending,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"notifier":   delivery.Notifier,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	if err := key.Session(&gorm.Session{}).First(delivery).Error; err != nil {
+		return false, err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"subscription_id": delivery.SubscriptionID,
+		"kind":            delivery.Kind,
+		"attempts":        delivery.Attempts,
+	}).Info("Retrying failed reminder delivery")
+
+	return true, nil
+}
+
+// MarkSent records that a claimed reminder was delivered
+func (r *ReminderRepository) MarkSent(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.ReminderDelivery{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     models.ReminderStatusSent,
+			"sent_at":    time.Now(),
+			"last_error": nil,
+		}).Error
+}
+
+// MarkFailed records that delivering a claimed reminder failed
+func (r *ReminderRepository) MarkFailed(ctx context.Context, id uint, reason string) error {
+	return r.db.WithContext(ctx).Model(&models.ReminderDelivery{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     models.ReminderStatusFailed,
+			"last_error": reason,
+		}).Error
+}
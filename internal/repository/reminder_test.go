@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupReminderRepository(t *testing.T) *ReminderRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.ReminderDelivery{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewReminderRepository(db, logger)
+}
+
+func newDelivery() *models.ReminderDelivery {
+	return &models.ReminderDelivery{
+		SubscriptionID: 1,
+		Kind:           models.ReminderKindRenewal,
+		DueDate:        time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC),
+		Notifier:       "log",
+	}
+}
+
+func TestReminderRepository_ClaimOnlyOnce(t *testing.T) {
+	repo := setupReminderRepository(t)
+	ctx := context.Background()
+
+	first := newDelivery()
+	claimed, err := repo.Claim(ctx, first, 3)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// A second scheduler run (or a restart) must not claim the same reminder again
+	claimed, err = repo.Claim(ctx, newDelivery(), 3)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	assert.NoError(t, repo.MarkSent(ctx, first.ID))
+	claimed, err = repo.Claim(ctx, newDelivery(), 3)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func TestReminderRepository_RetriesFailedUpToMaxAttempts(t *testing.T) {
+	repo := setupReminderRepository(t)
+	ctx := context.Background()
+
+	delivery := newDelivery()
+	claimed, _ := repo.Claim(ctx, delivery, 2)
+	assert.True(t, claimed)
+	assert.NoError(t, repo.MarkFailed(ctx, delivery.ID, "connection refused"))
+
+	retry := newDelivery()
+	claimed, err := repo.Claim(ctx, retry, 2)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, delivery.ID, retry.ID)
+	assert.Equal(t, 2, retry.Attempts)
+	assert.NoError(t, repo.MarkFailed(ctx, retry.ID, "connection refused"))
+
+	claimed, err = repo.Claim(ctx, newDelivery(), 2)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
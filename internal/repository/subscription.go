@@ -1,12 +1,18 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"subscription_tracker_api/internal/models"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SubscriptionRepository handles database operations for subscriptions
@@ -24,16 +30,16 @@ func NewSubscriptionRepository(db *gorm.DB, logger *logrus.Logger) *Subscription
 }
 
 // Create creates a new subscription
-func (r *SubscriptionRepository) Create(tx *gorm.DB, subscription *models.Subscription) error {
-	db := r.getDB(tx)
+func (r *SubscriptionRepository) Create(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) error {
+	db := r.getDB(ctx, tx)
 	return db.Create(subscription).Error
 }
 
 // GetByID retrieves a subscription by ID
-func (r *SubscriptionRepository) GetByID(tx *gorm.DB, id uint) (*models.Subscription, error) {
+func (r *SubscriptionRepository) GetByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Subscription, error) {
 	r.logger.WithField("subscription_id", id).Info("Retrieving subscription by ID")
 
-	db := r.getDB(tx)
+	db := r.getDB(ctx, tx)
 	var subscription models.Subscription
 	err := db.First(&subscription, id).Error
 	if err != nil {
@@ -52,79 +58,370 @@ func (r *SubscriptionRepository) GetByID(tx *gorm.DB, id uint) (*models.Subscrip
 	return &subscription, nil
 }
 
-// Update updates a subscription
-func (r *SubscriptionRepository) Update(tx *gorm.DB, subscription *models.Subscription) error {
-	db := r.getDB(tx)
-	return db.Save(subscription).Error
+// Update saves a subscription and increments its version, provided the stored row is still at
+// the version the subscription was read at. It reports false, leaving the subscription
+// unchanged, when another writer updated or deleted the row in the meantime.
+func (r *SubscriptionRepository) Update(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) (bool, error) {
+	db := r.getDB(ctx, tx)
+
+	readVersion := subscription.Version
+	subscription.Version++
+	result := db.Model(subscription).
+		Select("*").
+		Omit("id", "created_at").
+		Where("version = ?", readVersion).
+		Updates(subscription)
+	if result.Error != nil || result.RowsAffected == 0 {
+		subscription.Version = readVersion
+		return false, result.Error
+	}
+	return true, nil
 }
 
-// Delete deletes a subscription
-func (r *SubscriptionRepository) Delete(tx *gorm.DB, id uint) error {
-	db := r.getDB(tx)
-	return db.Delete(&models.Subscription{}, id).Error
+// Delete deletes a subscription if it is still at the given version. It reports false when
+// the subscription was changed or deleted in the meantime.
+func (r *SubscriptionRepository) Delete(ctx context.Context, tx *gorm.DB, id uint, version int) (bool, error) {
+	db := r.getDB(ctx, tx)
+	result := db.Where("version = ?", version).Delete(&models.Subscription{}, id)
+	return result.RowsAffected > 0, result.Error
 }
 
-// List retrieves all subscriptions with optional filtering
-func (r *SubscriptionRepository) List(userID *uuid.UUID, serviceName *string, limit, offset int) ([]models.Subscription, error) {
-	r.logger.WithFields(logrus.Fields{
-		"user_id":      userID,
-		"service_name": serviceName,
-		"limit":        limit,
-		"offset":       offset,
-	}).Info("Retrieving subscriptions list with filters")
+// GetByIDUnscoped retrieves a subscription by ID, including a soft-deleted one
+func (r *SubscriptionRepository) GetByIDUnscoped(ctx context.Context, tx *gorm.DB, id uint) (*models.Subscription, error) {
+	var subscription models.Subscription
+	if err := r.getDB(ctx, tx).Unscoped().First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ListDeleted retrieves soft-deleted subscriptions matching the filter, ordered by the filter's
+// sort fields or most recently deleted first
+func (r *SubscriptionRepository) ListDeleted(ctx context.Context, filter *models.SubscriptionFilter, limit, offset int) ([]models.Subscription, error) {
+	query := applyFilter(r.getDB(ctx, nil).Unscoped().Model(&models.Subscription{}).Where("deleted_at IS NOT NULL"), filter)
+	query = applySort(query, filter, "deleted_at DESC, id DESC")
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
 
 	var subscriptions []models.Subscription
-	query := r.db.Model(&models.Subscription{})
+	err := query.Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// CountDeleted counts the soft-deleted subscriptions matching the filter
+func (r *SubscriptionRepository) CountDeleted(ctx context.Context, filter *models.SubscriptionFilter) (int64, error) {
+	var count int64
+	err := applyFilter(r.getDB(ctx, nil).Unscoped().Model(&models.Subscription{}).Where("deleted_at IS NOT NULL"), filter).Count(&count).Error
+	return count, err
+}
 
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
+// Restore undoes the soft delete of a subscription and increments its version, provided it is
+// still deleted and at the version it was read at. It reports false otherwise.
+func (r *SubscriptionRepository) Restore(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) (bool, error) {
+	result := r.getDB(ctx, tx).Unscoped().Model(&models.Subscription{}).
+		Where("id = ? AND version = ? AND deleted_at IS NOT NULL", subscription.ID, subscription.Version).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    subscription.Version + 1,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
 	}
+	subscription.DeletedAt = gorm.DeletedAt{}
+	subscription.Version++
+	return true, nil
+}
+
+// Purge permanently deletes a subscription, soft-deleted or not, if it is still at the given
+// version. It reports false when the subscription was changed or purged in the meantime.
+func (r *SubscriptionRepository) Purge(ctx context.Context, tx *gorm.DB, id uint, version int) (bool, error) {
+	result := r.getDB(ctx, tx).Unscoped().Where("version = ?", version).Delete(&models.Subscription{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListDeletedBefore retrieves the subscriptions soft-deleted before the given time, oldest
+// deletion first
+func (r *SubscriptionRepository) ListDeletedBefore(ctx context.Context, tx *gorm.DB, before time.Time) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	err := r.getDB(ctx, tx).Unscoped().
+		Where("deleted_at < ?", before).
+		Order("deleted_at, id").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// List retrieves subscriptions matching the filter, ordered by the filter's sort fields or by
+// (created_at, id). When cursor is set, only the rows after it (or before it, for a backward
+// cursor) in (created_at, id) order are returned and offset is ignored; cursors cannot be
+// combined with a custom sort. Backward pages are still returned in ascending order.
+func (r *SubscriptionRepository) List(ctx context.Context, filter *models.SubscriptionFilter, cursor *models.Cursor, limit, offset int) ([]models.Subscription, error) {
+	r.logger.WithFields(logrus.Fields{
+		"filter": filter,
+		"cursor": cursor != nil,
+		"limit":  limit,
+		"offset": offset,
+	}).Info("Retrieving subscriptions list with filters")
 
-	if serviceName != nil {
-		query = query.Where("service_name ILIKE ?", "%"+*serviceName+"%")
+	var subscriptions []models.Subscription
+	query := applyFilter(r.getDB(ctx, nil).Model(&models.Subscription{}), filter)
+
+	switch {
+	case cursor == nil:
+		query = applySort(query, filter, "created_at, id")
+		if offset > 0 {
+			query = query.Offset(offset)
+		}
+	case cursor.Backward:
+		// Walk backwards from the cursor and restore ascending order below
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", cursor.CreatedAt, cursor.CreatedAt, cursor.ID).
+			Order("created_at DESC, id DESC")
+	default:
+		query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", cursor.CreatedAt, cursor.CreatedAt, cursor.ID).
+			Order("created_at, id")
 	}
 
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
 
-	if offset > 0 {
-		query = query.Offset(offset)
-	}
-
 	err := query.Find(&subscriptions).Error
 	if err == nil {
+		if cursor != nil && cursor.Backward {
+			slices.Reverse(subscriptions)
+		}
 		r.logger.WithFields(logrus.Fields{
 			"subscription_count": len(subscriptions),
-			"user_id":            userID,
-			"service_name":       serviceName,
+			"filter":             filter,
 		}).Info("Subscriptions list retrieved from database successfully")
 	}
 
 	return subscriptions, err
 }
 
-// GetSubscriptionsInDateRange retrieves subscriptions that overlap with the given date range
-func (r *SubscriptionRepository) GetSubscriptionsInDateRange(userID *uuid.UUID, serviceName *string, startDate, endDate string) ([]models.Subscription, error) {
+// Count counts the subscriptions matching the filter
+func (r *SubscriptionRepository) Count(ctx context.Context, filter *models.SubscriptionFilter) (int64, error) {
+	var count int64
+	err := applyFilter(r.getDB(ctx, nil).Model(&models.Subscription{}), filter).Count(&count).Error
+	return count, err
+}
+
+// Stream calls fn for every subscription matching the filter, in the filter's sort order or by
+// (created_at, id). Rows are read one at a time instead of being loaded into memory; an error
+// returned by fn stops the iteration and is returned as is.
+func (r *SubscriptionRepository) Stream(ctx context.Context, filter *models.SubscriptionFilter, fn func(subscription *models.Subscription) error) error {
+	db := r.getDB(ctx, nil)
+	rows, err := applySort(applyFilter(db.Model(&models.Subscription{}), filter), filter, "created_at, id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var subscription models.Subscription
+		if err := db.ScanRows(rows, &subscription); err != nil {
+			return err
+		}
+		if err := fn(&subscription); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
 	r.logger.WithFields(logrus.Fields{
-		"user_id":      userID,
-		"service_name": serviceName,
-		"start_date":   startDate,
-		"end_date":     endDate,
-	}).Info("Retrieving subscriptions in date range")
+		"subscription_count": count,
+		"filter":             filter,
+	}).Info("Subscriptions streamed from database successfully")
+	return nil
+}
 
-	var subscriptions []models.Subscription
-	query := r.db.Model(&models.Subscription{})
+// applyFilter restricts a subscriptions query to the rows matching the filter. List, Count and
+// the cost queries all go through it so they agree on what a filter matches.
+func applyFilter(query *gorm.DB, filter *models.SubscriptionFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if len(filter.ServiceNames) > 0 {
+		query = query.Where("LOWER(service_name) IN ?", filter.ServiceNames)
+	}
+	if filter.PriceMin != nil {
+		query = query.Where("price >= ?", *filter.PriceMin)
+	}
+	if filter.PriceMax != nil {
+		query = query.Where("price <= ?", *filter.PriceMax)
+	}
+	if filter.ActiveOn != nil {
+		query = query.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", *filter.ActiveOn, *filter.ActiveOn)
+	}
+	if filter.Status != nil {
+		switch *filter.Status {
+		case models.SubscriptionStatusActive:
+			query = query.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", filter.StatusMonth, filter.StatusMonth)
+		case models.SubscriptionStatusEnded:
+			query = query.Where("end_date < ?", filter.StatusMonth)
+		case models.SubscriptionStatusUpcoming:
+			query = query.Where("start_date > ?", filter.StatusMonth)
+		}
+	}
+	if filter.StartedAfter != nil {
+		query = query.Where("start_date > ?", *filter.StartedAfter)
+	}
+	if filter.StartedBefore != nil {
+		query = query.Where("start_date < ?", *filter.StartedBefore)
+	}
+	if filter.TrialConvertsFrom != nil {
+		query = query.Where(trialConversionSQL+" >= CAST(? AS date)", *filter.TrialConvertsFrom)
+	}
+	if filter.TrialConvertsTo != nil {
+		// Trials convert after their last month at the earliest, which lets the trial_end
+		// index narrow the rows down first
+		query = query.Where("trial_end < ? AND "+trialConversionSQL+" <= CAST(? AS date)",
+			models.YearMonthOf(*filter.TrialConvertsTo), *filter.TrialConvertsTo)
+	}
+	if filter.Category != nil {
+		query = query.Where("COALESCE((SELECT category FROM services WHERE services.id = subscriptions.service_id), ?) = ?", models.ServiceCategoryOther, *filter.Category)
+	}
+	return query
+}
 
-	// Filter by user ID if provided
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
+// applySort orders the query by the filter's sort fields, falling back to defaultOrder. The
+// fields are checked against models.SortableFields by the service; id breaks ties.
+func applySort(query *gorm.DB, filter *models.SubscriptionFilter, defaultOrder string) *gorm.DB {
+	if filter == nil || len(filter.Sort) == 0 {
+		return query.Order(defaultOrder)
+	}
+	for _, field := range filter.Sort {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: field.Field}, Desc: field.Desc})
 	}
+	return query.Order("id")
+}
+
+// monthIndexSQL converts a DATE column into a month index (year*12 + month)
+const monthIndexSQL = "(EXTRACT(YEAR FROM %[1]s) * 12 + EXTRACT(MONTH FROM %[1]s))::int"
 
-	// Filter by service name if provided
-	if serviceName != nil {
-		query = query.Where("service_name ILIKE ?", "%"+*serviceName+"%")
+// trialConversionSQL is the day a subscription's trial converts to the full price, computed like
+// models.Subscription.TrialConversion. It is NULL without a trial or when the subscription ends
+// before its first charge after the trial.
+var trialConversionSQL = nextChargeSQL("(trial_end + INTERVAL '1 month')::date", fmt.Sprintf(monthIndexSQL, "trial_end")+" + 1")
+
+// nextChargeSQL is the first charge of a subscription on or after day, computed like
+// models.Subscription.NextChargeOn. dayMonth is the index of the first month starting on or
+// after day. It is NULL when the subscription ends before that charge.
+func nextChargeSQL(day, dayMonth string) string {
+	charge := firstChargeFromSQL(day, dayMonth)
+	return "(CASE WHEN end_date IS NULL OR " + charge + " < (end_date + INTERVAL '1 month')::date THEN " + charge + " END)"
+}
+
+// firstChargeFromSQL is the first charge on or after day, in the form of nextChargeSQL, rounding
+// the distance from start_date up to a whole number of billing cycles. Weekly cycles step over
+// days; the others step over months.
+func firstChargeFromSQL(day, dayMonth string) string {
+	return "(CASE WHEN billing_period = 'week' THEN " +
+		"start_date + (GREATEST(" + day + " - start_date, 0) + 7 * billing_interval_count - 1) / (7 * billing_interval_count) * (7 * billing_interval_count)" +
+		" ELSE " +
+		fmt.Sprintf("(start_date + ((GREATEST(%[1]s - %[2]s, 0) + %[3]s - 1) / %[3]s * %[3]s) * INTERVAL '1 month')::date",
+			dayMonth, fmt.Sprintf(monthIndexSQL, "start_date"),
+			"(billing_interval_count * CASE billing_period WHEN 'year' THEN 12 WHEN 'quarter' THEN 3 ELSE 1 END)") +
+		" END)"
+}
+
+// priceSegmentsSQL joins every subscription to the periods its prices applied to. A price applies
+// from the first day of its effective_from month up to segment_end, the day before the next
+// price; the first price has no segment_start so it also covers months before it, and the last
+// one has no segment_end. Subscriptions without a price history are joined to NULLs and charged
+// their own price throughout.
+const priceSegmentsSQL = "LEFT JOIN (SELECT subscription_id, price AS segment_price, " +
+	"CASE WHEN ROW_NUMBER() OVER w > 1 THEN effective_from END AS segment_start, " +
+	"(LEAD(effective_from) OVER w - INTERVAL '1 day')::date AS segment_end " +
+	"FROM subscription_prices WINDOW w AS (PARTITION BY subscription_id ORDER BY effective_from)) AS prices " +
+	"ON prices.subscription_id = subscriptions.id"
+
+// trialPhasesSQL splits every subscription with a trial into its trial phase, which ends with
+// the trial_end month, and the paid phase after it. Subscriptions without a trial only have the
+// paid phase, which is unbounded.
+const trialPhasesSQL = "JOIN LATERAL (VALUES " +
+	"(TRUE, NULL::date, (subscriptions.trial_end + INTERVAL '1 month' - INTERVAL '1 day')::date), " +
+	"(FALSE, (subscriptions.trial_end + INTERVAL '1 month')::date, NULL::date)" +
+	") AS phases(trial, phase_start, phase_end) ON NOT phases.trial OR subscriptions.trial_end IS NOT NULL"
+
+// segmentPriceSQL is the price charged in the joined price segment and trial phase: the trial
+// price during the trial, the segment's price afterwards
+const segmentPriceSQL = "(CASE WHEN phases.trial THEN subscriptions.trial_price ELSE COALESCE(prices.segment_price, subscriptions.price) END)"
+
+// chargesSQL counts the charge events of a subscription that fall inside its own
+// [start_date, end_date] period, the requested range, the joined price segment and the joined
+// trial phase. Named arguments are built by chargesArgs.
+var chargesSQL = chargesInSQL("CAST(@range_start AS date)", "CAST(@range_end AS date)", "@range_start_month", "@range_end_month")
+
+// chargesInSQL counts the charge events of a subscription that fall inside its own
+// [start_date, end_date] period, the range of days [rangeStart, rangeEnd] covering the month
+// indexes [rangeStartMonth, rangeEndMonth], the joined price segment and the joined trial phase.
+// A subscription is charged on start_date and then every billing_interval_count periods, so the
+// count is the number of steps k >= 0 for which start + k*step lies in [lo, hi]. Monthly,
+// quarterly and yearly cycles step over month indexes; weekly cycles step over days. GREATEST
+// and LEAST ignore NULLs such as the end_date of open-ended subscriptions, which run to the end
+// of the range.
+func chargesInSQL(rangeStart, rangeEnd, rangeStartMonth, rangeEndMonth string) string {
+	return "(CASE WHEN billing_period = 'week' THEN " +
+		chargeCountSQL(
+			"start_date",
+			"GREATEST(start_date, "+rangeStart+", prices.segment_start, phases.phase_start)",
+			"LEAST((end_date + INTERVAL '1 month' - INTERVAL '1 day')::date, "+rangeEnd+", prices.segment_end, phases.phase_end)",
+			"(7 * billing_interval_count)",
+		) + " ELSE " +
+		chargeCountSQL(
+			fmt.Sprintf(monthIndexSQL, "start_date"),
+			"GREATEST("+fmt.Sprintf(monthIndexSQL, "start_date")+", "+rangeStartMonth+", "+fmt.Sprintf(monthIndexSQL, "prices.segment_start")+", "+fmt.Sprintf(monthIndexSQL, "phases.phase_start")+")",
+			"LEAST("+fmt.Sprintf(monthIndexSQL, "end_date")+", "+rangeEndMonth+", "+fmt.Sprintf(monthIndexSQL, "prices.segment_end")+", "+fmt.Sprintf(monthIndexSQL, "phases.phase_end")+")",
+			"(billing_interval_count * CASE billing_period WHEN 'year' THEN 12 WHEN 'quarter' THEN 3 ELSE 1 END)",
+		) + " END)"
+}
+
+// chargeCountSQL counts the values first + k*step (k >= 0) within [lo, hi], assuming first <= lo.
+// Empty intervals, such as price segments outside the range, count zero.
+func chargeCountSQL(first, lo, hi, step string) string {
+	return fmt.Sprintf("(CASE WHEN %[2]s <= %[3]s THEN (%[3]s - %[1]s) / %[4]s - (%[2]s - %[1]s + %[4]s - 1) / %[4]s + 1 ELSE 0 END)", first, lo, hi, step)
+}
+
+// billedMonthsSQL counts the months in which a subscription's own [start_date, end_date] period
+// overlaps the requested range, whatever its billing cycle. LEAST ignores the NULL end_date of
+// open-ended subscriptions, so they run to the end of the range. It takes the named arguments
+// of chargesArgs.
+var billedMonthsSQL = "(LEAST(" + fmt.Sprintf(monthIndexSQL, "end_date") + ", @range_end_month) - GREATEST(" +
+	fmt.Sprintf(monthIndexSQL, "start_date") + ", @range_start_month) + 1)"
+
+// chargesArgs binds the requested range to the named arguments of chargesSQL
+func chargesArgs(startDate, endDate models.YearMonth) map[string]interface{} {
+	return map[string]interface{}{
+		"range_start":       startDate.Time(),
+		"range_end":         endDate.AddMonths(1).Time().AddDate(0, 0, -1),
+		"range_start_month": monthIndex(startDate),
+		"range_end_month":   monthIndex(endDate),
 	}
+}
+
+// GetSubscriptionsInDateRange retrieves subscriptions matching the filter that overlap with the
+// given date range together with the number of months each one overlaps it, and the number of
+// times and amount each one is charged within it
+func (r *SubscriptionRepository) GetSubscriptionsInDateRange(ctx context.Context, filter *models.SubscriptionFilter, startDate, endDate models.YearMonth) ([]models.SubscriptionCost, error) {
+	r.logger.WithFields(logrus.Fields{
+		"filter":     filter,
+		"start_date": startDate,
+		"end_date":   endDate,
+	}).Info("Retrieving subscriptions in date range")
+
+	var subscriptions []models.SubscriptionCost
+	query := applyFilter(r.getDB(ctx, nil).Model(&models.Subscription{}), filter)
 
 	// Filter by date range - subscriptions that overlap with the given period
 	query = query.Where(
@@ -132,66 +429,223 @@ func (r *SubscriptionRepository) GetSubscriptionsInDateRange(userID *uuid.UUID,
 		endDate, startDate,
 	)
 
+	// Compute each subscription's share of the period in the database, summed over the prices
+	// that applied during it and its trial
+	query = applySort(query.Joins(priceSegmentsSQL).Joins(trialPhasesSQL).Select(
+		"subscriptions.*, MAX("+billedMonthsSQL+") AS billed_months, SUM("+chargesSQL+") AS charges, SUM("+segmentPriceSQL+" * "+chargesSQL+") AS subtotal",
+		chargesArgs(startDate, endDate),
+	).Group("subscriptions.id"), filter, "start_date, id")
+
 	err := query.Find(&subscriptions).Error
 	if err == nil {
 		r.logger.WithFields(logrus.Fields{
 			"subscription_count": len(subscriptions),
-			"date_range":         startDate + " to " + endDate,
-			"user_id":            userID,
-			"service_name":       serviceName,
+			"date_range":         startDate.String() + " to " + endDate.String(),
+			"filter":             filter,
 		}).Info("Subscriptions in date range retrieved from database successfully")
 	}
 
 	return subscriptions, err
 }
 
-// CalculateTotalCostInDB performs cost calculation with database aggregation
-func (r *SubscriptionRepository) CalculateTotalCostInDB(userID *uuid.UUID, serviceName *string, startDate, endDate string, totalMonths int) (int, error) {
-	var result struct {
-		TotalCost int `gorm:"column:total_cost"`
+// CalculateTotalCostInDB performs cost calculation with database aggregation, charging each
+// subscription matching the filter for the charge events of its billing cycle within the
+// requested range at the price in effect in the month of each charge, or at the trial price
+// until the end of its trial. Totals are grouped by currency. Pass tx to include the uncommitted
+// changes of a transaction.
+func (r *SubscriptionRepository) CalculateTotalCostInDB(ctx context.Context, tx *gorm.DB, filter *models.SubscriptionFilter, startDate, endDate models.YearMonth) ([]models.CurrencyAmount, error) {
+	var totals []models.CurrencyAmount
+
+	query := applyFilter(r.getDB(ctx, tx).Model(&models.Subscription{}), filter).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", endDate, startDate)
+
+	// Database aggregation over each subscription's charges in the range, at the price in effect
+	// when each charge was made
+	err := query.Joins(priceSegmentsSQL).Joins(trialPhasesSQL).Select(
+		"currency, COALESCE(SUM("+segmentPriceSQL+" * "+chargesSQL+"), 0) AS amount",
+		chargesArgs(startDate, endDate),
+	).Group("currency").Order("currency").Scan(&totals).Error
+	if err != nil {
+		return nil, err
 	}
 
-	query := r.db.Model(&models.Subscription{}).
+	return totals, nil
+}
+
+// monthChargesSQL counts the charges of a subscription within the month of the joined months
+// series, in the form of chargesSQL
+var monthChargesSQL = chargesInSQL(
+	"months.month::date",
+	"(months.month + INTERVAL '1 month' - INTERVAL '1 day')::date",
+	fmt.Sprintf(monthIndexSQL, "months.month"),
+	fmt.Sprintf(monthIndexSQL, "months.month"),
+)
+
+// spendCategorySQL is the catalog category of a subscription in a spend series: other for
+// subscriptions to uncatalogued services and empty for months without subscriptions
+const spendCategorySQL = "CASE WHEN subscriptions.id IS NULL THEN '' ELSE COALESCE(services.category, 'other') END"
+
+// CalculateSpendSeriesInDB aggregates the charges of the subscriptions matching the filter into
+// one amount per month of the range and currency, further broken down by service name, user or
+// catalog category when grouped by them. Each charge is priced like in CalculateTotalCostInDB.
+// The months come from generate_series, so months without any active subscription still appear
+// once, with a zero amount and an empty currency.
+func (r *SubscriptionRepository) CalculateSpendSeriesInDB(ctx context.Context, filter *models.SubscriptionFilter, startDate, endDate models.YearMonth, groupBy models.SpendGroupBy) ([]models.SpendPoint, error) {
+	subscriptions := applyFilter(r.getDB(ctx, nil).Model(&models.Subscription{}), filter).
 		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", endDate, startDate)
 
-	// Apply filters
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
+	columns := []string{"months.month AS period"}
+	groups := []string{"months.month"}
+	var joins []string
+	switch groupBy {
+	case models.SpendGroupByService:
+		columns = append(columns, "COALESCE(subscriptions.service_name, '') AS service_name")
+		groups = append(groups, "subscriptions.service_name")
+	case models.SpendGroupByUser:
+		columns = append(columns, "subscriptions.user_id")
+		groups = append(groups, "subscriptions.user_id")
+	case models.SpendGroupByCategory:
+		joins = append(joins, "LEFT JOIN services ON services.id = subscriptions.service_id")
+		columns = append(columns, spendCategorySQL+" AS category")
+		groups = append(groups, spendCategorySQL)
 	}
-	if serviceName != nil {
-		query = query.Where("service_name = ?", *serviceName)
+	columns = append(columns,
+		"COALESCE(subscriptions.currency, '') AS currency",
+		"COALESCE(SUM("+segmentPriceSQL+" * "+monthChargesSQL+"), 0) AS amount",
+	)
+	groups = append(groups, "subscriptions.currency")
+
+	// Left join every month of the range to the subscriptions active in it, so that the filters
+	// apply inside the join and do not drop the empty months
+	query := r.getDB(ctx, nil).
+		Table("generate_series(CAST(? AS timestamp), CAST(? AS timestamp), INTERVAL '1 month') AS months(month)", startDate.Time(), endDate.Time()).
+		Joins("LEFT JOIN (?) AS subscriptions ON subscriptions.start_date <= months.month AND (subscriptions.end_date IS NULL OR subscriptions.end_date >= months.month)", subscriptions).
+		Joins(priceSegmentsSQL).Joins(trialPhasesSQL)
+	for _, join := range joins {
+		query = query.Joins(join)
 	}
 
-	// Database aggregation with month consideration
-	err := query.Select("COALESCE(SUM(price * ?), 0) as total_cost", totalMonths).Scan(&result).Error
+	var points []models.SpendPoint
+	err := query.
+		Select(strings.Join(columns, ", ")).
+		Group(strings.Join(groups, ", ")).
+		Order(strings.Join(groups, ", ")).
+		Scan(&points).Error
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// upcomingChargeSQL is the next charge of a subscription on or after the day of the @today named
+// argument, in the form of nextChargeSQL. Named arguments are built by upcomingArgs.
+var upcomingChargeSQL = nextChargeSQL("CAST(@today AS date)", "@today_month")
+
+// upcomingArgs binds the days [today, horizon] to the named arguments of upcomingChargeSQL
+func upcomingArgs(today, horizon time.Time) map[string]interface{} {
+	// Monthly cycles charge on the first day of a month, so the next one starts a month later
+	// unless today is a first
+	todayMonth := models.YearMonthOf(today)
+	if today.Day() > 1 {
+		todayMonth = todayMonth.AddMonths(1)
+	}
+	return map[string]interface{}{
+		"today":       today.Format(time.DateOnly),
+		"today_month": monthIndex(todayMonth),
+		"horizon":     horizon.Format(time.DateOnly),
+	}
+}
+
+// ListChargedWithin retrieves up to limit subscriptions matching the filter whose next charge on
+// or after today falls on or before horizon, ordered by that charge and id. A limit of 0 returns
+// them all. Only the subscriptions active in the months of [today, horizon] are considered.
+func (r *SubscriptionRepository) ListChargedWithin(ctx context.Context, filter *models.SubscriptionFilter, today, horizon time.Time, limit int) ([]models.Subscription, error) {
+	db := r.getDB(ctx, nil)
+	candidates := applyFilter(db.Model(&models.Subscription{}), filter).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", models.YearMonthOf(horizon), models.YearMonthOf(today)).
+		Select("subscriptions.*, "+upcomingChargeSQL+" AS next_charge", upcomingArgs(today, horizon))
+
+	query := db.Table("(?) AS subscriptions", candidates).
+		Where("next_charge <= CAST(? AS date)", horizon.Format(time.DateOnly)).
+		Order("next_charge, id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var subscriptions []models.Subscription
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"subscription_count": len(subscriptions),
+		"today":              today.Format(time.DateOnly),
+		"horizon":            horizon.Format(time.DateOnly),
+		"filter":             filter,
+	}).Info("Subscriptions charged within the window retrieved from database successfully")
+	return subscriptions, nil
+}
+
+// ListDueForReminders retrieves, in id order, up to limit subscriptions after afterID whose next
+// charge on or after today or whose end falls on or before horizon, or whose trial converts
+// between today and trialHorizon. ReminderService pages through them to pick the reminders
+// that are due.
+func (r *SubscriptionRepository) ListDueForReminders(ctx context.Context, today, horizon, trialHorizon time.Time, afterID uint, limit int) ([]models.Subscription, error) {
+	last := horizon
+	if trialHorizon.After(last) {
+		last = trialHorizon
 	}
+	args := upcomingArgs(today, horizon)
+	args["trial_horizon"] = trialHorizon.Format(time.DateOnly)
+
+	// A subscription ends on the first day of the month after its end_date, so the ones that
+	// ended before today's month started are left out along with the ones starting too late
+	query := r.getDB(ctx, nil).Model(&models.Subscription{}).
+		Where("id > ?", afterID).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", models.YearMonthOf(last), models.YearMonthOf(today).AddMonths(-1)).
+		Where("("+upcomingChargeSQL+" <= CAST(@horizon AS date)"+
+			" OR "+trialConversionSQL+" BETWEEN CAST(@today AS date) AND CAST(@trial_horizon AS date)"+
+			" OR (end_date + INTERVAL '1 month')::date BETWEEN CAST(@today AS date) AND CAST(@horizon AS date))", args).
+		Order("id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var subscriptions []models.Subscription
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
 
-	return result.TotalCost, nil
+// monthIndex converts a month into the index used by monthIndexSQL
+func monthIndex(ym models.YearMonth) int {
+	return ym.Year*12 + int(ym.Month)
 }
 
-// Helper to get the correct DB instance (transaction or regular)
-func (r *SubscriptionRepository) getDB(tx *gorm.DB) *gorm.DB {
+// Helper to get the correct DB instance (transaction or regular) bound to ctx
+func (r *SubscriptionRepository) getDB(ctx context.Context, tx *gorm.DB) *gorm.DB {
 	if tx != nil {
-		return tx
+		return tx.WithContext(ctx)
 	}
-	return r.db
+	return r.db.WithContext(ctx)
 }
 
-// ExistsByUserServiceAndDate checks for duplicate subscriptions
-func (r *SubscriptionRepository) ExistsByUserServiceAndDate(tx *gorm.DB, userID uuid.UUID, serviceName, startDate string) (bool, error) {
-	db := r.getDB(tx)
+// ExistsByUserServiceAndDate checks for duplicate subscriptions. Service names are compared
+// case-insensitively.
+func (r *SubscriptionRepository) ExistsByUserServiceAndDate(ctx context.Context, tx *gorm.DB, userID uuid.UUID, serviceName string, startDate models.YearMonth) (bool, error) {
+	db := r.getDB(ctx, tx)
 	var count int64
 	err := db.Model(&models.Subscription{}).
-		Where("user_id = ? AND service_name = ? AND start_date = ?", userID, serviceName, startDate).
+		Where("user_id = ? AND LOWER(service_name) = LOWER(?) AND start_date = ?", userID, serviceName, startDate).
 		Count(&count).Error
 	return count > 0, err
 }
 
 // ExistsByID checks if subscription exists
-func (r *SubscriptionRepository) ExistsByID(tx *gorm.DB, id uint) (bool, error) {
-	db := r.getDB(tx)
+func (r *SubscriptionRepository) ExistsByID(ctx context.Context, tx *gorm.DB, id uint) (bool, error) {
+	db := r.getDB(ctx, tx)
 	var count int64
 	err := db.Model(&models.Subscription{}).Where("id = ?", id).Count(&count).Error
 	return count > 0, err
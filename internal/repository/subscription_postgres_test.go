@@ -0,0 +1,495 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/models"
+
+	"github.com/golang-migrate/migrate/v4"
+	migrate_pg "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// The cost queries use Postgres-only SQL (window functions, LATERAL joins, generate_series and
+// date arithmetic), so they are tested against a real database. Set TEST_DATABASE_URL to a
+// postgres:// URL to run them; every test migrates a schema of its own and drops it afterwards.
+
+// setupPostgres migrates a fresh schema of the TEST_DATABASE_URL database and returns a
+// connection that uses it, skipping the test when no database is configured
+func setupPostgres(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	config := &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)}
+	admin, err := gorm.Open(postgres.Open(databaseURL), config)
+	require.NoError(t, err, "failed to connect to test database")
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	require.NoError(t, admin.Exec("CREATE SCHEMA "+schema).Error)
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	schemaURL, err := url.Parse(databaseURL)
+	require.NoError(t, err, "TEST_DATABASE_URL must be a URL")
+	query := schemaURL.Query()
+	query.Set("search_path", schema)
+	schemaURL.RawQuery = query.Encode()
+
+	db, err := gorm.Open(postgres.Open(schemaURL.String()), config)
+	require.NoError(t, err, "failed to connect to test schema")
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	driver, err := migrate_pg.WithInstance(sqlDB, &migrate_pg.Config{})
+	require.NoError(t, err)
+	m, err := migrate.NewWithDatabaseInstance("file://../../db/migrations", "postgres", driver)
+	require.NoError(t, err)
+	require.NoError(t, m.Up(), "failed to migrate test schema")
+
+	return db
+}
+
+// pgFixture creates subscriptions and their price history in a migrated Postgres schema
+type pgFixture struct {
+	t      *testing.T
+	repo   *SubscriptionRepository
+	prices *PriceRepository
+}
+
+func setupPostgresFixture(t *testing.T) *pgFixture {
+	db := setupPostgres(t)
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return &pgFixture{
+		t:      t,
+		repo:   NewSubscriptionRepository(db, logger),
+		prices: NewPriceRepository(db, logger),
+	}
+}
+
+// subscribe creates a subscription of userID, defaulting to a monthly one in roubles
+func (f *pgFixture) subscribe(userID uuid.UUID, subscription models.Subscription, history ...models.SubscriptionPrice) *models.Subscription {
+	f.t.Helper()
+	subscription.UserID = userID
+	if subscription.ServiceName == "" {
+		subscription.ServiceName = "Netflix"
+	}
+	if subscription.Currency == "" {
+		subscription.Currency = "RUB"
+	}
+	if subscription.BillingPeriod == "" {
+		subscription.BillingPeriod = models.BillingPeriodMonth
+	}
+	if subscription.BillingIntervalCount == 0 {
+		subscription.BillingIntervalCount = 1
+	}
+	require.NoError(f.t, f.repo.Create(context.Background(), nil, &subscription))
+	for _, price := range history {
+		price.SubscriptionID = subscription.ID
+		require.NoError(f.t, f.prices.SavePrice(context.Background(), nil, &price))
+	}
+	return &subscription
+}
+
+// costs returns the charges and subtotal of every subscription of userID within the range,
+// keyed by subscription ID, and checks that the total agrees with them
+func (f *pgFixture) costs(userID uuid.UUID, start, end models.YearMonth) map[uint]models.SubscriptionCost {
+	f.t.Helper()
+	ctx := context.Background()
+	filter := &models.SubscriptionFilter{UserID: &userID}
+
+	subscriptions, err := f.repo.GetSubscriptionsInDateRange(ctx, filter, start, end)
+	require.NoError(f.t, err)
+	result := make(map[uint]models.SubscriptionCost, len(subscriptions))
+	subtotals := map[string]int{}
+	for _, s := range subscriptions {
+		result[s.ID] = s
+		subtotals[s.Currency] += s.Subtotal
+	}
+
+	totals, err := f.repo.CalculateTotalCostInDB(ctx, nil, filter, start, end)
+	require.NoError(f.t, err)
+	totalsByCurrency := map[string]int{}
+	for _, total := range totals {
+		totalsByCurrency[total.Currency] = total.Amount
+	}
+	assert.Equal(f.t, subtotals, totalsByCurrency, "totals must add up the subtotals")
+	return result
+}
+
+// assertCost checks the billed months, charges and subtotal of a subscription
+func assertCost(t *testing.T, costs map[uint]models.SubscriptionCost, subscription *models.Subscription, billedMonths, charges, subtotal int) {
+	t.Helper()
+	cost, ok := costs[subscription.ID]
+	if !assert.True(t, ok, "subscription %d is missing from the range", subscription.ID) {
+		return
+	}
+	assert.Equal(t, billedMonths, cost.BilledMonths, "billed months of subscription %d", subscription.ID)
+	assert.Equal(t, charges, cost.Charges, "charges of subscription %d", subscription.ID)
+	assert.Equal(t, subtotal, cost.Subtotal, "subtotal of subscription %d", subscription.ID)
+}
+
+func ym(year int, month time.Month) models.YearMonth {
+	return models.NewYearMonth(year, month)
+}
+
+func ymPtr(year int, month time.Month) *models.YearMonth {
+	value := ym(year, month)
+	return &value
+}
+
+func TestPostgresCosts_PartialOverlap(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+
+	startsInside := f.subscribe(userID, models.Subscription{Price: 100, StartDate: ym(2025, time.March)})
+	endsInside := f.subscribe(userID, models.Subscription{Price: 200, StartDate: ym(2024, time.October), EndDate: ymPtr(2025, time.February)})
+	f.subscribe(userID, models.Subscription{Price: 300, StartDate: ym(2025, time.August)})
+	f.subscribe(userID, models.Subscription{Price: 400, StartDate: ym(2024, time.January), EndDate: ymPtr(2024, time.December)})
+
+	costs := f.costs(userID, ym(2025, time.January), ym(2025, time.June))
+	assert.Len(t, costs, 2, "subscriptions outside the range are not charged")
+	assertCost(t, costs, startsInside, 4, 4, 400)
+	assertCost(t, costs, endsInside, 2, 2, 400)
+}
+
+func TestPostgresCosts_CrossYearRange(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+
+	monthly := f.subscribe(userID, models.Subscription{Price: 100, StartDate: ym(2024, time.June)})
+	// Charged in 09-2024, 12-2024 and 03-2025
+	quarterly := f.subscribe(userID, models.Subscription{
+		Price: 300, BillingPeriod: models.BillingPeriodQuarter,
+		StartDate: ym(2024, time.September), EndDate: ymPtr(2025, time.December),
+	})
+	yearly := f.subscribe(userID, models.Subscription{
+		Price: 1000, Currency: "USD", BillingPeriod: models.BillingPeriodYear,
+		StartDate: ym(2025, time.January),
+	})
+
+	costs := f.costs(userID, ym(2024, time.November), ym(2025, time.February))
+	assertCost(t, costs, monthly, 4, 4, 400)
+	assertCost(t, costs, quarterly, 4, 1, 300)
+	assertCost(t, costs, yearly, 2, 1, 1000)
+
+	totals, err := f.repo.CalculateTotalCostInDB(context.Background(), nil, &models.SubscriptionFilter{UserID: &userID}, ym(2024, time.November), ym(2025, time.February))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.CurrencyAmount{{Currency: "RUB", Amount: 700}, {Currency: "USD", Amount: 1000}}, totals)
+}
+
+func TestPostgresCosts_BillingCycles(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+
+	// Charged in 06-2024 and 06-2025
+	yearly := f.subscribe(userID, models.Subscription{
+		Price: 1200, BillingPeriod: models.BillingPeriodYear, StartDate: ym(2024, time.June),
+	})
+	// Charged in 11-2024 and then in 02, 05, 08 and 11-2025
+	quarterly := f.subscribe(userID, models.Subscription{
+		Price: 300, BillingPeriod: models.BillingPeriodQuarter, StartDate: ym(2024, time.November),
+	})
+	// Charged on the 1st, 8th, 15th, 22nd and 29th of December
+	weekly := f.subscribe(userID, models.Subscription{
+		Price: 50, BillingPeriod: models.BillingPeriodWeek, StartDate: ym(2025, time.December),
+	})
+	// Charged in 01, 03 and 05-2025
+	everyOtherMonth := f.subscribe(userID, models.Subscription{
+		Price: 100, BillingIntervalCount: 2, StartDate: ym(2025, time.January), EndDate: ymPtr(2025, time.June),
+	})
+	// Charged every other week from 01-03-2025: 1, 15 and 29 March, then 12 and 26 April
+	everyOtherWeek := f.subscribe(userID, models.Subscription{
+		Price: 10, BillingPeriod: models.BillingPeriodWeek, BillingIntervalCount: 2,
+		StartDate: ym(2025, time.March), EndDate: ymPtr(2025, time.April),
+	})
+
+	costs := f.costs(userID, ym(2025, time.January), ym(2025, time.December))
+	assertCost(t, costs, yearly, 12, 1, 1200)
+	assertCost(t, costs, quarterly, 12, 4, 1200)
+	assertCost(t, costs, weekly, 1, 5, 250)
+	assertCost(t, costs, everyOtherMonth, 6, 3, 300)
+	assertCost(t, costs, everyOtherWeek, 2, 5, 50)
+}
+
+func TestPostgresCosts_PriceChangeMidRange(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+
+	monthly := f.subscribe(userID,
+		models.Subscription{Price: 150, StartDate: ym(2025, time.January)},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.January), Price: 100},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.April), Price: 150},
+	)
+	// Charged in 01 and 04-2025, both before the price change in May
+	quarterly := f.subscribe(userID,
+		models.Subscription{Price: 500, BillingPeriod: models.BillingPeriodQuarter, StartDate: ym(2025, time.January)},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.January), Price: 400},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.May), Price: 500},
+	)
+	// Charged on 1, 8, 15, 22 and 29 May at the old price, then on 5, 12, 19 and 26 June
+	weekly := f.subscribe(userID,
+		models.Subscription{Price: 20, BillingPeriod: models.BillingPeriodWeek, StartDate: ym(2025, time.May)},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.May), Price: 10},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.June), Price: 20},
+	)
+	// A price history starting after the subscription still covers the months before it
+	lateHistory := f.subscribe(userID,
+		models.Subscription{Price: 70, StartDate: ym(2025, time.January)},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.March), Price: 70},
+	)
+
+	costs := f.costs(userID, ym(2025, time.January), ym(2025, time.June))
+	assertCost(t, costs, monthly, 6, 6, 3*100+3*150)
+	assertCost(t, costs, quarterly, 6, 2, 2*400)
+	assertCost(t, costs, weekly, 2, 9, 5*10+4*20)
+	assertCost(t, costs, lateHistory, 6, 6, 6*70)
+
+	// A range inside a single price segment only sees its price
+	costs = f.costs(userID, ym(2025, time.February), ym(2025, time.March))
+	assertCost(t, costs, monthly, 2, 2, 200)
+}
+
+func TestPostgresCosts_TrialBoundary(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+
+	// Charged the trial price in 01 and 02-2025 and the full price from 03-2025
+	monthly := f.subscribe(userID, models.Subscription{
+		Price: 500, StartDate: ym(2025, time.January), TrialEnd: ymPtr(2025, time.February), TrialPrice: 100,
+	})
+	// Free every week of January, then charged on 5, 12, 19 and 26 February
+	weekly := f.subscribe(userID, models.Subscription{
+		Price: 70, BillingPeriod: models.BillingPeriodWeek, StartDate: ym(2025, time.January), TrialEnd: ymPtr(2025, time.January),
+	})
+	// The trial price also wins over the price history
+	withHistory := f.subscribe(userID,
+		models.Subscription{Price: 300, StartDate: ym(2025, time.January), TrialEnd: ymPtr(2025, time.January), TrialPrice: 50},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.January), Price: 200},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.February), Price: 300},
+	)
+
+	costs := f.costs(userID, ym(2025, time.January), ym(2025, time.February))
+	assertCost(t, costs, monthly, 2, 2, 200)
+	assertCost(t, costs, weekly, 2, 9, 4*70)
+	assertCost(t, costs, withHistory, 2, 2, 50+300)
+
+	costs = f.costs(userID, ym(2025, time.February), ym(2025, time.April))
+	assertCost(t, costs, monthly, 3, 3, 100+2*500)
+
+	// A range after the trial is charged the full price only
+	costs = f.costs(userID, ym(2025, time.March), ym(2025, time.March))
+	assertCost(t, costs, monthly, 1, 1, 500)
+}
+
+func TestPostgresSpendSeries_IncludesEmptyMonths(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+	filter := &models.SubscriptionFilter{UserID: &userID}
+
+	f.subscribe(userID, models.Subscription{ServiceName: "Netflix", Price: 100, StartDate: ym(2025, time.February), EndDate: ymPtr(2025, time.February)})
+	// Active in March without being charged in it
+	f.subscribe(userID, models.Subscription{
+		ServiceName: "Spotify", Price: 300, BillingPeriod: models.BillingPeriodQuarter,
+		StartDate: ym(2025, time.February), EndDate: ymPtr(2025, time.March),
+	})
+	// Another user's subscription does not fill the empty months
+	f.subscribe(uuid.New(), models.Subscription{Price: 900, StartDate: ym(2025, time.January)})
+
+	points, err := f.repo.CalculateSpendSeriesInDB(context.Background(), filter, ym(2025, time.January), ym(2025, time.April), models.SpendGroupByMonth)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SpendPoint{
+		{Period: ym(2025, time.January), Currency: "", Amount: 0},
+		{Period: ym(2025, time.February), Currency: "RUB", Amount: 400},
+		{Period: ym(2025, time.March), Currency: "RUB", Amount: 0},
+		{Period: ym(2025, time.April), Currency: "", Amount: 0},
+	}, points)
+
+	points, err = f.repo.CalculateSpendSeriesInDB(context.Background(), filter, ym(2025, time.January), ym(2025, time.March), models.SpendGroupByService)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SpendPoint{
+		{Period: ym(2025, time.January), ServiceName: "", Currency: "", Amount: 0},
+		{Period: ym(2025, time.February), ServiceName: "Netflix", Currency: "RUB", Amount: 100},
+		{Period: ym(2025, time.February), ServiceName: "Spotify", Currency: "RUB", Amount: 300},
+		{Period: ym(2025, time.March), ServiceName: "Spotify", Currency: "RUB", Amount: 0},
+	}, points)
+}
+
+func TestPostgresSpendSeries_PricesChargesLikeTotals(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+	filter := &models.SubscriptionFilter{UserID: &userID}
+
+	f.subscribe(userID,
+		models.Subscription{Price: 150, StartDate: ym(2025, time.January), TrialEnd: ymPtr(2025, time.January), TrialPrice: 10},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.January), Price: 100},
+		models.SubscriptionPrice{EffectiveFrom: ym(2025, time.March), Price: 150},
+	)
+	// Charged on 1, 8, 15, 22 and 29 January, 5, 12, 19 and 26 February and 5, 12, 19 and 26 March
+	f.subscribe(userID, models.Subscription{Price: 5, BillingPeriod: models.BillingPeriodWeek, StartDate: ym(2025, time.January)})
+
+	points, err := f.repo.CalculateSpendSeriesInDB(context.Background(), filter, ym(2025, time.January), ym(2025, time.March), models.SpendGroupByMonth)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SpendPoint{
+		{Period: ym(2025, time.January), Currency: "RUB", Amount: 10 + 5*5},
+		{Period: ym(2025, time.February), Currency: "RUB", Amount: 100 + 4*5},
+		{Period: ym(2025, time.March), Currency: "RUB", Amount: 150 + 4*5},
+	}, points)
+
+	totals, err := f.repo.CalculateTotalCostInDB(context.Background(), nil, filter, ym(2025, time.January), ym(2025, time.March))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.CurrencyAmount{{Currency: "RUB", Amount: 35 + 120 + 170}}, totals)
+}
+
+func TestPostgresList_FiltersByTrialConversion(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+
+	// From July 1st, a 14-day window covers conversions up to July 15th
+	from := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 14)
+	subscriptions := map[string]*models.Subscription{
+		// Converts on July 1st, the first day of the window
+		"monthly":           f.subscribe(userID, models.Subscription{ServiceName: "monthly", Price: 100, StartDate: ym(2025, time.May), TrialEnd: ymPtr(2025, time.June)}),
+		"monthly later":     f.subscribe(userID, models.Subscription{ServiceName: "monthly later", Price: 100, StartDate: ym(2025, time.May), TrialEnd: ymPtr(2025, time.July)}),
+		"monthly converted": f.subscribe(userID, models.Subscription{ServiceName: "monthly converted", Price: 100, StartDate: ym(2025, time.April), TrialEnd: ymPtr(2025, time.May)}),
+		"monthly ended": f.subscribe(userID, models.Subscription{
+			ServiceName: "monthly ended", Price: 100, StartDate: ym(2025, time.May), EndDate: ymPtr(2025, time.June), TrialEnd: ymPtr(2025, time.June),
+		}),
+		// Charged in April and July, so the trial ending in May converts in July
+		"quarterly": f.subscribe(userID, models.Subscription{
+			ServiceName: "quarterly", Price: 300, BillingPeriod: models.BillingPeriodQuarter, StartDate: ym(2025, time.April), TrialEnd: ymPtr(2025, time.May),
+		}),
+		// Charged in May and August
+		"quarterly later": f.subscribe(userID, models.Subscription{
+			ServiceName: "quarterly later", Price: 300, BillingPeriod: models.BillingPeriodQuarter, StartDate: ym(2025, time.May), TrialEnd: ymPtr(2025, time.June),
+		}),
+		// Charged every week from June 1st, first after the trial on July 6th
+		"weekly": f.subscribe(userID, models.Subscription{
+			ServiceName: "weekly", Price: 10, BillingPeriod: models.BillingPeriodWeek, StartDate: ym(2025, time.June), TrialEnd: ymPtr(2025, time.June),
+		}),
+		// Charged every four weeks from June 1st, first after the trial on July 27th
+		"four-weekly": f.subscribe(userID, models.Subscription{
+			ServiceName: "four-weekly", Price: 40, BillingPeriod: models.BillingPeriodWeek, BillingIntervalCount: 4, StartDate: ym(2025, time.June), TrialEnd: ymPtr(2025, time.June),
+		}),
+		"no trial": f.subscribe(userID, models.Subscription{ServiceName: "no trial", Price: 100, StartDate: ym(2025, time.May)}),
+	}
+
+	listed, err := f.repo.List(context.Background(), &models.SubscriptionFilter{
+		UserID:            &userID,
+		TrialConvertsFrom: &from,
+		TrialConvertsTo:   &to,
+		Sort:              []models.SortField{{Field: "service_name"}},
+	}, nil, 0, 0)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, subscription := range listed {
+		names = append(names, subscription.ServiceName)
+	}
+	assert.Equal(t, []string{"monthly", "quarterly", "weekly"}, names)
+
+	// The filter agrees with the conversion dates the reminders use
+	for name, subscription := range subscriptions {
+		conversion, converts := subscription.TrialConversion()
+		within := converts && !conversion.Before(from) && !conversion.After(to)
+		assert.Equal(t, within, slices.Contains(names, name), "subscription %q converting on %s", name, conversion.Format(time.DateOnly))
+	}
+}
+
+func TestPostgresListDueForReminders(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+
+	// Saturday March 29th with a 72h lead time and a week for trials, like ReminderService
+	today := time.Date(2025, time.March, 29, 0, 0, 0, 0, time.UTC)
+	horizon := time.Date(2025, time.April, 1, 9, 0, 0, 0, time.UTC)
+	trialHorizon := time.Date(2025, time.April, 5, 9, 0, 0, 0, time.UTC)
+
+	// Renews on April 1st
+	f.subscribe(userID, models.Subscription{ServiceName: "renews", Price: 100, StartDate: ym(2025, time.January)})
+	f.subscribe(userID, models.Subscription{ServiceName: "starts later", Price: 100, StartDate: ym(2025, time.May)})
+	// Ends on April 1st
+	f.subscribe(userID, models.Subscription{ServiceName: "ends", Price: 100, StartDate: ym(2025, time.January), EndDate: ymPtr(2025, time.March)})
+	f.subscribe(userID, models.Subscription{ServiceName: "ended", Price: 100, StartDate: ym(2024, time.January), EndDate: ymPtr(2025, time.January)})
+	f.subscribe(userID, models.Subscription{ServiceName: "yearly", Price: 1200, BillingPeriod: models.BillingPeriodYear, StartDate: ym(2025, time.January)})
+	// Charged every week from Saturday March 1st, today included
+	f.subscribe(userID, models.Subscription{ServiceName: "weekly", Price: 10, BillingPeriod: models.BillingPeriodWeek, StartDate: ym(2025, time.March)})
+	// Charged every week from Wednesday January 1st, first after the trial on April 2nd
+	f.subscribe(userID, models.Subscription{
+		ServiceName: "converts", Price: 10, BillingPeriod: models.BillingPeriodWeek, StartDate: ym(2025, time.January), TrialEnd: ymPtr(2025, time.March),
+	})
+	// Charged every week from Wednesday January 1st, next on April 2nd
+	f.subscribe(userID, models.Subscription{ServiceName: "weekly later", Price: 10, BillingPeriod: models.BillingPeriodWeek, StartDate: ym(2025, time.January)})
+
+	var names []string
+	var afterID uint
+	for {
+		batch, err := f.repo.ListDueForReminders(context.Background(), today, horizon, trialHorizon, afterID, 2)
+		require.NoError(t, err)
+		for _, subscription := range batch {
+			names = append(names, subscription.ServiceName)
+		}
+		if len(batch) < 2 {
+			break
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+	assert.Equal(t, []string{"renews", "ends", "weekly", "converts"}, names)
+}
+
+func TestPostgresListChargedWithin(t *testing.T) {
+	f := setupPostgresFixture(t)
+	userID := uuid.New()
+
+	// Monday March 10th with a 30-day window up to April 9th
+	today := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	horizon := today.AddDate(0, 0, 30)
+
+	// Next charged on April 1st
+	f.subscribe(userID, models.Subscription{ServiceName: "yearly", Price: 5000, BillingPeriod: models.BillingPeriodYear, StartDate: ym(2024, time.April)})
+	f.subscribe(userID, models.Subscription{ServiceName: "monthly", Price: 990, StartDate: ym(2025, time.January)})
+	// Next charged on Saturday March 15th
+	f.subscribe(userID, models.Subscription{ServiceName: "weekly", Price: 100, BillingPeriod: models.BillingPeriodWeek, StartDate: ym(2025, time.March)})
+	f.subscribe(userID, models.Subscription{ServiceName: "ended", Price: 300, StartDate: ym(2025, time.January), EndDate: ymPtr(2025, time.February)})
+	f.subscribe(userID, models.Subscription{ServiceName: "ends in March", Price: 300, StartDate: ym(2025, time.January), EndDate: ymPtr(2025, time.March)})
+	f.subscribe(userID, models.Subscription{ServiceName: "quarterly", Price: 900, BillingPeriod: models.BillingPeriodQuarter, StartDate: ym(2025, time.March)})
+	f.subscribe(userID, models.Subscription{ServiceName: "starts in May", Price: 100, StartDate: ym(2025, time.May)})
+	f.subscribe(uuid.New(), models.Subscription{ServiceName: "someone else's", Price: 100, StartDate: ym(2025, time.January)})
+
+	names := func(limit int) []string {
+		subscriptions, err := f.repo.ListChargedWithin(context.Background(), &models.SubscriptionFilter{UserID: &userID}, today, horizon, limit)
+		require.NoError(t, err)
+		var names []string
+		for _, subscription := range subscriptions {
+			names = append(names, subscription.ServiceName)
+		}
+		return names
+	}
+
+	// Ordered by the next charge, then by id
+	assert.Equal(t, []string{"weekly", "yearly", "monthly"}, names(0))
+	assert.Equal(t, []string{"weekly", "yearly"}, names(2))
+}
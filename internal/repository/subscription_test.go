@@ -0,0 +1,359 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupSubscriptionRepository(t *testing.T) *SubscriptionRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Subscription{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewSubscriptionRepository(db, logger)
+}
+
+func TestSubscriptionRepository_ListKeysetPages(t *testing.T) {
+	repo := setupSubscriptionRepository(t)
+	ctx := context.Background()
+
+	// Two rows share a creation time, so the ID breaks the tie
+	base := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := []time.Time{base, base.Add(time.Hour), base.Add(time.Hour), base.Add(2 * time.Hour)}
+	userID := uuid.New()
+	for _, at := range createdAt {
+		subscription := &models.Subscription{
+			ServiceName:          "Netflix",
+			Price:                999,
+			Currency:             "RUB",
+			BillingPeriod:        models.BillingPeriodMonth,
+			BillingIntervalCount: 1,
+			UserID:               userID,
+			StartDate:            models.YearMonth{Year: 2025, Month: time.January},
+			CreatedAt:            at,
+		}
+		assert.NoError(t, repo.Create(ctx, nil, subscription))
+	}
+
+	ids := func(subscriptions []models.Subscription) []uint {
+		result := make([]uint, 0, len(subscriptions))
+		for _, s := range subscriptions {
+			result = append(result, s.ID)
+		}
+		return result
+	}
+
+	filter := &models.SubscriptionFilter{UserID: &userID}
+	first, err := repo.List(ctx, filter, nil, 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 2}, ids(first))
+
+	second, err := repo.List(ctx, filter, models.CursorAfter(&first[1]), 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{3, 4}, ids(second))
+
+	// Backward pages come back in ascending order
+	back, err := repo.List(ctx, filter, models.CursorBefore(&second[1]), 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{2, 3}, ids(back))
+
+	// Offsets keep working without a cursor
+	offset, err := repo.List(ctx, filter, nil, 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{4}, ids(offset))
+
+	count, err := repo.Count(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
+
+func TestSubscriptionRepository_ListAppliesFilter(t *testing.T) {
+	repo := setupSubscriptionRepository(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	monthPtr := func(month string) *models.YearMonth {
+		ym, _ := models.ParseYearMonth(month)
+		return &ym
+	}
+	month := func(value string) models.YearMonth { return *monthPtr(value) }
+	for _, subscription := range []models.Subscription{
+		{ServiceName: "Netflix", Price: 999, StartDate: month("01-2025")},
+		{ServiceName: "netflix", Price: 299, StartDate: month("03-2024"), EndDate: monthPtr("12-2024")},
+		{ServiceName: "Spotify", Price: 199, StartDate: month("09-2025")},
+		{ServiceName: "Netflix Kids", Price: 499, StartDate: month("02-2025")},
+	} {
+		subscription.UserID = userID
+		subscription.Currency = "RUB"
+		subscription.BillingPeriod = models.BillingPeriodMonth
+		subscription.BillingIntervalCount = 1
+		assert.NoError(t, repo.Create(ctx, nil, &subscription))
+	}
+
+	names := func(filter *models.SubscriptionFilter) []string {
+		subscriptions, err := repo.List(ctx, filter, nil, 0, 0)
+		assert.NoError(t, err)
+		result := make([]string, 0, len(subscriptions))
+		for _, s := range subscriptions {
+			result = append(result, s.ServiceName+"@"+s.StartDate.String())
+		}
+		return result
+	}
+
+	// Service names match exactly, ignoring case, so "Netflix Kids" is not a Netflix subscription
+	assert.Equal(t, []string{"Netflix@01-2025", "netflix@03-2024"}, names(&models.SubscriptionFilter{ServiceNames: []string{"netflix"}}))
+
+	priceMin, priceMax := 250, 999
+	assert.Equal(t, []string{"netflix@03-2024", "Netflix Kids@02-2025", "Netflix@01-2025"}, names(&models.SubscriptionFilter{
+		PriceMin: &priceMin,
+		PriceMax: &priceMax,
+		Sort:     []models.SortField{{Field: "price"}},
+	}))
+
+	activeOn := month("06-2024")
+	assert.Equal(t, []string{"netflix@03-2024"}, names(&models.SubscriptionFilter{ActiveOn: &activeOn}))
+
+	status := func(s models.SubscriptionStatus) *models.SubscriptionFilter {
+		return &models.SubscriptionFilter{Status: &s, StatusMonth: month("07-2025"), Sort: []models.SortField{{Field: "start_date", Desc: true}}}
+	}
+	assert.Equal(t, []string{"Netflix Kids@02-2025", "Netflix@01-2025"}, names(status(models.SubscriptionStatusActive)))
+	assert.Equal(t, []string{"netflix@03-2024"}, names(status(models.SubscriptionStatusEnded)))
+	assert.Equal(t, []string{"Spotify@09-2025"}, names(status(models.SubscriptionStatusUpcoming)))
+
+	after, before := month("01-2025"), month("09-2025")
+	assert.Equal(t, []string{"Netflix Kids@02-2025"}, names(&models.SubscriptionFilter{StartedAfter: &after, StartedBefore: &before}))
+
+	count, err := repo.Count(ctx, &models.SubscriptionFilter{ServiceNames: []string{"netflix", "spotify"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestSubscriptionRepository_UpdateChecksVersion(t *testing.T) {
+	repo := setupSubscriptionRepository(t)
+	ctx := context.Background()
+
+	subscription := &models.Subscription{
+		ServiceName:          "Netflix",
+		Price:                999,
+		Currency:             "RUB",
+		BillingPeriod:        models.BillingPeriodMonth,
+		BillingIntervalCount: 1,
+		UserID:               uuid.New(),
+		StartDate:            models.YearMonth{Year: 2025, Month: time.January},
+		Version:              1,
+	}
+	assert.NoError(t, repo.Create(ctx, nil, subscription))
+
+	// Two writers read version 1; only the first one may write
+	first, second := *subscription, *subscription
+	first.Price = 1199
+	second.Price = 1299
+
+	updated, err := repo.Update(ctx, nil, &first)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, 2, first.Version)
+
+	updated, err = repo.Update(ctx, nil, &second)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+	assert.Equal(t, 1, second.Version)
+
+	stored, err := repo.GetByID(ctx, nil, subscription.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1199, stored.Price)
+	assert.Equal(t, 2, stored.Version)
+	assert.Equal(t, subscription.CreatedAt.Unix(), stored.CreatedAt.Unix())
+
+	deleted, err := repo.Delete(ctx, nil, subscription.ID, 1)
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = repo.Delete(ctx, nil, subscription.ID, 2)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+}
+
+func TestSubscriptionRepository_ExistsIgnoresServiceNameCase(t *testing.T) {
+	repo := setupSubscriptionRepository(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	january := models.YearMonth{Year: 2025, Month: time.January}
+	assert.NoError(t, repo.Create(ctx, nil, &models.Subscription{
+		ServiceName:          "Netflix",
+		Price:                999,
+		Currency:             "RUB",
+		BillingPeriod:        models.BillingPeriodMonth,
+		BillingIntervalCount: 1,
+		UserID:               userID,
+		StartDate:            january,
+	}))
+
+	exists, err := repo.ExistsByUserServiceAndDate(ctx, nil, userID, "NETFLIX", january)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.ExistsByUserServiceAndDate(ctx, nil, userID, "Netflix", models.YearMonth{Year: 2025, Month: time.February})
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestSubscriptionRepository_ListFiltersByCategory(t *testing.T) {
+	repo := setupSubscriptionRepository(t)
+	ctx := context.Background()
+	if err := repo.db.AutoMigrate(&models.Service{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	netflix := &models.Service{Name: "Netflix", Category: models.ServiceCategoryStreaming, Currency: "RUB"}
+	assert.NoError(t, repo.db.Create(netflix).Error)
+
+	userID := uuid.New()
+	for _, subscription := range []*models.Subscription{
+		{ServiceName: "Netflix", ServiceID: &netflix.ID},
+		{ServiceName: "Local Gym"},
+	} {
+		subscription.Price = 999
+		subscription.Currency = "RUB"
+		subscription.BillingPeriod = models.BillingPeriodMonth
+		subscription.BillingIntervalCount = 1
+		subscription.UserID = userID
+		subscription.StartDate = models.YearMonth{Year: 2025, Month: time.January}
+		assert.NoError(t, repo.Create(ctx, nil, subscription))
+	}
+
+	names := func(category models.ServiceCategory) []string {
+		subscriptions, err := repo.List(ctx, &models.SubscriptionFilter{Category: &category}, nil, 0, 0)
+		assert.NoError(t, err)
+		result := make([]string, 0, len(subscriptions))
+		for _, s := range subscriptions {
+			result = append(result, s.ServiceName)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"Netflix"}, names(models.ServiceCategoryStreaming))
+	// Subscriptions outside the catalog belong to other
+	assert.Equal(t, []string{"Local Gym"}, names(models.ServiceCategoryOther))
+	assert.Empty(t, names(models.ServiceCategoryMusic))
+}
+
+func TestSubscriptionRepository_StreamAppliesFilterAndSort(t *testing.T) {
+	repo := setupSubscriptionRepository(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	for _, price := range []int{499, 999, 199} {
+		subscription := &models.Subscription{
+			ServiceName:          "Netflix",
+			Price:                price,
+			Currency:             "RUB",
+			BillingPeriod:        models.BillingPeriodMonth,
+			BillingIntervalCount: 1,
+			UserID:               userID,
+			StartDate:            models.YearMonth{Year: 2025, Month: time.January},
+		}
+		assert.NoError(t, repo.Create(ctx, nil, subscription))
+	}
+	assert.NoError(t, repo.Create(ctx, nil, &models.Subscription{ServiceName: "Spotify", Price: 299, Currency: "RUB", UserID: uuid.New(), StartDate: models.YearMonth{Year: 2025, Month: time.January}}))
+
+	var prices []int
+	err := repo.Stream(ctx, &models.SubscriptionFilter{UserID: &userID, Sort: []models.SortField{{Field: "price", Desc: true}}}, func(subscription *models.Subscription) error {
+		prices = append(prices, subscription.Price)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{999, 499, 199}, prices)
+
+	// An error from the callback stops the iteration
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.Stream(ctx, nil, func(*models.Subscription) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestSubscriptionRepository_TrashRestoreAndPurge(t *testing.T) {
+	repo := setupSubscriptionRepository(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	var ids []uint
+	for _, name := range []string{"Netflix", "Spotify", "Yandex Plus"} {
+		subscription := &models.Subscription{ServiceName: name, Price: 999, Currency: "RUB", UserID: userID, StartDate: models.YearMonth{Year: 2025, Month: time.January}, Version: 1}
+		assert.NoError(t, repo.Create(ctx, nil, subscription))
+		ids = append(ids, subscription.ID)
+	}
+	for _, id := range ids[:2] {
+		deleted, err := repo.Delete(ctx, nil, id, 1)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+	}
+
+	trash, err := repo.ListDeleted(ctx, &models.SubscriptionFilter{UserID: &userID}, 10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, trash, 2)
+	assert.True(t, trash[0].DeletedAt.Valid)
+	count, err := repo.CountDeleted(ctx, &models.SubscriptionFilter{ServiceNames: []string{"netflix"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// Restoring needs the version the row was read at
+	netflix, err := repo.GetByIDUnscoped(ctx, nil, ids[0])
+	assert.NoError(t, err)
+	stale := *netflix
+	stale.Version = 7
+	restored, err := repo.Restore(ctx, nil, &stale)
+	assert.NoError(t, err)
+	assert.False(t, restored)
+
+	restored, err = repo.Restore(ctx, nil, netflix)
+	assert.NoError(t, err)
+	assert.True(t, restored)
+	assert.Equal(t, 2, netflix.Version)
+	active, err := repo.GetByID(ctx, nil, ids[0])
+	assert.NoError(t, err)
+	assert.Equal(t, 2, active.Version)
+
+	// Only rows deleted before the cutoff are listed for the retention job
+	expired, err := repo.ListDeletedBefore(ctx, nil, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, expired)
+	expired, err = repo.ListDeletedBefore(ctx, nil, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	if assert.Len(t, expired, 1) {
+		assert.Equal(t, ids[1], expired[0].ID)
+		gone, err := repo.Purge(ctx, nil, expired[0].ID, expired[0].Version)
+		assert.NoError(t, err)
+		assert.True(t, gone)
+	}
+	_, err = repo.GetByIDUnscoped(ctx, nil, ids[1])
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Purge removes active rows too
+	gone, err := repo.Purge(ctx, nil, ids[2], 1)
+	assert.NoError(t, err)
+	assert.True(t, gone)
+	_, err = repo.GetByIDUnscoped(ctx, nil, ids[2])
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"subscription_tracker_api/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository handles database operations for webhook endpoints, the event outbox and deliveries
+type WebhookRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB, logger *logrus.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateEndpoint registers a webhook endpoint
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	return r.db.WithContext(ctx).Create(endpoint).Error
+}
+
+// GetEndpoint retrieves a webhook endpoint by ID
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id uint) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := r.db.WithContext(ctx).First(&endpoint, id).Error; err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// ListEndpoints retrieves every registered webhook endpoint
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.db.WithContext(ctx).Order("id").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// UpdateEndpoint saves changes to a webhook endpoint
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	return r.db.WithContext(ctx).Save(endpoint).Error
+}
+
+// DeleteEndpoint removes a webhook endpoint and reports whether it existed
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&models.WebhookEndpoint{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecordEvent writes an event to the outbox. Pass the transaction that changes the
+// subscription so the event is only published if the change commits.
+func (r *WebhookRepository) RecordEvent(ctx context.Context, tx *gorm.DB, event *models.WebhookEvent) error {
+	db := r.db
+	if tx != nil {
+		db = tx
+	}
+	return db.WithContext(ctx).Create(event).Error
+}
+
+// DispatchEvents fans undispatched outbox events out into one pending delivery per
+// subscribed active endpoint and marks them dispatched. It returns the number of events handled.
+func (r *WebhookRepository) DispatchEvents(ctx context.Context, limit int) (int, error) {
+	dispatched := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []models.WebhookEvent
+		if err := tx.Where("dispatched_at IS NULL").Order("id").Limit(limit).Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		var endpoints []models.WebhookEndpoint
+		if err := tx.Where("active = ?", true).Find(&endpoints).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var deliveries []models.WebhookDelivery
+		eventIDs := make([]uint, 0, len(events))
+		for _, event := range events {
+			eventIDs = append(eventIDs, event.ID)
+			for _, endpoint := range endpoints {
+				if endpoint.Accepts(event.EventType) {
+					deliveries = append(deliveries, models.WebhookDelivery{
+						EventID:       event.ID,
+						EndpointID:    endpoint.ID,
+						Status:        models.WebhookDeliveryPending,
+						NextAttemptAt: now,
+					})
+				}
+			}
+		}
+
+		if len(deliveries) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+				return err
+			}
+		}
+
+		// Only mark events still undispatched, so a concurrent worker's work is not repeated
+		result := tx.Model(&models.WebhookEvent{}).
+			Where("id IN ? AND dispatched_at IS NULL", eventIDs).
+			Update("dispatched_at", now)
+		dispatched = int(result.RowsAffected)
+		return result.Error
+	})
+	return dispatched, err
+}
+
+// ListDueDeliveries retrieves pending deliveries whose next attempt is due, with their event and endpoint
+func (r *WebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Preload("Event").
+		Preload("Endpoint").
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDelivery starts a new attempt of a due delivery: it bumps the attempt counter and
+// leases the delivery until leaseUntil so no other worker attempts it at the same time.
+// It reports false when another worker already claimed or finished it.
+func (r *WebhookRepository) ClaimDelivery(ctx context.Context, delivery *models.WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, models.WebhookDeliveryPending, delivery.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        delivery.Attempts + 1,
+			"next_attempt_at": leaseUntil,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+// SaveDeliveryAttempt stores the outcome of a delivery attempt
+func (r *WebhookRepository) SaveDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(delivery).
+		Select("status", "next_attempt_at", "last_attempt_at", "last_status_code", "last_error", "delivered_at", "updated_at").
+		Updates(delivery).Error
+}
+
+// ListDeliveries retrieves the delivery log of an endpoint, newest first
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID uint, status *models.WebhookDeliveryStatus, limit, offset int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	query := r.db.WithContext(ctx).Preload("Event").Where("endpoint_id = ?", endpointID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	err := query.Order("id DESC").Find(&deliveries).Error
+	return deliveries, err
+}
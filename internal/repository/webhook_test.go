@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupWebhookRepository(t *testing.T) *WebhookRepository {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.WebhookEndpoint{}, &models.WebhookEvent{}, &models.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewWebhookRepository(db, logger)
+}
+
+func TestWebhookRepository_DispatchFansOutToSubscribedEndpoints(t *testing.T) {
+	repo := setupWebhookRepository(t)
+	ctx := context.Background()
+
+	all := &models.WebhookEndpoint{URL: "https://a.example.com", Secret: "secret", Active: true}
+	createdOnly := &models.WebhookEndpoint{URL: "https://b.example.com", Secret: "secret", Active: true, EventTypes: models.StringList{models.EventSubscriptionCreated}}
+	inactive := &models.WebhookEndpoint{URL: "https://c.example.com", Secret: "secret", Active: true}
+	for _, endpoint := range []*models.WebhookEndpoint{all, createdOnly, inactive} {
+		assert.NoError(t, repo.CreateEndpoint(ctx, endpoint))
+	}
+	inactive.Active = false
+	assert.NoError(t, repo.UpdateEndpoint(ctx, inactive))
+
+	assert.NoError(t, repo.RecordEvent(ctx, nil, &models.WebhookEvent{EventType: models.EventSubscriptionCreated, SubscriptionID: 1, Payload: models.RawJSON(`{"id":1}`)}))
+	assert.NoError(t, repo.RecordEvent(ctx, nil, &models.WebhookEvent{EventType: models.EventSubscriptionDeleted, SubscriptionID: 1, Payload: models.RawJSON(`{"id":1}`)}))
+
+	dispatched, err := repo.DispatchEvents(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dispatched)
+
+	// Dispatched events are not fanned out again
+	dispatched, err = repo.DispatchEvents(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+
+	due, err := repo.ListDueDeliveries(ctx, time.Now(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, due, 3)
+	for _, delivery := range due {
+		assert.NotNil(t, delivery.Event)
+		assert.NotNil(t, delivery.Endpoint)
+		assert.NotEqual(t, inactive.ID, delivery.EndpointID)
+		if delivery.EndpointID == createdOnly.ID {
+			assert.Equal(t, models.EventSubscriptionCreated, delivery.Event.EventType)
+		}
+	}
+	assert.JSONEq(t, `{"id":1}`, string(due[0].Event.Payload))
+}
+
+func TestWebhookRepository_ClaimDeliveryOnlyOnce(t *testing.T) {
+	repo := setupWebhookRepository(t)
+	ctx := context.Background()
+
+	endpoint := &models.WebhookEndpoint{URL: "https://a.example.com", Secret: "secret", Active: true}
+	assert.NoError(t, repo.CreateEndpoint(ctx, endpoint))
+	assert.NoError(t, repo.RecordEvent(ctx, nil, &models.WebhookEvent{EventType: models.EventSubscriptionCreated, SubscriptionID: 1, Payload: models.RawJSON(`{}`)}))
+	_, err := repo.DispatchEvents(ctx, 10)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	due, err := repo.ListDueDeliveries(ctx, now, 10)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	first, second := due[0], due[0]
+	claimed, err := repo.ClaimDelivery(ctx, &first, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, 1, first.Attempts)
+
+	// A concurrent worker holding the same snapshot loses the race
+	claimed, err = repo.ClaimDelivery(ctx, &second, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	// The lease hides the delivery from other workers until it runs out
+	due, err = repo.ListDueDeliveries(ctx, now, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	statusCode := 200
+	first.Status = models.WebhookDeliverySucceeded
+	first.LastStatusCode = &statusCode
+	first.DeliveredAt = &now
+	assert.NoError(t, repo.SaveDeliveryAttempt(ctx, &first))
+
+	status := models.WebhookDeliverySucceeded
+	log, err := repo.ListDeliveries(ctx, endpoint.ID, &status, 10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, log, 1)
+	assert.Equal(t, 1, log[0].Attempts)
+	assert.Equal(t, 200, *log[0].LastStatusCode)
+}
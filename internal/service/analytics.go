@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxSpendMonths bounds the number of months in a spend series
+const MaxSpendMonths = 120
+
+// CalculateSpendSeries aggregates the charges of the subscriptions matching the request into a
+// monthly series, broken down by service, user or catalog category on request. It takes the
+// same range, filters and target currency as CalculateTotalCost; without a target currency
+// every point is in the currency of its subscriptions, so a month may have one point per
+// currency.
+func (s *SubscriptionService) CalculateSpendSeries(ctx context.Context, req *models.SpendRequest) ([]models.SpendPoint, error) {
+	startDate, endDate, targetCurrency, err := parseCostRange(&req.CostCalculationRequest)
+	if err != nil {
+		return nil, err
+	}
+	if months := calculateMonthsBetween(startDate, endDate); months > MaxSpendMonths {
+		return nil, errs.Validation("end_date", errs.CodeInvalidDateRange, fmt.Sprintf("the range must not exceed %d months", MaxSpendMonths))
+	}
+
+	groupBy := models.SpendGroupByMonth
+	if req.GroupBy != "" {
+		groupBy = models.SpendGroupBy(req.GroupBy)
+		if !slices.Contains(models.SpendGroupBys, groupBy) {
+			return nil, errs.Validation("group_by", errs.CodeInvalidInput, "group_by must be one of month, service, user, category")
+		}
+	}
+
+	// Restrict the series to the caller's own subscriptions
+	filter, err := s.parseFilter(ctx, &req.SubscriptionFilterRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.CalculateSpendSeriesInDB(ctx, filter, startDate, endDate, groupBy)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to calculate spend series in database")
+		return nil, errs.Internal("failed to calculate spend series")
+	}
+	if targetCurrency != "" {
+		if points, err = s.convertSpend(ctx, points, targetCurrency); err != nil {
+			return nil, err
+		}
+	}
+	if points == nil {
+		points = []models.SpendPoint{}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":         filter.UserID,
+		"service_names":   filter.ServiceNames,
+		"start_date":      req.StartDate,
+		"end_date":        req.EndDate,
+		"group_by":        groupBy,
+		"target_currency": targetCurrency,
+		"point_count":     len(points),
+	}).Info("Spend series calculated with database aggregation")
+
+	return points, nil
+}
+
+// spendKey identifies the points of a series that are merged once converted to one currency
+type spendKey struct {
+	period      models.YearMonth
+	serviceName string
+	userID      uuid.UUID
+	category    string
+}
+
+// convertSpend converts every point into targetCurrency and merges the points of the same
+// period, service, user and category, keeping their order
+func (s *SubscriptionService) convertSpend(ctx context.Context, points []models.SpendPoint, targetCurrency string) ([]models.SpendPoint, error) {
+	converted := make([]models.SpendPoint, 0, len(points))
+	index := make(map[spendKey]int, len(points))
+	for _, point := range points {
+		amount := 0
+		if point.Currency != "" {
+			var err error
+			if amount, err = s.converter.Convert(ctx, point.Amount, point.Currency, targetCurrency); err != nil {
+				return nil, err
+			}
+		}
+
+		key := spendKey{period: point.Period, serviceName: point.ServiceName, category: point.Category}
+		if point.UserID != nil {
+			key.userID = *point.UserID
+		}
+		if i, ok := index[key]; ok {
+			converted[i].Amount += amount
+			continue
+		}
+
+		point.Currency = targetCurrency
+		point.Amount = amount
+		index[key] = len(converted)
+		converted = append(converted, point)
+	}
+	return converted, nil
+}
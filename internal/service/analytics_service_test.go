@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCalculateSpendSeries_GroupsByMonthByDefault(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+
+	userID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID})
+	points := []models.SpendPoint{
+		{Period: yearMonth("01-2025"), Currency: "RUB", Amount: 999},
+		{Period: yearMonth("02-2025")},
+	}
+	mockRepo.On("CalculateSpendSeriesInDB", mock.Anything, mock.MatchedBy(func(filter *models.SubscriptionFilter) bool {
+		return filter.UserID != nil && *filter.UserID == userID
+	}), yearMonth("01-2025"), yearMonth("02-2025"), models.SpendGroupByMonth).Return(points, nil).Once()
+
+	result, err := service.CalculateSpendSeries(ctx, &models.SpendRequest{
+		CostCalculationRequest: models.CostCalculationRequest{StartDate: "01-2025", EndDate: "02-2025"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, points, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCalculateSpendSeries_ConvertsAndMergesCurrencies(t *testing.T) {
+	service, mockRepo, _, mockRatesRepo := setupTestServiceWithRates()
+
+	mockRepo.On("CalculateSpendSeriesInDB", mock.Anything, mock.Anything, yearMonth("01-2025"), yearMonth("02-2025"), models.SpendGroupByService).Return([]models.SpendPoint{
+		{Period: yearMonth("01-2025"), ServiceName: "Netflix", Currency: "RUB", Amount: 999},
+		{Period: yearMonth("01-2025"), ServiceName: "Netflix", Currency: "USD", Amount: 10},
+		{Period: yearMonth("01-2025"), ServiceName: "Spotify", Currency: "USD", Amount: 5},
+		{Period: yearMonth("02-2025")},
+	}, nil).Once()
+	mockRatesRepo.On("Get", mock.Anything, "USD", "RUB").Return(&models.ExchangeRate{FromCurrency: "USD", ToCurrency: "RUB", Rate: 90}, nil).Twice()
+
+	result, err := service.CalculateSpendSeries(context.Background(), &models.SpendRequest{
+		CostCalculationRequest: models.CostCalculationRequest{StartDate: "01-2025", EndDate: "02-2025", TargetCurrency: stringPtr("rub")},
+		GroupBy:                "service",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SpendPoint{
+		{Period: yearMonth("01-2025"), ServiceName: "Netflix", Currency: "RUB", Amount: 999 + 900},
+		{Period: yearMonth("01-2025"), ServiceName: "Spotify", Currency: "RUB", Amount: 450},
+		{Period: yearMonth("02-2025"), Currency: "RUB", Amount: 0},
+	}, result)
+	mockRepo.AssertExpectations(t)
+	mockRatesRepo.AssertExpectations(t)
+}
+
+func TestCalculateSpendSeries_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   models.SpendRequest
+		field string
+	}{
+		{"invalid start date", models.SpendRequest{CostCalculationRequest: models.CostCalculationRequest{StartDate: "2025-01", EndDate: "02-2025"}}, "start_date"},
+		{"end before start", models.SpendRequest{CostCalculationRequest: models.CostCalculationRequest{StartDate: "02-2025", EndDate: "01-2025"}}, "end_date"},
+		{"range too long", models.SpendRequest{CostCalculationRequest: models.CostCalculationRequest{StartDate: "01-2015", EndDate: "01-2025"}}, "end_date"},
+		{"unknown group_by", models.SpendRequest{CostCalculationRequest: models.CostCalculationRequest{StartDate: "01-2025", EndDate: "02-2025"}, GroupBy: "week"}, "group_by"},
+		{"invalid target currency", models.SpendRequest{CostCalculationRequest: models.CostCalculationRequest{StartDate: "01-2025", EndDate: "02-2025", TargetCurrency: stringPtr("XX")}}, "target_currency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, _ := setupTestService()
+
+			result, err := service.CalculateSpendSeries(context.Background(), &tt.req)
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, errs.ErrValidation)
+			var domainErr *errs.Error
+			assert.ErrorAs(t, err, &domainErr)
+			assert.Equal(t, tt.field, domainErr.Field)
+			mockRepo.AssertNotCalled(t, "CalculateSpendSeriesInDB", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
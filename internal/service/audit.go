@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// AuditService reads the audit log of subscription changes
+type AuditService struct {
+	repo          repository.AuditRepositoryInterface
+	subscriptions repository.SubscriptionRepositoryInterface
+	logger        *logrus.Logger
+}
+
+func NewAuditService(repo repository.AuditRepositoryInterface, subscriptions repository.SubscriptionRepositoryInterface, logger *logrus.Logger) *AuditService {
+	return &AuditService{
+		repo:          repo,
+		subscriptions: subscriptions,
+		logger:        logger,
+	}
+}
+
+// ListHistory retrieves the audit events of a subscription, newest first. Deleted subscriptions
+// keep their history until they are purged; after that it is only found through ListEvents.
+func (s *AuditService) ListHistory(ctx context.Context, subscriptionID uint, limit, offset int) ([]models.AuditEvent, error) {
+	subscription, err := s.subscriptions.GetByIDUnscoped(ctx, nil, subscriptionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")
+		}
+		s.logger.WithError(err).Error("Failed to retrieve subscription")
+		return nil, errs.Internal("failed to retrieve subscription")
+	}
+	if !canAccess(ctx, subscription) {
+		return nil, errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")
+	}
+
+	return s.list(ctx, &models.AuditFilter{SubscriptionID: &subscriptionID}, limit, offset)
+}
+
+// ListEvents retrieves the audit events matching the filter, newest first. Regular users only
+// see events of their own subscriptions.
+func (s *AuditService) ListEvents(ctx context.Context, req *models.AuditFilterRequest, limit, offset int) ([]models.AuditEvent, error) {
+	if req == nil {
+		req = &models.AuditFilterRequest{}
+	}
+
+	userID, err := scopeToCaller(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	filter := &models.AuditFilter{
+		SubscriptionID: req.SubscriptionID,
+		UserID:         userID,
+		Actor:          req.Actor,
+	}
+
+	if req.Action != "" {
+		action := models.AuditAction(req.Action)
+		if !slices.Contains(models.AuditActions, action) {
+			return nil, errs.Validation("action", errs.CodeInvalidInput, "action must be one of create, update, delete, restore, purge")
+		}
+		filter.Action = &action
+	}
+	if filter.From, err = parseOptionalTimestamp("from", req.From); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseOptionalTimestamp("to", req.To); err != nil {
+		return nil, err
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, errs.Validation("to", errs.CodeInvalidDateRange, "to must be after from")
+	}
+
+	return s.list(ctx, filter, limit, offset)
+}
+
+func (s *AuditService) list(ctx context.Context, filter *models.AuditFilter, limit, offset int) ([]models.AuditEvent, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	events, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list audit events")
+		return nil, errs.Internal("failed to retrieve audit events")
+	}
+	if events == nil {
+		events = []models.AuditEvent{}
+	}
+	return events, nil
+}
+
+// parseOptionalTimestamp parses an RFC 3339 timestamp, returning nil for an empty value
+func parseOptionalTimestamp(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errs.Validation(field, errs.CodeInvalidDateFormat, field+" must be an RFC 3339 timestamp, e.g. 2025-01-31T00:00:00Z")
+	}
+	return &parsed, nil
+}
+
+// recordAudit writes an audit event within the caller's transaction. before and after hold the
+// changed fields as built by changedFields; either is nil when the subscription did not exist
+// before or no longer exists after the change.
+func (s *SubscriptionService) recordAudit(ctx context.Context, tx *gorm.DB, action models.AuditAction, subscription *models.Subscription, before, after map[string]interface{}) error {
+	changes := make(map[string]models.FieldChange, max(len(before), len(after)))
+	for field, value := range before {
+		change := changes[field]
+		change.Before = value
+		changes[field] = change
+	}
+	for field, value := range after {
+		change := changes[field]
+		change.After = value
+		changes[field] = change
+	}
+
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to encode audit event")
+		return errs.Internal("failed to record audit event")
+	}
+
+	actor, actorUserID := auditActor(ctx)
+	event := &models.AuditEvent{
+		SubscriptionID: subscription.ID,
+		UserID:         subscription.UserID,
+		Actor:          actor,
+		ActorUserID:    actorUserID,
+		Action:         action,
+		Changes:        payload,
+	}
+	if err := s.audit.Record(ctx, tx, event); err != nil {
+		s.logger.WithError(err).WithField("action", action).Error("Failed to record audit event")
+		return errs.Internal("failed to record audit event")
+	}
+	return nil
+}
+
+// auditActor names who makes the change in ctx: the X-Actor header if one was sent, otherwise
+// the authenticated caller, otherwise a background job. The authenticated caller is returned
+// separately because X-Actor is not verified.
+func auditActor(ctx context.Context) (string, *uuid.UUID) {
+	var actorUserID *uuid.UUID
+	actor := models.AuditActorSystem
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		actorUserID = &principal.UserID
+		actor = principal.UserID.String()
+	}
+	if header, ok := auth.ActorFromContext(ctx); ok {
+		actor = header
+	}
+	return actor, actorUserID
+}
+
+// subscriptionFields lists the editable fields of a subscription that are set, in the form of
+// changedFields
+func subscriptionFields(subscription *models.Subscription) map[string]interface{} {
+	return changedFields(&models.Subscription{}, subscription)
+}
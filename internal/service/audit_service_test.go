@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockAuditRepository is a mock implementation of AuditRepositoryInterface
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Record(ctx context.Context, tx *gorm.DB, event *models.AuditEvent) error {
+	args := m.Called(ctx, tx, event)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter *models.AuditFilter, limit, offset int) ([]models.AuditEvent, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+func setupAuditService() (*AuditService, *MockAuditRepository, *MockSubscriptionRepository) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	mockRepo := &MockAuditRepository{}
+	mockSubscriptions := &MockSubscriptionRepository{}
+	return NewAuditService(mockRepo, mockSubscriptions, logger), mockRepo, mockSubscriptions
+}
+
+func TestUpdateSubscription_RecordsAuditDiff(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	userID := uuid.New()
+	ctx := auth.WithActor(auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID}), "support@example.com")
+	endDate := yearMonth("06-2024")
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      userID,
+		StartDate:   yearMonth("01-2024"),
+		EndDate:     &endDate,
+	}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(true, nil).Once()
+
+	price := 1199
+	_, err := service.UpdateSubscription(ctx, 1, &models.UpdateSubscriptionRequest{Price: &price, EndDate: models.Nullable[string]{Set: true}}, nil)
+
+	assert.NoError(t, err)
+	audit := service.audit.(*recordingAuditLog)
+	assert.Len(t, audit.events, 1)
+	event := audit.events[0]
+	assert.Equal(t, models.AuditActionUpdate, event.Action)
+	assert.Equal(t, uint(1), event.SubscriptionID)
+	assert.Equal(t, userID, event.UserID)
+	assert.Equal(t, "support@example.com", event.Actor)
+	assert.Equal(t, &userID, event.ActorUserID)
+	assert.JSONEq(t, `{"price":{"before":999,"after":1199},"end_date":{"before":"06-2024","after":null}}`, string(event.Changes))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteSubscription_RecordsAuditSnapshot(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:                   1,
+		ServiceName:          "Netflix",
+		Price:                999,
+		Currency:             "RUB",
+		BillingPeriod:        models.BillingPeriodMonth,
+		BillingIntervalCount: 1,
+		UserID:               uuid.New(),
+		StartDate:            yearMonth("01-2024"),
+		Version:              1,
+	}, nil).Once()
+	mockRepo.On("Delete", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1), 1).Return(true, nil).Once()
+
+	assert.NoError(t, service.DeleteSubscription(context.Background(), 1, nil))
+
+	event := service.audit.(*recordingAuditLog).events[0]
+	assert.Equal(t, models.AuditActionDelete, event.Action)
+	assert.Equal(t, models.AuditActorSystem, event.Actor)
+	assert.Nil(t, event.ActorUserID)
+	assert.JSONEq(t, `{
+		"service_name": {"before": "Netflix", "after": null},
+		"price": {"before": 999, "after": null},
+		"currency": {"before": "RUB", "after": null},
+		"billing_period": {"before": "month", "after": null},
+		"billing_interval_count": {"before": 1, "after": null},
+		"start_date": {"before": "01-2024", "after": null}
+	}`, string(event.Changes))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListHistory(t *testing.T) {
+	service, mockRepo, mockSubscriptions := setupAuditService()
+
+	ownerID := uuid.New()
+	owner := auth.WithPrincipal(context.Background(), auth.Principal{UserID: ownerID})
+	stranger := auth.WithPrincipal(context.Background(), auth.Principal{UserID: uuid.New()})
+	mockSubscriptions.On("GetByIDUnscoped", mock.Anything, (*gorm.DB)(nil), uint(1)).Return(trashedSubscription(ownerID, time.Now()), nil)
+	mockSubscriptions.On("GetByIDUnscoped", mock.Anything, (*gorm.DB)(nil), uint(2)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("List", mock.Anything, mock.MatchedBy(func(filter *models.AuditFilter) bool {
+		return *filter.SubscriptionID == 1 && filter.UserID == nil
+	}), 50, 0).Return(nil, nil).Once()
+
+	events, err := service.ListHistory(owner, 1, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.AuditEvent{}, events)
+
+	_, err = service.ListHistory(stranger, 1, 50, 0)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = service.ListHistory(owner, 2, 50, 0)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListEvents(t *testing.T) {
+	userID := uuid.New()
+	user := auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID})
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("scoped to the caller", func(t *testing.T) {
+		service, mockRepo, _ := setupAuditService()
+		mockRepo.On("List", mock.Anything, mock.MatchedBy(func(filter *models.AuditFilter) bool {
+			return *filter.UserID == userID && *filter.Action == models.AuditActionUpdate &&
+				filter.Actor == "support@example.com" && filter.From.Equal(from) && filter.To == nil
+		}), 10, 20).Return([]models.AuditEvent{{ID: 1}}, nil).Once()
+
+		events, err := service.ListEvents(user, &models.AuditFilterRequest{
+			Action: "update",
+			Actor:  "support@example.com",
+			From:   "2025-01-01T00:00:00Z",
+		}, 10, 20)
+
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	otherUser := uuid.New()
+	tests := []struct {
+		name  string
+		req   models.AuditFilterRequest
+		field string
+		kind  error
+	}{
+		{"unknown action", models.AuditFilterRequest{Action: "rename"}, "action", errs.ErrValidation},
+		{"malformed from", models.AuditFilterRequest{From: "2025-01-01"}, "from", errs.ErrValidation},
+		{"empty range", models.AuditFilterRequest{From: "2025-02-01T00:00:00Z", To: "2025-01-01T00:00:00Z"}, "to", errs.ErrValidation},
+		{"another user's events", models.AuditFilterRequest{UserID: &otherUser}, "user_id", errs.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, _ := setupAuditService()
+
+			_, err := service.ListEvents(user, &tt.req, 50, 0)
+
+			assert.ErrorIs(t, err, tt.kind)
+			assert.Equal(t, tt.field, err.(*errs.Error).Field)
+			mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
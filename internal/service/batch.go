@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/infra/database"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ExecuteBatch applies a list of create, update and delete operations with the same rules as
+// the single-item endpoints. In atomic mode every operation runs in one transaction and the
+// first failure is returned, naming the operation, after rolling back the whole batch. In
+// best-effort mode every operation runs in its own transaction and failures are reported in
+// the operation's result.
+func (s *SubscriptionService) ExecuteBatch(ctx context.Context, req *models.BatchRequest) (*models.BatchResponse, error) {
+	mode := req.Mode
+	switch mode {
+	case "":
+		mode = models.BatchModeAtomic
+	case models.BatchModeAtomic, models.BatchModeBestEffort:
+	default:
+		return nil, errs.Validation("mode", errs.CodeInvalidInput, "mode must be one of atomic, best_effort")
+	}
+
+	if len(req.Operations) == 0 {
+		return nil, errs.Validation("operations", errs.CodeRequired, "operations must not be empty")
+	}
+	if len(req.Operations) > s.batchCfg.MaxSize {
+		return nil, errs.Validation("operations", errs.CodeBatchTooLarge, fmt.Sprintf("a batch may contain at most %d operations", s.batchCfg.MaxSize))
+	}
+
+	response := &models.BatchResponse{Mode: mode, Results: make([]models.BatchResult, len(req.Operations))}
+	for i := range req.Operations {
+		response.Results[i] = models.BatchResult{Index: i, Op: req.Operations[i].Op}
+	}
+
+	if mode == models.BatchModeAtomic {
+		err := s.txMgr.Execute(ctx, func(tx database.Transaction) error {
+			gormTx := database.GetDB(tx)
+			for i := range req.Operations {
+				subscription, err := s.applyOperation(ctx, gormTx, &req.Operations[i])
+				if err != nil {
+					return batchOperationError(i, err)
+				}
+				response.Results[i].Subscription = subscription
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		response.Succeeded = len(req.Operations)
+	} else {
+		for i := range req.Operations {
+			result, err := s.txMgr.ExecuteWithResult(ctx, func(tx database.Transaction) (interface{}, error) {
+				return s.applyOperation(ctx, database.GetDB(tx), &req.Operations[i])
+			})
+			if err != nil {
+				response.Results[i].Err = err
+				response.Failed++
+				continue
+			}
+			response.Results[i].Subscription, _ = result.(*models.Subscription)
+			response.Succeeded++
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"mode":      mode,
+		"succeeded": response.Succeeded,
+		"failed":    response.Failed,
+	}).Info("Subscription batch executed successfully")
+
+	return response, nil
+}
+
+// applyOperation runs one batch operation in tx. Deletes return no subscription.
+func (s *SubscriptionService) applyOperation(ctx context.Context, tx *gorm.DB, op *models.BatchOperation) (*models.Subscription, error) {
+	switch op.Op {
+	case models.BatchOpCreate:
+		if op.Create == nil {
+			return nil, errs.Validation("data", errs.CodeRequired, "data is required for create")
+		}
+		subscription, err := s.buildSubscription(ctx, op.Create)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.insertSubscription(ctx, tx, subscription); err != nil {
+			return nil, err
+		}
+		return subscription, nil
+	case models.BatchOpUpdate:
+		if op.ID == 0 {
+			return nil, errs.Validation("id", errs.CodeRequired, "id is required for update")
+		}
+		if op.Update == nil {
+			return nil, errs.Validation("data", errs.CodeRequired, "data is required for update")
+		}
+		return s.modifyTx(ctx, tx, op.ID, op.Precondition(), patchChange(op.Update))
+	case models.BatchOpDelete:
+		if op.ID == 0 {
+			return nil, errs.Validation("id", errs.CodeRequired, "id is required for delete")
+		}
+		return nil, s.deleteTx(ctx, tx, op.ID, op.Precondition())
+	default:
+		return nil, errs.Validation("op", errs.CodeInvalidInput, "op must be one of create, update, delete")
+	}
+}
+
+// batchOperationError names the failed operation in an error of an atomic batch, prefixing
+// its field errors with the operation's position
+func batchOperationError(index int, err error) error {
+	var domainErr *errs.Error
+	if !errors.As(err, &domainErr) {
+		return err
+	}
+
+	prefix := fmt.Sprintf("operations[%d]", index)
+	wrapped := *domainErr
+	wrapped.Message = fmt.Sprintf("operation %d failed: %s", index, domainErr.Message)
+	wrapped.Field = ""
+	wrapped.Details = nil
+	for _, fieldErr := range domainErr.FieldErrors() {
+		if fieldErr.Field == "" {
+			fieldErr.Field = prefix
+		} else {
+			fieldErr.Field = prefix + "." + fieldErr.Field
+		}
+		wrapped.Details = append(wrapped.Details, fieldErr)
+	}
+	if len(wrapped.Details) == 0 {
+		wrapped.Details = []errs.FieldError{{Field: prefix, Code: domainErr.Code, Message: domainErr.Message}}
+	}
+	return &wrapped
+}
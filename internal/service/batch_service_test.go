@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestExecuteBatch_AtomicAppliesAllOperations(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	userID := uuid.New()
+	price := 1199
+	version := 2
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), userID, "Netflix", yearMonth("01-2025")).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Run(func(args mock.Arguments) {
+		args.Get(2).(*models.Subscription).ID = 5
+	}).Return(nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{ID: 1, UserID: userID, ServiceName: "Spotify", Price: 999, StartDate: yearMonth("01-2025"), Version: 2}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.ID == 1 && sub.Price == 1199
+	})).Return(true, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(2)).Return(&models.Subscription{ID: 2, UserID: userID, Version: 1}, nil).Once()
+	mockRepo.On("Delete", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(2), 1).Return(true, nil).Once()
+
+	response, err := service.ExecuteBatch(context.Background(), &models.BatchRequest{Operations: []models.BatchOperation{
+		{Op: models.BatchOpCreate, Create: &models.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 999, UserID: userID, StartDate: "01-2025"}},
+		{Op: models.BatchOpUpdate, ID: 1, Version: &version, Update: &models.UpdateSubscriptionRequest{Price: &price}},
+		{Op: models.BatchOpDelete, ID: 2},
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.BatchModeAtomic, response.Mode)
+	assert.Equal(t, 3, response.Succeeded)
+	assert.Equal(t, uint(5), response.Results[0].Subscription.ID)
+	assert.Equal(t, 1199, response.Results[1].Subscription.Price)
+	assert.Nil(t, response.Results[2].Subscription)
+	mockRepo.AssertExpectations(t)
+	mockTxMgr.AssertExpectations(t)
+}
+
+func TestExecuteBatch_AtomicStopsAtFirstFailure(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	userID := uuid.New()
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), userID, "Netflix", yearMonth("01-2025")).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(nil).Once()
+
+	_, err := service.ExecuteBatch(context.Background(), &models.BatchRequest{Mode: models.BatchModeAtomic, Operations: []models.BatchOperation{
+		{Op: models.BatchOpCreate, Create: &models.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 999, UserID: userID, StartDate: "01-2025"}},
+		{Op: models.BatchOpCreate, Create: &models.CreateSubscriptionRequest{ServiceName: "Spotify", Price: -1, UserID: userID, StartDate: "01-2025"}},
+		{Op: models.BatchOpDelete, ID: 2},
+	}})
+
+	assert.ErrorIs(t, err, errs.ErrValidation)
+	var domainErr *errs.Error
+	assert.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, "operations[1].price", domainErr.FieldErrors()[0].Field)
+	assert.Contains(t, domainErr.Message, "operation 1 failed")
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExecuteBatch_BestEffortReportsFailuresPerOperation(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Times(2)
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(nil, gorm.ErrRecordNotFound).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(2)).Return(&models.Subscription{ID: 2, UserID: uuid.New(), Version: 1}, nil).Once()
+	mockRepo.On("Delete", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(2), 1).Return(true, nil).Once()
+
+	response, err := service.ExecuteBatch(context.Background(), &models.BatchRequest{Mode: models.BatchModeBestEffort, Operations: []models.BatchOperation{
+		{Op: models.BatchOpDelete, ID: 1},
+		{Op: models.BatchOpDelete, ID: 2},
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, response.Succeeded)
+	assert.Equal(t, 1, response.Failed)
+	assert.ErrorIs(t, response.Results[0].Err, errs.ErrNotFound)
+	assert.NoError(t, response.Results[1].Err)
+	mockRepo.AssertExpectations(t)
+	mockTxMgr.AssertExpectations(t)
+}
+
+func TestExecuteBatch_RejectsInvalidBatches(t *testing.T) {
+	service, _, mockTxMgr := setupTestService()
+
+	deletes := func(n int) []models.BatchOperation {
+		ops := make([]models.BatchOperation, n)
+		for i := range ops {
+			ops[i] = models.BatchOperation{Op: models.BatchOpDelete, ID: uint(i + 1)}
+		}
+		return ops
+	}
+
+	tests := []struct {
+		name string
+		req  *models.BatchRequest
+		code string
+	}{
+		{"empty", &models.BatchRequest{}, errs.CodeRequired},
+		{"too large", &models.BatchRequest{Operations: deletes(4)}, errs.CodeBatchTooLarge},
+		{"unknown mode", &models.BatchRequest{Mode: "eventual", Operations: deletes(1)}, errs.CodeInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ExecuteBatch(context.Background(), tt.req)
+
+			var domainErr *errs.Error
+			assert.True(t, errors.As(err, &domainErr))
+			assert.Equal(t, tt.code, domainErr.Code)
+		})
+	}
+	mockTxMgr.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/infra/database"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Bounds of the months reported by a budget status, the current month included. Over-budget
+// events are raised for the default number of months.
+const (
+	DefaultBudgetMonths = 3
+	MaxBudgetMonths     = 12
+)
+
+// BudgetService manages spending budgets and evaluates them against the cost of subscriptions
+type BudgetService struct {
+	repo          repository.BudgetRepositoryInterface
+	subscriptions repository.SubscriptionRepositoryInterface
+	catalog       repository.ServiceCatalogInterface
+	converter     CurrencyConverter
+	events        repository.EventOutboxInterface
+	txMgr         database.TransactionManager
+	logger        *logrus.Logger
+	now           func() time.Time
+}
+
+func NewBudgetService(repo repository.BudgetRepositoryInterface, subscriptions repository.SubscriptionRepositoryInterface, catalog repository.ServiceCatalogInterface, converter CurrencyConverter, events repository.EventOutboxInterface, txMgr database.TransactionManager, logger *logrus.Logger) *BudgetService {
+	return &BudgetService{
+		repo:          repo,
+		subscriptions: subscriptions,
+		catalog:       catalog,
+		converter:     converter,
+		events:        events,
+		txMgr:         txMgr,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+// CreateBudget sets a budget for the requested user, or for the caller when no user_id is given.
+// A user has at most one budget per scope, category and service.
+func (s *BudgetService) CreateBudget(ctx context.Context, req *models.CreateBudgetRequest) (*models.Budget, error) {
+	var requestedUserID *uuid.UUID
+	if req.UserID != uuid.Nil {
+		requestedUserID = &req.UserID
+	}
+	userID, err := scopeToCaller(ctx, requestedUserID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == nil {
+		return nil, errs.Validation("user_id", errs.CodeRequired, "user_id is required")
+	}
+
+	budget := &models.Budget{
+		UserID:   *userID,
+		Scope:    models.BudgetScopeOverall,
+		Currency: DefaultCurrency,
+	}
+	if req.Scope != "" {
+		budget.Scope = models.BudgetScope(req.Scope)
+		if !slices.Contains(models.BudgetScopes, budget.Scope) {
+			return nil, errs.Validation("scope", errs.CodeInvalidInput, "scope must be one of overall, category, service")
+		}
+	}
+	if err := s.setBudgetTarget(ctx, budget, req.Category, req.ServiceName); err != nil {
+		return nil, err
+	}
+	if err := setBudgetLimit(budget, req.MonthlyLimit); err != nil {
+		return nil, err
+	}
+	if req.Currency != "" {
+		if budget.Currency, err = parseCurrency("currency", req.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.txMgr.Execute(ctx, func(tx database.Transaction) error {
+		gormTx := database.GetDB(tx)
+		exists, err := s.repo.Exists(ctx, gormTx, budget)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to check for duplicate budget")
+			return errs.Internal("failed to validate budget uniqueness")
+		}
+		if exists {
+			return errs.Conflict("scope", errs.CodeBudgetExists, "a budget with this scope already exists for this user")
+		}
+		if err := s.repo.Create(ctx, gormTx, budget); err != nil {
+			s.logger.WithError(err).Error("Failed to create budget")
+			return errs.Internal("failed to create budget")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"budget_id": budget.ID,
+		"user_id":   budget.UserID,
+		"scope":     budget.Scope,
+	}).Info("Budget created successfully")
+
+	return budget, nil
+}
+
+// GetBudget retrieves a budget the caller may access
+func (s *BudgetService) GetBudget(ctx context.Context, id uint) (*models.Budget, error) {
+	return s.get(ctx, nil, id)
+}
+
+// ListBudgets retrieves the budgets of a user, restricted to the caller's own budgets for
+// regular users
+func (s *BudgetService) ListBudgets(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error) {
+	userID, err := scopeToCaller(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.repo.List(ctx, userID)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list budgets")
+		return nil, errs.Internal("failed to retrieve budgets")
+	}
+	if budgets == nil {
+		budgets = []models.Budget{}
+	}
+	return budgets, nil
+}
+
+// UpdateBudget changes the limit or currency of a budget
+func (s *BudgetService) UpdateBudget(ctx context.Context, id uint, req *models.UpdateBudgetRequest) (*models.Budget, error) {
+	result, err := s.txMgr.ExecuteWithResult(ctx, func(tx database.Transaction) (interface{}, error) {
+		gormTx := database.GetDB(tx)
+		budget, err := s.get(ctx, gormTx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.MonthlyLimit != nil {
+			if err := setBudgetLimit(budget, *req.MonthlyLimit); err != nil {
+				return nil, err
+			}
+		}
+		if req.Currency != nil {
+			if budget.Currency, err = parseCurrency("currency", *req.Currency); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := s.repo.Update(ctx, gormTx, budget); err != nil {
+			s.logger.WithError(err).WithField("budget_id", id).Error("Failed to update budget")
+			return nil, errs.Internal("failed to update budget")
+		}
+		return budget, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("budget_id", id).Info("Budget updated successfully")
+	return result.(*models.Budget), nil
+}
+
+// DeleteBudget removes a budget the caller may access
+func (s *BudgetService) DeleteBudget(ctx context.Context, id uint) error {
+	err := s.txMgr.Execute(ctx, func(tx database.Transaction) error {
+		gormTx := database.GetDB(tx)
+		if _, err := s.get(ctx, gormTx, id); err != nil {
+			return err
+		}
+		deleted, err := s.repo.Delete(ctx, gormTx, id)
+		if err != nil {
+			s.logger.WithError(err).WithField("budget_id", id).Error("Failed to delete budget")
+			return errs.Internal("failed to delete budget")
+		}
+		if !deleted {
+			return errs.NotFound(errs.CodeBudgetNotFound, "budget not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.WithField("budget_id", id).Info("Budget deleted successfully")
+	return nil
+}
+
+// GetBudgetStatus reports the spend of every budget of a user against its limit in the current
+// month and the months after it
+func (s *BudgetService) GetBudgetStatus(ctx context.Context, userID *uuid.UUID, months int) ([]models.BudgetStatus, error) {
+	if months < 1 || months > MaxBudgetMonths {
+		return nil, errs.Validation("months", errs.CodeInvalidInput, fmt.Sprintf("months must be between 1 and %d", MaxBudgetMonths))
+	}
+
+	budgets, err := s.ListBudgets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := models.YearMonthOf(s.now().UTC())
+	statuses := make([]models.BudgetStatus, 0, len(budgets))
+	for i := range budgets {
+		status := models.BudgetStatus{Budget: budgets[i], Months: make([]models.BudgetMonth, 0, months)}
+		for month := current; month.Before(current.AddMonths(months)); month = month.AddMonths(1) {
+			spent, err := s.spend(ctx, nil, &budgets[i], month)
+			if err != nil {
+				return nil, err
+			}
+			status.Months = append(status.Months, budgetMonth(&budgets[i], month, spent))
+		}
+		statuses = append(statuses, status)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"months":       months,
+		"budget_count": len(statuses),
+	}).Info("Budget status calculated successfully")
+
+	return statuses, nil
+}
+
+// WatchBudgets runs write, a change of subscription within tx, and records a budget.exceeded
+// event in tx for every budget of the subscription's user and month that the change pushes over
+// its limit. Only the current and upcoming months in which the subscription is active are
+// watched. Budgets whose spend cannot be converted into their currency, before or after the
+// change, are skipped so that they never block the write.
+func (s *BudgetService) WatchBudgets(ctx context.Context, tx *gorm.DB, subscription *models.Subscription, write func() error) error {
+	budgets, err := s.repo.ListForSubscription(ctx, tx, subscription)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list budgets of subscription")
+		return errs.Internal("failed to evaluate budgets")
+	}
+	months := s.watchedMonths(subscription)
+	if len(budgets) == 0 || len(months) == 0 {
+		return write()
+	}
+
+	// Spend of every budget and month before the change; budgets that cannot be evaluated are left out
+	before := make(map[uint][]int, len(budgets))
+	for i := range budgets {
+		spent, err := s.spendMonths(ctx, tx, &budgets[i], months)
+		if err != nil {
+			if errors.Is(err, errs.ErrValidation) {
+				s.logger.WithError(err).WithField("budget_id", budgets[i].ID).Warn("Skipping budget that cannot be evaluated")
+				continue
+			}
+			return err
+		}
+		before[budgets[i].ID] = spent
+	}
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	for i := range budgets {
+		budget := &budgets[i]
+		previous, ok := before[budget.ID]
+		if !ok {
+			continue
+		}
+		spent, err := s.spendMonths(ctx, tx, budget, months)
+		if err != nil {
+			// The change may bring in a currency the budget's one cannot be converted from
+			if errors.Is(err, errs.ErrValidation) {
+				s.logger.WithError(err).WithField("budget_id", budget.ID).Warn("Skipping budget that cannot be evaluated")
+				continue
+			}
+			return err
+		}
+		for j, month := range months {
+			if previous[j] > budget.MonthlyLimit || spent[j] <= budget.MonthlyLimit {
+				continue
+			}
+			if err := s.recordAlert(ctx, tx, &models.BudgetAlert{Budget: *budget, Period: month, Spent: spent[j], SubscriptionID: subscription.ID}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// watchedMonths lists the months WatchBudgets evaluates for a subscription
+func (s *BudgetService) watchedMonths(subscription *models.Subscription) []models.YearMonth {
+	current := models.YearMonthOf(s.now().UTC())
+	var months []models.YearMonth
+	for month := current; month.Before(current.AddMonths(DefaultBudgetMonths)); month = month.AddMonths(1) {
+		if month.Before(subscription.StartDate) || subscription.EndDate != nil && month.After(*subscription.EndDate) {
+			continue
+		}
+		months = append(months, month)
+	}
+	return months
+}
+
+// spendMonths evaluates a budget for each of months
+func (s *BudgetService) spendMonths(ctx context.Context, tx *gorm.DB, budget *models.Budget, months []models.YearMonth) ([]int, error) {
+	spent := make([]int, len(months))
+	for i, month := range months {
+		var err error
+		if spent[i], err = s.spend(ctx, tx, budget, month); err != nil {
+			return nil, err
+		}
+	}
+	return spent, nil
+}
+
+// spend is the cost of the subscriptions a budget covers in one month, aggregated like a cost
+// calculation of that month and converted into the budget's currency
+func (s *BudgetService) spend(ctx context.Context, tx *gorm.DB, budget *models.Budget, month models.YearMonth) (int, error) {
+	filter := &models.SubscriptionFilter{UserID: &budget.UserID, Category: budget.Category}
+	if budget.ServiceName != nil {
+		filter.ServiceNames = []string{strings.ToLower(*budget.ServiceName)}
+	}
+
+	totals, err := s.subscriptions.CalculateTotalCostInDB(ctx, tx, filter, month, month)
+	if err != nil {
+		s.logger.WithError(err).WithField("budget_id", budget.ID).Error("Failed to calculate budget spend in database")
+		return 0, errs.Internal("failed to evaluate budget")
+	}
+
+	spent := 0
+	for _, total := range totals {
+		converted, err := s.converter.Convert(ctx, total.Amount, total.Currency, budget.Currency)
+		if err != nil {
+			if errors.Is(err, errs.ErrValidation) {
+				return 0, errs.Validation("", errs.CodeExchangeRateNotFound,
+					fmt.Sprintf("budget %d cannot be evaluated: no exchange rate from %s to %s", budget.ID, total.Currency, budget.Currency))
+			}
+			return 0, err
+		}
+		spent += converted
+	}
+	return spent, nil
+}
+
+// recordAlert writes a budget.exceeded event to the webhook outbox within the caller's transaction
+func (s *BudgetService) recordAlert(ctx context.Context, tx *gorm.DB, alert *models.BudgetAlert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to encode budget event")
+		return errs.Internal("failed to record budget event")
+	}
+
+	event := &models.WebhookEvent{
+		EventType:      models.EventBudgetExceeded,
+		SubscriptionID: alert.SubscriptionID,
+		Payload:        payload,
+	}
+	if err := s.events.RecordEvent(ctx, tx, event); err != nil {
+		s.logger.WithError(err).WithField("budget_id", alert.Budget.ID).Error("Failed to record budget event")
+		return errs.Internal("failed to record budget event")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"budget_id":       alert.Budget.ID,
+		"user_id":         alert.Budget.UserID,
+		"period":          alert.Period.String(),
+		"spent":           alert.Spent,
+		"monthly_limit":   alert.Budget.MonthlyLimit,
+		"subscription_id": alert.SubscriptionID,
+	}).Info("Budget exceeded")
+	return nil
+}
+
+func (s *BudgetService) get(ctx context.Context, tx *gorm.DB, id uint) (*models.Budget, error) {
+	budget, err := s.repo.GetByID(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.NotFound(errs.CodeBudgetNotFound, "budget not found")
+		}
+		s.logger.WithError(err).WithField("budget_id", id).Error("Failed to retrieve budget")
+		return nil, errs.Internal("failed to retrieve budget")
+	}
+
+	// Other users' budgets are reported as missing so their IDs are not disclosed
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.CanAccess(budget.UserID) {
+		return nil, errs.NotFound(errs.CodeBudgetNotFound, "budget not found")
+	}
+	return budget, nil
+}
+
+// setBudgetTarget validates the category or service a budget is scoped to. Service names are
+// resolved through the catalog like those of subscriptions.
+func (s *BudgetService) setBudgetTarget(ctx context.Context, budget *models.Budget, category, serviceName string) error {
+	if budget.Scope != models.BudgetScopeCategory && category != "" {
+		return errs.Validation("category", errs.CodeInvalidInput, "category is only allowed for category budgets")
+	}
+	if budget.Scope != models.BudgetScopeService && serviceName != "" {
+		return errs.Validation("service_name", errs.CodeInvalidInput, "service_name is only allowed for service budgets")
+	}
+
+	switch budget.Scope {
+	case models.BudgetScopeCategory:
+		if category == "" {
+			return errs.Validation("category", errs.CodeRequired, "category is required for category budgets")
+		}
+		parsed := models.ServiceCategory(category)
+		if !slices.Contains(models.ServiceCategories, parsed) {
+			return invalidCategoryError()
+		}
+		budget.Category = &parsed
+	case models.BudgetScopeService:
+		name := models.CleanServiceName(serviceName)
+		if name == "" {
+			return errs.Validation("service_name", errs.CodeRequired, "service_name is required for service budgets")
+		}
+		service, err := s.catalog.ResolveName(ctx, name)
+		if err != nil {
+			s.logger.WithError(err).WithField("service_name", name).Error("Failed to resolve service name")
+			return errs.Internal("failed to resolve service name")
+		}
+		if service != nil {
+			name = service.Name
+		}
+		budget.ServiceName = &name
+	}
+	return nil
+}
+
+// setBudgetLimit validates the monthly limit of a budget
+func setBudgetLimit(budget *models.Budget, limit int) error {
+	if limit <= 0 {
+		return errs.Validation("monthly_limit", errs.CodeInvalidInput, "monthly_limit must be greater than 0")
+	}
+	budget.MonthlyLimit = limit
+	return nil
+}
+
+// budgetMonth compares the spend of a budget in a month with its limit
+func budgetMonth(budget *models.Budget, month models.YearMonth, spent int) models.BudgetMonth {
+	return models.BudgetMonth{
+		Period:     month,
+		Spent:      spent,
+		Remaining:  budget.MonthlyLimit - spent,
+		OverBudget: spent > budget.MonthlyLimit,
+	}
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// MockBudgetRepository for testing budgets
+type MockBudgetRepository struct {
+	mock.Mock
+}
+
+func (m *MockBudgetRepository) Create(ctx context.Context, tx *gorm.DB, budget *models.Budget) error {
+	args := m.Called(ctx, tx, budget)
+	return args.Error(0)
+}
+
+func (m *MockBudgetRepository) GetByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Budget, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetRepository) List(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetRepository) ListForSubscription(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) ([]models.Budget, error) {
+	args := m.Called(ctx, tx, subscription)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Budget), args.Error(1)
+}
+
+func (m *MockBudgetRepository) Update(ctx context.Context, tx *gorm.DB, budget *models.Budget) error {
+	args := m.Called(ctx, tx, budget)
+	return args.Error(0)
+}
+
+func (m *MockBudgetRepository) Delete(ctx context.Context, tx *gorm.DB, id uint) (bool, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBudgetRepository) Exists(ctx context.Context, tx *gorm.DB, budget *models.Budget) (bool, error) {
+	args := m.Called(ctx, tx, budget)
+	return args.Bool(0), args.Error(1)
+}
+
+func setupBudgetService() (*BudgetService, *MockBudgetRepository, *MockSubscriptionRepository, *MockExchangeRateRepository, *MockTransactionManager) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect to test database: " + err.Error())
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	mockRepo := &MockBudgetRepository{}
+	mockSubscriptions := &MockSubscriptionRepository{}
+	mockRatesRepo := &MockExchangeRateRepository{}
+	mockTxMgr := NewMockTransactionManager(db)
+	service := NewBudgetService(mockRepo, mockSubscriptions, &fakeCatalog{}, NewExchangeRateService(mockRatesRepo, logger), &recordingOutbox{}, mockTxMgr, logger)
+	service.now = func() time.Time { return time.Date(2025, time.March, 15, 10, 0, 0, 0, time.UTC) }
+	return service, mockRepo, mockSubscriptions, mockRatesRepo, mockTxMgr
+}
+
+func TestCreateBudget_DefaultsToCallerAndResolvesService(t *testing.T) {
+	service, mockRepo, _, _, mockTxMgr := setupBudgetService()
+	service.catalog = &fakeCatalog{services: []models.Service{{ID: 4, Name: "Netflix", Aliases: []string{"netflix hd"}}}}
+	userID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID})
+
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("Exists", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Budget")).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Budget")).Return(nil).Once()
+
+	budget, err := service.CreateBudget(ctx, &models.CreateBudgetRequest{Scope: "service", ServiceName: " Netflix HD ", MonthlyLimit: 1000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, budget.UserID)
+	assert.Equal(t, "Netflix", *budget.ServiceName)
+	assert.Nil(t, budget.Category)
+	assert.Equal(t, DefaultCurrency, budget.Currency)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateBudget_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   models.CreateBudgetRequest
+		field string
+	}{
+		{"unknown scope", models.CreateBudgetRequest{Scope: "weekly", MonthlyLimit: 1000}, "scope"},
+		{"missing category", models.CreateBudgetRequest{Scope: "category", MonthlyLimit: 1000}, "category"},
+		{"unknown category", models.CreateBudgetRequest{Scope: "category", Category: "movies", MonthlyLimit: 1000}, "category"},
+		{"category of overall budget", models.CreateBudgetRequest{Category: "music", MonthlyLimit: 1000}, "category"},
+		{"missing service", models.CreateBudgetRequest{Scope: "service", ServiceName: " ", MonthlyLimit: 1000}, "service_name"},
+		{"non-positive limit", models.CreateBudgetRequest{}, "monthly_limit"},
+		{"invalid currency", models.CreateBudgetRequest{MonthlyLimit: 1000, Currency: "rubles"}, "currency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, _, _, _ := setupBudgetService()
+			tt.req.UserID = uuid.New()
+
+			_, err := service.CreateBudget(context.Background(), &tt.req)
+
+			var domainErr *errs.Error
+			if assert.ErrorAs(t, err, &domainErr) {
+				assert.True(t, errors.Is(err, errs.ErrValidation))
+				assert.Equal(t, tt.field, domainErr.Field)
+			}
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateBudget_Duplicate(t *testing.T) {
+	service, mockRepo, _, _, mockTxMgr := setupBudgetService()
+
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("Exists", mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Once()
+
+	_, err := service.CreateBudget(context.Background(), &models.CreateBudgetRequest{UserID: uuid.New(), MonthlyLimit: 1000})
+
+	var domainErr *errs.Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.True(t, errors.Is(err, errs.ErrConflict))
+		assert.Equal(t, errs.CodeBudgetExists, domainErr.Code)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetBudget_HidesOtherUsersBudgets(t *testing.T) {
+	service, mockRepo, _, _, _ := setupBudgetService()
+	mockRepo.On("GetByID", mock.Anything, mock.Anything, uint(1)).Return(&models.Budget{ID: 1, UserID: uuid.New()}, nil)
+
+	_, err := service.GetBudget(auth.WithPrincipal(context.Background(), auth.Principal{UserID: uuid.New()}), 1)
+
+	var domainErr *errs.Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.Equal(t, errs.CodeBudgetNotFound, domainErr.Code)
+	}
+}
+
+func TestGetBudgetStatus_ConvertsSpendOfEveryMonth(t *testing.T) {
+	service, mockRepo, mockSubscriptions, mockRatesRepo, _ := setupBudgetService()
+	userID := uuid.New()
+	streaming := models.ServiceCategoryStreaming
+	budget := models.Budget{ID: 1, UserID: userID, Scope: models.BudgetScopeCategory, Category: &streaming, MonthlyLimit: 2000, Currency: "RUB"}
+
+	mockRepo.On("List", mock.Anything, &userID).Return([]models.Budget{budget}, nil).Once()
+	filter := &models.SubscriptionFilter{UserID: &userID, Category: &streaming}
+	mockSubscriptions.On("CalculateTotalCostInDB", mock.Anything, (*gorm.DB)(nil), filter, yearMonth("03-2025"), yearMonth("03-2025")).Return([]models.CurrencyAmount{{Currency: "RUB", Amount: 999}, {Currency: "USD", Amount: 10}}, nil).Once()
+	mockSubscriptions.On("CalculateTotalCostInDB", mock.Anything, (*gorm.DB)(nil), filter, yearMonth("04-2025"), yearMonth("04-2025")).Return([]models.CurrencyAmount{{Currency: "RUB", Amount: 999}}, nil).Once()
+	mockRatesRepo.On("Get", mock.Anything, "USD", "RUB").Return(&models.ExchangeRate{FromCurrency: "USD", ToCurrency: "RUB", Rate: 90}, nil).Once()
+
+	statuses, err := service.GetBudgetStatus(context.Background(), &userID, 2)
+
+	assert.NoError(t, err)
+	if assert.Len(t, statuses, 1) {
+		assert.Equal(t, []models.BudgetMonth{
+			{Period: yearMonth("03-2025"), Spent: 1899, Remaining: 101},
+			{Period: yearMonth("04-2025"), Spent: 999, Remaining: 1001},
+		}, statuses[0].Months)
+	}
+
+	_, err = service.GetBudgetStatus(context.Background(), &userID, MaxBudgetMonths+1)
+	var domainErr *errs.Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.Equal(t, "months", domainErr.Field)
+	}
+	mockSubscriptions.AssertExpectations(t)
+}
+
+func TestWatchBudgets_RecordsMonthsPushedOverLimit(t *testing.T) {
+	service, mockRepo, mockSubscriptions, _, _ := setupBudgetService()
+	userID := uuid.New()
+	budget := models.Budget{ID: 1, UserID: userID, Scope: models.BudgetScopeOverall, MonthlyLimit: 2000, Currency: "RUB"}
+	subscription := &models.Subscription{ID: 7, UserID: userID, ServiceName: "Netflix", Price: 1500, Currency: "RUB", StartDate: yearMonth("04-2025")}
+
+	mockRepo.On("ListForSubscription", mock.Anything, mock.Anything, subscription).Return([]models.Budget{budget}, nil).Once()
+	spend := func(month string, amount int) *mock.Call {
+		return mockSubscriptions.On("CalculateTotalCostInDB", mock.Anything, mock.Anything, mock.Anything, yearMonth(month), yearMonth(month)).
+			Return([]models.CurrencyAmount{{Currency: "RUB", Amount: amount}}, nil).Once()
+	}
+	// Before the write April is within the limit and May already over it
+	spend("04-2025", 1000)
+	spend("05-2025", 2500)
+	written := false
+	spend("04-2025", 2500)
+	spend("05-2025", 4000)
+
+	err := service.WatchBudgets(context.Background(), nil, subscription, func() error {
+		written = true
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, written)
+	events := service.events.(*recordingOutbox).events
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, models.EventBudgetExceeded, events[0].EventType)
+		assert.Equal(t, uint(7), events[0].SubscriptionID)
+		var alert models.BudgetAlert
+		assert.NoError(t, json.Unmarshal(events[0].Payload, &alert))
+		assert.Equal(t, yearMonth("04-2025"), alert.Period)
+		assert.Equal(t, 2500, alert.Spent)
+		assert.Equal(t, uint(1), alert.Budget.ID)
+	}
+	mockSubscriptions.AssertExpectations(t)
+}
+
+func TestWatchBudgets_SkipsBudgetsWithoutRateAfterWrite(t *testing.T) {
+	service, mockRepo, mockSubscriptions, mockRatesRepo, _ := setupBudgetService()
+	userID := uuid.New()
+	budget := models.Budget{ID: 1, UserID: userID, Scope: models.BudgetScopeOverall, MonthlyLimit: 2000, Currency: "RUB"}
+	endDate := yearMonth("03-2025")
+	subscription := &models.Subscription{ID: 7, UserID: userID, ServiceName: "Netflix", Price: 30, Currency: "USD", StartDate: yearMonth("03-2025"), EndDate: &endDate}
+
+	mockRepo.On("ListForSubscription", mock.Anything, mock.Anything, subscription).Return([]models.Budget{budget}, nil).Once()
+	mockSubscriptions.On("CalculateTotalCostInDB", mock.Anything, mock.Anything, mock.Anything, yearMonth("03-2025"), yearMonth("03-2025")).
+		Return([]models.CurrencyAmount{{Currency: "RUB", Amount: 1000}}, nil).Once()
+	// The new USD subscription cannot be converted into the budget's RUB
+	mockSubscriptions.On("CalculateTotalCostInDB", mock.Anything, mock.Anything, mock.Anything, yearMonth("03-2025"), yearMonth("03-2025")).
+		Return([]models.CurrencyAmount{{Currency: "RUB", Amount: 1000}, {Currency: "USD", Amount: 30}}, nil).Once()
+	mockRatesRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return((*models.ExchangeRate)(nil), nil)
+
+	written := false
+	err := service.WatchBudgets(context.Background(), nil, subscription, func() error {
+		written = true
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, written)
+	assert.Empty(t, service.events.(*recordingOutbox).events)
+	mockSubscriptions.AssertExpectations(t)
+}
+
+func TestGetBudgetStatus_MissingRate(t *testing.T) {
+	service, mockRepo, mockSubscriptions, mockRatesRepo, _ := setupBudgetService()
+	userID := uuid.New()
+
+	mockRepo.On("List", mock.Anything, &userID).Return([]models.Budget{{ID: 3, UserID: userID, Scope: models.BudgetScopeOverall, MonthlyLimit: 2000, Currency: "RUB"}}, nil).Once()
+	mockSubscriptions.On("CalculateTotalCostInDB", mock.Anything, (*gorm.DB)(nil), mock.Anything, yearMonth("03-2025"), yearMonth("03-2025")).
+		Return([]models.CurrencyAmount{{Currency: "USD", Amount: 30}}, nil).Once()
+	mockRatesRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return((*models.ExchangeRate)(nil), nil)
+
+	_, err := service.GetBudgetStatus(context.Background(), &userID, 1)
+
+	var domainErr *errs.Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.True(t, errors.Is(err, errs.ErrValidation))
+		assert.Equal(t, errs.CodeExchangeRateNotFound, domainErr.Code)
+		assert.Empty(t, domainErr.Field)
+		assert.Contains(t, domainErr.Message, "budget 3")
+	}
+}
+
+func TestWatchBudgets_WritesWithoutBudgets(t *testing.T) {
+	service, mockRepo, mockSubscriptions, _, _ := setupBudgetService()
+	subscription := &models.Subscription{UserID: uuid.New(), ServiceName: "Netflix", StartDate: yearMonth("01-2025")}
+	mockRepo.On("ListForSubscription", mock.Anything, mock.Anything, subscription).Return([]models.Budget{}, nil).Once()
+
+	writeErr := errs.Internal("failed to create subscription")
+	err := service.WatchBudgets(context.Background(), nil, subscription, func() error { return writeErr })
+
+	assert.Equal(t, writeErr, err)
+	mockSubscriptions.AssertNotCalled(t, "CalculateTotalCostInDB", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateSubscription_WatchesBudgetsOnPriceChange(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+	watcher := &recordingBudgetWatcher{}
+	service.budgets = watcher
+
+	current := &models.Subscription{ID: 1, ServiceName: "Netflix", Price: 999, UserID: uuid.New(), StartDate: yearMonth("01-2025")}
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Twice()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(current, nil).Twice()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(true, nil).Twice()
+
+	end := "06-2025"
+	_, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{EndDate: models.Nullable[string]{Set: true, Value: &end}}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, watcher.prices)
+
+	price := 1199
+	_, err = service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{Price: &price}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1199}, watcher.prices)
+	mockRepo.AssertExpectations(t)
+}
+
+// recordingBudgetWatcher runs subscription changes and collects the prices they were watched at
+type recordingBudgetWatcher struct {
+	prices []int
+}
+
+func (w *recordingBudgetWatcher) WatchBudgets(ctx context.Context, tx *gorm.DB, subscription *models.Subscription, write func() error) error {
+	w.prices = append(w.prices, subscription.Price)
+	return write()
+}
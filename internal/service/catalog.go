@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/infra/database"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// maxServiceNameLength is the size of the name and alias columns of the catalog
+const maxServiceNameLength = 255
+
+// CatalogService manages the service catalog
+type CatalogService struct {
+	repo   repository.ServiceRepositoryInterface
+	txMgr  database.TransactionManager
+	logger *logrus.Logger
+}
+
+func NewCatalogService(repo repository.ServiceRepositoryInterface, txMgr database.TransactionManager, logger *logrus.Logger) *CatalogService {
+	return &CatalogService{
+		repo:   repo,
+		txMgr:  txMgr,
+		logger: logger,
+	}
+}
+
+// CreateService adds a service to the catalog. Its name and aliases must not resolve to another
+// service yet.
+func (s *CatalogService) CreateService(ctx context.Context, req *models.CreateServiceRequest) (*models.Service, error) {
+	service := &models.Service{
+		Category: models.ServiceCategoryOther,
+		Currency: DefaultCurrency,
+	}
+	if err := setServiceName(service, req.Name); err != nil {
+		return nil, err
+	}
+	if err := setServiceAliases(service, req.Aliases); err != nil {
+		return nil, err
+	}
+	if req.Category != "" {
+		if err := setServiceCategory(service, req.Category); err != nil {
+			return nil, err
+		}
+	}
+	if err := setServiceDefaultPrice(service, req.DefaultPrice); err != nil {
+		return nil, err
+	}
+	if req.Currency != "" {
+		currency, err := parseCurrency("currency", req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		service.Currency = currency
+	}
+
+	err := s.txMgr.Execute(ctx, func(tx database.Transaction) error {
+		gormTx := database.GetDB(tx)
+		if err := s.checkAliases(ctx, gormTx, service); err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, gormTx, service); err != nil {
+			s.logger.WithError(err).Error("Failed to create catalog service")
+			return errs.Internal("failed to create service")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"service_id": service.ID,
+		"name":       service.Name,
+		"aliases":    service.Aliases,
+		"category":   service.Category,
+	}).Info("Catalog service created successfully")
+
+	return service, nil
+}
+
+// GetService retrieves a catalogued service by ID
+func (s *CatalogService) GetService(ctx context.Context, id uint) (*models.Service, error) {
+	return s.get(ctx, nil, id)
+}
+
+// ListServices retrieves the catalogued services ordered by name, optionally only those of a
+// category
+func (s *CatalogService) ListServices(ctx context.Context, category string) ([]models.Service, error) {
+	var categoryFilter *models.ServiceCategory
+	if category != "" {
+		parsed := models.ServiceCategory(category)
+		if !slices.Contains(models.ServiceCategories, parsed) {
+			return nil, invalidCategoryError()
+		}
+		categoryFilter = &parsed
+	}
+
+	services, err := s.repo.List(ctx, categoryFilter)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list catalog services")
+		return nil, errs.Internal("failed to retrieve services")
+	}
+	if services == nil {
+		services = []models.Service{}
+	}
+	return services, nil
+}
+
+// UpdateService changes a catalogued service. A new aliases list replaces the old one.
+// Subscriptions linked to the service keep the name they were created with.
+func (s *CatalogService) UpdateService(ctx context.Context, id uint, req *models.UpdateServiceRequest) (*models.Service, error) {
+	result, err := s.txMgr.ExecuteWithResult(ctx, func(tx database.Transaction) (interface{}, error) {
+		gormTx := database.GetDB(tx)
+		service, err := s.get(ctx, gormTx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.Name != nil {
+			if err := setServiceName(service, *req.Name); err != nil {
+				return nil, err
+			}
+		}
+		if req.Aliases != nil {
+			if err := setServiceAliases(service, *req.Aliases); err != nil {
+				return nil, err
+			}
+		} else if err := setServiceAliases(service, service.Aliases); err != nil {
+			// The new name may equal one of the kept aliases
+			return nil, err
+		}
+		if req.Category != nil {
+			if err := setServiceCategory(service, *req.Category); err != nil {
+				return nil, err
+			}
+		}
+		if req.DefaultPrice.Set {
+			if err := setServiceDefaultPrice(service, req.DefaultPrice.Value); err != nil {
+				return nil, err
+			}
+		}
+		if req.Currency != nil {
+			currency, err := parseCurrency("currency", *req.Currency)
+			if err != nil {
+				return nil, err
+			}
+			service.Currency = currency
+		}
+
+		if err := s.checkAliases(ctx, gormTx, service); err != nil {
+			return nil, err
+		}
+		if err := s.repo.Update(ctx, gormTx, service); err != nil {
+			s.logger.WithError(err).WithField("service_id", id).Error("Failed to update catalog service")
+			return nil, errs.Internal("failed to update service")
+		}
+		return service, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("service_id", id).Info("Catalog service updated successfully")
+	return result.(*models.Service), nil
+}
+
+// DeleteService removes a service from the catalog. Subscriptions linked to it keep their name
+// and lose the link.
+func (s *CatalogService) DeleteService(ctx context.Context, id uint) error {
+	err := s.txMgr.Execute(ctx, func(tx database.Transaction) error {
+		deleted, err := s.repo.Delete(ctx, database.GetDB(tx), id)
+		if err != nil {
+			s.logger.WithError(err).WithField("service_id", id).Error("Failed to delete catalog service")
+			return errs.Internal("failed to delete service")
+		}
+		if !deleted {
+			return errs.NotFound(errs.CodeServiceNotFound, "service not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.WithField("service_id", id).Info("Catalog service deleted successfully")
+	return nil
+}
+
+func (s *CatalogService) get(ctx context.Context, tx *gorm.DB, id uint) (*models.Service, error) {
+	service, err := s.repo.GetByID(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.NotFound(errs.CodeServiceNotFound, "service not found")
+		}
+		s.logger.WithError(err).WithField("service_id", id).Error("Failed to retrieve catalog service")
+		return nil, errs.Internal("failed to retrieve service")
+	}
+	return service, nil
+}
+
+// checkAliases enforces that the name and aliases of a service do not resolve to another one
+func (s *CatalogService) checkAliases(ctx context.Context, tx *gorm.DB, service *models.Service) error {
+	names := append([]string{models.NormalizeServiceName(service.Name)}, service.Aliases...)
+	taken, err := s.repo.TakenAliases(ctx, tx, names, service.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to check catalog aliases")
+		return errs.Internal("failed to validate service uniqueness")
+	}
+	if len(taken) == 0 {
+		return nil
+	}
+
+	field, alias := "aliases", taken[0]
+	if slices.Contains(taken, names[0]) {
+		field, alias = "name", names[0]
+	}
+	return errs.Conflict(field, errs.CodeServiceExists, fmt.Sprintf("%q already belongs to another service", alias))
+}
+
+// setServiceName validates and cleans the canonical name of a service
+func setServiceName(service *models.Service, name string) error {
+	cleaned := models.CleanServiceName(name)
+	if cleaned == "" {
+		return errs.Validation("name", errs.CodeRequired, "name is required")
+	}
+	if len(cleaned) > maxServiceNameLength {
+		return errs.Validation("name", errs.CodeInvalidInput, fmt.Sprintf("name must be at most %d characters", maxServiceNameLength))
+	}
+	service.Name = cleaned
+	return nil
+}
+
+// setServiceAliases normalizes the aliases of a service, dropping duplicates and the alias of
+// its canonical name
+func setServiceAliases(service *models.Service, aliases []string) error {
+	nameAlias := models.NormalizeServiceName(service.Name)
+	normalized := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = models.NormalizeServiceName(alias)
+		if alias == "" {
+			return errs.Validation("aliases", errs.CodeInvalidInput, "aliases cannot be empty")
+		}
+		if len(alias) > maxServiceNameLength {
+			return errs.Validation("aliases", errs.CodeInvalidInput, fmt.Sprintf("aliases must be at most %d characters", maxServiceNameLength))
+		}
+		if alias != nameAlias && !slices.Contains(normalized, alias) {
+			normalized = append(normalized, alias)
+		}
+	}
+	slices.Sort(normalized)
+	service.Aliases = normalized
+	return nil
+}
+
+// setServiceCategory validates the category of a service
+func setServiceCategory(service *models.Service, category string) error {
+	parsed := models.ServiceCategory(category)
+	if !slices.Contains(models.ServiceCategories, parsed) {
+		return invalidCategoryError()
+	}
+	service.Category = parsed
+	return nil
+}
+
+// setServiceDefaultPrice validates the default price of a service; nil clears it
+func setServiceDefaultPrice(service *models.Service, price *int) error {
+	if price != nil && *price <= 0 {
+		return errs.Validation("default_price", errs.CodeInvalidPrice, "default_price must be greater than 0")
+	}
+	service.DefaultPrice = price
+	return nil
+}
+
+func invalidCategoryError() error {
+	names := make([]string, len(models.ServiceCategories))
+	for i, category := range models.ServiceCategories {
+		names[i] = string(category)
+	}
+	return errs.Validation("category", errs.CodeInvalidInput, "category must be one of "+strings.Join(names, ", "))
+}
+
+// resolveServiceName looks a subscription's service name up in the catalog. It returns the name
+// to store, which is the canonical name of a catalogued service and otherwise the name cleaned
+// of extra whitespace, together with the catalogued service or nil.
+func (s *SubscriptionService) resolveServiceName(ctx context.Context, name string) (string, *models.Service, error) {
+	cleaned := models.CleanServiceName(name)
+	if cleaned == "" {
+		return "", nil, nil
+	}
+	service, err := s.catalog.ResolveName(ctx, cleaned)
+	if err != nil {
+		s.logger.WithError(err).WithField("service_name", cleaned).Error("Failed to resolve service name")
+		return "", nil, errs.Internal("failed to resolve service name")
+	}
+	if service == nil {
+		return cleaned, nil, nil
+	}
+	return service.Name, service, nil
+}
+
+// applyCatalog resolves the service name of a create request and fills in the catalogued
+// default price when the request omits one, together with the service's currency unless the
+// request names one. A price sent by the caller keeps the request's own currency. It returns
+// the ID of the catalogued service, or nil.
+func (s *SubscriptionService) applyCatalog(ctx context.Context, req *models.CreateSubscriptionRequest) (*uint, error) {
+	name, service, err := s.resolveServiceName(ctx, req.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	req.ServiceName = name
+	if service == nil {
+		return nil, nil
+	}
+
+	if req.Price == 0 && service.DefaultPrice != nil {
+		req.Price = *service.DefaultPrice
+		if req.Currency == "" {
+			req.Currency = service.Currency
+		}
+	}
+	return &service.ID, nil
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// MockServiceRepository for testing the service catalog
+type MockServiceRepository struct {
+	mock.Mock
+}
+
+func (m *MockServiceRepository) ResolveName(ctx context.Context, name string) (*models.Service, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Service), args.Error(1)
+}
+
+func (m *MockServiceRepository) Create(ctx context.Context, tx *gorm.DB, service *models.Service) error {
+	args := m.Called(ctx, tx, service)
+	return args.Error(0)
+}
+
+func (m *MockServiceRepository) GetByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Service, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Service), args.Error(1)
+}
+
+func (m *MockServiceRepository) List(ctx context.Context, category *models.ServiceCategory) ([]models.Service, error) {
+	args := m.Called(ctx, category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Service), args.Error(1)
+}
+
+func (m *MockServiceRepository) Update(ctx context.Context, tx *gorm.DB, service *models.Service) error {
+	args := m.Called(ctx, tx, service)
+	return args.Error(0)
+}
+
+func (m *MockServiceRepository) Delete(ctx context.Context, tx *gorm.DB, id uint) (bool, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockServiceRepository) TakenAliases(ctx context.Context, tx *gorm.DB, aliases []string, excludeID uint) ([]string, error) {
+	args := m.Called(ctx, tx, aliases, excludeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func setupCatalogService() (*CatalogService, *MockServiceRepository, *MockTransactionManager) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect to test database: " + err.Error())
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	mockRepo := &MockServiceRepository{}
+	mockTxMgr := NewMockTransactionManager(db)
+	return NewCatalogService(mockRepo, mockTxMgr, logger), mockRepo, mockTxMgr
+}
+
+func TestCreateService_NormalizesNameAndAliases(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupCatalogService()
+
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("TakenAliases", mock.Anything, mock.AnythingOfType("*gorm.DB"), []string{"netflix", "netflix hd"}, uint(0)).Return([]string{}, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Service")).Return(nil).Once()
+
+	created, err := service.CreateService(context.Background(), &models.CreateServiceRequest{
+		Name:    "  Netflix ",
+		Aliases: []string{"NETFLIX", "Netflix  HD", "netflix hd"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Netflix", created.Name)
+	assert.Equal(t, []string{"netflix hd"}, created.Aliases)
+	assert.Equal(t, models.ServiceCategoryOther, created.Category)
+	assert.Equal(t, DefaultCurrency, created.Currency)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateService_ValidationErrors(t *testing.T) {
+	price := 0
+	tests := []struct {
+		name  string
+		req   models.CreateServiceRequest
+		field string
+	}{
+		{"missing name", models.CreateServiceRequest{Name: "  "}, "name"},
+		{"empty alias", models.CreateServiceRequest{Name: "Netflix", Aliases: []string{" "}}, "aliases"},
+		{"unknown category", models.CreateServiceRequest{Name: "Netflix", Category: "movies"}, "category"},
+		{"non-positive default price", models.CreateServiceRequest{Name: "Netflix", DefaultPrice: &price}, "default_price"},
+		{"invalid currency", models.CreateServiceRequest{Name: "Netflix", Currency: "rubles"}, "currency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, _ := setupCatalogService()
+
+			_, err := service.CreateService(context.Background(), &tt.req)
+
+			var domainErr *errs.Error
+			if assert.ErrorAs(t, err, &domainErr) {
+				assert.True(t, errors.Is(err, errs.ErrValidation))
+				assert.Equal(t, tt.field, domainErr.Field)
+			}
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateService_ConflictingNameOrAlias(t *testing.T) {
+	tests := []struct {
+		name  string
+		taken []string
+		field string
+	}{
+		{"name", []string{"netflix"}, "name"},
+		{"alias", []string{"netflix hd"}, "aliases"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, mockTxMgr := setupCatalogService()
+
+			mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+			mockRepo.On("TakenAliases", mock.Anything, mock.Anything, mock.Anything, uint(0)).Return(tt.taken, nil).Once()
+
+			_, err := service.CreateService(context.Background(), &models.CreateServiceRequest{Name: "Netflix", Aliases: []string{"Netflix HD"}})
+
+			var domainErr *errs.Error
+			if assert.ErrorAs(t, err, &domainErr) {
+				assert.True(t, errors.Is(err, errs.ErrConflict))
+				assert.Equal(t, errs.CodeServiceExists, domainErr.Code)
+				assert.Equal(t, tt.field, domainErr.Field)
+			}
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateService_RenameKeepsAliases(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupCatalogService()
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Service{
+		ID:       1,
+		Name:     "YouTube Premium",
+		Aliases:  []string{"youtube", "yt premium"},
+		Category: models.ServiceCategoryVideo,
+		Currency: "RUB",
+	}, nil).Once()
+	mockRepo.On("TakenAliases", mock.Anything, mock.Anything, []string{"youtube", "yt premium"}, uint(1)).Return([]string{}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Service")).Return(nil).Once()
+
+	name, price := "YouTube", 299
+	updated, err := service.UpdateService(context.Background(), 1, &models.UpdateServiceRequest{
+		Name:         &name,
+		DefaultPrice: models.Nullable[int]{Set: true, Value: &price},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "YouTube", updated.Name)
+	// The alias equal to the new name is dropped
+	assert.Equal(t, []string{"yt premium"}, updated.Aliases)
+	assert.Equal(t, &price, updated.DefaultPrice)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteService_NotFound(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupCatalogService()
+
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("Delete", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(9)).Return(false, nil).Once()
+
+	err := service.DeleteService(context.Background(), 9)
+
+	var domainErr *errs.Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.Equal(t, errs.CodeServiceNotFound, domainErr.Code)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateSubscription_ResolvesCatalogAlias(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+	price := 999
+	service.catalog = &fakeCatalog{services: []models.Service{{
+		ID:           4,
+		Name:         "Netflix",
+		Aliases:      []string{"netflix hd"},
+		DefaultPrice: &price,
+		Currency:     "USD",
+	}}}
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.Anything, mock.Anything, "Netflix", mock.Anything).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(nil).Once()
+
+	created, err := service.CreateSubscription(context.Background(), &models.CreateSubscriptionRequest{
+		ServiceName: " NETFLIX  hd",
+		UserID:      uuid.New(),
+		StartDate:   "01-2025",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Netflix", created.ServiceName)
+	assert.Equal(t, uint(4), *created.ServiceID)
+	assert.Equal(t, 999, created.Price)
+	assert.Equal(t, "USD", created.Currency)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSubscription_RenameResolvesCatalog(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+	service.now = func() time.Time { return time.Date(2025, time.March, 15, 10, 0, 0, 0, time.UTC) }
+	service.catalog = &fakeCatalog{services: []models.Service{{ID: 4, Name: "Spotify", Aliases: []string{"spotify premium"}}}}
+
+	catalogued := uint(2)
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		ServiceID:   &catalogued,
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   yearMonth("01-2025"),
+	}, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(true, nil).Once()
+
+	name := "spotify PREMIUM"
+	updated, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{ServiceName: &name}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Spotify", updated.ServiceName)
+	assert.Equal(t, uint(4), *updated.ServiceID)
+
+	// Names missing from the catalog are kept cleaned and unlinked
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(updated, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(true, nil).Once()
+
+	name = " Apple   Music "
+	updated, err = service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{ServiceName: &name}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Apple Music", updated.ServiceName)
+	assert.Nil(t, updated.ServiceID)
+}
+
+func TestCreateSubscription_OwnPriceKeepsRequestCurrency(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+	price := 999
+	service.catalog = &fakeCatalog{services: []models.Service{{ID: 4, Name: "Netflix", DefaultPrice: &price, Currency: "USD"}}}
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.Anything, mock.Anything, "Netflix", mock.Anything).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(nil).Once()
+
+	created, err := service.CreateSubscription(context.Background(), &models.CreateSubscriptionRequest{
+		ServiceName: "netflix",
+		Price:       599,
+		UserID:      uuid.New(),
+		StartDate:   "01-2025",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), *created.ServiceID)
+	assert.Equal(t, 599, created.Price)
+	assert.Equal(t, DefaultCurrency, created.Currency)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReplaceSubscription_IgnoresCatalogDefaults(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+	price := 999
+	service.catalog = &fakeCatalog{services: []models.Service{{ID: 4, Name: "Netflix", DefaultPrice: &price, Currency: "USD"}}}
+
+	catalogued := uint(4)
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		ServiceID:   &catalogued,
+		Price:       999,
+		Currency:    "USD",
+		UserID:      uuid.New(),
+		StartDate:   yearMonth("01-2025"),
+	}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(true, nil).Once()
+
+	// The omitted currency is reset to the default, not taken from the catalog
+	replaced, err := service.ReplaceSubscription(context.Background(), 1, &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       1299,
+		StartDate:   "01-2025",
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1299, replaced.Price)
+	assert.Equal(t, DefaultCurrency, replaced.Currency)
+	assert.Equal(t, uint(4), *replaced.ServiceID)
+
+	// A replacement without a price is rejected instead of taking the default price
+	_, err = service.ReplaceSubscription(context.Background(), 1, &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		StartDate:   "01-2025",
+	}, nil)
+
+	var domainErr *errs.Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.Equal(t, "price", domainErr.FieldErrors()[0].Field)
+	}
+	mockRepo.AssertExpectations(t)
+}
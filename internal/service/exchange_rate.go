@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/currency"
+)
+
+// DefaultCurrency is used for subscriptions created without an explicit currency
+const DefaultCurrency = "RUB"
+
+type ExchangeRateService struct {
+	repo   repository.ExchangeRateRepositoryInterface
+	logger *logrus.Logger
+}
+
+func NewExchangeRateService(repo repository.ExchangeRateRepositoryInterface, logger *logrus.Logger) *ExchangeRateService {
+	return &ExchangeRateService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ListRates retrieves every stored exchange rate
+func (s *ExchangeRateService) ListRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	rates, err := s.repo.List(ctx)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list exchange rates")
+		return nil, errs.Internal("failed to retrieve exchange rates")
+	}
+	return rates, nil
+}
+
+// SetRate creates or replaces the rate converting fromCurrency into toCurrency
+func (s *ExchangeRateService) SetRate(ctx context.Context, fromCurrency, toCurrency string, rate float64) (*models.ExchangeRate, error) {
+	from, to, err := parseCurrencyPair(fromCurrency, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, errs.Validation("to_currency", errs.CodeInvalidCurrency, "from_currency and to_currency must differ")
+	}
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return nil, errs.Validation("rate", errs.CodeInvalidRate, "rate must be greater than 0")
+	}
+
+	exchangeRate := &models.ExchangeRate{FromCurrency: from, ToCurrency: to, Rate: rate}
+	if err := s.repo.Upsert(ctx, exchangeRate); err != nil {
+		s.logger.WithError(err).Error("Failed to store exchange rate")
+		return nil, errs.Internal("failed to store exchange rate")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"from_currency": from,
+		"to_currency":   to,
+		"rate":          rate,
+	}).Info("Exchange rate stored successfully")
+
+	return exchangeRate, nil
+}
+
+// DeleteRate removes the rate converting fromCurrency into toCurrency
+func (s *ExchangeRateService) DeleteRate(ctx context.Context, fromCurrency, toCurrency string) error {
+	from, to, err := parseCurrencyPair(fromCurrency, toCurrency)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, from, to)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to delete exchange rate")
+		return errs.Internal("failed to delete exchange rate")
+	}
+	if !deleted {
+		return errs.NotFound(errs.CodeExchangeRateNotFound, "exchange rate not found")
+	}
+	return nil
+}
+
+// Convert converts an amount between currencies using the stored rates. A missing
+// direct rate falls back to the inverse of the opposite pair. Results are rounded.
+func (s *ExchangeRateService) Convert(ctx context.Context, amount int, fromCurrency, toCurrency string) (int, error) {
+	if fromCurrency == toCurrency {
+		return amount, nil
+	}
+
+	rate, err := s.repo.Get(ctx, fromCurrency, toCurrency)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to load exchange rate")
+		return 0, errs.Internal("failed to load exchange rate")
+	}
+	if rate != nil {
+		return int(math.Round(float64(amount) * rate.Rate)), nil
+	}
+
+	inverse, err := s.repo.Get(ctx, toCurrency, fromCurrency)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to load exchange rate")
+		return 0, errs.Internal("failed to load exchange rate")
+	}
+	if inverse != nil {
+		return int(math.Round(float64(amount) / inverse.Rate)), nil
+	}
+
+	return 0, errs.Validation("target_currency", errs.CodeExchangeRateNotFound,
+		fmt.Sprintf("no exchange rate from %s to %s", fromCurrency, toCurrency))
+}
+
+// parseCurrency validates an ISO-4217 currency code and returns it in canonical upper case
+func parseCurrency(field, value string) (string, error) {
+	unit, err := currency.ParseISO(strings.TrimSpace(value))
+	if err != nil {
+		return "", errs.Validation(field, errs.CodeInvalidCurrency, field+" must be an ISO-4217 currency code")
+	}
+	return unit.String(), nil
+}
+
+func parseCurrencyPair(fromCurrency, toCurrency string) (string, string, error) {
+	from, err := parseCurrency("from_currency", fromCurrency)
+	if err != nil {
+		return "", "", err
+	}
+	to, err := parseCurrency("to_currency", toCurrency)
+	if err != nil {
+		return "", "", err
+	}
+	return from, to, nil
+}
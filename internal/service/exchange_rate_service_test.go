@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExchangeRateRepository for testing currency conversion
+type MockExchangeRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRateRepository) List(ctx context.Context) ([]models.ExchangeRate, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRateRepository) Get(ctx context.Context, fromCurrency, toCurrency string) (*models.ExchangeRate, error) {
+	args := m.Called(ctx, fromCurrency, toCurrency)
+	return args.Get(0).(*models.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRateRepository) Upsert(ctx context.Context, rate *models.ExchangeRate) error {
+	args := m.Called(ctx, rate)
+	return args.Error(0)
+}
+
+func (m *MockExchangeRateRepository) Delete(ctx context.Context, fromCurrency, toCurrency string) (bool, error) {
+	args := m.Called(ctx, fromCurrency, toCurrency)
+	return args.Bool(0), args.Error(1)
+}
+
+func setupExchangeRateService() (*ExchangeRateService, *MockExchangeRateRepository) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	mockRepo := &MockExchangeRateRepository{}
+	return NewExchangeRateService(mockRepo, logger), mockRepo
+}
+
+func TestSetRate_NormalizesCurrencies(t *testing.T) {
+	service, mockRepo := setupExchangeRateService()
+
+	mockRepo.On("Upsert", mock.Anything, &models.ExchangeRate{FromCurrency: "USD", ToCurrency: "RUB", Rate: 92.5}).Return(nil)
+
+	rate, err := service.SetRate(context.Background(), "usd", "rub", 92.5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", rate.FromCurrency)
+	assert.Equal(t, "RUB", rate.ToCurrency)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetRate_ValidationErrors(t *testing.T) {
+	service, _ := setupExchangeRateService()
+
+	testCases := []struct {
+		name          string
+		from, to      string
+		rate          float64
+		expectedField string
+	}{
+		{name: "unknown source currency", from: "ABC1", to: "RUB", rate: 1, expectedField: "from_currency"},
+		{name: "same currencies", from: "USD", to: "usd", rate: 1, expectedField: "to_currency"},
+		{name: "non-positive rate", from: "USD", to: "RUB", rate: 0, expectedField: "rate"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := service.SetRate(context.Background(), tc.from, tc.to, tc.rate)
+
+			assert.Nil(t, rate)
+			assert.ErrorIs(t, err, errs.ErrValidation)
+			assert.Equal(t, tc.expectedField, err.(*errs.Error).Field)
+		})
+	}
+}
+
+func TestDeleteRate_NotFound(t *testing.T) {
+	service, mockRepo := setupExchangeRateService()
+
+	mockRepo.On("Delete", mock.Anything, "USD", "RUB").Return(false, nil)
+
+	err := service.DeleteRate(context.Background(), "USD", "RUB")
+
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	mockRepo.AssertExpectations(t)
+}
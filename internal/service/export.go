@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"time"
+)
+
+// ExportSubscriptions calls fn for every subscription matching the filter, in the same order as
+// ListSubscriptions but without paging. Rows are streamed from the database, so exports of any
+// size use constant memory. An error returned by fn stops the export and is returned as is.
+func (s *SubscriptionService) ExportSubscriptions(ctx context.Context, filterReq *models.SubscriptionFilterRequest, fn func(subscription *models.Subscription) error) error {
+	filter, err := s.parseFilter(ctx, filterReq)
+	if err != nil {
+		return err
+	}
+
+	var fnErr error
+	err = s.repo.Stream(ctx, filter, func(subscription *models.Subscription) error {
+		fnErr = fn(subscription)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to export subscriptions")
+		return errs.Internal("failed to export subscriptions")
+	}
+	return nil
+}
+
+// ExportChargeSchedules calls fn with the remaining charges of every subscription matching the
+// filter. Subscriptions without charges left are skipped.
+func (s *SubscriptionService) ExportChargeSchedules(ctx context.Context, filterReq *models.SubscriptionFilterRequest, fn func(schedule *models.ChargeSchedule) error) error {
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	return s.ExportSubscriptions(ctx, filterReq, func(subscription *models.Subscription) error {
+		next, ok := subscription.NextChargeOn(today)
+		if !ok {
+			return nil
+		}
+		return fn(&models.ChargeSchedule{Subscription: subscription, NextCharge: next})
+	})
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportChargeSchedules_SkipsEndedSubscriptions(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	service.now = func() time.Time { return time.Date(2025, time.March, 15, 9, 0, 0, 0, time.UTC) }
+
+	userID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID})
+	ended := models.YearMonth{Year: 2025, Month: time.February}
+	mockRepo.On("Stream", mock.Anything, mock.MatchedBy(func(filter *models.SubscriptionFilter) bool {
+		return *filter.UserID == userID
+	})).Return([]models.Subscription{
+		{ID: 1, UserID: userID, BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: models.YearMonth{Year: 2025, Month: time.January}},
+		{ID: 2, UserID: userID, BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: models.YearMonth{Year: 2024, Month: time.January}, EndDate: &ended},
+		{ID: 3, UserID: userID, BillingPeriod: models.BillingPeriodYear, BillingIntervalCount: 1, StartDate: models.YearMonth{Year: 2024, Month: time.June}},
+	}, nil).Once()
+
+	next := map[uint]string{}
+	err := service.ExportChargeSchedules(ctx, nil, func(schedule *models.ChargeSchedule) error {
+		next[schedule.Subscription.ID] = schedule.NextCharge.Format(time.DateOnly)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint]string{1: "2025-04-01", 3: "2025-06-01"}, next)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportSubscriptions_Errors(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+
+	// Errors of the caller's writer are returned unchanged
+	writeErr := errors.New("client went away")
+	mockRepo.On("Stream", mock.Anything, mock.Anything).Return([]models.Subscription{{ID: 1}}, nil).Once()
+	err := service.ExportSubscriptions(context.Background(), nil, func(*models.Subscription) error { return writeErr })
+	assert.ErrorIs(t, err, writeErr)
+
+	// Database errors are not leaked
+	mockRepo.On("Stream", mock.Anything, mock.Anything).Return([]models.Subscription{}, errors.New("connection reset")).Once()
+	err = service.ExportSubscriptions(context.Background(), nil, func(*models.Subscription) error { return nil })
+	assert.ErrorIs(t, err, errs.ErrInternal)
+
+	// Filters are validated before anything is read
+	err = service.ExportSubscriptions(context.Background(), &models.SubscriptionFilterRequest{Status: "paused"}, func(*models.Subscription) error { return nil })
+	assert.ErrorIs(t, err, errs.ErrValidation)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"time"
+)
+
+// parseFilter validates the filter and sort parameters and restricts them to the caller's own
+// subscriptions
+func (s *SubscriptionService) parseFilter(ctx context.Context, req *models.SubscriptionFilterRequest) (*models.SubscriptionFilter, error) {
+	if req == nil {
+		req = &models.SubscriptionFilterRequest{}
+	}
+
+	userID, err := scopeToCaller(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	filter := &models.SubscriptionFilter{UserID: userID}
+
+	// Service names are resolved through the catalog like the names of new subscriptions, so an
+	// alias finds the subscriptions stored under the canonical name, and match case-insensitively
+	for _, name := range req.ServiceNames {
+		resolved, _, err := s.resolveServiceName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		name = models.NormalizeServiceName(resolved)
+		if name != "" && !slices.Contains(filter.ServiceNames, name) {
+			filter.ServiceNames = append(filter.ServiceNames, name)
+		}
+	}
+
+	if req.PriceMin != nil && *req.PriceMin < 0 {
+		return nil, errs.Validation("price_min", errs.CodeInvalidPrice, "price_min must not be negative")
+	}
+	if req.PriceMax != nil && *req.PriceMax < 0 {
+		return nil, errs.Validation("price_max", errs.CodeInvalidPrice, "price_max must not be negative")
+	}
+	if req.PriceMin != nil && req.PriceMax != nil && *req.PriceMax < *req.PriceMin {
+		return nil, errs.Validation("price_max", errs.CodeInvalidPrice, "price_max must not be less than price_min")
+	}
+	filter.PriceMin, filter.PriceMax = req.PriceMin, req.PriceMax
+
+	if filter.ActiveOn, err = parseOptionalYearMonth("active_on", req.ActiveOn); err != nil {
+		return nil, err
+	}
+	if filter.StartedAfter, err = parseOptionalYearMonth("started_after", req.StartedAfter); err != nil {
+		return nil, err
+	}
+	if filter.StartedBefore, err = parseOptionalYearMonth("started_before", req.StartedBefore); err != nil {
+		return nil, err
+	}
+	if filter.StartedAfter != nil && filter.StartedBefore != nil && !filter.StartedBefore.After(*filter.StartedAfter) {
+		return nil, errs.Validation("started_before", errs.CodeInvalidDateRange, "started_before must be after started_after")
+	}
+
+	if req.Status != "" {
+		status := models.SubscriptionStatus(req.Status)
+		switch status {
+		case models.SubscriptionStatusActive, models.SubscriptionStatusEnded, models.SubscriptionStatusUpcoming:
+			filter.Status = &status
+			filter.StatusMonth = models.YearMonthOf(s.now().UTC())
+		default:
+			return nil, errs.Validation("status", errs.CodeInvalidInput, "status must be one of active, ended, upcoming")
+		}
+	}
+
+	// A trial ends within the window when it converts to the full price, on the first charge
+	// after it, between today and the last day of the window
+	if req.TrialEndsWithin != nil {
+		if *req.TrialEndsWithin < 1 || *req.TrialEndsWithin > MaxUpcomingWithinDays {
+			return nil, errs.Validation("trial_ends_within", errs.CodeInvalidInput, fmt.Sprintf("trial_ends_within must be between 1 and %d", MaxUpcomingWithinDays))
+		}
+		now := s.now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		horizon := today.AddDate(0, 0, *req.TrialEndsWithin)
+		filter.TrialConvertsFrom, filter.TrialConvertsTo = &today, &horizon
+	}
+
+	if filter.Sort, err = parseSort(req.Sort); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// parseOptionalYearMonth parses an optional MM-YYYY parameter
+func parseOptionalYearMonth(field, value string) (*models.YearMonth, error) {
+	if value == "" {
+		return nil, nil
+	}
+	ym, err := parseYearMonth(field, value)
+	if err != nil {
+		return nil, err
+	}
+	return &ym, nil
+}
+
+// parseSort parses a comma separated list of sort fields such as "price,-start_date"
+func parseSort(sort string) ([]models.SortField, error) {
+	if sort == "" {
+		return nil, nil
+	}
+
+	var fields []models.SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		field := models.SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !slices.Contains(models.SortableFields, field.Field) {
+			return nil, errs.Validation("sort", errs.CodeInvalidSort, fmt.Sprintf("cannot sort by %q; sortable fields are %s", field.Field, strings.Join(models.SortableFields, ", ")))
+		}
+		if seen[field.Field] {
+			return nil, errs.Validation("sort", errs.CodeInvalidSort, fmt.Sprintf("%s is sorted on more than once", field.Field))
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
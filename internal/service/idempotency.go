@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/infra/database"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// MaxIdempotencyKeyLength bounds the Idempotency-Key header
+const MaxIdempotencyKeyLength = 255
+
+// errIdempotencyKeyTaken aborts a create whose key was stored by a concurrent request
+var errIdempotencyKeyTaken = errors.New("idempotency key taken by a concurrent request")
+
+// CreateSubscriptionIdempotent creates a subscription like CreateSubscription and stores the
+// response under the caller's idempotency key in the same transaction. A retry with the same
+// key and request replays the stored subscription and reports replayed; reusing the key for a
+// different request fails as unprocessable. Failed creates are not stored and may be retried.
+func (s *SubscriptionService) CreateSubscriptionIdempotent(ctx context.Context, req *models.CreateSubscriptionRequest, idempotencyKey string) (*models.Subscription, bool, error) {
+	if len(idempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, false, errs.Validation("Idempotency-Key", errs.CodeInvalidInput, "Idempotency-Key must be at most 255 characters")
+	}
+
+	scope := idempotencyScope(ctx)
+	// Hash the request as sent, before user_id defaults to the caller
+	requestHash, err := hashRequest(req)
+	if err != nil {
+		return nil, false, errs.Internal("failed to hash request")
+	}
+
+	if subscription, found, err := s.replay(ctx, scope, idempotencyKey, requestHash); found || err != nil {
+		return subscription, found, err
+	}
+
+	subscription, err := s.buildSubscription(ctx, req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	_, err = s.txMgr.ExecuteWithResult(ctx, func(tx database.Transaction) (interface{}, error) {
+		gormTx := database.GetDB(tx)
+
+		if err := s.insertSubscription(ctx, gormTx, subscription); err != nil {
+			return nil, err
+		}
+
+		body, err := json.Marshal(subscription)
+		if err != nil {
+			return nil, errs.Internal("failed to encode response")
+		}
+		now := s.now()
+		saved, err := s.idempotency.Save(ctx, gormTx, &models.IdempotencyKey{
+			Scope:        scope,
+			Key:          idempotencyKey,
+			RequestHash:  requestHash,
+			ResponseBody: body,
+			CreatedAt:    now,
+			ExpiresAt:    now.Add(s.idempotencyCfg.TTL),
+		})
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to store idempotency key")
+			return nil, errs.Internal("failed to store idempotency key")
+		}
+		if !saved {
+			return nil, errIdempotencyKeyTaken
+		}
+		return subscription, nil
+	})
+	if err != nil {
+		// A concurrent request with the same key may have won the race; answer like a retry
+		if replayed, found, replayErr := s.replay(ctx, scope, idempotencyKey, requestHash); found || replayErr != nil {
+			return replayed, found, replayErr
+		}
+		if errors.Is(err, errIdempotencyKeyTaken) {
+			return nil, false, errs.Conflict("Idempotency-Key", errs.CodeIdempotencyKeyInUse, "a request with this Idempotency-Key is still being processed")
+		}
+		return nil, false, err
+	}
+
+	return subscription, false, nil
+}
+
+// replay looks up a stored response for the key and reports whether one was found
+func (s *SubscriptionService) replay(ctx context.Context, scope, key, requestHash string) (*models.Subscription, bool, error) {
+	record, err := s.idempotency.Get(ctx, nil, scope, key, s.now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, nil
+		}
+		s.logger.WithError(err).Error("Failed to look up idempotency key")
+		return nil, false, errs.Internal("failed to look up idempotency key")
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, false, errs.Unprocessable("Idempotency-Key", errs.CodeIdempotencyKeyReused, "Idempotency-Key was already used with a different request")
+	}
+
+	var subscription models.Subscription
+	if err := json.Unmarshal(record.ResponseBody, &subscription); err != nil {
+		s.logger.WithError(err).Error("Failed to decode stored idempotent response")
+		return nil, false, errs.Internal("failed to replay stored response")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"scope":           scope,
+	}).Info("Idempotent create replayed successfully")
+
+	return &subscription, true, nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes the idempotency keys whose TTL has passed
+func (s *SubscriptionService) PurgeExpiredIdempotencyKeys(ctx context.Context) error {
+	_, err := s.idempotency.DeleteExpired(ctx, s.now())
+	return err
+}
+
+// idempotencyScope identifies the caller owning an idempotency key, so callers cannot replay
+// each other's responses
+func idempotencyScope(ctx context.Context) string {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return "anonymous"
+	}
+	return principal.UserID.String()
+}
+
+// hashRequest fingerprints a request by the SHA-256 of its JSON encoding
+func hashRequest(req interface{}) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
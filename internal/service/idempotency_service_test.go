@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateSubscriptionIdempotent_StoresResponse(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+	idempotency := service.idempotency.(*MockIdempotencyRepository)
+
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	userID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID})
+	req := &models.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 999, StartDate: "01-2025"}
+
+	idempotency.On("Get", mock.Anything, (*gorm.DB)(nil), userID.String(), "key-1", now).Return(nil, gorm.ErrRecordNotFound).Once()
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), userID, "Netflix", yearMonth("01-2025")).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Run(func(args mock.Arguments) {
+		args.Get(2).(*models.Subscription).ID = 7
+	}).Return(nil).Once()
+	idempotency.On("Save", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(record *models.IdempotencyKey) bool {
+		return record.Scope == userID.String() && record.Key == "key-1" && len(record.RequestHash) == 64 &&
+			record.ExpiresAt.Equal(now.Add(24*time.Hour)) && json.Valid(record.ResponseBody)
+	})).Return(true, nil).Once()
+
+	subscription, replayed, err := service.CreateSubscriptionIdempotent(ctx, req, "key-1")
+
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, uint(7), subscription.ID)
+	assert.Equal(t, userID, subscription.UserID)
+	mockRepo.AssertExpectations(t)
+	idempotency.AssertExpectations(t)
+}
+
+func TestCreateSubscriptionIdempotent_Replays(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	idempotency := service.idempotency.(*MockIdempotencyRepository)
+
+	req := &models.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 999, UserID: uuid.New(), StartDate: "01-2025"}
+	hash, err := hashRequest(req)
+	assert.NoError(t, err)
+
+	idempotency.On("Get", mock.Anything, (*gorm.DB)(nil), "anonymous", "key-1", mock.Anything).Return(&models.IdempotencyKey{
+		RequestHash:  hash,
+		ResponseBody: models.RawJSON(`{"id":7,"service_name":"Netflix","price":999,"start_date":"01-2025"}`),
+	}, nil)
+
+	subscription, replayed, err := service.CreateSubscriptionIdempotent(context.Background(), req, "key-1")
+
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, uint(7), subscription.ID)
+	assert.Equal(t, yearMonth("01-2025"), subscription.StartDate)
+
+	// The same key with another body is rejected
+	changed := *req
+	changed.Price = 1299
+	subscription, replayed, err = service.CreateSubscriptionIdempotent(context.Background(), &changed, "key-1")
+
+	assert.Nil(t, subscription)
+	assert.False(t, replayed)
+	assert.ErrorIs(t, err, errs.ErrUnprocessable)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateSubscriptionIdempotent_ConcurrentRequestWins(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+	idempotency := service.idempotency.(*MockIdempotencyRepository)
+
+	req := &models.CreateSubscriptionRequest{ServiceName: "Netflix", Price: 999, UserID: uuid.New(), StartDate: "01-2025"}
+	hash, err := hashRequest(req)
+	assert.NoError(t, err)
+
+	// Not stored yet when the request arrives, stored by the other request once it loses the race
+	idempotency.On("Get", mock.Anything, (*gorm.DB)(nil), "anonymous", "key-1", mock.Anything).Return(nil, gorm.ErrRecordNotFound).Once()
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), req.UserID, "Netflix", yearMonth("01-2025")).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(nil).Once()
+	idempotency.On("Save", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.IdempotencyKey")).Return(false, nil).Once()
+	idempotency.On("Get", mock.Anything, (*gorm.DB)(nil), "anonymous", "key-1", mock.Anything).Return(&models.IdempotencyKey{
+		RequestHash:  hash,
+		ResponseBody: models.RawJSON(`{"id":3,"service_name":"Netflix","price":999,"start_date":"01-2025"}`),
+	}, nil).Once()
+
+	subscription, replayed, err := service.CreateSubscriptionIdempotent(context.Background(), req, "key-1")
+
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, uint(3), subscription.ID)
+	idempotency.AssertExpectations(t)
+}
+
+func TestCreateSubscriptionIdempotent_KeyTooLong(t *testing.T) {
+	service, _, _ := setupTestService()
+
+	key := make([]byte, MaxIdempotencyKeyLength+1)
+	for i := range key {
+		key[i] = 'k'
+	}
+
+	_, _, err := service.CreateSubscriptionIdempotent(context.Background(), &models.CreateSubscriptionRequest{}, string(key))
+
+	assert.ErrorIs(t, err, errs.ErrValidation)
+}
+
+func TestPurgeExpiredIdempotencyKeys(t *testing.T) {
+	service, _, _ := setupTestService()
+	idempotency := service.idempotency.(*MockIdempotencyRepository)
+
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	idempotency.On("DeleteExpired", mock.Anything, now).Return(int64(2), nil).Once()
+
+	assert.NoError(t, service.PurgeExpiredIdempotencyKeys(context.Background()))
+	idempotency.AssertExpectations(t)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/infra/database"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// importKey identifies the subscriptions that must not be duplicated, see checkDuplicate
+type importKey struct {
+	userID      uuid.UUID
+	serviceName string // Normalized, see models.NormalizeServiceName
+	startDate   models.YearMonth
+}
+
+// ImportSubscriptions creates a subscription for every valid row with the same rules as
+// CreateSubscription. Each row is committed on its own, so rows with errors are reported in the
+// response without affecting the others. A dry run validates every row, including the duplicate
+// check, without writing anything.
+func (s *SubscriptionService) ImportSubscriptions(ctx context.Context, rows []models.ImportRow, dryRun bool) (*models.ImportResponse, error) {
+	if len(rows) == 0 {
+		return nil, errs.Validation("file", errs.CodeRequired, "the CSV file contains no rows")
+	}
+	if len(rows) > s.batchCfg.ImportMaxRows {
+		return nil, errs.Validation("file", errs.CodeImportTooLarge, fmt.Sprintf("an import may contain at most %d rows", s.batchCfg.ImportMaxRows))
+	}
+
+	response := &models.ImportResponse{DryRun: dryRun, Total: len(rows), Rejections: []models.ImportRejection{}}
+	seen := make(map[importKey]int, len(rows))
+	for i := range rows {
+		subscription, err := s.importRow(ctx, &rows[i], seen, dryRun)
+		if err != nil {
+			response.Rejections = append(response.Rejections, models.ImportRejection{Line: rows[i].Line, Err: err})
+			response.Rejected++
+			continue
+		}
+		seen[importKey{subscription.UserID, models.NormalizeServiceName(subscription.ServiceName), subscription.StartDate}] = rows[i].Line
+		response.Accepted++
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"dry_run":  dryRun,
+		"total":    response.Total,
+		"accepted": response.Accepted,
+		"rejected": response.Rejected,
+	}).Info("Subscription import completed successfully")
+
+	return response, nil
+}
+
+// importRow validates a row and, unless this is a dry run, stores it in its own transaction
+func (s *SubscriptionService) importRow(ctx context.Context, row *models.ImportRow, seen map[importKey]int, dryRun bool) (*models.Subscription, error) {
+	if row.Err != nil {
+		return nil, row.Err
+	}
+	req, err := importRequest(row)
+	if err != nil {
+		return nil, err
+	}
+	subscription, err := s.buildSubscription(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Earlier rows are only visible to the duplicate check once written, which a dry run never does
+	if line, ok := seen[importKey{subscription.UserID, models.NormalizeServiceName(subscription.ServiceName), subscription.StartDate}]; ok {
+		return nil, errs.Conflict("start_date", errs.CodeSubscriptionExists, fmt.Sprintf("subscription duplicates the one on line %d", line))
+	}
+
+	if dryRun {
+		return subscription, s.checkDuplicate(ctx, nil, subscription)
+	}
+	err = s.txMgr.Execute(ctx, func(tx database.Transaction) error {
+		return s.insertSubscription(ctx, database.GetDB(tx), subscription)
+	})
+	return subscription, err
+}
+
+// importRequest converts the text values of a CSV row into a create request. Empty optional
+// columns are treated as omitted.
+func importRequest(row *models.ImportRow) (*models.CreateSubscriptionRequest, error) {
+	req := &models.CreateSubscriptionRequest{
+		ServiceName:   strings.TrimSpace(row.ServiceName),
+		Currency:      strings.TrimSpace(row.Currency),
+		BillingPeriod: strings.TrimSpace(row.BillingPeriod),
+		StartDate:     strings.TrimSpace(row.StartDate),
+	}
+
+	for _, column := range []struct {
+		field  string
+		value  string
+		target *int
+	}{
+		{"price", row.Price, &req.Price},
+		{"billing_interval_count", row.BillingIntervalCount, &req.BillingIntervalCount},
+		{"trial_price", row.TrialPrice, &req.TrialPrice},
+	} {
+		if value := strings.TrimSpace(column.value); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, errs.Validation(column.field, errs.CodeInvalidType, column.field+" must be an integer")
+			}
+			*column.target = parsed
+		}
+	}
+
+	if userID := strings.TrimSpace(row.UserID); userID != "" {
+		parsed, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, errs.Validation("user_id", errs.CodeInvalidInput, "Invalid user_id format")
+		}
+		req.UserID = parsed
+	}
+
+	if endDate := strings.TrimSpace(row.EndDate); endDate != "" {
+		req.EndDate = &endDate
+	}
+	if trialEnd := strings.TrimSpace(row.TrialEnd); trialEnd != "" {
+		req.TrialEnd = &trialEnd
+	}
+
+	return req, nil
+}
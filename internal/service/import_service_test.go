@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestImportSubscriptions_DryRunReportsEveryRejectedLine(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	userID := uuid.New()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, (*gorm.DB)(nil), userID, "Netflix", yearMonth("01-2025")).Return(false, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, (*gorm.DB)(nil), userID, "Spotify", yearMonth("01-2025")).Return(true, nil).Once()
+
+	response, err := service.ImportSubscriptions(context.Background(), []models.ImportRow{
+		{Line: 2, ServiceName: "Netflix", Price: "999", UserID: userID.String(), StartDate: "01-2025"},
+		{Line: 3, ServiceName: "Spotify", Price: "299", UserID: userID.String(), StartDate: "01-2025"},
+		{Line: 4, ServiceName: " Netflix ", Price: "999", UserID: userID.String(), StartDate: "01-2025"},
+	}, true)
+
+	assert.NoError(t, err)
+	assert.True(t, response.DryRun)
+	assert.Equal(t, 3, response.Total)
+	assert.Equal(t, 1, response.Accepted)
+	assert.Equal(t, 2, response.Rejected)
+	assert.Equal(t, 3, response.Rejections[0].Line)
+	assert.ErrorIs(t, response.Rejections[0].Err, errs.ErrConflict)
+	assert.Equal(t, 4, response.Rejections[1].Line)
+	assert.EqualError(t, response.Rejections[1].Err, "subscription duplicates the one on line 2")
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	mockTxMgr.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportSubscriptions_ValidatesRowsLikeCreate(t *testing.T) {
+	service, _, _ := setupTestService()
+
+	endDate := "13-2025"
+	tests := []struct {
+		name  string
+		row   models.ImportRow
+		field string
+		code  string
+	}{
+		{"fractional price", models.ImportRow{ServiceName: "Netflix", Price: "9.99", StartDate: "01-2025"}, "price", errs.CodeInvalidType},
+		{"missing price", models.ImportRow{ServiceName: "Netflix", StartDate: "01-2025"}, "price", errs.CodeInvalidPrice},
+		{"invalid user", models.ImportRow{ServiceName: "Netflix", Price: "999", UserID: "bob", StartDate: "01-2025"}, "user_id", errs.CodeInvalidInput},
+		{"fractional trial price", models.ImportRow{ServiceName: "Netflix", Price: "999", StartDate: "01-2025", TrialEnd: "02-2025", TrialPrice: "0.5"}, "trial_price", errs.CodeInvalidType},
+		{"unreadable row", models.ImportRow{Err: errs.Validation("file", errs.CodeInvalidCSV, "expected 3 fields, found 2")}, "file", errs.CodeInvalidCSV},
+		{"invalid end date", models.ImportRow{ServiceName: "Netflix", Price: "999", UserID: uuid.NewString(), StartDate: "01-2025", EndDate: endDate}, "end_date", errs.CodeInvalidDateFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := service.ImportSubscriptions(context.Background(), []models.ImportRow{tt.row}, true)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, response.Rejected)
+			var domainErr *errs.Error
+			assert.ErrorAs(t, response.Rejections[0].Err, &domainErr)
+			fieldErr := domainErr.FieldErrors()[0]
+			assert.Equal(t, tt.field, fieldErr.Field)
+			assert.Equal(t, tt.code, fieldErr.Code)
+		})
+	}
+}
+
+func TestImportSubscriptions_WritesEachRowInItsOwnTransaction(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	userID := uuid.New()
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Times(2)
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), userID, mock.Anything, yearMonth("01-2025")).Return(false, nil).Times(2)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.ServiceName == "Netflix" && sub.Price == 999 && sub.EndDate != nil && *sub.EndDate == yearMonth("06-2025") &&
+			sub.Currency == "USD" && sub.BillingPeriod == models.BillingPeriodQuarter && sub.BillingIntervalCount == 2 &&
+			sub.TrialEnd != nil && *sub.TrialEnd == yearMonth("02-2025") && sub.TrialPrice == 99
+	})).Return(nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.ServiceName == "Spotify" && sub.EndDate == nil
+	})).Return(nil).Once()
+
+	response, err := service.ImportSubscriptions(context.Background(), []models.ImportRow{
+		{
+			Line: 2, ServiceName: "Netflix", Price: "999", Currency: "USD", BillingPeriod: "quarter", BillingIntervalCount: "2",
+			UserID: userID.String(), StartDate: "01-2025", EndDate: "06-2025", TrialEnd: "02-2025", TrialPrice: "99",
+		},
+		{Line: 3, ServiceName: "Spotify", Price: "abc", UserID: userID.String(), StartDate: "01-2025"},
+		{Line: 4, ServiceName: "Spotify", Price: "299", UserID: userID.String(), StartDate: "01-2025"},
+	}, false)
+
+	assert.NoError(t, err)
+	assert.False(t, response.DryRun)
+	assert.Equal(t, 2, response.Accepted)
+	assert.Equal(t, 1, response.Rejected)
+	assert.Equal(t, 3, response.Rejections[0].Line)
+	assert.Equal(t, []string{models.EventSubscriptionCreated, models.EventSubscriptionCreated}, service.events.(*recordingOutbox).eventTypes())
+	mockRepo.AssertExpectations(t)
+	mockTxMgr.AssertExpectations(t)
+}
+
+func TestImportSubscriptions_RejectsEmptyAndOversizedFiles(t *testing.T) {
+	service, _, _ := setupTestService()
+
+	_, err := service.ImportSubscriptions(context.Background(), nil, false)
+	assert.ErrorIs(t, err, errs.ErrValidation)
+
+	rows := make([]models.ImportRow, 4)
+	_, err = service.ImportSubscriptions(context.Background(), rows, true)
+	var domainErr *errs.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, errs.CodeImportTooLarge, domainErr.Code)
+}
@@ -1,16 +1,83 @@
 package service
 
 import (
+	"context"
+
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"subscription_tracker_api/internal/models"
 )
 
 // SubscriptionServiceInterface defines what the handlers need from the service
 type SubscriptionServiceInterface interface {
-	CreateSubscription(req *models.CreateSubscriptionRequest) (*models.Subscription, error)
-	GetSubscriptionByID(id uint) (*models.Subscription, error)
-	UpdateSubscription(id uint, updates map[string]interface{}) (*models.Subscription, error)
-	DeleteSubscription(id uint) error
-	ListSubscriptions(userID *uuid.UUID, serviceName *string, limit, offset int) ([]models.Subscription, error)
-	CalculateTotalCost(req *models.CostCalculationRequest) (*models.CostCalculationResponse, error)
+	CreateSubscription(ctx context.Context, req *models.CreateSubscriptionRequest) (*models.Subscription, error)
+	CreateSubscriptionIdempotent(ctx context.Context, req *models.CreateSubscriptionRequest, idempotencyKey string) (*models.Subscription, bool, error)
+	GetSubscriptionByID(ctx context.Context, id uint) (*models.Subscription, error)
+	UpdateSubscription(ctx context.Context, id uint, req *models.UpdateSubscriptionRequest, precondition *models.Precondition) (*models.Subscription, error)
+	ReplaceSubscription(ctx context.Context, id uint, req *models.CreateSubscriptionRequest, precondition *models.Precondition) (*models.Subscription, error)
+	DeleteSubscription(ctx context.Context, id uint, precondition *models.Precondition) error
+	PurgeSubscription(ctx context.Context, id uint, precondition *models.Precondition) error
+	RestoreSubscription(ctx context.Context, id uint, precondition *models.Precondition) (*models.Subscription, error)
+	ListPriceHistory(ctx context.Context, id uint) ([]models.SubscriptionPrice, error)
+	ListTrash(ctx context.Context, filter *models.SubscriptionFilterRequest, page models.PageRequest) (*models.TrashPage, error)
+	ExecuteBatch(ctx context.Context, req *models.BatchRequest) (*models.BatchResponse, error)
+	ImportSubscriptions(ctx context.Context, rows []models.ImportRow, dryRun bool) (*models.ImportResponse, error)
+	ListSubscriptions(ctx context.Context, filter *models.SubscriptionFilterRequest, page models.PageRequest) (*models.SubscriptionPage, error)
+	ExportSubscriptions(ctx context.Context, filter *models.SubscriptionFilterRequest, fn func(subscription *models.Subscription) error) error
+	ExportChargeSchedules(ctx context.Context, filter *models.SubscriptionFilterRequest, fn func(schedule *models.ChargeSchedule) error) error
+	CalculateTotalCost(ctx context.Context, req *models.CostCalculationRequest) (*models.CostCalculationResponse, error)
+	CalculateSpendSeries(ctx context.Context, req *models.SpendRequest) ([]models.SpendPoint, error)
+	ListUpcomingCharges(ctx context.Context, userID *uuid.UUID, withinDays, limit int) ([]models.UpcomingCharge, error)
+}
+
+// AuditServiceInterface defines what the handlers need to read the audit log
+type AuditServiceInterface interface {
+	ListHistory(ctx context.Context, subscriptionID uint, limit, offset int) ([]models.AuditEvent, error)
+	ListEvents(ctx context.Context, filter *models.AuditFilterRequest, limit, offset int) ([]models.AuditEvent, error)
+}
+
+// CatalogServiceInterface defines what the handlers need to manage the service catalog
+type CatalogServiceInterface interface {
+	CreateService(ctx context.Context, req *models.CreateServiceRequest) (*models.Service, error)
+	GetService(ctx context.Context, id uint) (*models.Service, error)
+	ListServices(ctx context.Context, category string) ([]models.Service, error)
+	UpdateService(ctx context.Context, id uint, req *models.UpdateServiceRequest) (*models.Service, error)
+	DeleteService(ctx context.Context, id uint) error
+}
+
+// BudgetServiceInterface defines what the handlers need to manage budgets
+type BudgetServiceInterface interface {
+	CreateBudget(ctx context.Context, req *models.CreateBudgetRequest) (*models.Budget, error)
+	GetBudget(ctx context.Context, id uint) (*models.Budget, error)
+	ListBudgets(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error)
+	UpdateBudget(ctx context.Context, id uint, req *models.UpdateBudgetRequest) (*models.Budget, error)
+	DeleteBudget(ctx context.Context, id uint) error
+	GetBudgetStatus(ctx context.Context, userID *uuid.UUID, months int) ([]models.BudgetStatus, error)
+}
+
+// BudgetWatcher raises over-budget events for the subscription changes it runs
+type BudgetWatcher interface {
+	WatchBudgets(ctx context.Context, tx *gorm.DB, subscription *models.Subscription, write func() error) error
+}
+
+// ExchangeRateServiceInterface defines what the handlers need to manage exchange rates
+type ExchangeRateServiceInterface interface {
+	ListRates(ctx context.Context) ([]models.ExchangeRate, error)
+	SetRate(ctx context.Context, fromCurrency, toCurrency string, rate float64) (*models.ExchangeRate, error)
+	DeleteRate(ctx context.Context, fromCurrency, toCurrency string) error
+}
+
+// CurrencyConverter converts amounts between currencies
+type CurrencyConverter interface {
+	Convert(ctx context.Context, amount int, fromCurrency, toCurrency string) (int, error)
+}
+
+// WebhookServiceInterface defines what the handlers need to manage webhook endpoints
+type WebhookServiceInterface interface {
+	CreateWebhook(ctx context.Context, req *models.CreateWebhookRequest) (*models.WebhookEndpointWithSecret, error)
+	GetWebhook(ctx context.Context, id uint) (*models.WebhookEndpoint, error)
+	ListWebhooks(ctx context.Context) ([]models.WebhookEndpoint, error)
+	UpdateWebhook(ctx context.Context, id uint, req *models.UpdateWebhookRequest) (*models.WebhookEndpoint, error)
+	DeleteWebhook(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, id uint, status *string, limit, offset int) ([]models.WebhookDelivery, error)
 }
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ListPriceHistory retrieves the prices a subscription has had, oldest first. Each price applies
+// from its effective_from month until the next one; cost calculations charge every month at the
+// price in effect then.
+func (s *SubscriptionService) ListPriceHistory(ctx context.Context, id uint) ([]models.SubscriptionPrice, error) {
+	subscription, err := s.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := s.prices.ListPrices(ctx, subscription.ID)
+	if err != nil {
+		s.logger.WithError(err).WithField("subscription_id", id).Error("Failed to list subscription prices")
+		return nil, errs.Internal("failed to retrieve subscription prices")
+	}
+	if len(prices) == 0 {
+		// Subscriptions are always priced; report the current price for one without a history
+		prices = []models.SubscriptionPrice{{
+			SubscriptionID: subscription.ID,
+			EffectiveFrom:  subscription.StartDate,
+			Price:          subscription.Price,
+			CreatedAt:      subscription.CreatedAt,
+		}}
+	}
+	return prices, nil
+}
+
+// savePrice appends the current price of a subscription to its price history within the
+// caller's transaction. A price saved earlier for the same month is replaced.
+func (s *SubscriptionService) savePrice(ctx context.Context, tx *gorm.DB, subscription *models.Subscription, effectiveFrom models.YearMonth) error {
+	price := &models.SubscriptionPrice{
+		SubscriptionID: subscription.ID,
+		EffectiveFrom:  effectiveFrom,
+		Price:          subscription.Price,
+	}
+	if err := s.prices.SavePrice(ctx, tx, price); err != nil {
+		s.logger.WithError(err).WithField("subscription_id", subscription.ID).Error("Failed to save subscription price")
+		return errs.Internal("failed to save subscription price")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateSubscription_SavesInitialPrice(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Run(func(args mock.Arguments) {
+		args.Get(2).(*models.Subscription).ID = 1
+	}).Return(nil).Once()
+
+	_, err := service.CreateSubscription(context.Background(), &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   "01-2024",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SubscriptionPrice{{SubscriptionID: 1, EffectiveFrom: yearMonth("01-2024"), Price: 999}}, service.prices.(*recordingPriceHistory).prices)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSubscription_SavesPriceChanges(t *testing.T) {
+	tests := []struct {
+		name          string
+		startDate     string
+		price         int
+		effectiveFrom []models.YearMonth
+	}{
+		{"applies from the current month", "01-2024", 1199, []models.YearMonth{yearMonth("03-2025")}},
+		{"applies from the start of an upcoming subscription", "06-2025", 1199, []models.YearMonth{yearMonth("06-2025")}},
+		{"unchanged price keeps the history", "01-2024", 999, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, mockTxMgr := setupTestService()
+			service.now = func() time.Time { return time.Date(2025, time.March, 15, 10, 0, 0, 0, time.UTC) }
+
+			mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+			mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+				ID:          1,
+				ServiceName: "Netflix",
+				Price:       999,
+				UserID:      uuid.New(),
+				StartDate:   yearMonth(tt.startDate),
+			}, nil).Once()
+			mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(true, nil).Maybe()
+
+			_, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{Price: &tt.price}, nil)
+
+			assert.NoError(t, err)
+			var effectiveFrom []models.YearMonth
+			for _, price := range service.prices.(*recordingPriceHistory).prices {
+				assert.Equal(t, tt.price, price.Price)
+				effectiveFrom = append(effectiveFrom, price.EffectiveFrom)
+			}
+			assert.Equal(t, tt.effectiveFrom, effectiveFrom)
+		})
+	}
+}
+
+func TestListPriceHistory(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+
+	subscription := &models.Subscription{ID: 1, Price: 1199, UserID: uuid.New(), StartDate: yearMonth("01-2024")}
+	mockRepo.On("GetByID", mock.Anything, (*gorm.DB)(nil), uint(1)).Return(subscription, nil)
+	mockRepo.On("GetByID", mock.Anything, (*gorm.DB)(nil), uint(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	// Subscriptions without a history report their current price
+	prices, err := service.ListPriceHistory(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SubscriptionPrice{{SubscriptionID: 1, EffectiveFrom: yearMonth("01-2024"), Price: 1199}}, prices)
+
+	history := service.prices.(*recordingPriceHistory)
+	history.prices = []models.SubscriptionPrice{
+		{SubscriptionID: 1, EffectiveFrom: yearMonth("01-2024"), Price: 999},
+		{SubscriptionID: 1, EffectiveFrom: yearMonth("03-2025"), Price: 1199},
+	}
+	prices, err = service.ListPriceHistory(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, history.prices, prices)
+
+	_, err = service.ListPriceHistory(context.Background(), 2)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/notify"
+	"subscription_tracker_api/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReminderService notifies owners about subscriptions that renew, expire or leave their trial soon
+type ReminderService struct {
+	subscriptions repository.SubscriptionRepositoryInterface
+	deliveries    repository.ReminderRepositoryInterface
+	notifier      notify.Notifier
+	leadTime      time.Duration
+	trialLeadTime time.Duration
+	maxAttempts   int
+	batchSize     int
+	logger        *logrus.Logger
+	now           func() time.Time
+}
+
+func NewReminderService(subscriptions repository.SubscriptionRepositoryInterface, deliveries repository.ReminderRepositoryInterface, notifier notify.Notifier, leadTime, trialLeadTime time.Duration, maxAttempts, batchSize int, logger *logrus.Logger) *ReminderService {
+	return &ReminderService{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		notifier:      notifier,
+		leadTime:      leadTime,
+		trialLeadTime: trialLeadTime,
+		maxAttempts:   maxAttempts,
+		batchSize:     batchSize,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+// SendDueReminders notifies about every renewal or expiry falling between today and the
+// lead time, and about every trial converting to the full price within the trial lead time.
+// Candidate subscriptions are read from the database in batches of batchSize. Each reminder is
+// claimed in the delivery table before it is sent, so it is delivered at most once even across
+// restarts; failed deliveries are retried on later runs.
+func (s *ReminderService) SendDueReminders(ctx context.Context) error {
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	horizon := now.Add(s.leadTime)
+	trialHorizon := now.Add(s.trialLeadTime)
+
+	sent, failed := 0, 0
+	var afterID uint
+	for {
+		subscriptions, err := s.subscriptions.ListDueForReminders(ctx, today, horizon, trialHorizon, afterID, s.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, reminder := range dueReminders(subscriptions, today, horizon, trialHorizon) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			ok, err := s.deliver(ctx, reminder)
+			if err != nil {
+				failed++
+				s.logger.WithError(err).WithFields(logrus.Fields{
+					"subscription_id": reminder.Subscription.ID,
+					"kind":            reminder.Kind,
+				}).Error("Failed to deliver reminder")
+			} else if ok {
+				sent++
+			}
+		}
+
+		if len(subscriptions) == 0 || len(subscriptions) < s.batchSize {
+			break
+		}
+		afterID = subscriptions[len(subscriptions)-1].ID
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"sent":     sent,
+		"failed":   failed,
+		"notifier": s.notifier.Name(),
+	}).Info("Due reminders processed successfully")
+
+	return nil
+}
+
+// deliver claims and sends a single reminder, reporting whether it was sent by this call
+func (s *ReminderService) deliver(ctx context.Context, reminder models.Reminder) (bool, error) {
+	dueDate, _ := time.Parse(time.DateOnly, reminder.DueDate)
+	delivery := &models.ReminderDelivery{
+		SubscriptionID: reminder.Subscription.ID,
+		Kind:           reminder.Kind,
+		DueDate:        dueDate,
+		Notifier:       s.notifier.Name(),
+	}
+
+	claimed, err := s.deliveries.Claim(ctx, delivery, s.maxAttempts)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	if err := s.notifier.Notify(ctx, reminder); err != nil {
+		if markErr := s.deliveries.MarkFailed(ctx, delivery.ID, err.Error()); markErr != nil {
+			s.logger.WithError(markErr).Error("Failed to record failed reminder delivery")
+		}
+		return false, err
+	}
+
+	if err := s.deliveries.MarkSent(ctx, delivery.ID); err != nil {
+		// The reminder went out; it stays claimed and will not be sent again
+		s.logger.WithError(err).Error("Failed to record sent reminder delivery")
+	}
+	return true, nil
+}
+
+// dueReminders lists the renewals and expiries of subscriptions falling within [today, horizon]
+// and the trial conversions falling within [today, trialHorizon]
+func dueReminders(subscriptions []models.Subscription, today, horizon, trialHorizon time.Time) []models.Reminder {
+	var reminders []models.Reminder
+	for _, sub := range subscriptions {
+		// Trial conversions are announced further ahead than renewals, leaving time to cancel
+		conversion, converts := sub.TrialConversion()
+		if converts && !conversion.Before(today) && !conversion.After(trialHorizon) {
+			reminders = append(reminders, models.Reminder{
+				Kind:         models.ReminderKindTrial,
+				DueDate:      conversion.Format(time.DateOnly),
+				Subscription: sub,
+			})
+		}
+
+		if next, ok := sub.NextChargeOn(today); ok && isRenewal(&sub, next, conversion) && !next.After(horizon) {
+			reminders = append(reminders, models.Reminder{
+				Kind:         models.ReminderKindRenewal,
+				DueDate:      next.Format(time.DateOnly),
+				Subscription: sub,
+			})
+		}
+
+		// A subscription ends once its end_date month is over
+		if sub.EndDate != nil {
+			ends := sub.EndDate.AddMonths(1).Time()
+			if !ends.Before(today) && !ends.After(horizon) {
+				reminders = append(reminders, models.Reminder{
+					Kind:         models.ReminderKindExpiry,
+					DueDate:      ends.Format(time.DateOnly),
+					Subscription: sub,
+				})
+			}
+		}
+	}
+	return reminders
+}
+
+// isRenewal reports whether a charge is announced by a renewal reminder. The first charge on
+// start_date is not a renewal, the first charge after a trial has a reminder of its own and the
+// charges of a free trial cost nothing.
+func isRenewal(sub *models.Subscription, charge, conversion time.Time) bool {
+	if !charge.After(sub.StartDate.Time()) || charge.Equal(conversion) {
+		return false
+	}
+	inTrial := sub.TrialEnd != nil && charge.Before(sub.TrialEnd.AddMonths(1).Time())
+	return !inTrial || sub.TrialPrice > 0
+}
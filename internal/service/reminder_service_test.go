@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"subscription_tracker_api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReminderRepository for testing reminder delivery
+type MockReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderRepository) Claim(ctx context.Context, delivery *models.ReminderDelivery, maxAttempts int) (bool, error) {
+	args := m.Called(ctx, delivery, maxAttempts)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReminderRepository) MarkSent(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockReminderRepository) MarkFailed(ctx context.Context, id uint, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+// MockNotifier for testing reminder delivery
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Name() string {
+	return "mock"
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
+
+func setupReminderService() (*ReminderService, *MockSubscriptionRepository, *MockReminderRepository, *MockNotifier) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	subscriptions := &MockSubscriptionRepository{}
+	deliveries := &MockReminderRepository{}
+	notifier := &MockNotifier{}
+	service := NewReminderService(subscriptions, deliveries, notifier, 72*time.Hour, 7*24*time.Hour, 3, 100, logger)
+	service.now = func() time.Time { return time.Date(2025, time.March, 29, 9, 0, 0, 0, time.UTC) }
+
+	return service, subscriptions, deliveries, notifier
+}
+
+func reminderFor(kind models.ReminderKind, subscriptionID uint) interface{} {
+	return mock.MatchedBy(func(reminder models.Reminder) bool {
+		return reminder.Kind == kind && reminder.Subscription.ID == subscriptionID
+	})
+}
+
+func TestSendDueReminders_RenewalsAndExpiries(t *testing.T) {
+	service, subscriptions, deliveries, notifier := setupReminderService()
+
+	endDate := yearMonth("03-2025")
+	subscriptions.On("ListDueForReminders", mock.Anything, mock.Anything, mock.Anything, mock.Anything, uint(0), 100).Return([]models.Subscription{
+		{ID: 1, ServiceName: "Renews", BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: yearMonth("01-2025")},
+		{ID: 2, ServiceName: "Starts", BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: yearMonth("04-2025")},
+		{ID: 3, ServiceName: "Ends", BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: yearMonth("01-2025"), EndDate: &endDate},
+		{ID: 4, ServiceName: "Yearly", BillingPeriod: models.BillingPeriodYear, BillingIntervalCount: 1, StartDate: yearMonth("01-2025")},
+	}, nil)
+
+	deliveries.On("Claim", mock.Anything, mock.MatchedBy(func(d *models.ReminderDelivery) bool {
+		return d.SubscriptionID == 1 && d.Kind == models.ReminderKindRenewal && d.DueDate.Equal(time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC))
+	}), 3).Return(true, nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.ReminderDelivery).ID = 10
+	})
+	deliveries.On("Claim", mock.Anything, mock.MatchedBy(func(d *models.ReminderDelivery) bool {
+		return d.SubscriptionID == 3 && d.Kind == models.ReminderKindExpiry
+	}), 3).Return(true, nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.ReminderDelivery).ID = 30
+	})
+
+	notifier.On("Notify", mock.Anything, reminderFor(models.ReminderKindRenewal, 1)).Return(nil)
+	notifier.On("Notify", mock.Anything, reminderFor(models.ReminderKindExpiry, 3)).Return(errors.New("smtp unavailable"))
+	deliveries.On("MarkSent", mock.Anything, uint(10)).Return(nil)
+	deliveries.On("MarkFailed", mock.Anything, uint(30), "smtp unavailable").Return(nil)
+
+	err := service.SendDueReminders(context.Background())
+
+	assert.NoError(t, err)
+	deliveries.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+	deliveries.AssertNumberOfCalls(t, "Claim", 2)
+}
+
+func TestSendDueReminders_SkipsAlreadyClaimed(t *testing.T) {
+	service, subscriptions, deliveries, notifier := setupReminderService()
+
+	subscriptions.On("ListDueForReminders", mock.Anything, mock.Anything, mock.Anything, mock.Anything, uint(0), 100).Return([]models.Subscription{
+		{ID: 1, ServiceName: "Renews", BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: yearMonth("01-2025")},
+	}, nil)
+	deliveries.On("Claim", mock.Anything, mock.Anything, 3).Return(false, nil)
+
+	err := service.SendDueReminders(context.Background())
+
+	assert.NoError(t, err)
+	notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestSendDueReminders_PagesThroughCandidates(t *testing.T) {
+	service, subscriptions, deliveries, notifier := setupReminderService()
+	service.batchSize = 2
+
+	today := time.Date(2025, time.March, 29, 0, 0, 0, 0, time.UTC)
+	horizon := time.Date(2025, time.April, 1, 9, 0, 0, 0, time.UTC)
+	trialHorizon := time.Date(2025, time.April, 5, 9, 0, 0, 0, time.UTC)
+	renews := func(id uint) models.Subscription {
+		return models.Subscription{ID: id, ServiceName: "Renews", BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: yearMonth("01-2025")}
+	}
+	subscriptions.On("ListDueForReminders", mock.Anything, today, horizon, trialHorizon, uint(0), 2).Return([]models.Subscription{renews(1), renews(2)}, nil).Once()
+	subscriptions.On("ListDueForReminders", mock.Anything, today, horizon, trialHorizon, uint(2), 2).Return([]models.Subscription{renews(5)}, nil).Once()
+	deliveries.On("Claim", mock.Anything, mock.Anything, 3).Return(true, nil)
+	deliveries.On("MarkSent", mock.Anything, mock.Anything).Return(nil)
+	notifier.On("Notify", mock.Anything, mock.Anything).Return(nil)
+
+	err := service.SendDueReminders(context.Background())
+
+	assert.NoError(t, err)
+	subscriptions.AssertExpectations(t)
+	notifier.AssertNumberOfCalls(t, "Notify", 3)
+}
+
+func TestDueReminders_Trials(t *testing.T) {
+	today := time.Date(2025, time.March, 29, 0, 0, 0, 0, time.UTC)
+	horizon := today.Add(72 * time.Hour)
+	trialHorizon := today.Add(7 * 24 * time.Hour)
+
+	march, april, february := yearMonth("03-2025"), yearMonth("04-2025"), yearMonth("02-2025")
+	reminders := dueReminders([]models.Subscription{
+		{ID: 1, ServiceName: "Converts", Price: 999, TrialEnd: &march, BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: yearMonth("01-2025")},
+		{ID: 2, ServiceName: "Free trial", Price: 999, TrialEnd: &april, BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: yearMonth("03-2025")},
+		{ID: 3, ServiceName: "Paid trial", Price: 999, TrialEnd: &april, TrialPrice: 100, BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: yearMonth("02-2025")},
+		{ID: 4, ServiceName: "Yearly", Price: 999, TrialEnd: &february, BillingPeriod: models.BillingPeriodYear, BillingIntervalCount: 1, StartDate: yearMonth("01-2025")},
+		{ID: 5, ServiceName: "Ends in trial", Price: 999, TrialEnd: &march, EndDate: &march, BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, StartDate: yearMonth("01-2025")},
+	}, today, horizon, trialHorizon)
+
+	var got []string
+	for _, reminder := range reminders {
+		got = append(got, reminder.Subscription.ServiceName+" "+string(reminder.Kind)+" "+reminder.DueDate)
+	}
+	// The first full-price charge is announced as the end of the trial instead of a renewal, free
+	// trial renewals are not announced and yearly subscriptions convert on their next charge
+	assert.Equal(t, []string{
+		"Converts trial 2025-04-01",
+		"Paid trial renewal 2025-04-01",
+		"Ends in trial expiry 2025-04-01",
+	}, got)
+}
@@ -2,200 +2,398 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
-	"regexp"
-	"strconv"
-	"strings"
+	"sort"
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/config"
+	"subscription_tracker_api/internal/errs"
 	"subscription_tracker_api/internal/infra/database"
 	"subscription_tracker_api/internal/models"
 	"subscription_tracker_api/internal/repository"
+	"time"
+)
+
+// DefaultListLimit is the page size of subscription listings without an explicit limit
+const DefaultListLimit = 50
+
+// Bounds of the look-ahead window and of the length of the upcoming charges listing
+const (
+	DefaultUpcomingWithinDays = 30
+	MaxUpcomingWithinDays     = 366
+	DefaultUpcomingLimit      = 100
+	MaxUpcomingLimit          = 1000
 )
 
 type SubscriptionService struct {
-	repo   repository.SubscriptionRepositoryInterface
-	txMgr  database.TransactionManager
-	logger *logrus.Logger
+	repo           repository.SubscriptionRepositoryInterface
+	converter      CurrencyConverter
+	events         repository.EventOutboxInterface
+	audit          repository.AuditLogInterface
+	prices         repository.PriceHistoryInterface
+	catalog        repository.ServiceCatalogInterface
+	budgets        BudgetWatcher
+	idempotency    repository.IdempotencyRepositoryInterface
+	idempotencyCfg config.IdempotencyConfig
+	batchCfg       config.BatchConfig
+	trashCfg       config.TrashConfig
+	txMgr          database.TransactionManager
+	logger         *logrus.Logger
+	now            func() time.Time
 }
 
-func NewSubscriptionService(repo repository.SubscriptionRepositoryInterface, txMgr database.TransactionManager, logger *logrus.Logger) *SubscriptionService {
+func NewSubscriptionService(repo repository.SubscriptionRepositoryInterface, converter CurrencyConverter, events repository.EventOutboxInterface, audit repository.AuditLogInterface, prices repository.PriceHistoryInterface, catalog repository.ServiceCatalogInterface, budgets BudgetWatcher, idempotency repository.IdempotencyRepositoryInterface, idempotencyCfg config.IdempotencyConfig, batchCfg config.BatchConfig, trashCfg config.TrashConfig, txMgr database.TransactionManager, logger *logrus.Logger) *SubscriptionService {
 	return &SubscriptionService{
-		repo:   repo,
-		txMgr:  txMgr,
-		logger: logger,
+		repo:           repo,
+		converter:      converter,
+		events:         events,
+		audit:          audit,
+		prices:         prices,
+		catalog:        catalog,
+		budgets:        budgets,
+		idempotency:    idempotency,
+		idempotencyCfg: idempotencyCfg,
+		batchCfg:       batchCfg,
+		trashCfg:       trashCfg,
+		txMgr:          txMgr,
+		logger:         logger,
+		now:            time.Now,
 	}
 }
 
 // CreateSubscription creates a new subscription with transaction-based validation
-func (s *SubscriptionService) CreateSubscription(req *models.CreateSubscriptionRequest) (*models.Subscription, error) {
-	if req.ServiceName == "" || req.Price <= 0 || req.UserID == uuid.Nil {
-		return nil, errors.New("invalid input data: service_name, price, and user_id are required")
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, req *models.CreateSubscriptionRequest) (*models.Subscription, error) {
+	subscription, err := s.buildSubscription(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	if !isValidDate(req.StartDate) {
-		return nil, errors.New("start_date must be in MM-YYYY format")
+	result, err := s.txMgr.ExecuteWithResult(ctx, func(tx database.Transaction) (interface{}, error) {
+		if err := s.insertSubscription(ctx, database.GetDB(tx), subscription); err != nil {
+			return nil, err
+		}
+		return subscription, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if req.EndDate != nil && *req.EndDate != "" {
-		if !isValidDate(*req.EndDate) {
-			return nil, errors.New("end_date must be in MM-YYYY format")
-		}
-		if *req.EndDate <= req.StartDate {
-			return nil, errors.New("end_date must be after start_date")
+	return result.(*models.Subscription), nil
+}
+
+// buildSubscription validates a create request and turns it into a new subscription owned by
+// the requested user, or by the caller when no user_id is given
+func (s *SubscriptionService) buildSubscription(ctx context.Context, req *models.CreateSubscriptionRequest) (*models.Subscription, error) {
+	// Regular users may only create subscriptions for themselves; user_id defaults to the caller
+	var requestedUserID *uuid.UUID
+	if req.UserID != uuid.Nil {
+		requestedUserID = &req.UserID
+	}
+	userID, err := scopeToCaller(ctx, requestedUserID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != nil {
+		req.UserID = *userID
+	}
+
+	serviceID, err := s.applyCatalog(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if fieldErrs := validateCreateRequest(req); len(fieldErrs) > 0 {
+		return nil, errs.ValidationFields("invalid input data: service_name, price, and user_id are required", fieldErrs)
+	}
+
+	subscriptionCurrency := DefaultCurrency
+	if req.Currency != "" {
+		subscriptionCurrency, err = parseCurrency("currency", req.Currency)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	result, err := s.txMgr.ExecuteWithResult(func(tx database.Transaction) (interface{}, error) {
-		gormTx := database.GetDB(tx)
+	billingPeriod, intervalCount, err := parseBillingCycle(req.BillingPeriod, req.BillingIntervalCount)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := parseYearMonth("start_date", req.StartDate)
+	if err != nil {
+		return nil, err
+	}
 
-		// Business rule: Check for duplicates
-		exists, err := s.repo.ExistsByUserServiceAndDate(gormTx, req.UserID, req.ServiceName, req.StartDate)
+	var endDate *models.YearMonth
+	if req.EndDate != nil && *req.EndDate != "" {
+		parsedEnd, err := parseYearMonth("end_date", *req.EndDate)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to check for duplicate subscription")
-			return nil, errors.New("failed to validate subscription uniqueness")
+			return nil, err
 		}
-		if exists {
-			return nil, errors.New("subscription already exists for this user and service in the same period")
+		if !parsedEnd.After(startDate) {
+			return nil, errs.Validation("end_date", errs.CodeInvalidDateRange, "end_date must be after start_date")
 		}
+		endDate = &parsedEnd
+	}
 
-		// Create subscription
-		subscription := &models.Subscription{
-			ServiceName: req.ServiceName,
-			Price:       req.Price,
-			UserID:      req.UserID,
-			StartDate:   req.StartDate,
-			EndDate:     req.EndDate,
+	var trialEnd *models.YearMonth
+	if req.TrialEnd != nil && *req.TrialEnd != "" {
+		parsedTrialEnd, err := parseYearMonth("trial_end", *req.TrialEnd)
+		if err != nil {
+			return nil, err
 		}
+		trialEnd = &parsedTrialEnd
+	}
 
-		err = s.repo.Create(gormTx, subscription)
-		if err != nil {
+	subscription := &models.Subscription{
+		ServiceName:          req.ServiceName,
+		ServiceID:            serviceID,
+		Price:                req.Price,
+		Currency:             subscriptionCurrency,
+		BillingPeriod:        billingPeriod,
+		BillingIntervalCount: intervalCount,
+		UserID:               req.UserID,
+		StartDate:            startDate,
+		EndDate:              endDate,
+		TrialEnd:             trialEnd,
+		TrialPrice:           req.TrialPrice,
+		Version:              1,
+	}
+	if err := validateTrial(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// insertSubscription stores a validated subscription and records its created event in tx
+func (s *SubscriptionService) insertSubscription(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) error {
+	if err := s.checkDuplicate(ctx, tx, subscription); err != nil {
+		return err
+	}
+
+	err := s.budgets.WatchBudgets(ctx, tx, subscription, func() error {
+		if err := s.repo.Create(ctx, tx, subscription); err != nil {
 			s.logger.WithError(err).Error("Failed to create subscription")
-			return nil, errors.New("failed to create subscription")
+			return errs.Internal("failed to create subscription")
 		}
+		return s.savePrice(ctx, tx, subscription, subscription.StartDate)
+	})
+	if err != nil {
+		return err
+	}
 
-		s.logger.WithFields(logrus.Fields{
-			"subscription_id": subscription.ID,
-			"user_id":         req.UserID,
-			"service_name":    req.ServiceName,
-		}).Info("Subscription created successfully")
+	if err := s.recordEvent(ctx, tx, models.EventSubscriptionCreated, subscription); err != nil {
+		return err
+	}
+	if err := s.recordAudit(ctx, tx, models.AuditActionCreate, subscription, nil, subscriptionFields(subscription)); err != nil {
+		return err
+	}
 
-		return subscription, nil
-	})
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": subscription.ID,
+		"user_id":         subscription.UserID,
+		"service_name":    subscription.ServiceName,
+	}).Info("Subscription created successfully")
 
+	return nil
+}
+
+// checkDuplicate enforces the business rule that a user has one subscription per service starting in a month
+func (s *SubscriptionService) checkDuplicate(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) error {
+	exists, err := s.repo.ExistsByUserServiceAndDate(ctx, tx, subscription.UserID, subscription.ServiceName, subscription.StartDate)
 	if err != nil {
-		return nil, err
+		s.logger.WithError(err).Error("Failed to check for duplicate subscription")
+		return errs.Internal("failed to validate subscription uniqueness")
 	}
-
-	return result.(*models.Subscription), nil
+	if exists {
+		return errs.Conflict("start_date", errs.CodeSubscriptionExists, "subscription already exists for this user and service in the same period")
+	}
+	return nil
 }
 
 // GetSubscriptionByID retrieves a subscription by ID
-func (s *SubscriptionService) GetSubscriptionByID(id uint) (*models.Subscription, error) {
-	return s.repo.GetByID(nil, id)
+func (s *SubscriptionService) GetSubscriptionByID(ctx context.Context, id uint) (*models.Subscription, error) {
+	subscription, err := s.repo.GetByID(ctx, nil, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")
+		}
+		s.logger.WithError(err).Error("Failed to retrieve subscription")
+		return nil, errs.Internal("failed to retrieve subscription")
+	}
+
+	// Other users' subscriptions are reported as missing so their IDs are not disclosed
+	if !canAccess(ctx, subscription) {
+		return nil, errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")
+	}
+	return subscription, nil
 }
 
-// UpdateSubscription updates an existing subscription with transaction-based validation
-func (s *SubscriptionService) UpdateSubscription(id uint, updates map[string]interface{}) (*models.Subscription, error) {
-	result, err := s.txMgr.ExecuteWithResult(func(tx database.Transaction) (interface{}, error) {
-		gormTx := database.GetDB(tx)
+// UpdateSubscription applies a JSON Merge Patch to an existing subscription with transaction-based
+// validation. Fields absent from the patch are left unchanged.
+func (s *SubscriptionService) UpdateSubscription(ctx context.Context, id uint, req *models.UpdateSubscriptionRequest, precondition *models.Precondition) (*models.Subscription, error) {
+	return s.modify(ctx, id, precondition, patchChange(req))
+}
 
-		// Get current subscription
-		subscription, err := s.repo.GetByID(gormTx, id)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, errors.New("subscription not found")
+// patchChange applies the fields present in a merge patch to a subscription
+func patchChange(req *models.UpdateSubscriptionRequest) func(next *models.Subscription) error {
+	return func(next *models.Subscription) error {
+		if req.ServiceName != nil {
+			if *req.ServiceName == "" {
+				return errs.Validation("service_name", errs.CodeRequired, "service_name cannot be empty")
 			}
-			return nil, errors.New("failed to retrieve subscription")
+			next.ServiceName = *req.ServiceName
 		}
 
-		updatedFields := make(map[string]interface{})
-		hasChanges := false
+		if req.Price != nil {
+			if *req.Price <= 0 {
+				return errs.Validation("price", errs.CodeInvalidPrice, "price must be greater than 0")
+			}
+			next.Price = *req.Price
+		}
 
-		// Process updates with business validation
-		if serviceName, ok := updates["service_name"].(string); ok && serviceName != "" {
-			if serviceName != subscription.ServiceName {
-				// Business rule: Check for conflicts with new service name
-				exists, err := s.repo.ExistsByUserServiceAndDate(gormTx, subscription.UserID, serviceName, subscription.StartDate)
-				if err != nil {
-					return nil, errors.New("failed to validate subscription uniqueness")
-				}
-				if exists {
-					return nil, errors.New("subscription with this service name already exists for this user and date")
-				}
-				subscription.ServiceName = serviceName
-				updatedFields["service_name"] = serviceName
-				hasChanges = true
+		if req.Currency != nil {
+			currency, err := parseCurrency("currency", *req.Currency)
+			if err != nil {
+				return err
 			}
+			next.Currency = currency
 		}
 
-		if price, ok := updates["price"].(float64); ok {
-			if price <= 0 {
-				return nil, errors.New("price must be greater than 0")
+		if req.BillingPeriod != nil {
+			period, err := models.ParseBillingPeriod(*req.BillingPeriod)
+			if err != nil {
+				return errs.Validation("billing_period", errs.CodeInvalidBillingPeriod, "billing_period must be one of week, month, quarter, year")
 			}
-			newPrice := int(price)
-			if newPrice != subscription.Price {
-				subscription.Price = newPrice
-				updatedFields["price"] = newPrice
-				hasChanges = true
+			next.BillingPeriod = period
+		}
+
+		if req.BillingIntervalCount != nil {
+			if *req.BillingIntervalCount < 1 {
+				return errs.Validation("billing_interval_count", errs.CodeInvalidIntervalCount, "billing_interval_count must be a positive integer")
 			}
+			next.BillingIntervalCount = *req.BillingIntervalCount
 		}
 
-		if startDate, ok := updates["start_date"].(string); ok && startDate != "" {
-			if !isValidDate(startDate) {
-				return nil, errors.New("start_date must be in MM-YYYY format")
+		if req.StartDate != nil {
+			startDate, err := parseYearMonth("start_date", *req.StartDate)
+			if err != nil {
+				return err
 			}
-			if startDate != subscription.StartDate {
-				// Validate against end_date
-				if subscription.EndDate != nil && *subscription.EndDate <= startDate {
-					return nil, errors.New("start_date must be before end_date")
-				}
-				// Check for conflicts with new start date
-				exists, err := s.repo.ExistsByUserServiceAndDate(gormTx, subscription.UserID, subscription.ServiceName, startDate)
+			next.StartDate = startDate
+		}
+
+		if req.EndDate.Set {
+			next.EndDate = nil
+			if req.EndDate.Value != nil {
+				endDate, err := parseYearMonth("end_date", *req.EndDate.Value)
 				if err != nil {
-					return nil, errors.New("failed to validate subscription uniqueness")
-				}
-				if exists {
-					return nil, errors.New("subscription already exists for this user, service, and date")
+					return err
 				}
-				subscription.StartDate = startDate
-				updatedFields["start_date"] = startDate
-				hasChanges = true
+				next.EndDate = &endDate
 			}
 		}
 
-		if endDate, ok := updates["end_date"].(string); ok {
-			if endDate != "" {
-				if !isValidDate(endDate) {
-					return nil, errors.New("end_date must be in MM-YYYY format")
-				}
-				if endDate <= subscription.StartDate {
-					return nil, errors.New("end_date must be after start_date")
+		// Clearing trial_end also resets trial_price
+		if req.TrialEnd.Set {
+			next.TrialEnd = nil
+			next.TrialPrice = 0
+			if req.TrialEnd.Value != nil {
+				trialEnd, err := parseYearMonth("trial_end", *req.TrialEnd.Value)
+				if err != nil {
+					return err
 				}
-				subscription.EndDate = &endDate
-			} else {
-				subscription.EndDate = nil
+				next.TrialEnd = &trialEnd
 			}
-			updatedFields["end_date"] = endDate
-			hasChanges = true
 		}
 
-		// Only update if there are changes
-		if hasChanges {
-			err = s.repo.Update(gormTx, subscription)
-			if err != nil {
-				s.logger.WithError(err).Error("Failed to update subscription")
-				return nil, errors.New("failed to update subscription")
-			}
+		if req.TrialPrice != nil {
+			next.TrialPrice = *req.TrialPrice
+		}
+		return nil
+	}
+}
+
+// ReplaceSubscription replaces every field of an existing subscription. Optional fields missing
+// from the request are reset to their defaults, and a missing end_date makes the subscription
+// open-ended. The owner cannot be changed. Catalog defaults do not apply: a replacement is
+// taken as sent, and only a new service name is resolved through the catalog.
+func (s *SubscriptionService) ReplaceSubscription(ctx context.Context, id uint, req *models.CreateSubscriptionRequest, precondition *models.Precondition) (*models.Subscription, error) {
+	var fieldErrs []errs.FieldError
+	for _, fieldErr := range validateCreateRequest(req) {
+		// user_id may be omitted, the subscription keeps its owner
+		if fieldErr.Field != "user_id" {
+			fieldErrs = append(fieldErrs, fieldErr)
 		}
+	}
+	if len(fieldErrs) > 0 {
+		return nil, errs.ValidationFields("invalid input data: service_name, price and start_date are required", fieldErrs)
+	}
 
-		s.logger.WithFields(logrus.Fields{
-			"subscription_id": id,
-			"updated_fields":  updatedFields,
-		}).Info("Subscription updated successfully")
+	currency := DefaultCurrency
+	if req.Currency != "" {
+		var err error
+		if currency, err = parseCurrency("currency", req.Currency); err != nil {
+			return nil, err
+		}
+	}
 
-		return subscription, nil
+	billingPeriod, intervalCount, err := parseBillingCycle(req.BillingPeriod, req.BillingIntervalCount)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := parseYearMonth("start_date", req.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var endDate *models.YearMonth
+	if req.EndDate != nil && *req.EndDate != "" {
+		parsedEnd, err := parseYearMonth("end_date", *req.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		endDate = &parsedEnd
+	}
+
+	var trialEnd *models.YearMonth
+	if req.TrialEnd != nil && *req.TrialEnd != "" {
+		parsedTrialEnd, err := parseYearMonth("trial_end", *req.TrialEnd)
+		if err != nil {
+			return nil, err
+		}
+		trialEnd = &parsedTrialEnd
+	}
+
+	return s.modify(ctx, id, precondition, func(next *models.Subscription) error {
+		if req.UserID != uuid.Nil && req.UserID != next.UserID {
+			return errs.Validation("user_id", errs.CodeInvalidInput, "user_id of a subscription cannot be changed")
+		}
+		next.ServiceName = req.ServiceName
+		next.Price = req.Price
+		next.Currency = currency
+		next.BillingPeriod = billingPeriod
+		next.BillingIntervalCount = intervalCount
+		next.StartDate = startDate
+		next.EndDate = endDate
+		next.TrialEnd = trialEnd
+		next.TrialPrice = req.TrialPrice
+		return nil
+	})
+}
+
+// modify loads a subscription the caller may access, checks the precondition, lets change edit a
+// copy of it and saves the result when it differs. The duplicate check, lifecycle events and
+// audit event run in the same transaction.
+func (s *SubscriptionService) modify(ctx context.Context, id uint, precondition *models.Precondition, change func(next *models.Subscription) error) (*models.Subscription, error) {
+	result, err := s.txMgr.ExecuteWithResult(ctx, func(tx database.Transaction) (interface{}, error) {
+		return s.modifyTx(ctx, database.GetDB(tx), id, precondition, change)
 	})
 
 	if err != nil {
@@ -205,87 +403,368 @@ func (s *SubscriptionService) UpdateSubscription(id uint, updates map[string]int
 	return result.(*models.Subscription), nil
 }
 
-// DeleteSubscription deletes a subscription with validation
-func (s *SubscriptionService) DeleteSubscription(id uint) error {
-	return s.txMgr.Execute(func(tx database.Transaction) error {
-		gormTx := database.GetDB(tx)
+// modifyTx is modify within the caller's transaction
+func (s *SubscriptionService) modifyTx(ctx context.Context, gormTx *gorm.DB, id uint, precondition *models.Precondition, change func(next *models.Subscription) error) (*models.Subscription, error) {
+	// Get current subscription
+	subscription, err := s.repo.GetByID(ctx, gormTx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")
+		}
+		return nil, errs.Internal("failed to retrieve subscription")
+	}
+	if !canAccess(ctx, subscription) {
+		return nil, errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")
+	}
+	if !precondition.Matches(subscription.Version) {
+		return nil, errs.PreconditionFailed("subscription has been modified; fetch it again and retry")
+	}
+
+	next := *subscription
+	if err := change(&next); err != nil {
+		return nil, err
+	}
 
-		// Business validation: Check if exists
-		exists, err := s.repo.ExistsByID(gormTx, id)
+	// A new service name is resolved through the catalog, which may link or unlink a service
+	if next.ServiceName != subscription.ServiceName {
+		name, service, err := s.resolveServiceName(ctx, next.ServiceName)
 		if err != nil {
-			return errors.New("failed to validate subscription")
+			return nil, err
+		}
+		if name == "" {
+			return nil, errs.Validation("service_name", errs.CodeRequired, "service_name cannot be empty")
 		}
-		if !exists {
-			return errors.New("subscription not found")
+		next.ServiceName = name
+		next.ServiceID = nil
+		if service != nil {
+			next.ServiceID = &service.ID
 		}
+	}
+
+	if next.EndDate != nil && !next.EndDate.After(next.StartDate) {
+		return nil, errs.Validation("end_date", errs.CodeInvalidDateRange, "end_date must be after start_date")
+	}
+	if err := validateTrial(&next); err != nil {
+		return nil, err
+	}
+
+	updatedFields := changedFields(subscription, &next)
+	if len(updatedFields) == 0 {
+		return subscription, nil
+	}
 
-		// Delete subscription
-		err = s.repo.Delete(gormTx, id)
+	// Business rule: a new service name or start date must not collide with another subscription.
+	// Names are compared case-insensitively, so a change of case alone cannot collide.
+	renamed := models.NormalizeServiceName(next.ServiceName) != models.NormalizeServiceName(subscription.ServiceName)
+	if renamed || next.StartDate != subscription.StartDate {
+		exists, err := s.repo.ExistsByUserServiceAndDate(ctx, gormTx, next.UserID, next.ServiceName, next.StartDate)
 		if err != nil {
-			s.logger.WithError(err).Error("Failed to delete subscription")
-			return errors.New("failed to delete subscription")
+			return nil, errs.Internal("failed to validate subscription uniqueness")
 		}
+		if exists {
+			field := "start_date"
+			if renamed {
+				field = "service_name"
+			}
+			return nil, errs.Conflict(field, errs.CodeSubscriptionExists, "subscription already exists for this user, service, and date")
+		}
+	}
 
-		s.logger.WithField("subscription_id", id).Info("Subscription deleted successfully")
-		return nil
+	_, repriced := updatedFields["price"]
+	_, trialEndChanged := updatedFields["trial_end"]
+	_, trialPriceChanged := updatedFields["trial_price"]
+	write := func() error {
+		updated, err := s.repo.Update(ctx, gormTx, &next)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to update subscription")
+			return errs.Internal("failed to update subscription")
+		}
+		if !updated {
+			return concurrentWriteError(precondition)
+		}
+
+		// A new price applies from the current month on, so past months keep costing the old one
+		if !repriced {
+			return nil
+		}
+		effectiveFrom := models.YearMonthOf(s.now().UTC())
+		if effectiveFrom.Before(next.StartDate) {
+			effectiveFrom = next.StartDate
+		}
+		return s.savePrice(ctx, gormTx, &next, effectiveFrom)
+	}
+
+	// Only a change of price or trial can push a budget over its limit
+	if repriced || trialEndChanged || trialPriceChanged {
+		err = s.budgets.WatchBudgets(ctx, gormTx, &next, write)
+	} else {
+		err = write()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordEvent(ctx, gormTx, models.EventSubscriptionUpdated, &next); err != nil {
+		return nil, err
+	}
+	if _, ended := updatedFields["end_date"]; ended && next.EndDate != nil {
+		if err := s.recordEvent(ctx, gormTx, models.EventSubscriptionEnded, &next); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.recordAudit(ctx, gormTx, models.AuditActionUpdate, &next, changedFields(&next, subscription), updatedFields); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"subscription_id": id,
+		"updated_fields":  updatedFields,
+	}).Info("Subscription updated successfully")
+
+	return &next, nil
+}
+
+// concurrentWriteError reports a write that lost the race against another writer between
+// reading and writing the subscription. Clients that sent If-Match get the same 412 as for a
+// stale ETag; the others get a conflict they can retry.
+func concurrentWriteError(precondition *models.Precondition) error {
+	if precondition != nil {
+		return errs.PreconditionFailed("subscription has been modified; fetch it again and retry")
+	}
+	return errs.Conflict("", errs.CodeVersionConflict, "subscription was modified concurrently; retry the request")
+}
+
+// changedFields lists the editable fields that differ between two versions of a subscription
+func changedFields(current, next *models.Subscription) map[string]interface{} {
+	changed := make(map[string]interface{})
+	if next.ServiceName != current.ServiceName {
+		changed["service_name"] = next.ServiceName
+	}
+	if next.Price != current.Price {
+		changed["price"] = next.Price
+	}
+	if next.Currency != current.Currency {
+		changed["currency"] = next.Currency
+	}
+	if next.BillingPeriod != current.BillingPeriod {
+		changed["billing_period"] = next.BillingPeriod
+	}
+	if next.BillingIntervalCount != current.BillingIntervalCount {
+		changed["billing_interval_count"] = next.BillingIntervalCount
+	}
+	if next.StartDate != current.StartDate {
+		changed["start_date"] = next.StartDate.String()
+	}
+	switch {
+	case next.EndDate == nil && current.EndDate != nil:
+		changed["end_date"] = nil
+	case next.EndDate != nil && (current.EndDate == nil || *next.EndDate != *current.EndDate):
+		changed["end_date"] = next.EndDate.String()
+	}
+	switch {
+	case next.TrialEnd == nil && current.TrialEnd != nil:
+		changed["trial_end"] = nil
+	case next.TrialEnd != nil && (current.TrialEnd == nil || *next.TrialEnd != *current.TrialEnd):
+		changed["trial_end"] = next.TrialEnd.String()
+	}
+	if next.TrialPrice != current.TrialPrice {
+		changed["trial_price"] = next.TrialPrice
+	}
+	return changed
+}
+
+// DeleteSubscription deletes a subscription with validation
+func (s *SubscriptionService) DeleteSubscription(ctx context.Context, id uint, precondition *models.Precondition) error {
+	return s.txMgr.Execute(ctx, func(tx database.Transaction) error {
+		return s.deleteTx(ctx, database.GetDB(tx), id, precondition)
 	})
 }
 
-// ListSubscriptions retrieves subscriptions with optional filtering
-func (s *SubscriptionService) ListSubscriptions(userID *uuid.UUID, serviceName *string, limit, offset int) ([]models.Subscription, error) {
-	if limit <= 0 {
-		limit = 50
+// deleteTx is DeleteSubscription within the caller's transaction
+func (s *SubscriptionService) deleteTx(ctx context.Context, gormTx *gorm.DB, id uint, precondition *models.Precondition) error {
+	// Business validation: Check if exists and belongs to the caller
+	subscription, err := s.repo.GetByID(ctx, gormTx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")
+		}
+		return errs.Internal("failed to validate subscription")
+	}
+	if !canAccess(ctx, subscription) {
+		return errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")
+	}
+	if !precondition.Matches(subscription.Version) {
+		return errs.PreconditionFailed("subscription has been modified; fetch it again and retry")
 	}
-	return s.repo.List(userID, serviceName, limit, offset)
+
+	// Delete subscription
+	deleted, err := s.repo.Delete(ctx, gormTx, id, subscription.Version)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to delete subscription")
+		return errs.Internal("failed to delete subscription")
+	}
+	if !deleted {
+		return concurrentWriteError(precondition)
+	}
+
+	if err := s.recordEvent(ctx, gormTx, models.EventSubscriptionDeleted, subscription); err != nil {
+		return err
+	}
+	if err := s.recordAudit(ctx, gormTx, models.AuditActionDelete, subscription, subscriptionFields(subscription), nil); err != nil {
+		return err
+	}
+
+	s.logger.WithField("subscription_id", id).Info("Subscription deleted successfully")
+	return nil
 }
 
-// CalculateTotalCost calculates total cost with proper month consideration and database aggregation
-func (s *SubscriptionService) CalculateTotalCost(req *models.CostCalculationRequest) (*models.CostCalculationResponse, error) {
-	// Validate date formats
-	if !isValidDate(req.StartDate) {
-		return nil, errors.New("start_date must be in MM-YYYY format")
+// ListSubscriptions retrieves a page of subscriptions matching the filter, ordered by creation
+// time unless a sort is requested. Pages are addressed by an opaque keyset cursor; a plain
+// offset is still honoured when no cursor is given, and is the only option with a custom sort.
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, filterReq *models.SubscriptionFilterRequest, page models.PageRequest) (*models.SubscriptionPage, error) {
+	limit := page.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
 	}
-	if !isValidDate(req.EndDate) {
-		return nil, errors.New("end_date must be in MM-YYYY format")
+
+	var cursor *models.Cursor
+	if page.Cursor != "" {
+		var err error
+		if cursor, err = models.DecodeCursor(page.Cursor); err != nil {
+			return nil, errs.Validation("cursor", errs.CodeInvalidCursor, "invalid cursor")
+		}
 	}
 
-	// Validate date range
-	if req.EndDate <= req.StartDate {
-		return nil, errors.New("end_date must be after start_date")
+	filter, err := s.parseFilter(ctx, filterReq)
+	if err != nil {
+		return nil, err
+	}
+	// Cursors encode a (created_at, id) position, which means nothing in another order
+	keyset := len(filter.Sort) == 0
+	if cursor != nil && !keyset {
+		return nil, errs.Validation("cursor", errs.CodeInvalidCursor, "cursor cannot be combined with sort; use offset instead")
+	}
+
+	// Fetch one extra row to learn whether another page follows in the walking direction
+	subscriptions, err := s.repo.List(ctx, filter, cursor, limit+1, page.Offset)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list subscriptions")
+		return nil, errs.Internal("failed to retrieve subscriptions")
+	}
+	hasMore := len(subscriptions) > limit
+	if hasMore {
+		if cursor != nil && cursor.Backward {
+			subscriptions = subscriptions[1:]
+		} else {
+			subscriptions = subscriptions[:limit]
+		}
+	}
+
+	result := &models.SubscriptionPage{Items: subscriptions}
+	if result.Items == nil {
+		result.Items = []models.Subscription{}
+	}
+
+	var next, prev *models.Cursor
+	switch {
+	case !keyset:
+	case len(subscriptions) > 0:
+		first, last := &subscriptions[0], &subscriptions[len(subscriptions)-1]
+		backward := cursor != nil && cursor.Backward
+		if hasMore && !backward || backward {
+			next = models.CursorAfter(last)
+		}
+		if hasMore && backward || cursor != nil && !backward || cursor == nil && page.Offset > 0 {
+			prev = models.CursorBefore(first)
+		}
+	case cursor != nil && cursor.Backward:
+		// Nothing precedes the cursor; allow walking forward from the same position
+		next = &models.Cursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID}
+	case cursor != nil:
+		prev = &models.Cursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID, Backward: true}
+	}
+	if next != nil {
+		token := next.Encode()
+		result.NextCursor = &token
+	}
+	if prev != nil {
+		token := prev.Encode()
+		result.PrevCursor = &token
+	}
+
+	if page.IncludeTotal {
+		total, err := s.repo.Count(ctx, filter)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to count subscriptions")
+			return nil, errs.Internal("failed to retrieve subscriptions")
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
+// CalculateTotalCost calculates total cost with database aggregation, charging each
+// subscription only for the months its own period overlaps the requested range
+func (s *SubscriptionService) CalculateTotalCost(ctx context.Context, req *models.CostCalculationRequest) (*models.CostCalculationResponse, error) {
+	startDate, endDate, targetCurrency, err := parseCostRange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restrict the report to the caller's own subscriptions
+	filter, err := s.parseFilter(ctx, &req.SubscriptionFilterRequest)
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate total months in requested period
-	totalMonths := calculateMonthsBetween(req.StartDate, req.EndDate)
+	totalMonths := calculateMonthsBetween(startDate, endDate)
 
-	// Use repository method for database aggregation
-	totalCost, err := s.repo.CalculateTotalCostInDB(req.UserID, req.ServiceName, req.StartDate, req.EndDate, totalMonths)
+	// Use repository method for database aggregation, grouped by currency
+	totals, err := s.repo.CalculateTotalCostInDB(ctx, nil, filter, startDate, endDate)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to calculate total cost in database")
+		return nil, errs.Internal("failed to calculate total cost")
+	}
+	if totals == nil {
+		totals = []models.CurrencyAmount{}
+	}
+
+	totalCost, totalCurrency, err := s.combineTotals(ctx, totals, targetCurrency)
+	if err != nil {
 		return nil, err
 	}
 
-	// Get subscriptions for response details
-	subscriptions, err := s.repo.GetSubscriptionsInDateRange(req.UserID, req.ServiceName, req.StartDate, req.EndDate)
+	// Get subscriptions with their billed months and subtotals for response details
+	subscriptions, err := s.repo.GetSubscriptionsInDateRange(ctx, filter, startDate, endDate)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to get subscriptions in date range")
-		return nil, err
+		return nil, errs.Internal("failed to retrieve subscriptions in date range")
 	}
 
 	response := &models.CostCalculationResponse{
 		TotalCost:     totalCost,
-		StartDate:     req.StartDate,
-		EndDate:       req.EndDate,
-		UserID:        req.UserID,
-		ServiceName:   req.ServiceName,
+		Currency:      totalCurrency,
+		Totals:        totals,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		UserID:        filter.UserID,
 		Subscriptions: subscriptions,
 	}
+	if len(req.ServiceNames) == 1 {
+		response.ServiceName = &req.ServiceNames[0]
+	} else if len(req.ServiceNames) > 1 {
+		response.ServiceNames = req.ServiceNames
+	}
 
 	s.logger.WithFields(logrus.Fields{
-		"user_id":            req.UserID,
-		"service_name":       req.ServiceName,
+		"user_id":            filter.UserID,
+		"service_names":      filter.ServiceNames,
 		"start_date":         req.StartDate,
 		"end_date":           req.EndDate,
-		"total_cost":         totalCost,
+		"totals":             totals,
+		"target_currency":    targetCurrency,
 		"total_months":       totalMonths,
 		"subscription_count": len(subscriptions),
 	}).Info("Total cost calculated with database aggregation")
@@ -293,21 +772,231 @@ func (s *SubscriptionService) CalculateTotalCost(req *models.CostCalculationRequ
 	return response, nil
 }
 
-// Helper function to calculate months between MM-YYYY dates
-func calculateMonthsBetween(startDate, endDate string) int {
-	startParts := strings.Split(startDate, "-")
-	endParts := strings.Split(endDate, "-")
+// parseCostRange validates the range and target currency of a cost calculation. The target
+// currency is empty when none was requested.
+func parseCostRange(req *models.CostCalculationRequest) (models.YearMonth, models.YearMonth, string, error) {
+	// Validate date formats
+	startDate, err := parseYearMonth("start_date", req.StartDate)
+	if err != nil {
+		return models.YearMonth{}, models.YearMonth{}, "", err
+	}
+	endDate, err := parseYearMonth("end_date", req.EndDate)
+	if err != nil {
+		return models.YearMonth{}, models.YearMonth{}, "", err
+	}
+
+	// Validate date range
+	if !endDate.After(startDate) {
+		return models.YearMonth{}, models.YearMonth{}, "", errs.Validation("end_date", errs.CodeInvalidDateRange, "end_date must be after start_date")
+	}
+
+	var targetCurrency string
+	if req.TargetCurrency != nil && *req.TargetCurrency != "" {
+		targetCurrency, err = parseCurrency("target_currency", *req.TargetCurrency)
+		if err != nil {
+			return models.YearMonth{}, models.YearMonth{}, "", err
+		}
+	}
+	return startDate, endDate, targetCurrency, nil
+}
+
+// ListUpcomingCharges lists the next charge of the active subscriptions falling within the
+// next withinDays days (today included), sorted by charge date. Only the first limit charges
+// are listed.
+func (s *SubscriptionService) ListUpcomingCharges(ctx context.Context, userID *uuid.UUID, withinDays, limit int) ([]models.UpcomingCharge, error) {
+	if withinDays < 1 || withinDays > MaxUpcomingWithinDays {
+		return nil, errs.Validation("within_days", errs.CodeInvalidInput, fmt.Sprintf("within_days must be between 1 and %d", MaxUpcomingWithinDays))
+	}
+	if limit < 1 || limit > MaxUpcomingLimit {
+		return nil, errs.Validation("limit", errs.CodeInvalidInput, fmt.Sprintf("limit must be between 1 and %d", MaxUpcomingLimit))
+	}
 
-	startMonth, _ := strconv.Atoi(startParts[0])
-	startYear, _ := strconv.Atoi(startParts[1])
-	endMonth, _ := strconv.Atoi(endParts[0])
-	endYear, _ := strconv.Atoi(endParts[1])
+	// Restrict the listing to the caller's own subscriptions
+	userID, err := scopeToCaller(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	return (endYear-startYear)*12 + (endMonth - startMonth) + 1
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	horizon := today.AddDate(0, 0, withinDays)
+
+	subscriptions, err := s.repo.ListChargedWithin(ctx, &models.SubscriptionFilter{UserID: userID}, today, horizon, limit)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list subscriptions for upcoming charges")
+		return nil, errs.Internal("failed to retrieve subscriptions")
+	}
+
+	charges := []models.UpcomingCharge{}
+	for i := range subscriptions {
+		sub := &subscriptions[i]
+		next, ok := sub.NextChargeOn(today)
+		if !ok || next.After(horizon) {
+			continue
+		}
+		charges = append(charges, models.UpcomingCharge{
+			SubscriptionID:       sub.ID,
+			ServiceName:          sub.ServiceName,
+			UserID:               sub.UserID,
+			Amount:               sub.PriceOn(next),
+			Currency:             sub.Currency,
+			BillingPeriod:        sub.BillingPeriod,
+			BillingIntervalCount: sub.BillingIntervalCount,
+			NextChargeDate:       next.Format(time.DateOnly),
+			DaysUntil:            int(next.Sub(today).Hours() / 24),
+		})
+	}
+
+	sort.SliceStable(charges, func(i, j int) bool {
+		if charges[i].NextChargeDate != charges[j].NextChargeDate {
+			return charges[i].NextChargeDate < charges[j].NextChargeDate
+		}
+		return charges[i].SubscriptionID < charges[j].SubscriptionID
+	})
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"within_days":  withinDays,
+		"limit":        limit,
+		"charge_count": len(charges),
+	}).Info("Upcoming charges calculated successfully")
+
+	return charges, nil
+}
+
+// recordEvent writes a lifecycle event to the webhook outbox within the caller's transaction
+func (s *SubscriptionService) recordEvent(ctx context.Context, tx *gorm.DB, eventType string, subscription *models.Subscription) error {
+	payload, err := json.Marshal(subscription)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to encode subscription event")
+		return errs.Internal("failed to record subscription event")
+	}
+
+	event := &models.WebhookEvent{
+		EventType:      eventType,
+		SubscriptionID: subscription.ID,
+		Payload:        payload,
+	}
+	if err := s.events.RecordEvent(ctx, tx, event); err != nil {
+		s.logger.WithError(err).WithField("event_type", eventType).Error("Failed to record subscription event")
+		return errs.Internal("failed to record subscription event")
+	}
+	return nil
 }
 
-// Simple date validation helper
-func isValidDate(date string) bool {
-	matched, _ := regexp.MatchString(`^(0[1-9]|1[0-2])-[0-9]{4}$`, date)
-	return matched
+// combineTotals folds per-currency totals into a single amount. With a target currency every
+// total is converted; otherwise a single amount is only reported when all totals share a currency.
+func (s *SubscriptionService) combineTotals(ctx context.Context, totals []models.CurrencyAmount, targetCurrency string) (*int, string, error) {
+	if targetCurrency == "" {
+		switch len(totals) {
+		case 0:
+			zero := 0
+			return &zero, "", nil
+		case 1:
+			amount := totals[0].Amount
+			return &amount, totals[0].Currency, nil
+		default:
+			return nil, "", nil
+		}
+	}
+
+	combined := 0
+	for _, total := range totals {
+		converted, err := s.converter.Convert(ctx, total.Amount, total.Currency, targetCurrency)
+		if err != nil {
+			return nil, "", err
+		}
+		combined += converted
+	}
+	return &combined, targetCurrency, nil
+}
+
+// scopeToCaller restricts a user filter to the authenticated caller. Admins and internal
+// callers without a principal may query any user; everyone else only sees their own rows.
+func scopeToCaller(ctx context.Context, userID *uuid.UUID) (*uuid.UUID, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.IsAdmin() {
+		return userID, nil
+	}
+	if userID != nil && *userID != principal.UserID {
+		return nil, errs.Forbidden("user_id", "cannot access subscriptions of another user")
+	}
+	callerID := principal.UserID
+	return &callerID, nil
+}
+
+// canAccess reports whether the caller in ctx may see the subscription
+func canAccess(ctx context.Context, subscription *models.Subscription) bool {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	return !ok || principal.CanAccess(subscription.UserID)
+}
+
+// validateCreateRequest reports every missing or invalid required field
+func validateCreateRequest(req *models.CreateSubscriptionRequest) []errs.FieldError {
+	var fieldErrs []errs.FieldError
+	if req.ServiceName == "" {
+		fieldErrs = append(fieldErrs, errs.FieldError{Field: "service_name", Code: errs.CodeRequired, Message: "service_name is required"})
+	}
+	if req.Price <= 0 {
+		fieldErrs = append(fieldErrs, errs.FieldError{Field: "price", Code: errs.CodeInvalidPrice, Message: "price must be greater than 0"})
+	}
+	if req.UserID == uuid.Nil {
+		fieldErrs = append(fieldErrs, errs.FieldError{Field: "user_id", Code: errs.CodeRequired, Message: "user_id is required"})
+	}
+	return fieldErrs
+}
+
+// parseBillingCycle validates a requested billing cycle, defaulting to every 1 month
+func parseBillingCycle(period string, intervalCount int) (models.BillingPeriod, int, error) {
+	billingPeriod := models.BillingPeriodMonth
+	if period != "" {
+		parsed, err := models.ParseBillingPeriod(period)
+		if err != nil {
+			return "", 0, errs.Validation("billing_period", errs.CodeInvalidBillingPeriod, "billing_period must be one of week, month, quarter, year")
+		}
+		billingPeriod = parsed
+	}
+
+	switch {
+	case intervalCount == 0:
+		intervalCount = 1
+	case intervalCount < 0:
+		return "", 0, errs.Validation("billing_interval_count", errs.CodeInvalidIntervalCount, "billing_interval_count must be a positive integer")
+	}
+	return billingPeriod, intervalCount, nil
+}
+
+// validateTrial checks that a trial lies within the subscription's period and that a trial
+// price is only set together with a trial
+func validateTrial(subscription *models.Subscription) error {
+	if subscription.TrialPrice < 0 {
+		return errs.Validation("trial_price", errs.CodeInvalidPrice, "trial_price must not be negative")
+	}
+	if subscription.TrialEnd == nil {
+		if subscription.TrialPrice != 0 {
+			return errs.Validation("trial_price", errs.CodeInvalidInput, "trial_price requires trial_end")
+		}
+		return nil
+	}
+	if subscription.TrialEnd.Before(subscription.StartDate) {
+		return errs.Validation("trial_end", errs.CodeInvalidDateRange, "trial_end must not be before start_date")
+	}
+	if subscription.EndDate != nil && subscription.TrialEnd.After(*subscription.EndDate) {
+		return errs.Validation("trial_end", errs.CodeInvalidDateRange, "trial_end must not be after end_date")
+	}
+	return nil
+}
+
+// Helper function to calculate the number of months in an inclusive range
+func calculateMonthsBetween(startDate, endDate models.YearMonth) int {
+	return startDate.MonthsUntil(endDate) + 1
+}
+
+// parseYearMonth parses an MM-YYYY value and reports which field was malformed
+func parseYearMonth(field, value string) (models.YearMonth, error) {
+	ym, err := models.ParseYearMonth(value)
+	if err != nil {
+		return models.YearMonth{}, errs.Validation(field, errs.CodeInvalidDateFormat, field+" must be in MM-YYYY format")
+	}
+	return ym, nil
 }
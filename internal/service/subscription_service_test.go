@@ -1,12 +1,18 @@
 package service
 
 import (
+	"context"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"log"
+	"slices"
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/config"
+	"subscription_tracker_api/internal/errs"
 	"subscription_tracker_api/internal/infra/database"
 	"subscription_tracker_api/internal/models"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -19,51 +25,114 @@ type MockSubscriptionRepository struct {
 	mock.Mock
 }
 
-func (m *MockSubscriptionRepository) Create(tx *gorm.DB, subscription *models.Subscription) error {
-	args := m.Called(tx, subscription)
+func (m *MockSubscriptionRepository) Create(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) error {
+	args := m.Called(ctx, tx, subscription)
 	return args.Error(0)
 }
 
-func (m *MockSubscriptionRepository) GetByID(tx *gorm.DB, id uint) (*models.Subscription, error) {
-	args := m.Called(tx, id)
+func (m *MockSubscriptionRepository) GetByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Subscription, error) {
+	args := m.Called(ctx, tx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Subscription), args.Error(1)
 }
 
-func (m *MockSubscriptionRepository) Update(tx *gorm.DB, subscription *models.Subscription) error {
-	args := m.Called(tx, subscription)
-	return args.Error(0)
+func (m *MockSubscriptionRepository) Update(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) (bool, error) {
+	args := m.Called(ctx, tx, subscription)
+	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockSubscriptionRepository) Delete(tx *gorm.DB, id uint) error {
-	args := m.Called(tx, id)
-	return args.Error(0)
+func (m *MockSubscriptionRepository) Delete(ctx context.Context, tx *gorm.DB, id uint, version int) (bool, error) {
+	args := m.Called(ctx, tx, id, version)
+	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockSubscriptionRepository) ExistsByID(tx *gorm.DB, id uint) (bool, error) {
-	args := m.Called(tx, id)
+func (m *MockSubscriptionRepository) ExistsByID(ctx context.Context, tx *gorm.DB, id uint) (bool, error) {
+	args := m.Called(ctx, tx, id)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockSubscriptionRepository) List(userID *uuid.UUID, serviceName *string, limit, offset int) ([]models.Subscription, error) {
-	args := m.Called(userID, serviceName, limit, offset)
+func (m *MockSubscriptionRepository) List(ctx context.Context, filter *models.SubscriptionFilter, cursor *models.Cursor, limit, offset int) ([]models.Subscription, error) {
+	args := m.Called(ctx, filter, cursor, limit, offset)
 	return args.Get(0).([]models.Subscription), args.Error(1)
 }
 
-func (m *MockSubscriptionRepository) GetSubscriptionsInDateRange(userID *uuid.UUID, serviceName *string, startDate, endDate string) ([]models.Subscription, error) {
-	args := m.Called(userID, serviceName, startDate, endDate)
+func (m *MockSubscriptionRepository) Stream(ctx context.Context, filter *models.SubscriptionFilter, fn func(subscription *models.Subscription) error) error {
+	args := m.Called(ctx, filter)
+	for _, subscription := range args.Get(0).([]models.Subscription) {
+		if err := fn(&subscription); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetByIDUnscoped(ctx context.Context, tx *gorm.DB, id uint) (*models.Subscription, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) ListDeleted(ctx context.Context, filter *models.SubscriptionFilter, limit, offset int) ([]models.Subscription, error) {
+	args := m.Called(ctx, filter, limit, offset)
 	return args.Get(0).([]models.Subscription), args.Error(1)
 }
 
-func (m *MockSubscriptionRepository) CalculateTotalCostInDB(userID *uuid.UUID, serviceName *string, startDate, endDate string, totalMonths int) (int, error) {
-	args := m.Called(userID, serviceName, startDate, endDate, totalMonths)
-	return args.Get(0).(int), args.Error(1)
+func (m *MockSubscriptionRepository) CountDeleted(ctx context.Context, filter *models.SubscriptionFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Restore(ctx context.Context, tx *gorm.DB, subscription *models.Subscription) (bool, error) {
+	args := m.Called(ctx, tx, subscription)
+	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockSubscriptionRepository) ExistsByUserServiceAndDate(tx *gorm.DB, userID uuid.UUID, serviceName, startDate string) (bool, error) {
-	args := m.Called(tx, userID, serviceName, startDate)
+func (m *MockSubscriptionRepository) Purge(ctx context.Context, tx *gorm.DB, id uint, version int) (bool, error) {
+	args := m.Called(ctx, tx, id, version)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) ListDeletedBefore(ctx context.Context, tx *gorm.DB, before time.Time) ([]models.Subscription, error) {
+	args := m.Called(ctx, tx, before)
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) ListChargedWithin(ctx context.Context, filter *models.SubscriptionFilter, today, horizon time.Time, limit int) ([]models.Subscription, error) {
+	args := m.Called(ctx, filter, today, horizon, limit)
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) ListDueForReminders(ctx context.Context, today, horizon, trialHorizon time.Time, afterID uint, limit int) ([]models.Subscription, error) {
+	args := m.Called(ctx, today, horizon, trialHorizon, afterID, limit)
+	return args.Get(0).([]models.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Count(ctx context.Context, filter *models.SubscriptionFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetSubscriptionsInDateRange(ctx context.Context, filter *models.SubscriptionFilter, startDate, endDate models.YearMonth) ([]models.SubscriptionCost, error) {
+	args := m.Called(ctx, filter, startDate, endDate)
+	return args.Get(0).([]models.SubscriptionCost), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) CalculateTotalCostInDB(ctx context.Context, tx *gorm.DB, filter *models.SubscriptionFilter, startDate, endDate models.YearMonth) ([]models.CurrencyAmount, error) {
+	args := m.Called(ctx, tx, filter, startDate, endDate)
+	return args.Get(0).([]models.CurrencyAmount), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) CalculateSpendSeriesInDB(ctx context.Context, filter *models.SubscriptionFilter, startDate, endDate models.YearMonth, groupBy models.SpendGroupBy) ([]models.SpendPoint, error) {
+	args := m.Called(ctx, filter, startDate, endDate, groupBy)
+	return args.Get(0).([]models.SpendPoint), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) ExistsByUserServiceAndDate(ctx context.Context, tx *gorm.DB, userID uuid.UUID, serviceName string, startDate models.YearMonth) (bool, error) {
+	args := m.Called(ctx, tx, userID, serviceName, startDate)
 	return args.Bool(0), args.Error(1)
 }
 
@@ -79,36 +148,36 @@ func NewMockTransactionManager(db *gorm.DB) *MockTransactionManager {
 	}
 }
 
-func (m *MockTransactionManager) Begin() (database.Transaction, error) {
-	args := m.Called()
+func (m *MockTransactionManager) Begin(ctx context.Context) (database.Transaction, error) {
+	args := m.Called(ctx)
 	if args.Error(1) != nil {
 		return nil, args.Error(1)
 	}
 	// Return actual transaction for testing - this allows real DB operations
-	return m.db.Begin(), nil
+	return m.db.WithContext(ctx).Begin(), nil
 }
 
-func (m *MockTransactionManager) Commit(tx database.Transaction) error {
-	args := m.Called(tx)
+func (m *MockTransactionManager) Commit(ctx context.Context, tx database.Transaction) error {
+	args := m.Called(ctx, tx)
 	if gormTx, ok := tx.(*gorm.DB); ok && args.Error(0) == nil {
 		return gormTx.Commit().Error
 	}
 	return args.Error(0)
 }
 
-func (m *MockTransactionManager) Rollback(tx database.Transaction) error {
-	args := m.Called(tx)
+func (m *MockTransactionManager) Rollback(ctx context.Context, tx database.Transaction) error {
+	args := m.Called(ctx, tx)
 	if gormTx, ok := tx.(*gorm.DB); ok && args.Error(0) == nil {
 		return gormTx.Rollback().Error
 	}
 	return args.Error(0)
 }
 
-func (m *MockTransactionManager) Execute(fn func(tx database.Transaction) error) error {
-	args := m.Called(fn)
+func (m *MockTransactionManager) Execute(ctx context.Context, fn func(tx database.Transaction) error) error {
+	args := m.Called(ctx, fn)
 
 	if len(args) > 0 && !args.Bool(0) { // First return value indicates whether to skip execution
-		tx := m.db.Begin()
+		tx := m.db.WithContext(ctx).Begin()
 		defer tx.Rollback()
 
 		err := fn(tx)
@@ -124,11 +193,11 @@ func (m *MockTransactionManager) Execute(fn func(tx database.Transaction) error)
 	return nil
 }
 
-func (m *MockTransactionManager) ExecuteWithResult(fn func(tx database.Transaction) (interface{}, error)) (interface{}, error) {
-	args := m.Called(fn)
+func (m *MockTransactionManager) ExecuteWithResult(ctx context.Context, fn func(tx database.Transaction) (interface{}, error)) (interface{}, error) {
+	args := m.Called(ctx, fn)
 
 	if len(args) > 0 && !args.Bool(0) { // First return value indicates whether to skip execution
-		tx := m.db.Begin()
+		tx := m.db.WithContext(ctx).Begin()
 		defer tx.Rollback()
 
 		result, err := fn(tx)
@@ -149,7 +218,105 @@ func (m *MockTransactionManager) ExecuteWithResult(fn func(tx database.Transacti
 	return nil, nil
 }
 
+// MockIdempotencyRepository is a mock implementation of IdempotencyRepositoryInterface
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Get(ctx context.Context, tx *gorm.DB, scope, key string, now time.Time) (*models.IdempotencyKey, error) {
+	args := m.Called(ctx, tx, scope, key, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Save(ctx context.Context, tx *gorm.DB, record *models.IdempotencyKey) (bool, error) {
+	args := m.Called(ctx, tx, record)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// recordingOutbox collects the events written by the service instead of persisting them
+type recordingOutbox struct {
+	events []models.WebhookEvent
+}
+
+func (o *recordingOutbox) RecordEvent(ctx context.Context, tx *gorm.DB, event *models.WebhookEvent) error {
+	o.events = append(o.events, *event)
+	return nil
+}
+
+func (o *recordingOutbox) eventTypes() []string {
+	types := make([]string, 0, len(o.events))
+	for _, event := range o.events {
+		types = append(types, event.EventType)
+	}
+	return types
+}
+
+// recordingAuditLog collects the audit events written by the service instead of persisting them
+type recordingAuditLog struct {
+	events []models.AuditEvent
+}
+
+func (l *recordingAuditLog) Record(ctx context.Context, tx *gorm.DB, event *models.AuditEvent) error {
+	l.events = append(l.events, *event)
+	return nil
+}
+
+// recordingPriceHistory collects the prices saved by the service instead of persisting them
+type recordingPriceHistory struct {
+	prices []models.SubscriptionPrice
+}
+
+func (h *recordingPriceHistory) SavePrice(ctx context.Context, tx *gorm.DB, price *models.SubscriptionPrice) error {
+	h.prices = append(h.prices, *price)
+	return nil
+}
+
+func (h *recordingPriceHistory) ListPrices(ctx context.Context, subscriptionID uint) ([]models.SubscriptionPrice, error) {
+	var prices []models.SubscriptionPrice
+	for _, price := range h.prices {
+		if price.SubscriptionID == subscriptionID {
+			prices = append(prices, price)
+		}
+	}
+	return prices, nil
+}
+
+// fakeCatalog resolves names through a fixed list of catalogued services
+type fakeCatalog struct {
+	services []models.Service
+}
+
+func (c *fakeCatalog) ResolveName(ctx context.Context, name string) (*models.Service, error) {
+	normalized := models.NormalizeServiceName(name)
+	for i, service := range c.services {
+		if models.NormalizeServiceName(service.Name) == normalized || slices.Contains(service.Aliases, normalized) {
+			return &c.services[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// unwatchedBudgets runs subscription changes without evaluating any budget
+type unwatchedBudgets struct{}
+
+func (unwatchedBudgets) WatchBudgets(ctx context.Context, tx *gorm.DB, subscription *models.Subscription, write func() error) error {
+	return write()
+}
+
 func setupTestService() (*SubscriptionService, *MockSubscriptionRepository, *MockTransactionManager) {
+	service, mockRepo, mockTxMgr, _ := setupTestServiceWithRates()
+	return service, mockRepo, mockTxMgr
+}
+
+func setupTestServiceWithRates() (*SubscriptionService, *MockSubscriptionRepository, *MockTransactionManager, *MockExchangeRateRepository) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect to test database: " + err.Error())
@@ -160,9 +327,10 @@ func setupTestService() (*SubscriptionService, *MockSubscriptionRepository, *Moc
 
 	mockRepo := &MockSubscriptionRepository{}
 	mockTxMgr := NewMockTransactionManager(db)
-	service := NewSubscriptionService(mockRepo, mockTxMgr, logger)
+	mockRatesRepo := &MockExchangeRateRepository{}
+	service := NewSubscriptionService(mockRepo, NewExchangeRateService(mockRatesRepo, logger), &recordingOutbox{}, &recordingAuditLog{}, &recordingPriceHistory{}, &fakeCatalog{}, unwatchedBudgets{}, &MockIdempotencyRepository{}, config.IdempotencyConfig{TTL: 24 * time.Hour}, config.BatchConfig{MaxSize: 3, ImportMaxRows: 3}, config.TrashConfig{Retention: 30 * 24 * time.Hour}, mockTxMgr, logger)
 
-	return service, mockRepo, mockTxMgr
+	return service, mockRepo, mockTxMgr, mockRatesRepo
 }
 
 func TestCreateSubscription_Success(t *testing.T) {
@@ -177,31 +345,33 @@ func TestCreateSubscription_Success(t *testing.T) {
 	}
 
 	// Mock transaction execution to actually run the function
-	mockTxMgr.On("ExecuteWithResult", mock.Anything).Return(false, nil, nil).Once()
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
 
 	// Mock the duplicate check first
-	mockRepo.On("ExistsByUserServiceAndDate",
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything,
 		mock.AnythingOfType("*gorm.DB"),
 		userID,
 		"Netflix",
-		"01-2024").Return(false, nil)
+		yearMonth("01-2024")).Return(false, nil)
 
 	// Mock the Create method
-	mockRepo.On("Create",
+	mockRepo.On("Create", mock.Anything,
 		mock.AnythingOfType("*gorm.DB"),
 		mock.MatchedBy(func(sub *models.Subscription) bool {
 			return sub.ServiceName == "Netflix" &&
 				sub.Price == 999 &&
 				sub.UserID == userID &&
-				sub.StartDate == "01-2024"
+				sub.StartDate == yearMonth("01-2024") &&
+				sub.BillingPeriod == models.BillingPeriodMonth &&
+				sub.BillingIntervalCount == 1
 		})).Return(nil).Run(func(args mock.Arguments) {
 		// Simulate database setting ID
-		sub := args.Get(1).(*models.Subscription)
+		sub := args.Get(2).(*models.Subscription)
 		sub.ID = 1
 	})
 
 	// Call service
-	result, err := service.CreateSubscription(req)
+	result, err := service.CreateSubscription(context.Background(), req)
 
 	// Assertions
 	assert.NoError(t, err)
@@ -210,7 +380,46 @@ func TestCreateSubscription_Success(t *testing.T) {
 	assert.Equal(t, "Netflix", result.ServiceName)
 	assert.Equal(t, 999, result.Price)
 	assert.Equal(t, userID, result.UserID)
-	assert.Equal(t, "01-2024", result.StartDate)
+	assert.Equal(t, yearMonth("01-2024"), result.StartDate)
+
+	// The webhook event is written in the same transaction
+	outbox := service.events.(*recordingOutbox)
+	assert.Equal(t, []string{models.EventSubscriptionCreated}, outbox.eventTypes())
+	assert.Equal(t, uint(1), outbox.events[0].SubscriptionID)
+	assert.Contains(t, string(outbox.events[0].Payload), `"service_name":"Netflix"`)
+
+	// So is the audit event
+	audit := service.audit.(*recordingAuditLog)
+	assert.Len(t, audit.events, 1)
+	assert.Equal(t, models.AuditActionCreate, audit.events[0].Action)
+	assert.Contains(t, string(audit.events[0].Changes), `"price":{"before":null,"after":999}`)
+
+	mockRepo.AssertExpectations(t)
+	mockTxMgr.AssertExpectations(t)
+}
+
+func TestCreateSubscription_Duplicate(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	userID := uuid.New()
+	req := &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      userID,
+		StartDate:   "01-2024",
+	}
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), userID, "Netflix", yearMonth("01-2024")).Return(true, nil)
+
+	result, err := service.CreateSubscription(context.Background(), req)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, errs.ErrConflict)
+
+	var domainErr *errs.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, errs.CodeSubscriptionExists, domainErr.Code)
 
 	mockRepo.AssertExpectations(t)
 	mockTxMgr.AssertExpectations(t)
@@ -286,15 +495,61 @@ func TestCreateSubscription_ValidationErrors(t *testing.T) {
 			},
 			expectedErr: "end_date must be after start_date",
 		},
+		{
+			name: "end date in earlier year with later month",
+			req: &models.CreateSubscriptionRequest{
+				ServiceName: "Netflix",
+				Price:       999,
+				UserID:      uuid.New(),
+				StartDate:   "01-2025",
+				EndDate:     stringPtr("12-2024"),
+			},
+			expectedErr: "end_date must be after start_date",
+		},
+		{
+			name: "unknown currency",
+			req: &models.CreateSubscriptionRequest{
+				ServiceName: "Netflix",
+				Price:       999,
+				Currency:    "XYZ1",
+				UserID:      uuid.New(),
+				StartDate:   "01-2024",
+			},
+			expectedErr: "currency must be an ISO-4217 currency code",
+		},
+		{
+			name: "unknown billing period",
+			req: &models.CreateSubscriptionRequest{
+				ServiceName:   "Netflix",
+				Price:         999,
+				BillingPeriod: "fortnight",
+				UserID:        uuid.New(),
+				StartDate:     "01-2024",
+			},
+			expectedErr: "billing_period must be one of week, month, quarter, year",
+		},
+		{
+			name: "negative billing interval count",
+			req: &models.CreateSubscriptionRequest{
+				ServiceName:          "Netflix",
+				Price:                999,
+				BillingPeriod:        "year",
+				BillingIntervalCount: -1,
+				UserID:               uuid.New(),
+				StartDate:            "01-2024",
+			},
+			expectedErr: "billing_interval_count must be a positive integer",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := service.CreateSubscription(tc.req)
+			result, err := service.CreateSubscription(context.Background(), tc.req)
 
 			assert.Error(t, err)
 			assert.Nil(t, result)
 			assert.Contains(t, err.Error(), tc.expectedErr)
+			assert.ErrorIs(t, err, errs.ErrValidation)
 		})
 	}
 }
@@ -308,29 +563,28 @@ func TestUpdateSubscription_Success(t *testing.T) {
 		ServiceName: "Netflix",
 		Price:       999,
 		UserID:      userID,
-		StartDate:   "01-2024",
+		StartDate:   yearMonth("01-2024"),
 	}
 
-	updates := map[string]interface{}{
-		"price": float64(1199),
-	}
+	price := 1199
+	updates := &models.UpdateSubscriptionRequest{Price: &price}
 
 	// Mock transaction execution to actually run the function
-	mockTxMgr.On("ExecuteWithResult", mock.Anything).Return(false, nil, nil).Once()
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
 
 	// Mock getting existing subscription
-	mockRepo.On("GetByID",
+	mockRepo.On("GetByID", mock.Anything,
 		mock.AnythingOfType("*gorm.DB"),
 		uint(1),
 	).Return(existingSubscription, nil).Once()
 
 	// Mock successful update
-	mockRepo.On("Update", mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
 		return sub.ID == 1 && sub.Price == 1199
-	})).Return(nil).Once()
+	})).Return(true, nil).Once()
 
 	// Call service
-	result, err := service.UpdateSubscription(1, updates)
+	result, err := service.UpdateSubscription(context.Background(), 1, updates, nil)
 
 	// Assertions
 	assert.NoError(t, err)
@@ -341,27 +595,342 @@ func TestUpdateSubscription_Success(t *testing.T) {
 	mockTxMgr.AssertExpectations(t)
 }
 
+func TestUpdateSubscription_BillingCycle(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	existingSubscription := &models.Subscription{
+		ID:                   1,
+		ServiceName:          "Netflix",
+		Price:                999,
+		BillingPeriod:        models.BillingPeriodMonth,
+		BillingIntervalCount: 1,
+		UserID:               uuid.New(),
+		StartDate:            yearMonth("01-2024"),
+	}
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil)
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(existingSubscription, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.BillingPeriod == models.BillingPeriodYear && sub.BillingIntervalCount == 2
+	})).Return(true, nil).Once()
+
+	period, count := "year", 2
+	result, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{
+		BillingPeriod:        &period,
+		BillingIntervalCount: &count,
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.BillingPeriodYear, result.BillingPeriod)
+	assert.Equal(t, 2, result.BillingIntervalCount)
+
+	count = 0
+	_, err = service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{BillingIntervalCount: &count}, nil)
+	assert.ErrorIs(t, err, errs.ErrValidation)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSubscription_EndDateRecordsEndedEvent(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   yearMonth("01-2024"),
+	}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(true, nil).Once()
+
+	_, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{EndDate: models.NullableOf("06-2024")}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.EventSubscriptionUpdated, models.EventSubscriptionEnded}, service.events.(*recordingOutbox).eventTypes())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSubscription_NullEndDateClearsIt(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	endDate := yearMonth("06-2024")
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   yearMonth("01-2024"),
+		EndDate:     &endDate,
+	}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.EndDate == nil
+	})).Return(true, nil).Once()
+
+	result, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{
+		EndDate: models.Nullable[string]{Set: true},
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.EndDate)
+	assert.Equal(t, []string{models.EventSubscriptionUpdated}, service.events.(*recordingOutbox).eventTypes())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateSubscription_Trial(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.Anything, "Netflix", yearMonth("01-2025")).Return(false, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(nil).Once()
+
+	trialEnd := "02-2025"
+	result, err := service.CreateSubscription(context.Background(), &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   "01-2025",
+		TrialEnd:    &trialEnd,
+		TrialPrice:  1,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, yearMonth("02-2025"), *result.TrialEnd)
+	assert.Equal(t, 1, result.TrialPrice)
+	mockRepo.AssertExpectations(t)
+
+	malformed, beforeStart, afterEnd, endDate := "2025-02", "12-2024", "07-2025", "06-2025"
+	testCases := []struct {
+		name          string
+		trialEnd      *string
+		trialPrice    int
+		expectedField string
+	}{
+		{name: "malformed trial end", trialEnd: &malformed, expectedField: "trial_end"},
+		{name: "trial end before start", trialEnd: &beforeStart, expectedField: "trial_end"},
+		{name: "trial end after end", trialEnd: &afterEnd, expectedField: "trial_end"},
+		{name: "negative trial price", trialEnd: &trialEnd, trialPrice: -1, expectedField: "trial_price"},
+		{name: "trial price without trial", trialPrice: 100, expectedField: "trial_price"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := service.CreateSubscription(context.Background(), &models.CreateSubscriptionRequest{
+				ServiceName: "Netflix",
+				Price:       999,
+				UserID:      uuid.New(),
+				StartDate:   "01-2025",
+				EndDate:     &endDate,
+				TrialEnd:    tc.trialEnd,
+				TrialPrice:  tc.trialPrice,
+			})
+			assert.ErrorIs(t, err, errs.ErrValidation)
+			assert.Equal(t, tc.expectedField, err.(*errs.Error).Field)
+		})
+	}
+}
+
+func TestUpdateSubscription_NullTrialEndEndsTrial(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+	watcher := &recordingBudgetWatcher{}
+	service.budgets = watcher
+
+	trialEnd := yearMonth("03-2025")
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Twice()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		Price:       999,
+		TrialEnd:    &trialEnd,
+		TrialPrice:  99,
+		UserID:      uuid.New(),
+		StartDate:   yearMonth("01-2025"),
+	}, nil).Twice()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.TrialEnd == nil && sub.TrialPrice == 0
+	})).Return(true, nil).Once()
+
+	result, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{
+		TrialEnd: models.Nullable[string]{Set: true},
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.TrialEnd)
+	assert.Equal(t, 0, result.TrialPrice)
+	// Ending a trial early raises the cost, so budgets are watched
+	assert.Len(t, watcher.prices, 1)
+
+	trialPrice := 49
+	_, err = service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{
+		TrialEnd:   models.Nullable[string]{Set: true},
+		TrialPrice: &trialPrice,
+	}, nil)
+	assert.ErrorIs(t, err, errs.ErrValidation)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSubscription_NoChangesSkipsUpdate(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   yearMonth("01-2024"),
+	}, nil).Once()
+
+	price := 999
+	result, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{Price: &price}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 999, result.Price)
+	assert.Empty(t, service.events.(*recordingOutbox).eventTypes())
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateSubscription_Preconditions(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil)
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   yearMonth("01-2024"),
+		Version:     3,
+	}, nil)
+
+	price := 1199
+	result, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{Price: &price}, &models.Precondition{Versions: []int{2}})
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, errs.ErrPrecondition)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+
+	// The version matched on read but another writer got there first
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.Version == 3
+	})).Return(false, nil)
+
+	_, err = service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{Price: &price}, &models.Precondition{Versions: []int{3}})
+	assert.ErrorIs(t, err, errs.ErrPrecondition)
+
+	_, err = service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{Price: &price}, nil)
+	assert.ErrorIs(t, err, errs.ErrConflict)
+	assert.Empty(t, service.events.(*recordingOutbox).eventTypes())
+}
+
+func TestUpdateSubscription_RenameChecksDuplicates(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	userID := uuid.New()
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      userID,
+		StartDate:   yearMonth("01-2024"),
+	}, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), userID, "Spotify", yearMonth("01-2024")).Return(true, nil).Once()
+
+	name := "Spotify"
+	result, err := service.UpdateSubscription(context.Background(), 1, &models.UpdateSubscriptionRequest{ServiceName: &name}, nil)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, errs.ErrConflict)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReplaceSubscription_ResetsOmittedFields(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	userID := uuid.New()
+	endDate := yearMonth("06-2024")
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:                   1,
+		ServiceName:          "Netflix",
+		Price:                999,
+		Currency:             "USD",
+		BillingPeriod:        models.BillingPeriodYear,
+		BillingIntervalCount: 2,
+		UserID:               userID,
+		StartDate:            yearMonth("01-2024"),
+		EndDate:              &endDate,
+	}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Return(true, nil).Once()
+
+	result, err := service.ReplaceSubscription(context.Background(), 1, &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       1299,
+		StartDate:   "01-2024",
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1299, result.Price)
+	assert.Equal(t, DefaultCurrency, result.Currency)
+	assert.Equal(t, models.BillingPeriodMonth, result.BillingPeriod)
+	assert.Equal(t, 1, result.BillingIntervalCount)
+	assert.Nil(t, result.EndDate)
+	assert.Equal(t, userID, result.UserID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReplaceSubscription_Validation(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   yearMonth("01-2024"),
+	}, nil).Once()
+
+	// Required fields cannot be omitted
+	_, err := service.ReplaceSubscription(context.Background(), 1, &models.CreateSubscriptionRequest{Price: 999}, nil)
+	assert.ErrorIs(t, err, errs.ErrValidation)
+
+	// The owner cannot be changed
+	_, err = service.ReplaceSubscription(context.Background(), 1, &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   "01-2024",
+	}, nil)
+	var domainErr *errs.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "user_id", domainErr.Field)
+
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestUpdateSubscription_NotFound(t *testing.T) {
 	service, mockRepo, mockTxMgr := setupTestService()
 
 	// Mock transaction execution to actually run the function
-	mockTxMgr.On("ExecuteWithResult", mock.Anything).Return(false, nil, nil).Once()
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
 
 	// Mock subscription not found
-	mockRepo.On("GetByID", mock.AnythingOfType("*gorm.DB"), uint(999)).Return(nil, gorm.ErrRecordNotFound).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(999)).Return(nil, gorm.ErrRecordNotFound).Once()
 
-	updates := map[string]interface{}{
-		"price": float64(1199),
-	}
+	price := 1199
+	updates := &models.UpdateSubscriptionRequest{Price: &price}
 
 	// Call service
-	result, err := service.UpdateSubscription(999, updates)
+	result, err := service.UpdateSubscription(context.Background(), 999, updates, nil)
 
 	// Assertions
 	assert.Error(t, err)
 	log.Println(err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "subscription not found")
+	assert.ErrorIs(t, err, errs.ErrNotFound)
 
 	mockRepo.AssertExpectations(t)
 	mockTxMgr.AssertExpectations(t)
@@ -371,66 +940,376 @@ func TestDeleteSubscription_Success(t *testing.T) {
 	service, mockRepo, mockTxMgr := setupTestService()
 
 	// Mock transaction execution to actually run the function
-	mockTxMgr.On("Execute", mock.Anything).Return(false, nil).Once()
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
 
 	// Mock subscription exists
-	mockRepo.On("ExistsByID", mock.AnythingOfType("*gorm.DB"), uint(1)).Return(true, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{ID: 1, UserID: uuid.New(), Version: 3}, nil).Once()
 
 	// Mock successful delete
-	mockRepo.On("Delete", mock.AnythingOfType("*gorm.DB"), uint(1)).Return(nil).Once()
+	mockRepo.On("Delete", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1), 3).Return(true, nil).Once()
 
 	// Call service
-	err := service.DeleteSubscription(1)
+	err := service.DeleteSubscription(context.Background(), 1, nil)
 
 	// Assertions
 	assert.NoError(t, err)
+	assert.Equal(t, []string{models.EventSubscriptionDeleted}, service.events.(*recordingOutbox).eventTypes())
 
 	mockRepo.AssertExpectations(t)
 	mockTxMgr.AssertExpectations(t)
 }
 
+func TestDeleteSubscription_OtherUsersSubscriptionIsNotFound(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: uuid.New()})
+
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{ID: 1, UserID: uuid.New()}, nil).Once()
+
+	err := service.DeleteSubscription(ctx, 1, nil)
+
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteSubscription_Preconditions(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil)
+	mockRepo.On("GetByID", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{ID: 1, UserID: uuid.New(), Version: 3}, nil)
+
+	// A stale ETag is rejected before anything is deleted
+	err := service.DeleteSubscription(context.Background(), 1, &models.Precondition{Versions: []int{2}})
+	assert.ErrorIs(t, err, errs.ErrPrecondition)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// A writer that changes the row between the read and the delete wins
+	mockRepo.On("Delete", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1), 3).Return(false, nil)
+
+	err = service.DeleteSubscription(context.Background(), 1, &models.Precondition{Versions: []int{2, 3}})
+	assert.ErrorIs(t, err, errs.ErrPrecondition)
+
+	err = service.DeleteSubscription(context.Background(), 1, nil)
+	assert.ErrorIs(t, err, errs.ErrConflict)
+}
+
+func TestGetSubscriptionByID_Scoping(t *testing.T) {
+	ownerID := uuid.New()
+	subscription := &models.Subscription{ID: 1, ServiceName: "Netflix", UserID: ownerID}
+
+	testCases := []struct {
+		name      string
+		principal auth.Principal
+		expectErr error
+	}{
+		{"owner", auth.Principal{UserID: ownerID}, nil},
+		{"other user", auth.Principal{UserID: uuid.New()}, errs.ErrNotFound},
+		{"admin", auth.Principal{UserID: uuid.New(), Role: auth.RoleAdmin}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, mockRepo, _ := setupTestService()
+			mockRepo.On("GetByID", mock.Anything, (*gorm.DB)(nil), uint(1)).Return(subscription, nil).Once()
+
+			result, err := service.GetSubscriptionByID(auth.WithPrincipal(context.Background(), tc.principal), 1)
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, subscription, result)
+			}
+		})
+	}
+}
+
+func TestListSubscriptions_ScopedToCaller(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+
+	callerID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: callerID})
+
+	// Without a user_id filter the list is restricted to the caller
+	mockRepo.On("List", mock.Anything, &models.SubscriptionFilter{UserID: &callerID}, (*models.Cursor)(nil), 51, 0).Return([]models.Subscription{}, nil).Once()
+
+	_, err := service.ListSubscriptions(ctx, nil, models.PageRequest{})
+	assert.NoError(t, err)
+
+	// Asking for another user's subscriptions is forbidden
+	otherID := uuid.New()
+	_, err = service.ListSubscriptions(ctx, &models.SubscriptionFilterRequest{UserID: &otherID}, models.PageRequest{})
+	assert.ErrorIs(t, err, errs.ErrForbidden)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListSubscriptions_CursorPages(t *testing.T) {
+	base := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([]models.Subscription, 3)
+	for i := range rows {
+		rows[i] = models.Subscription{ID: uint(i + 1), ServiceName: "Netflix", CreatedAt: base.Add(time.Duration(i) * time.Hour)}
+	}
+
+	t.Run("first page has only a next cursor", func(t *testing.T) {
+		service, mockRepo, _ := setupTestService()
+		mockRepo.On("List", mock.Anything, &models.SubscriptionFilter{}, (*models.Cursor)(nil), 3, 0).Return(rows, nil).Once()
+
+		page, err := service.ListSubscriptions(context.Background(), nil, models.PageRequest{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, rows[:2], page.Items)
+		assert.Nil(t, page.PrevCursor)
+		assert.Nil(t, page.Total)
+		next, err := models.DecodeCursor(*page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, models.CursorAfter(&rows[1]), next)
+	})
+
+	t.Run("last page has only a previous cursor", func(t *testing.T) {
+		service, mockRepo, _ := setupTestService()
+		cursor := models.CursorAfter(&rows[1])
+		mockRepo.On("List", mock.Anything, &models.SubscriptionFilter{}, cursor, 3, 0).Return(rows[2:], nil).Once()
+		mockRepo.On("Count", mock.Anything, &models.SubscriptionFilter{}).Return(int64(3), nil).Once()
+
+		page, err := service.ListSubscriptions(context.Background(), nil, models.PageRequest{Limit: 2, Cursor: cursor.Encode(), IncludeTotal: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, rows[2:], page.Items)
+		assert.Nil(t, page.NextCursor)
+		assert.Equal(t, int64(3), *page.Total)
+		prev, err := models.DecodeCursor(*page.PrevCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, models.CursorBefore(&rows[2]), prev)
+	})
+
+	t.Run("backward page drops the extra row at its start", func(t *testing.T) {
+		service, mockRepo, _ := setupTestService()
+		cursor := models.CursorBefore(&rows[2])
+		mockRepo.On("List", mock.Anything, &models.SubscriptionFilter{}, cursor, 2, 0).Return(rows[:2], nil).Once()
+
+		page, err := service.ListSubscriptions(context.Background(), nil, models.PageRequest{Limit: 1, Cursor: cursor.Encode()})
+
+		assert.NoError(t, err)
+		assert.Equal(t, rows[1:2], page.Items)
+		assert.NotNil(t, page.PrevCursor)
+		assert.NotNil(t, page.NextCursor)
+	})
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		service, _, _ := setupTestService()
+
+		page, err := service.ListSubscriptions(context.Background(), nil, models.PageRequest{Cursor: "not-a-cursor"})
+
+		assert.Nil(t, page)
+		assert.ErrorIs(t, err, errs.ErrValidation)
+		assert.Equal(t, errs.CodeInvalidCursor, err.(*errs.Error).Code)
+	})
+}
+
+func TestCreateSubscription_DefaultsUserToCaller(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	callerID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: callerID})
+
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), callerID, "Netflix", yearMonth("01-2024")).Return(false, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.MatchedBy(func(sub *models.Subscription) bool {
+		return sub.UserID == callerID
+	})).Return(nil)
+
+	result, err := service.CreateSubscription(ctx, &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       999,
+		StartDate:   "01-2024",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, callerID, result.UserID)
+
+	// Creating a subscription for someone else requires the admin role
+	_, err = service.CreateSubscription(ctx, &models.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       999,
+		UserID:      uuid.New(),
+		StartDate:   "01-2024",
+	})
+	assert.ErrorIs(t, err, errs.ErrForbidden)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestCalculateTotalCost_Success(t *testing.T) {
 	service, mockRepo, _ := setupTestService()
 
 	userID := uuid.New()
-	serviceName := "Netflix"
 	req := &models.CostCalculationRequest{
-		UserID:      &userID,
-		ServiceName: &serviceName,
-		StartDate:   "01-2024",
-		EndDate:     "03-2024",
+		SubscriptionFilterRequest: models.SubscriptionFilterRequest{
+			UserID:       &userID,
+			ServiceNames: []string{"Netflix"},
+		},
+		StartDate: "01-2024",
+		EndDate:   "03-2024",
 	}
+	// Cost and list share the filter, so service names match case-insensitively in both
+	filter := &models.SubscriptionFilter{UserID: &userID, ServiceNames: []string{"netflix"}}
 
-	subscriptions := []models.Subscription{
+	subscriptions := []models.SubscriptionCost{
 		{
-			ID:          1,
-			ServiceName: "Netflix",
-			Price:       999,
-			UserID:      userID,
-			StartDate:   "01-2024",
+			Subscription: models.Subscription{
+				ID:          1,
+				ServiceName: "Netflix",
+				Price:       999,
+				UserID:      userID,
+				StartDate:   yearMonth("01-2024"),
+			},
+			BilledMonths: 3,
+			Charges:      3,
+			Subtotal:     2997,
 		},
 	}
 
 	// Mock database aggregation
-	mockRepo.On("CalculateTotalCostInDB", &userID, &serviceName, "01-2024", "03-2024", 3).Return(2997, nil)
+	mockRepo.On("CalculateTotalCostInDB", mock.Anything, (*gorm.DB)(nil), filter, yearMonth("01-2024"), yearMonth("03-2024")).Return([]models.CurrencyAmount{{Currency: "RUB", Amount: 2997}}, nil)
 
 	// Mock getting subscriptions for response
-	mockRepo.On("GetSubscriptionsInDateRange", &userID, &serviceName, "01-2024", "03-2024").Return(subscriptions, nil)
+	mockRepo.On("GetSubscriptionsInDateRange", mock.Anything, filter, yearMonth("01-2024"), yearMonth("03-2024")).Return(subscriptions, nil)
 
 	// Call service
-	result, err := service.CalculateTotalCost(req)
+	result, err := service.CalculateTotalCost(context.Background(), req)
 
 	// Assertions
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, 2997, result.TotalCost)
-	assert.Equal(t, "01-2024", result.StartDate)
-	assert.Equal(t, "03-2024", result.EndDate)
+	assert.Equal(t, 2997, *result.TotalCost)
+	assert.Equal(t, "RUB", result.Currency)
+	assert.Equal(t, yearMonth("01-2024"), result.StartDate)
+	assert.Equal(t, yearMonth("03-2024"), result.EndDate)
 	assert.Equal(t, len(subscriptions), len(result.Subscriptions))
+	assert.Equal(t, 3, result.Subscriptions[0].BilledMonths)
+	assert.Equal(t, 3, result.Subscriptions[0].Charges)
+	assert.Equal(t, 2997, result.Subscriptions[0].Subtotal)
 
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCalculateTotalCost_MixedCurrencies(t *testing.T) {
+	service, mockRepo, _, mockRatesRepo := setupTestServiceWithRates()
+
+	totals := []models.CurrencyAmount{
+		{Currency: "RUB", Amount: 1000},
+		{Currency: "USD", Amount: 20},
+	}
+	mockRepo.On("CalculateTotalCostInDB", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(totals, nil)
+	mockRepo.On("GetSubscriptionsInDateRange", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.SubscriptionCost{}, nil)
+
+	t.Run("without target currency totals are reported per currency", func(t *testing.T) {
+		result, err := service.CalculateTotalCost(context.Background(), &models.CostCalculationRequest{StartDate: "01-2024", EndDate: "03-2024"})
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.TotalCost)
+		assert.Empty(t, result.Currency)
+		assert.Equal(t, totals, result.Totals)
+	})
+
+	t.Run("converts into target currency using direct and inverse rates", func(t *testing.T) {
+		mockRatesRepo.On("Get", mock.Anything, "USD", "EUR").Return((*models.ExchangeRate)(nil), nil).Once()
+		mockRatesRepo.On("Get", mock.Anything, "EUR", "USD").Return(&models.ExchangeRate{FromCurrency: "EUR", ToCurrency: "USD", Rate: 1.25}, nil).Once()
+		mockRatesRepo.On("Get", mock.Anything, "RUB", "EUR").Return(&models.ExchangeRate{FromCurrency: "RUB", ToCurrency: "EUR", Rate: 0.01}, nil).Once()
+
+		result, err := service.CalculateTotalCost(context.Background(), &models.CostCalculationRequest{
+			StartDate:      "01-2024",
+			EndDate:        "03-2024",
+			TargetCurrency: stringPtr("eur"),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10+16, *result.TotalCost)
+		assert.Equal(t, "EUR", result.Currency)
+		assert.Equal(t, totals, result.Totals)
+	})
+
+	t.Run("missing rate is a validation error", func(t *testing.T) {
+		mockRatesRepo.On("Get", mock.Anything, "RUB", "GBP").Return((*models.ExchangeRate)(nil), nil).Once()
+		mockRatesRepo.On("Get", mock.Anything, "GBP", "RUB").Return((*models.ExchangeRate)(nil), nil).Once()
+
+		result, err := service.CalculateTotalCost(context.Background(), &models.CostCalculationRequest{
+			StartDate:      "01-2024",
+			EndDate:        "03-2024",
+			TargetCurrency: stringPtr("GBP"),
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, errs.ErrValidation)
+		assert.Contains(t, err.Error(), "no exchange rate from RUB to GBP")
+	})
+
+	mockRatesRepo.AssertExpectations(t)
+}
+
+func TestListUpcomingCharges_SortedByNextChargeDate(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	service.now = func() time.Time { return time.Date(2025, time.March, 10, 15, 0, 0, 0, time.UTC) }
+
+	userID := uuid.New()
+	endDate := yearMonth("02-2025")
+	subscriptions := []models.Subscription{
+		{ID: 1, ServiceName: "Yearly", Price: 5000, BillingPeriod: models.BillingPeriodYear, BillingIntervalCount: 1, UserID: userID, StartDate: yearMonth("04-2024")},
+		{ID: 2, ServiceName: "Monthly", Price: 990, BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, UserID: userID, StartDate: yearMonth("01-2025")},
+		{ID: 3, ServiceName: "Weekly", Price: 100, BillingPeriod: models.BillingPeriodWeek, BillingIntervalCount: 1, UserID: userID, StartDate: yearMonth("03-2025")},
+		{ID: 4, ServiceName: "Ended", Price: 300, BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, UserID: userID, StartDate: yearMonth("01-2025"), EndDate: &endDate},
+	}
+	today := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("ListChargedWithin", mock.Anything, &models.SubscriptionFilter{UserID: &userID}, today, today.AddDate(0, 0, 30), 100).Return(subscriptions, nil)
+
+	result, err := service.ListUpcomingCharges(context.Background(), &userID, 30, 100)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+	assert.Equal(t, "Weekly", result[0].ServiceName)
+	assert.Equal(t, "2025-03-15", result[0].NextChargeDate)
+	assert.Equal(t, 5, result[0].DaysUntil)
+	// Charges on the same day are ordered by subscription ID
+	assert.Equal(t, "Yearly", result[1].ServiceName)
+	assert.Equal(t, "2025-04-01", result[1].NextChargeDate)
+	assert.Equal(t, 5000, result[1].Amount)
+	assert.Equal(t, "Monthly", result[2].ServiceName)
+	assert.Equal(t, "2025-04-01", result[2].NextChargeDate)
+
+	_, err = service.ListUpcomingCharges(context.Background(), &userID, 0, 100)
+	assert.ErrorIs(t, err, errs.ErrValidation)
+	_, err = service.ListUpcomingCharges(context.Background(), &userID, 30, MaxUpcomingLimit+1)
+	assert.ErrorIs(t, err, errs.ErrValidation)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListUpcomingCharges_TrialPrice(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	service.now = func() time.Time { return time.Date(2025, time.March, 10, 15, 0, 0, 0, time.UTC) }
+
+	userID := uuid.New()
+	trialEnd := yearMonth("03-2025")
+	mockRepo.On("ListChargedWithin", mock.Anything, &models.SubscriptionFilter{UserID: &userID}, mock.Anything, mock.Anything, 100).Return([]models.Subscription{
+		{ID: 1, ServiceName: "Converting", Price: 990, TrialEnd: &trialEnd, BillingPeriod: models.BillingPeriodMonth, BillingIntervalCount: 1, UserID: userID, StartDate: yearMonth("02-2025")},
+		{ID: 2, ServiceName: "Trialing", Price: 500, TrialEnd: &trialEnd, TrialPrice: 1, BillingPeriod: models.BillingPeriodWeek, BillingIntervalCount: 1, UserID: userID, StartDate: yearMonth("03-2025")},
+	}, nil)
+
+	result, err := service.ListUpcomingCharges(context.Background(), &userID, 30, 100)
+
+	assert.NoError(t, err)
+	if assert.Len(t, result, 2) {
+		assert.Equal(t, "Trialing", result[0].ServiceName)
+		assert.Equal(t, 1, result[0].Amount)
+		assert.Equal(t, "Converting", result[1].ServiceName)
+		assert.Equal(t, 990, result[1].Amount)
+	}
+}
+
 func TestCalculateTotalCost_ValidationErrors(t *testing.T) {
 	service, _, _ := setupTestService()
 
@@ -467,7 +1346,7 @@ func TestCalculateTotalCost_ValidationErrors(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := service.CalculateTotalCost(tc.req)
+			result, err := service.CalculateTotalCost(context.Background(), tc.req)
 
 			assert.Error(t, err)
 			assert.Nil(t, result)
@@ -476,30 +1355,6 @@ func TestCalculateTotalCost_ValidationErrors(t *testing.T) {
 	}
 }
 
-func TestIsValidDate(t *testing.T) {
-	testCases := []struct {
-		name     string
-		date     string
-		expected bool
-	}{
-		{"valid date", "01-2024", true},
-		{"valid date dec", "12-2024", true},
-		{"invalid format year-month", "2024-01", false},
-		{"invalid month", "13-2024", false},
-		{"invalid month zero", "00-2024", false},
-		{"missing dash", "012024", false},
-		{"extra characters", "01-2024-01", false},
-		{"empty string", "", false},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			result := isValidDate(tc.date)
-			assert.Equal(t, tc.expected, result)
-		})
-	}
-}
-
 func TestCalculateMonthsBetween(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -512,11 +1367,12 @@ func TestCalculateMonthsBetween(t *testing.T) {
 		{"quarter", "01-2024", "03-2024", 3},
 		{"year span", "12-2023", "01-2024", 2},
 		{"full year", "01-2024", "12-2024", 12},
+		{"across two years", "11-2023", "02-2025", 16},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := calculateMonthsBetween(tc.startDate, tc.endDate)
+			result := calculateMonthsBetween(yearMonth(tc.startDate), yearMonth(tc.endDate))
 			assert.Equal(t, tc.expected, result)
 		})
 	}
@@ -526,3 +1382,90 @@ func TestCalculateMonthsBetween(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func yearMonth(s string) models.YearMonth {
+	ym, err := models.ParseYearMonth(s)
+	if err != nil {
+		panic(err)
+	}
+	return ym
+}
+
+func TestParseFilter(t *testing.T) {
+	service, _, _ := setupTestService()
+	service.now = func() time.Time { return time.Date(2025, time.July, 20, 12, 0, 0, 0, time.UTC) }
+
+	priceMin, priceMax := 100, 500
+	filter, err := service.parseFilter(context.Background(), &models.SubscriptionFilterRequest{
+		ServiceNames: []string{" Netflix", "netflix", "Spotify", ""},
+		PriceMin:     &priceMin,
+		PriceMax:     &priceMax,
+		Status:       "ended",
+		StartedAfter: "01-2024",
+		Sort:         "price, -start_date",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"netflix", "spotify"}, filter.ServiceNames)
+	assert.Equal(t, models.SubscriptionStatusEnded, *filter.Status)
+	assert.Equal(t, yearMonth("07-2025"), filter.StatusMonth)
+	assert.Equal(t, yearMonth("01-2024"), *filter.StartedAfter)
+	assert.Nil(t, filter.StartedBefore)
+	assert.Equal(t, []models.SortField{{Field: "price"}, {Field: "start_date", Desc: true}}, filter.Sort)
+
+	// Aliases and stray whitespace resolve to the catalogued name, like on create
+	service.catalog = &fakeCatalog{services: []models.Service{{ID: 4, Name: "YouTube Premium", Aliases: []string{"yt premium"}}}}
+	filter, err = service.parseFilter(context.Background(), &models.SubscriptionFilterRequest{
+		ServiceNames: []string{"YT  Premium", "youtube premium", "Apple   Music"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"youtube premium", "apple music"}, filter.ServiceNames)
+
+	// The current month is taken in UTC, whatever the server's zone
+	service.now = func() time.Time { return time.Date(2025, time.August, 1, 1, 0, 0, 0, time.FixedZone("MSK", 3*60*60)) }
+	filter, err = service.parseFilter(context.Background(), &models.SubscriptionFilterRequest{Status: "active"})
+	assert.NoError(t, err)
+	assert.Equal(t, yearMonth("07-2025"), filter.StatusMonth)
+	service.now = func() time.Time { return time.Date(2025, time.July, 20, 12, 0, 0, 0, time.UTC) }
+
+	// On July 20th a 14-day window covers conversions from that day up to August 3rd
+	within := 14
+	filter, err = service.parseFilter(context.Background(), &models.SubscriptionFilterRequest{TrialEndsWithin: &within})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.July, 20, 0, 0, 0, 0, time.UTC), *filter.TrialConvertsFrom)
+	assert.Equal(t, time.Date(2025, time.August, 3, 0, 0, 0, 0, time.UTC), *filter.TrialConvertsTo)
+
+	negative := -1
+	testCases := []struct {
+		name          string
+		req           models.SubscriptionFilterRequest
+		expectedField string
+	}{
+		{name: "negative price", req: models.SubscriptionFilterRequest{PriceMin: &negative}, expectedField: "price_min"},
+		{name: "inverted price range", req: models.SubscriptionFilterRequest{PriceMin: &priceMax, PriceMax: &priceMin}, expectedField: "price_max"},
+		{name: "malformed month", req: models.SubscriptionFilterRequest{ActiveOn: "2025-01"}, expectedField: "active_on"},
+		{name: "inverted start range", req: models.SubscriptionFilterRequest{StartedAfter: "03-2025", StartedBefore: "03-2025"}, expectedField: "started_before"},
+		{name: "unknown status", req: models.SubscriptionFilterRequest{Status: "paused"}, expectedField: "status"},
+		{name: "field outside whitelist", req: models.SubscriptionFilterRequest{Sort: "user_id"}, expectedField: "sort"},
+		{name: "duplicate sort field", req: models.SubscriptionFilterRequest{Sort: "price,-price"}, expectedField: "sort"},
+		{name: "empty trial window", req: models.SubscriptionFilterRequest{TrialEndsWithin: &negative}, expectedField: "trial_ends_within"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := service.parseFilter(context.Background(), &tc.req)
+			assert.ErrorIs(t, err, errs.ErrValidation)
+			assert.Equal(t, tc.expectedField, err.(*errs.Error).Field)
+		})
+	}
+}
+
+func TestListSubscriptions_CursorRejectedWithSort(t *testing.T) {
+	service, _, _ := setupTestService()
+
+	cursor := models.CursorAfter(&models.Subscription{ID: 1, CreatedAt: time.Now()})
+	_, err := service.ListSubscriptions(context.Background(), &models.SubscriptionFilterRequest{Sort: "price"}, models.PageRequest{Cursor: cursor.Encode()})
+
+	assert.ErrorIs(t, err, errs.ErrValidation)
+	assert.Equal(t, "cursor", err.(*errs.Error).Field)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/infra/database"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ListTrash retrieves a page of the caller's soft-deleted subscriptions matching the filter,
+// together with the time the retention job will purge each of them. The trash is paged with
+// offsets only.
+func (s *SubscriptionService) ListTrash(ctx context.Context, filterReq *models.SubscriptionFilterRequest, page models.PageRequest) (*models.TrashPage, error) {
+	if page.Cursor != "" {
+		return nil, errs.Validation("cursor", errs.CodeInvalidCursor, "the trash is paged with offset, not cursors")
+	}
+	limit := page.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	filter, err := s.parseFilter(ctx, filterReq)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := s.repo.ListDeleted(ctx, filter, limit, page.Offset)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list deleted subscriptions")
+		return nil, errs.Internal("failed to retrieve deleted subscriptions")
+	}
+
+	result := &models.TrashPage{Items: make([]models.TrashedSubscription, 0, len(subscriptions))}
+	for _, subscription := range subscriptions {
+		deletedAt := subscription.DeletedAt.Time
+		result.Items = append(result.Items, models.TrashedSubscription{
+			Subscription: subscription,
+			DeletedAt:    deletedAt,
+			PurgeAfter:   deletedAt.Add(s.trashCfg.Retention),
+		})
+	}
+
+	if page.IncludeTotal {
+		total, err := s.repo.CountDeleted(ctx, filter)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to count deleted subscriptions")
+			return nil, errs.Internal("failed to retrieve deleted subscriptions")
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
+// RestoreSubscription takes a subscription out of the trash. It fails with a conflict when a
+// subscription for the same user, service and start month was created after the delete.
+func (s *SubscriptionService) RestoreSubscription(ctx context.Context, id uint, precondition *models.Precondition) (*models.Subscription, error) {
+	result, err := s.txMgr.ExecuteWithResult(ctx, func(tx database.Transaction) (interface{}, error) {
+		gormTx := database.GetDB(tx)
+
+		subscription, err := s.repo.GetByIDUnscoped(ctx, gormTx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errs.NotFound(errs.CodeSubscriptionNotFound, "deleted subscription not found")
+			}
+			return nil, errs.Internal("failed to validate subscription")
+		}
+		if !subscription.DeletedAt.Valid || !canAccess(ctx, subscription) {
+			return nil, errs.NotFound(errs.CodeSubscriptionNotFound, "deleted subscription not found")
+		}
+		if !precondition.Matches(subscription.Version) {
+			return nil, errs.PreconditionFailed("subscription has been modified; fetch it again and retry")
+		}
+
+		if err := s.checkDuplicate(ctx, gormTx, subscription); err != nil {
+			return nil, err
+		}
+
+		restored, err := s.repo.Restore(ctx, gormTx, subscription)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to restore subscription")
+			return nil, errs.Internal("failed to restore subscription")
+		}
+		if !restored {
+			return nil, concurrentWriteError(precondition)
+		}
+
+		if err := s.recordEvent(ctx, gormTx, models.EventSubscriptionRestored, subscription); err != nil {
+			return nil, err
+		}
+		if err := s.recordAudit(ctx, gormTx, models.AuditActionRestore, subscription, nil, subscriptionFields(subscription)); err != nil {
+			return nil, err
+		}
+		return subscription, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("subscription_id", id).Info("Subscription restored successfully")
+	return result.(*models.Subscription), nil
+}
+
+// PurgeSubscription permanently deletes a subscription, whether it is active or in the trash.
+// Only admins may purge; the deleted event is only recorded for subscriptions that were active.
+func (s *SubscriptionService) PurgeSubscription(ctx context.Context, id uint, precondition *models.Precondition) error {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.IsAdmin() {
+		return errs.Forbidden("hard", "only admins can permanently delete subscriptions")
+	}
+
+	err := s.txMgr.Execute(ctx, func(tx database.Transaction) error {
+		gormTx := database.GetDB(tx)
+
+		subscription, err := s.repo.GetByIDUnscoped(ctx, gormTx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.NotFound(errs.CodeSubscriptionNotFound, "subscription not found")
+			}
+			return errs.Internal("failed to validate subscription")
+		}
+		if !precondition.Matches(subscription.Version) {
+			return errs.PreconditionFailed("subscription has been modified; fetch it again and retry")
+		}
+
+		purged, err := s.repo.Purge(ctx, gormTx, id, subscription.Version)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to purge subscription")
+			return errs.Internal("failed to delete subscription")
+		}
+		if !purged {
+			return concurrentWriteError(precondition)
+		}
+
+		if err := s.recordAudit(ctx, gormTx, models.AuditActionPurge, subscription, subscriptionFields(subscription), nil); err != nil {
+			return err
+		}
+		if subscription.DeletedAt.Valid {
+			return nil
+		}
+		return s.recordEvent(ctx, gormTx, models.EventSubscriptionDeleted, subscription)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.WithField("subscription_id", id).Info("Subscription purged successfully")
+	return nil
+}
+
+// PurgeExpiredTrash permanently deletes the subscriptions that have been in the trash for longer
+// than the configured retention. Each deletion is audited as a purge by the system in the same
+// transaction; subscriptions restored or purged in the meantime are left alone.
+func (s *SubscriptionService) PurgeExpiredTrash(ctx context.Context) error {
+	purged := 0
+	err := s.txMgr.Execute(ctx, func(tx database.Transaction) error {
+		gormTx := database.GetDB(tx)
+
+		expired, err := s.repo.ListDeletedBefore(ctx, gormTx, s.now().Add(-s.trashCfg.Retention))
+		if err != nil {
+			return err
+		}
+		for i := range expired {
+			subscription := &expired[i]
+			ok, err := s.repo.Purge(ctx, gormTx, subscription.ID, subscription.Version)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := s.recordAudit(ctx, gormTx, models.AuditActionPurge, subscription, subscriptionFields(subscription), nil); err != nil {
+				return err
+			}
+			purged++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"purged":    purged,
+			"retention": s.trashCfg.Retention.String(),
+		}).Info("Expired deleted subscriptions purged successfully")
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"subscription_tracker_api/internal/auth"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func trashedSubscription(userID uuid.UUID, deletedAt time.Time) *models.Subscription {
+	return &models.Subscription{
+		ID:          1,
+		ServiceName: "Netflix",
+		UserID:      userID,
+		StartDate:   yearMonth("01-2025"),
+		Version:     2,
+		DeletedAt:   gorm.DeletedAt{Time: deletedAt, Valid: true},
+	}
+}
+
+func TestRestoreSubscription_Success(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+
+	userID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID})
+	mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+	mockRepo.On("GetByIDUnscoped", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(trashedSubscription(userID, time.Now()), nil).Once()
+	mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.AnythingOfType("*gorm.DB"), userID, "Netflix", yearMonth("01-2025")).Return(false, nil).Once()
+	mockRepo.On("Restore", mock.Anything, mock.AnythingOfType("*gorm.DB"), mock.AnythingOfType("*models.Subscription")).Run(func(args mock.Arguments) {
+		sub := args.Get(2).(*models.Subscription)
+		sub.DeletedAt = gorm.DeletedAt{}
+		sub.Version++
+	}).Return(true, nil).Once()
+
+	restored, err := service.RestoreSubscription(ctx, 1, &models.Precondition{Versions: []int{2}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, restored.Version)
+	assert.Equal(t, []string{models.EventSubscriptionRestored}, service.events.(*recordingOutbox).eventTypes())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRestoreSubscription_Failures(t *testing.T) {
+	userID := uuid.New()
+	owner := auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID})
+	stranger := auth.WithPrincipal(context.Background(), auth.Principal{UserID: uuid.New()})
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		subscription *models.Subscription
+		duplicate    bool
+		precondition *models.Precondition
+		kind         error
+	}{
+		{"not in the trash", owner, &models.Subscription{ID: 1, UserID: userID, Version: 2}, false, nil, errs.ErrNotFound},
+		{"another user's subscription", stranger, trashedSubscription(userID, time.Now()), false, nil, errs.ErrNotFound},
+		{"stale version", owner, trashedSubscription(userID, time.Now()), false, &models.Precondition{Versions: []int{1}}, errs.ErrPrecondition},
+		{"recreated since the delete", owner, trashedSubscription(userID, time.Now()), true, nil, errs.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, mockTxMgr := setupTestService()
+			mockTxMgr.On("ExecuteWithResult", mock.Anything, mock.Anything).Return(false, nil, nil).Once()
+			mockRepo.On("GetByIDUnscoped", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(tt.subscription, nil).Once()
+			mockRepo.On("ExistsByUserServiceAndDate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.duplicate, nil).Maybe()
+
+			_, err := service.RestoreSubscription(tt.ctx, 1, tt.precondition)
+
+			assert.ErrorIs(t, err, tt.kind)
+			mockRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPurgeSubscription(t *testing.T) {
+	t.Run("admins only", func(t *testing.T) {
+		service, _, mockTxMgr := setupTestService()
+		ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: uuid.New()})
+
+		err := service.PurgeSubscription(ctx, 1, nil)
+
+		assert.ErrorIs(t, err, errs.ErrForbidden)
+		mockTxMgr.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+	})
+
+	t.Run("active subscriptions record a deleted event", func(t *testing.T) {
+		service, mockRepo, mockTxMgr := setupTestService()
+		ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: uuid.New(), Role: auth.RoleAdmin})
+		mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+		mockRepo.On("GetByIDUnscoped", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(&models.Subscription{ID: 1, UserID: uuid.New(), Version: 4}, nil).Once()
+		mockRepo.On("Purge", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1), 4).Return(true, nil).Once()
+
+		assert.NoError(t, service.PurgeSubscription(ctx, 1, nil))
+		assert.Equal(t, []string{models.EventSubscriptionDeleted}, service.events.(*recordingOutbox).eventTypes())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("trashed subscriptions were already reported as deleted", func(t *testing.T) {
+		service, mockRepo, mockTxMgr := setupTestService()
+		mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+		mockRepo.On("GetByIDUnscoped", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1)).Return(trashedSubscription(uuid.New(), time.Now()), nil).Once()
+		mockRepo.On("Purge", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1), 2).Return(true, nil).Once()
+
+		assert.NoError(t, service.PurgeSubscription(context.Background(), 1, nil))
+		assert.Empty(t, service.events.(*recordingOutbox).eventTypes())
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestListTrash_ReportsPurgeTime(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+
+	userID := uuid.New()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: userID})
+	deletedAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("ListDeleted", mock.Anything, mock.MatchedBy(func(filter *models.SubscriptionFilter) bool {
+		return *filter.UserID == userID
+	}), 20, 40).Return([]models.Subscription{*trashedSubscription(userID, deletedAt)}, nil).Once()
+
+	page, err := service.ListTrash(ctx, nil, models.PageRequest{Limit: 20, Offset: 40})
+
+	assert.NoError(t, err)
+	assert.Equal(t, deletedAt, page.Items[0].DeletedAt)
+	assert.Equal(t, time.Date(2025, time.March, 31, 12, 0, 0, 0, time.UTC), page.Items[0].PurgeAfter)
+	assert.Nil(t, page.Total)
+
+	_, err = service.ListTrash(ctx, nil, models.PageRequest{Cursor: "abc"})
+	assert.ErrorIs(t, err, errs.ErrValidation)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPurgeExpiredTrash_UsesRetentionAndAudits(t *testing.T) {
+	service, mockRepo, mockTxMgr := setupTestService()
+	now := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	userID := uuid.New()
+	expired := []models.Subscription{
+		{ID: 1, ServiceName: "Netflix", Price: 999, UserID: userID, StartDate: yearMonth("01-2025"), Version: 2},
+		{ID: 2, ServiceName: "Spotify", Price: 299, UserID: userID, StartDate: yearMonth("01-2025"), Version: 3},
+	}
+	mockTxMgr.On("Execute", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("ListDeletedBefore", mock.Anything, mock.AnythingOfType("*gorm.DB"), now.Add(-30*24*time.Hour)).Return(expired, nil).Once()
+	mockRepo.On("Purge", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(1), 2).Return(true, nil).Once()
+	// Restored since it was listed, so it is neither purged nor audited
+	mockRepo.On("Purge", mock.Anything, mock.AnythingOfType("*gorm.DB"), uint(2), 3).Return(false, nil).Once()
+
+	assert.NoError(t, service.PurgeExpiredTrash(context.Background()))
+
+	audit := service.audit.(*recordingAuditLog)
+	if assert.Len(t, audit.events, 1) {
+		assert.Equal(t, models.AuditActionPurge, audit.events[0].Action)
+		assert.Equal(t, uint(1), audit.events[0].SubscriptionID)
+		assert.Equal(t, models.AuditActorSystem, audit.events[0].Actor)
+		assert.Nil(t, audit.events[0].ActorUserID)
+		assert.Contains(t, string(audit.events[0].Changes), `"price":{"before":999,"after":null}`)
+	}
+	mockRepo.AssertExpectations(t)
+}
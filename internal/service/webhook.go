@@ -0,0 +1,399 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"subscription_tracker_api/internal/config"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"subscription_tracker_api/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// minWebhookSecretLength guards against trivially guessable signing keys
+const minWebhookSecretLength = 16
+
+// maxWebhookErrorLength bounds the response excerpt stored with a failed attempt
+const maxWebhookErrorLength = 512
+
+// WebhookService manages webhook endpoints and delivers outbox events to them
+type WebhookService struct {
+	repo   repository.WebhookRepositoryInterface
+	client *http.Client
+	cfg    config.WebhooksConfig
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+func NewWebhookService(repo repository.WebhookRepositoryInterface, cfg config.WebhooksConfig, logger *logrus.Logger) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// CreateWebhook registers an endpoint. The signing secret is only returned here.
+func (s *WebhookService) CreateWebhook(ctx context.Context, req *models.CreateWebhookRequest) (*models.WebhookEndpointWithSecret, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := parseEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			s.logger.WithError(err).Error("Failed to generate webhook secret")
+			return nil, errs.Internal("failed to generate webhook secret")
+		}
+	} else if len(secret) < minWebhookSecretLength {
+		return nil, errs.Validation("secret", errs.CodeInvalidInput, fmt.Sprintf("secret must be at least %d characters", minWebhookSecretLength))
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+	}
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		s.logger.WithError(err).Error("Failed to create webhook endpoint")
+		return nil, errs.Internal("failed to create webhook")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"webhook_id":  endpoint.ID,
+		"url":         endpoint.URL,
+		"event_types": []string(endpoint.EventTypes),
+	}).Info("Webhook endpoint created successfully")
+
+	return &models.WebhookEndpointWithSecret{WebhookEndpoint: *endpoint, Secret: secret}, nil
+}
+
+// GetWebhook retrieves a webhook endpoint by ID
+func (s *WebhookService) GetWebhook(ctx context.Context, id uint) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.NotFound(errs.CodeWebhookNotFound, "webhook not found")
+		}
+		s.logger.WithError(err).WithField("webhook_id", id).Error("Failed to retrieve webhook endpoint")
+		return nil, errs.Internal("failed to retrieve webhook")
+	}
+	return endpoint, nil
+}
+
+// ListWebhooks retrieves every webhook endpoint
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	endpoints, err := s.repo.ListEndpoints(ctx)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list webhook endpoints")
+		return nil, errs.Internal("failed to retrieve webhooks")
+	}
+	return endpoints, nil
+}
+
+// UpdateWebhook changes the URL, event types or active flag of an endpoint
+func (s *WebhookService) UpdateWebhook(ctx context.Context, id uint, req *models.UpdateWebhookRequest) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		endpoint.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := parseEventTypes(*req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.EventTypes = eventTypes
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		s.logger.WithError(err).WithField("webhook_id", id).Error("Failed to update webhook endpoint")
+		return nil, errs.Internal("failed to update webhook")
+	}
+
+	s.logger.WithField("webhook_id", id).Info("Webhook endpoint updated successfully")
+	return endpoint, nil
+}
+
+// DeleteWebhook removes an endpoint. Its pending deliveries are failed on their next attempt.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id uint) error {
+	deleted, err := s.repo.DeleteEndpoint(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).WithField("webhook_id", id).Error("Failed to delete webhook endpoint")
+		return errs.Internal("failed to delete webhook")
+	}
+	if !deleted {
+		return errs.NotFound(errs.CodeWebhookNotFound, "webhook not found")
+	}
+
+	s.logger.WithField("webhook_id", id).Info("Webhook endpoint deleted successfully")
+	return nil
+}
+
+// ListDeliveries retrieves the delivery log of an endpoint, newest first
+func (s *WebhookService) ListDeliveries(ctx context.Context, id uint, status *string, limit, offset int) ([]models.WebhookDelivery, error) {
+	if _, err := s.GetWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var statusFilter *models.WebhookDeliveryStatus
+	if status != nil {
+		parsed := models.WebhookDeliveryStatus(*status)
+		switch parsed {
+		case models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryFailed:
+			statusFilter = &parsed
+		default:
+			return nil, errs.Validation("status", errs.CodeInvalidInput, "status must be one of pending, succeeded, failed")
+		}
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, id, statusFilter, limit, offset)
+	if err != nil {
+		s.logger.WithError(err).WithField("webhook_id", id).Error("Failed to list webhook deliveries")
+		return nil, errs.Internal("failed to retrieve webhook deliveries")
+	}
+	return deliveries, nil
+}
+
+// DeliverPending fans new outbox events out to the subscribed endpoints and attempts every
+// delivery that is due. Failed attempts are retried with exponential backoff until the
+// configured number of attempts is exhausted.
+func (s *WebhookService) DeliverPending(ctx context.Context) error {
+	dispatched, err := s.repo.DispatchEvents(ctx, s.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	due, err := s.repo.ListDueDeliveries(ctx, s.now(), s.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	succeeded, failed := 0, 0
+	for i := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		delivery := &due[i]
+
+		// Lease the delivery for longer than a request may take, so a crashed worker's
+		// attempt is retried once the lease runs out
+		claimed, err := s.repo.ClaimDelivery(ctx, delivery, s.now().Add(2*s.cfg.Timeout))
+		if err != nil {
+			s.logger.WithError(err).WithField("delivery_id", delivery.ID).Error("Failed to claim webhook delivery")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		s.attempt(ctx, delivery)
+		if err := s.repo.SaveDeliveryAttempt(ctx, delivery); err != nil {
+			s.logger.WithError(err).WithField("delivery_id", delivery.ID).Error("Failed to save webhook delivery attempt")
+			continue
+		}
+		if delivery.Status == models.WebhookDeliverySucceeded {
+			succeeded++
+		} else {
+			failed++
+		}
+	}
+
+	if dispatched > 0 || len(due) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"dispatched_events": dispatched,
+			"succeeded":         succeeded,
+			"failed":            failed,
+		}).Info("Webhook deliveries processed successfully")
+	}
+
+	return nil
+}
+
+// attempt posts the event to the endpoint and records the outcome on the delivery
+func (s *WebhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	now := s.now()
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = nil
+	delivery.LastError = nil
+
+	if delivery.Endpoint == nil || delivery.Event == nil {
+		s.fail(delivery, "webhook endpoint was deleted", true)
+		return
+	}
+	if !delivery.Endpoint.Active {
+		s.fail(delivery, "webhook endpoint is inactive", true)
+		return
+	}
+
+	statusCode, err := s.post(ctx, delivery.Endpoint, delivery.Event, now)
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+	if err != nil {
+		s.fail(delivery, err.Error(), false)
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"delivery_id": delivery.ID,
+			"webhook_id":  delivery.EndpointID,
+			"attempts":    delivery.Attempts,
+		}).Warn("Webhook delivery attempt failed")
+		return
+	}
+
+	delivery.Status = models.WebhookDeliverySucceeded
+	delivery.DeliveredAt = &now
+}
+
+// fail records a failed attempt and schedules the retry, or gives up when the attempts
+// are exhausted or retrying cannot help
+func (s *WebhookService) fail(delivery *models.WebhookDelivery, reason string, permanent bool) {
+	delivery.LastError = &reason
+	if permanent || delivery.Attempts >= s.cfg.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		return
+	}
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.NextAttemptAt = s.now().Add(s.backoff(delivery.Attempts))
+}
+
+// backoff is the delay after the given number of attempts: InitialBackoff doubled after
+// every further attempt, capped at MaxBackoff
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.cfg.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= s.cfg.MaxBackoff {
+			return s.cfg.MaxBackoff
+		}
+	}
+	return min(delay, s.cfg.MaxBackoff)
+}
+
+// post sends the signed payload and returns the response status code
+func (s *WebhookService) post(ctx context.Context, endpoint *models.WebhookEndpoint, event *models.WebhookEvent, now time.Time) (int, error) {
+	body, err := json.Marshal(models.WebhookPayload{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, strconv.FormatUint(uint64(event.ID), 10))
+	req.Header.Set(WebhookEventHeader, event.EventType)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(endpoint.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorLength))
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d: %s", resp.StatusCode, excerpt)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload computes the X-Webhook-Signature header value: the hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the endpoint secret. Receivers should
+// recompute it and reject stale timestamps to prevent replays.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateWebhookURL accepts absolute http and https URLs
+func validateWebhookURL(rawURL string) error {
+	if rawURL == "" {
+		return errs.Validation("url", errs.CodeRequired, "url is required")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errs.Validation("url", errs.CodeInvalidURL, "url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// parseEventTypes validates the event types an endpoint subscribes to, dropping duplicates
+func parseEventTypes(eventTypes []string) (models.StringList, error) {
+	parsed := models.StringList{}
+	seen := make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		known := false
+		for _, t := range models.EventTypes {
+			if t == eventType {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, errs.Validation("event_types", errs.CodeInvalidEventType, fmt.Sprintf("unknown event type %q", eventType))
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			parsed = append(parsed, eventType)
+		}
+	}
+	return parsed, nil
+}
+
+// generateWebhookSecret returns a random 256-bit hex encoded signing key
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
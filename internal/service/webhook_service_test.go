@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"subscription_tracker_api/internal/config"
+	"subscription_tracker_api/internal/errs"
+	"subscription_tracker_api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockWebhookRepository for testing webhook management and delivery
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	args := m.Called(ctx, endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetEndpoint(ctx context.Context, id uint) (*models.WebhookEndpoint, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	args := m.Called(ctx, endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) DeleteEndpoint(ctx context.Context, id uint) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RecordEvent(ctx context.Context, tx *gorm.DB, event *models.WebhookEvent) error {
+	args := m.Called(ctx, tx, event)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) DispatchEvents(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDelivery(ctx context.Context, delivery *models.WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	args := m.Called(ctx, delivery, leaseUntil)
+	if args.Bool(0) {
+		delivery.Attempts++
+		delivery.NextAttemptAt = leaseUntil
+	}
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) SaveDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, endpointID uint, status *models.WebhookDeliveryStatus, limit, offset int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, endpointID, status, limit, offset)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+var webhookNow = time.Date(2025, time.July, 20, 12, 0, 0, 0, time.UTC)
+
+func setupWebhookService() (*WebhookService, *MockWebhookRepository) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	mockRepo := &MockWebhookRepository{}
+	service := NewWebhookService(mockRepo, config.WebhooksConfig{
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     90 * time.Second,
+		Timeout:        time.Second,
+		BatchSize:      10,
+	}, logger)
+	service.now = func() time.Time { return webhookNow }
+	return service, mockRepo
+}
+
+func newDueDelivery(url string, attempts int) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:         7,
+		EventID:    42,
+		EndpointID: 3,
+		Status:     models.WebhookDeliveryPending,
+		Attempts:   attempts,
+		Event: &models.WebhookEvent{
+			ID:        42,
+			EventType: models.EventSubscriptionCreated,
+			Payload:   models.RawJSON(`{"id":1,"service_name":"Netflix"}`),
+			CreatedAt: webhookNow.Add(-time.Minute),
+		},
+		Endpoint: &models.WebhookEndpoint{ID: 3, URL: url, Secret: "0123456789abcdef", Active: true},
+	}
+}
+
+func expectDue(mockRepo *MockWebhookRepository, delivery models.WebhookDelivery) {
+	mockRepo.On("DispatchEvents", mock.Anything, 10).Return(1, nil)
+	mockRepo.On("ListDueDeliveries", mock.Anything, webhookNow, 10).Return([]models.WebhookDelivery{delivery}, nil)
+	mockRepo.On("ClaimDelivery", mock.Anything, mock.Anything, webhookNow.Add(2*time.Second)).Return(true, nil)
+}
+
+func TestDeliverPending_SignsAndDeliversPayload(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	service, mockRepo := setupWebhookService()
+	expectDue(mockRepo, newDueDelivery(server.URL, 0))
+
+	var saved *models.WebhookDelivery
+	mockRepo.On("SaveDeliveryAttempt", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*models.WebhookDelivery)
+	}).Return(nil)
+
+	assert.NoError(t, service.DeliverPending(context.Background()))
+
+	timestamp := strconv.FormatInt(webhookNow.Unix(), 10)
+	assert.Equal(t, "42", received.Header.Get(WebhookIDHeader))
+	assert.Equal(t, models.EventSubscriptionCreated, received.Header.Get(WebhookEventHeader))
+	assert.Equal(t, timestamp, received.Header.Get(WebhookTimestampHeader))
+	assert.Equal(t, SignWebhookPayload("0123456789abcdef", webhookNow.Unix(), body), received.Header.Get(WebhookSignatureHeader))
+	assert.JSONEq(t, `{"id":42,"type":"subscription.created","created_at":"2025-07-20T11:59:00Z","data":{"id":1,"service_name":"Netflix"}}`, string(body))
+
+	assert.Equal(t, models.WebhookDeliverySucceeded, saved.Status)
+	assert.Equal(t, 1, saved.Attempts)
+	assert.Equal(t, http.StatusNoContent, *saved.LastStatusCode)
+	assert.Equal(t, webhookNow, *saved.DeliveredAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeliverPending_RetriesWithExponentialBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream down", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name            string
+		attempts        int
+		expectedStatus  models.WebhookDeliveryStatus
+		expectedNextRun time.Time
+	}{
+		{name: "first failure waits the initial backoff", attempts: 0, expectedStatus: models.WebhookDeliveryPending, expectedNextRun: webhookNow.Add(time.Minute)},
+		{name: "backoff doubles and is capped", attempts: 1, expectedStatus: models.WebhookDeliveryPending, expectedNextRun: webhookNow.Add(90 * time.Second)},
+		{name: "gives up after max attempts", attempts: 2, expectedStatus: models.WebhookDeliveryFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, mockRepo := setupWebhookService()
+			expectDue(mockRepo, newDueDelivery(server.URL, tc.attempts))
+
+			var saved *models.WebhookDelivery
+			mockRepo.On("SaveDeliveryAttempt", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*models.WebhookDelivery)
+			}).Return(nil)
+
+			assert.NoError(t, service.DeliverPending(context.Background()))
+
+			assert.Equal(t, tc.expectedStatus, saved.Status)
+			assert.Equal(t, http.StatusBadGateway, *saved.LastStatusCode)
+			assert.Contains(t, *saved.LastError, "upstream down")
+			assert.Nil(t, saved.DeliveredAt)
+			if tc.expectedStatus == models.WebhookDeliveryPending {
+				assert.Equal(t, tc.expectedNextRun, saved.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestDeliverPending_DeletedEndpointFailsWithoutRetry(t *testing.T) {
+	service, mockRepo := setupWebhookService()
+
+	delivery := newDueDelivery("http://unused.invalid", 0)
+	delivery.Endpoint = nil
+	expectDue(mockRepo, delivery)
+	mockRepo.On("SaveDeliveryAttempt", mock.Anything, mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.Status == models.WebhookDeliveryFailed && d.LastStatusCode == nil
+	})).Return(nil)
+
+	assert.NoError(t, service.DeliverPending(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateWebhook_GeneratesSecret(t *testing.T) {
+	service, mockRepo := setupWebhookService()
+
+	mockRepo.On("CreateEndpoint", mock.Anything, mock.AnythingOfType("*models.WebhookEndpoint")).Return(nil)
+
+	webhook, err := service.CreateWebhook(context.Background(), &models.CreateWebhookRequest{
+		URL:        "https://billing.example.com/hooks",
+		EventTypes: []string{models.EventSubscriptionCreated, models.EventSubscriptionCreated},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, webhook.Secret, 64)
+	assert.Equal(t, webhook.Secret, webhook.WebhookEndpoint.Secret)
+	assert.Equal(t, models.StringList{models.EventSubscriptionCreated}, webhook.EventTypes)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateWebhook_ValidationErrors(t *testing.T) {
+	service, _ := setupWebhookService()
+
+	testCases := []struct {
+		name          string
+		req           models.CreateWebhookRequest
+		expectedField string
+	}{
+		{name: "missing url", req: models.CreateWebhookRequest{}, expectedField: "url"},
+		{name: "relative url", req: models.CreateWebhookRequest{URL: "/hooks"}, expectedField: "url"},
+		{name: "unsupported scheme", req: models.CreateWebhookRequest{URL: "ftp://example.com"}, expectedField: "url"},
+		{name: "unknown event type", req: models.CreateWebhookRequest{URL: "https://example.com", EventTypes: []string{"subscription.renamed"}}, expectedField: "event_types"},
+		{name: "short secret", req: models.CreateWebhookRequest{URL: "https://example.com", Secret: "short"}, expectedField: "secret"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhook, err := service.CreateWebhook(context.Background(), &tc.req)
+
+			assert.Nil(t, webhook)
+			assert.ErrorIs(t, err, errs.ErrValidation)
+			assert.Equal(t, tc.expectedField, err.(*errs.Error).Field)
+		})
+	}
+}
+
+func TestGetWebhook_NotFound(t *testing.T) {
+	service, mockRepo := setupWebhookService()
+
+	mockRepo.On("GetEndpoint", mock.Anything, uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	webhook, err := service.GetWebhook(context.Background(), 9)
+
+	assert.Nil(t, webhook)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+	assert.Equal(t, errs.CodeWebhookNotFound, err.(*errs.Error).Code)
+}